@@ -3,6 +3,7 @@ package gophers
 import (
 	"fmt"
 	"sort"
+	"strings"
 )
 
 // Agg packs aggregations into a slice for GroupBy.
@@ -30,10 +31,87 @@ func Agg(items ...interface{}) []Aggregation {
     }
     return aggs
 }
+
+// Alias names the output column of the aggregation, so several aggregations
+// can read the same source column, e.g. Mean("x").Alias("x_mean").
+func (a Aggregation) Alias(name string) Aggregation {
+	a.OutputName = name
+	return a
+}
+
+// outputName returns the result column name of the aggregation.
+func (a Aggregation) outputName() string {
+	if a.OutputName != "" {
+		return a.OutputName
+	}
+	return a.ColumnName
+}
+
+// groupDates returns the date columns (see DataFrame.Dates) of a GroupBy
+// result with columns names from outputNames, given the input's dates: the
+// date keys and the aggregations that keep a date column's values.
+func groupDates(dates map[string]bool, keys []string, aggs []Aggregation, names []string) map[string]bool {
+	var out map[string]bool
+	mark := func(name string) {
+		if out == nil {
+			out = make(map[string]bool)
+		}
+		out[name] = true
+	}
+	for _, k := range keys {
+		if dates[k] {
+			mark(k)
+		}
+	}
+	for j, agg := range aggs {
+		if dates[agg.ColumnName] && agg.keepsDate() {
+			mark(names[len(keys)+j])
+		}
+	}
+	return out
+}
+
+// outputNames returns the GroupBy result columns: the keys, then one name per
+// aggregation. An unaliased aggregation keeps its column name unless a key, an
+// alias or another aggregation would also use it; then it is named after its
+// function, so Sum("x") with Mean("x") gives "sum_x" and "mean_x" in either
+// order. A name still taken gets a numeric suffix, so no result overwrites
+// another.
+func outputNames(keys []string, aggs []Aggregation) []string {
+	uses := make(map[string]int, len(keys)+len(aggs))
+	seen := make(map[string]bool, len(keys)+len(aggs))
+	for _, k := range keys {
+		uses[k]++
+		seen[k] = true
+	}
+	for _, a := range aggs {
+		uses[a.outputName()]++
+	}
+	names := make([]string, len(aggs))
+	// aliases are claimed first so they never depend on where they appear
+	for i, a := range aggs {
+		if a.OutputName != "" {
+			names[i] = uniqueName(seen, a.OutputName)
+		}
+	}
+	for i, a := range aggs {
+		if a.OutputName != "" {
+			continue
+		}
+		name := a.ColumnName
+		if uses[name] > 1 && a.kind != "" {
+			name = a.kind + "_" + name
+		}
+		names[i] = uniqueName(seen, name)
+	}
+	return append(append(make([]string, 0, len(keys)+len(aggs)), keys...), names...)
+}
+
 // Sum returns an Aggregation that sums numeric values from the specified column.
 func Sum(name string) Aggregation {
 	return Aggregation{
 		ColumnName: name,
+		kind:       "sum",
 		Fn: func(vals []interface{}) interface{} {
 			sum := 0.0
 			for _, v := range vals {
@@ -49,10 +127,17 @@ func Sum(name string) Aggregation {
 	}
 }
 
+// keepsDate reports whether the aggregation gives values of its column as
+// they are, so it is a date column when its column is.
+func (a Aggregation) keepsDate() bool {
+	return a.kind == "first"
+}
+
 // Max returns an Aggregation that finds the maximum numeric value from the specified column.
 func Max(name string) Aggregation {
 	return Aggregation{
 		ColumnName: name,
+		kind:       "max",
 		Fn: func(vals []interface{}) interface{} {
 			maxSet := false
 			var max float64
@@ -79,6 +164,7 @@ func Max(name string) Aggregation {
 func Min(name string) Aggregation {
 	return Aggregation{
 		ColumnName: name,
+		kind:       "min",
 		Fn: func(vals []interface{}) interface{} {
 			minSet := false
 			var min float64
@@ -105,6 +191,7 @@ func Min(name string) Aggregation {
 func Median(name string) Aggregation {
 	return Aggregation{
 		ColumnName: name,
+		kind:       "median",
 		Fn: func(vals []interface{}) interface{} {
 			var nums []float64
 			for _, v := range vals {
@@ -139,6 +226,7 @@ func Median(name string) Aggregation {
 func Mean(name string) Aggregation {
 	return Aggregation{
 		ColumnName: name,
+		kind:       "mean",
 		Fn: func(vals []interface{}) interface{} {
 			sum := 0.0
 			count := 0
@@ -163,6 +251,7 @@ func Mean(name string) Aggregation {
 func Mode(name string) Aggregation {
 	return Aggregation{
 		ColumnName: name,
+		kind:       "mode",
 		Fn: func(vals []interface{}) interface{} {
 			// Use a map to count frequencies.
 			freq := make(map[float64]int)
@@ -194,6 +283,7 @@ func Mode(name string) Aggregation {
 func Unique(name string) Aggregation {
 	return Aggregation{
 		ColumnName: name,
+		kind:       "unique",
 		Fn: func(vals []interface{}) interface{} {
 			uniqueSet := make(map[interface{}]bool)
 			for _, v := range vals {
@@ -208,6 +298,7 @@ func Unique(name string) Aggregation {
 func First(name string) Aggregation {
 	return Aggregation{
 		ColumnName: name,
+		kind:       "first",
 		Fn: func(vals []interface{}) interface{} {
 			if len(vals) == 0 {
 				return nil
@@ -221,6 +312,7 @@ func First(name string) Aggregation {
 func CollectList(name string) Aggregation {
     return Aggregation{
         ColumnName: name,
+        kind:       "collectlist",
         Fn: func(vals []interface{}) interface{} {
             out := make([]interface{}, len(vals))
             copy(out, vals)
@@ -233,6 +325,7 @@ func CollectList(name string) Aggregation {
 func CollectSet(name string) Aggregation {
     return Aggregation{
         ColumnName: name,
+        kind:       "collectset",
         Fn: func(vals []interface{}) interface{} {
             seen := make(map[interface{}]struct{}, len(vals))
             out := make([]interface{}, 0, len(vals))
@@ -244,4 +337,30 @@ func CollectSet(name string) Aggregation {
             return out
         },
     }
-}
\ No newline at end of file
+}
+// aggregationByName returns the built-in aggregation called name ("sum", "Mean", ...) over col.
+func aggregationByName(name, col string) (Aggregation, bool) {
+	switch strings.ToLower(name) {
+	case "sum":
+		return Sum(col), true
+	case "max":
+		return Max(col), true
+	case "min":
+		return Min(col), true
+	case "median":
+		return Median(col), true
+	case "mean", "avg":
+		return Mean(col), true
+	case "mode":
+		return Mode(col), true
+	case "unique":
+		return Unique(col), true
+	case "first":
+		return First(col), true
+	case "collectlist":
+		return CollectList(col), true
+	case "collectset":
+		return CollectSet(col), true
+	}
+	return Aggregation{}, false
+}
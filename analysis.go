@@ -30,7 +30,7 @@ type modalKind int
 
 const (
 	modalNone        modalKind = iota
-	modalDFConfig              // DF panel: delete df or back
+	modalDFConfig              // DF panel: describe df, delete df or back
 	modalHistoryOpts           // History panel: full command, delete or back
 	modalRowDetail             // Table panel: row columns, selectable for full value
 	modalColValue              // Full column value view from row detail
@@ -113,7 +113,7 @@ type analysisModel struct {
 
 func newAnalysisModel() analysisModel {
 	ta := textarea.New()
-	ta.Placeholder = `Go code: df := ReadJSON("path"), df.Select("col"), df.Filter(Col("x").Gt(5))`
+	ta.Placeholder = `Go code: df, err := ReadJSON("path"), df.Select("col"), df.Filter(Col("x").Gt(5))`
 	ta.ShowLineNumbers = false
 	ta.Prompt = "» "
 	ta.SetHeight(1)
@@ -134,12 +134,16 @@ func newAnalysisModel() analysisModel {
 			ReadNDJSON      = gophers.ReadNDJSON
 			ReadYAML        = gophers.ReadYAML
 			ReadParquet     = gophers.ReadParquet
+			ReadXLSX        = gophers.ReadXLSX
 			ReadHTML        = gophers.ReadHTML
 			ReadHTMLTop     = gophers.ReadHTMLTop
 			ReadSqlite      = gophers.ReadSqlite
 			GetAPI          = gophers.GetAPI
 			SqliteSQL       = gophers.SqliteSQL
 			CloneJSON       = gophers.CloneJSON
+			ScanCSV         = gophers.ScanCSV
+			ScanNDJSON      = gophers.ScanNDJSON
+			ScanSqlite      = gophers.ScanSqlite
 			Col             = gophers.Col
 			Lit             = gophers.Lit
 			Concat          = gophers.Concat
@@ -153,6 +157,10 @@ func newAnalysisModel() analysisModel {
 			If              = gophers.If
 			Or              = gophers.Or
 			And             = gophers.And
+			Not             = gophers.Not
+			Greatest        = gophers.Greatest
+			Least           = gophers.Least
+			Coalesce        = gophers.Coalesce
 			Agg             = gophers.Agg
 			Sum             = gophers.Sum
 			Max             = gophers.Max
@@ -164,7 +172,18 @@ func newAnalysisModel() analysisModel {
 			First           = gophers.First
 			CollectList     = gophers.CollectList
 			CollectSet      = gophers.CollectSet
+			Window          = gophers.Window
+			RowNumber       = gophers.RowNumber
+			Rank            = gophers.Rank
+			DenseRank       = gophers.DenseRank
+			Lag             = gophers.Lag
+			Lead            = gophers.Lead
 			CreateReport    = gophers.CreateReport
+			Help            = gophers.Help
+			RenderReport    = gophers.RenderReport
+			SQL             = gophers.SQL
+			ReadReportSpec  = gophers.ReadReportSpec
+			WriteXLSX       = gophers.WriteXLSX
 			ConnectLLM      = gophers.ConnectLLM
 			CustomLLM       = gophers.CustomLLM
 		)
@@ -806,8 +825,8 @@ func (m analysisModel) updateModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	switch m.modal {
 	case modalDFConfig:
-		// Options: 0=Delete, 1=Back
-		optCount := 2
+		// Options: 0=Describe, 1=Delete, 2=Back
+		optCount := 3
 		if key.Matches(msg, m.keys.Up) {
 			if m.modalCursor > 0 {
 				m.modalCursor--
@@ -820,7 +839,10 @@ func (m analysisModel) updateModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		if isConfirm {
 			switch m.modalCursor {
-			case 0: // Delete DataFrame
+			case 0: // Describe DataFrame
+				m.modal = modalNone
+				m.describeDF(m.modalIdx)
+			case 1: // Delete DataFrame
 				idx := m.modalIdx
 				if idx >= 0 && idx < len(m.dfs) {
 					m.dfs = append(m.dfs[:idx], m.dfs[idx+1:]...)
@@ -838,7 +860,7 @@ func (m analysisModel) updateModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					m.rebuildTable()
 				}
 				m.modal = modalNone
-			case 1: // Back
+			case 2: // Back
 				m.modal = modalNone
 			}
 		}
@@ -1058,8 +1080,9 @@ func (m analysisModel) renderModal() string {
 		var lines []string
 		lines = append(lines, title)
 		lines = append(lines, "")
-		lines = append(lines, renderOption(0, m.modalCursor, "Delete DataFrame"))
-		lines = append(lines, renderOption(1, m.modalCursor, "Back"))
+		lines = append(lines, renderOption(0, m.modalCursor, "Describe"))
+		lines = append(lines, renderOption(1, m.modalCursor, "Delete DataFrame"))
+		lines = append(lines, renderOption(2, m.modalCursor, "Back"))
 
 		return modalBorder.Render(strings.Join(lines, "\n"))
 
@@ -1262,6 +1285,38 @@ func (m *analysisModel) evalCommand(input string) {
 	m.syncDataFrames()
 }
 
+// describeDF runs Describe on the DataFrame at idx as a command, so the summary
+// shows up in the history and the DataFrame list as <name>_describe, and
+// selects it in the table.
+func (m *analysisModel) describeDF(idx int) {
+	if idx < 0 || idx >= len(m.names) {
+		return
+	}
+	target := m.names[idx] + "_describe"
+	op := ":="
+	for _, v := range m.trackedVars {
+		if v == target {
+			op = "="
+		}
+	}
+	input := fmt.Sprintf("%s %s %s.Describe()", target, op, m.names[idx])
+	m.history = append(m.history, input)
+	m.histSel = len(m.history) - 1
+	if m.histSel >= m.historyHeight-1 {
+		m.histOffset = m.histSel - (m.historyHeight - 2)
+	}
+	m.evalCommand(input)
+	for i, name := range m.names {
+		if name == target {
+			m.selected = i
+			m.dfListSel = i
+			m.focus = 2
+			m.textarea.Blur()
+		}
+	}
+	m.rebuildTable()
+}
+
 // syncDataFrames scans all tracked variable names in the interpreter,
 // checks if they are *DataFrame, and updates the TUI DF list accordingly.
 func (m *analysisModel) syncDataFrames() {
@@ -18,9 +18,26 @@ func gophersSymbols() interp.Exports {
 			"Aggregation":       reflect.ValueOf((*Aggregation)(nil)),
 			"SimpleAggregation": reflect.ValueOf((*SimpleAggregation)(nil)),
 			"Chart":             reflect.ValueOf((*Chart)(nil)),
+			"ChartOptions":      reflect.ValueOf((*ChartOptions)(nil)),
+			"ChartSpec":         reflect.ValueOf((*ChartSpec)(nil)),
+			"ChartSpecAgg":      reflect.ValueOf((*ChartSpecAgg)(nil)),
 			"Report":            reflect.ValueOf((*Report)(nil)),
 			"LLM":               reflect.ValueOf((*LLM)(nil)),
 			"ColumnSchema":      reflect.ValueOf((*ColumnSchema)(nil)),
+			"ParquetOptions":    reflect.ValueOf((*ParquetOptions)(nil)),
+			"CSVOptions":        reflect.ValueOf((*CSVOptions)(nil)),
+			"SaveOptions":       reflect.ValueOf((*SaveOptions)(nil)),
+			"ReportSpec":        reflect.ValueOf((*ReportSpec)(nil)),
+			"ReportPageSpec":    reflect.ValueOf((*ReportPageSpec)(nil)),
+			"ReportBlockSpec":   reflect.ValueOf((*ReportBlockSpec)(nil)),
+			"ReportChartSpec":   reflect.ValueOf((*ReportChartSpec)(nil)),
+			"ReportPage":        reflect.ValueOf((*ReportPage)(nil)),
+			"ReportBlock":       reflect.ValueOf((*ReportBlock)(nil)),
+			"XLSXSheet":         reflect.ValueOf((*XLSXSheet)(nil)),
+			"LazyFrame":         reflect.ValueOf((*LazyFrame)(nil)),
+			"BatchIterator":     reflect.ValueOf((*BatchIterator)(nil)),
+			"WindowSpec":        reflect.ValueOf((*WindowSpec)(nil)),
+			"WindowColumn":      reflect.ValueOf((*WindowColumn)(nil)),
 			"AggregatorFn":      reflect.ValueOf((*AggregatorFn)(nil)),
 
 			// DataFrame creation / source functions
@@ -30,12 +47,16 @@ func gophersSymbols() interp.Exports {
 			"ReadNDJSON":  reflect.ValueOf(ReadNDJSON),
 			"ReadYAML":    reflect.ValueOf(ReadYAML),
 			"ReadParquet":  reflect.ValueOf(ReadParquet),
+			"ReadXLSX":     reflect.ValueOf(ReadXLSX),
 			"ReadHTML":     reflect.ValueOf(ReadHTML),
 			"ReadHTMLTop":  reflect.ValueOf(ReadHTMLTop),
 			"ReadSqlite":   reflect.ValueOf(ReadSqlite),
 			"GetAPI":       reflect.ValueOf(GetAPI),
 			"SqliteSQL":    reflect.ValueOf(SqliteSQL),
 			"CloneJSON":    reflect.ValueOf(CloneJSON),
+			"ScanCSV":      reflect.ValueOf(ScanCSV),
+			"ScanNDJSON":   reflect.ValueOf(ScanNDJSON),
+			"ScanSqlite":   reflect.ValueOf(ScanSqlite),
 
 			// Column / expression functions
 			"Col":              reflect.ValueOf(Col),
@@ -51,6 +72,10 @@ func gophersSymbols() interp.Exports {
 			"If":               reflect.ValueOf(If),
 			"Or":               reflect.ValueOf(Or),
 			"And":              reflect.ValueOf(And),
+			"Not":              reflect.ValueOf(Not),
+			"Greatest":         reflect.ValueOf(Greatest),
+			"Least":            reflect.ValueOf(Least),
+			"Coalesce":         reflect.ValueOf(Coalesce),
 
 			// Aggregation functions
 			"Agg":         reflect.ValueOf(Agg),
@@ -65,6 +90,14 @@ func gophersSymbols() interp.Exports {
 			"CollectList":  reflect.ValueOf(CollectList),
 			"CollectSet":   reflect.ValueOf(CollectSet),
 
+			// Window functions
+			"Window":    reflect.ValueOf(Window),
+			"RowNumber": reflect.ValueOf(RowNumber),
+			"Rank":      reflect.ValueOf(Rank),
+			"DenseRank": reflect.ValueOf(DenseRank),
+			"Lag":       reflect.ValueOf(Lag),
+			"Lead":      reflect.ValueOf(Lead),
+
 			// SQLite helpers
 			"ListSqliteTables":    reflect.ValueOf(ListSqliteTables),
 			"GetSqliteSchema":     reflect.ValueOf(GetSqliteSchema),
@@ -74,6 +107,11 @@ func gophersSymbols() interp.Exports {
 
 			// Report / display / misc
 			"CreateReport": reflect.ValueOf(CreateReport),
+			"Help":         reflect.ValueOf(Help),
+			"RenderReport": reflect.ValueOf(RenderReport),
+			"SQL":          reflect.ValueOf(SQL),
+			"ReadReportSpec": reflect.ValueOf(ReadReportSpec),
+			"WriteXLSX":    reflect.ValueOf(WriteXLSX),
 			"DisplayChart": reflect.ValueOf(DisplayChart),
 			"DisplayHTML":  reflect.ValueOf(DisplayHTML),
 			"QuoteArray":   reflect.ValueOf(QuoteArray),
@@ -0,0 +1,110 @@
+package gophers
+
+//go:generate go run assets_fetch.go
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// assetFS holds the pinned front-end assets listed in assets/assets.json.
+//
+//go:embed assets
+var assetFS embed.FS
+
+// offlineAsset is one entry of assets/assets.json.
+type offlineAsset struct {
+	File        string   `json:"file"`
+	URL         string   `json:"url"`
+	Replaces    []string `json:"replaces"`
+	InlineFonts bool     `json:"inline_fonts"`
+}
+
+var (
+	offlineAssetsOnce  sync.Once
+	offlineAssetsByURL map[string]offlineAsset
+	offlineAssetsErr   error
+)
+
+// offlineAssets returns the manifest entries keyed by the CDN URL they replace.
+func offlineAssets() (map[string]offlineAsset, error) {
+	offlineAssetsOnce.Do(func() {
+		b, err := assetFS.ReadFile("assets/assets.json")
+		if err != nil {
+			offlineAssetsErr = fmt.Errorf("offline assets: %v", err)
+			return
+		}
+		var list []offlineAsset
+		if err := json.Unmarshal(b, &list); err != nil {
+			offlineAssetsErr = fmt.Errorf("offline assets: invalid manifest: %v", err)
+			return
+		}
+		offlineAssetsByURL = make(map[string]offlineAsset)
+		for _, a := range list {
+			for _, u := range a.Replaces {
+				offlineAssetsByURL[u] = a
+			}
+		}
+	})
+	return offlineAssetsByURL, offlineAssetsErr
+}
+
+var (
+	assetScriptTag = regexp.MustCompile(`<script src="([^"]+)"></script>`)
+	assetLinkTag   = regexp.MustCompile(`<link\s[^>]*rel="stylesheet"[^>]*>`)
+	assetLinkHref  = regexp.MustCompile(`href="([^"]+)"`)
+)
+
+// inlineAssets replaces the CDN <script src> and stylesheet <link> tags in html
+// with inline <script> and <style> elements holding the embedded copies, so the
+// page renders without network access. It returns an error listing any asset
+// that is referenced but not bundled.
+func inlineAssets(html string) (string, error) {
+	assets, err := offlineAssets()
+	if err != nil {
+		return "", err
+	}
+	var missing []string
+	load := func(url string) (string, bool) {
+		a, ok := assets[url]
+		if !ok {
+			missing = append(missing, url)
+			return "", false
+		}
+		b, err := assetFS.ReadFile(path.Join("assets", a.File))
+		if err != nil {
+			missing = append(missing, a.File)
+			return "", false
+		}
+		return string(b), true
+	}
+
+	html = assetScriptTag.ReplaceAllStringFunc(html, func(tag string) string {
+		src, ok := load(assetScriptTag.FindStringSubmatch(tag)[1])
+		if !ok {
+			return tag
+		}
+		return "<script>" + strings.ReplaceAll(src, "</script", `<\/script`) + "</script>"
+	})
+	html = assetLinkTag.ReplaceAllStringFunc(html, func(tag string) string {
+		m := assetLinkHref.FindStringSubmatch(tag)
+		if m == nil {
+			return tag
+		}
+		css, ok := load(m[1])
+		if !ok {
+			return tag
+		}
+		return "<style>" + strings.ReplaceAll(css, "</style", `<\/style`) + "</style>"
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("offline assets not bundled: %s (run go generate to fetch them)", strings.Join(missing, ", "))
+	}
+	return html, nil
+}
@@ -0,0 +1,94 @@
+//go:build ignore
+
+// assets_fetch downloads the pinned front-end assets listed in
+// assets/assets.json into the assets directory for offline reports.
+//
+//	go generate github.com/speartech/gophers
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type asset struct {
+	File        string   `json:"file"`
+	URL         string   `json:"url"`
+	Replaces    []string `json:"replaces"`
+	InlineFonts bool     `json:"inline_fonts"`
+}
+
+// fonts.googleapis.com picks the font format from the user agent; ask for woff2.
+const userAgent = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+
+var fontURL = regexp.MustCompile(`url\((https://[^)]+)\)`)
+
+func main() {
+	b, err := os.ReadFile(filepath.Join("assets", "assets.json"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var assets []asset
+	if err := json.Unmarshal(b, &assets); err != nil {
+		log.Fatalf("assets.json: %v", err)
+	}
+	for _, a := range assets {
+		body, err := fetch(a.URL)
+		if err != nil {
+			log.Fatalf("%s: %v", a.File, err)
+		}
+		if a.InlineFonts {
+			if body, err = inlineFonts(body); err != nil {
+				log.Fatalf("%s: %v", a.File, err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join("assets", a.File), body, 0644); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s (%d bytes)\n", a.File, len(body))
+	}
+}
+
+func fetch(url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// inlineFonts replaces the font URLs in a stylesheet with data URIs.
+func inlineFonts(css []byte) ([]byte, error) {
+	var ferr error
+	out := fontURL.ReplaceAllStringFunc(string(css), func(m string) string {
+		url := fontURL.FindStringSubmatch(m)[1]
+		font, err := fetch(url)
+		if err != nil {
+			ferr = err
+			return m
+		}
+		mime := "font/woff2"
+		if strings.HasSuffix(url, ".ttf") {
+			mime = "font/ttf"
+		}
+		return "url(data:" + mime + ";base64," + base64.StdEncoding.EncodeToString(font) + ")"
+	})
+	return []byte(out), ferr
+}
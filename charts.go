@@ -3,176 +3,88 @@ package gophers
 import (
 	"encoding/json"
 	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // BarChart returns Bar Chart HTML for the DataFrame.
-// It takes a title, subtitle, group column, and one or more aggregations.
-func (df *DataFrame) BarChart(title string, subtitle string, groupcol string, aggs []Aggregation) Chart {
+// It takes a title, subtitle, group column, one or more aggregations and optional ChartOptions.
+func (df *DataFrame) BarChart(title string, subtitle string, groupcol string, aggs []Aggregation, opts ...ChartOptions) Chart {
 	// Group the DataFrame by the specified column and apply the aggregations.
-	df = df.GroupBy(groupcol, aggs...)
-	// df.Show(25)
-
-	// Extract categories and series data.
-	categories := []string{}
-	for _, val := range df.Data[groupcol] {
-		categories = append(categories, fmt.Sprintf("%v", val))
-	}
-
-	series := []map[string]interface{}{}
-	for _, agg := range aggs {
-		data := []interface{}{}
-		for _, val := range df.Data[agg.ColumnName] {
-			data = append(data, val)
-		}
-		series = append(series, map[string]interface{}{
-			"name": agg.ColumnName,
-			"data": data,
-		})
+	categories, series := df.aggregatedSeries(groupcol, aggs)
+
+	config := map[string]interface{}{
+		"chart":    map[string]interface{}{"type": "bar"},
+		"title":    map[string]interface{}{"text": title},
+		"subtitle": map[string]interface{}{"text": subtitle},
+		"xAxis": map[string]interface{}{
+			"categories":    categories,
+			"title":         map[string]interface{}{"text": groupcol},
+			"gridLineWidth": 1,
+			"lineWidth":     0,
+		},
+		"yAxis": map[string]interface{}{
+			"min":           0,
+			"title":         map[string]interface{}{"text": "", "align": "middle"},
+			"labels":        map[string]interface{}{"overflow": "justify"},
+			"gridLineWidth": 0,
+		},
+		"tooltip": map[string]interface{}{"valueSuffix": ""},
+		"plotOptions": map[string]interface{}{
+			"bar": map[string]interface{}{
+				"borderRadius": "50%",
+				"dataLabels":   map[string]interface{}{"enabled": true},
+				"groupPadding": 0.1,
+			},
+		},
+		"credits": map[string]interface{}{"enabled": false},
+		"series":  series,
 	}
-
-	// Convert categories and series to JSON.
-	categoriesJSON, _ := json.Marshal(categories)
-	seriesJSON, _ := json.Marshal(series)
-
-	// Build the HTML and JavaScript for the chart.
-	Htmlpreid := `<div id="`
-	Htmldivid := `barchart`
-	Htmlpostid := `" class="flex justify-center mx-auto p-4"></div>`
-	Jspreid := `Highcharts.chart('`
-	Jspostid := fmt.Sprintf(`', {
-    chart: {
-        type: 'bar'
-    },
-    title: {
-        text: '%s'
-    },
-    subtitle: {
-        text: '%s'
-    },
-    xAxis: {
-        categories: %s,
-        title: {
-            text: '%s'
-        },
-        gridLineWidth: 1,
-        lineWidth: 0
-    },
-    yAxis: {
-        min: 0,
-        title: {
-            text: '',
-            align: 'middle'
-        },
-        labels: {
-            overflow: 'justify'
-        },
-        gridLineWidth: 0
-    },
-    tooltip: {
-        valueSuffix: ''
-    },
-    plotOptions: {
-        bar: {
-            borderRadius: '50%%',
-            dataLabels: {
-                enabled: true
-            },
-            groupPadding: 0.1
-        }
-    },
-    credits: {
-        enabled: false
-    },
-    series: %s
-});`, title, subtitle, categoriesJSON, groupcol, seriesJSON)
-
-	newChart := Chart{Htmlpreid, Htmldivid, Htmlpostid, Jspreid, Jspostid}
-	return newChart
+	chart := newChart("barchart", config, opts)
+	chart.Spec = df.chartSpec("bar", []string{groupcol}, opts, aggs...)
+	return chart
 }
 
 // ColumnChart returns Column Chart HTML for the DataFrame.
-// It takes a title, subtitle, group column, and one or more aggregations.
-func (df *DataFrame) ColumnChart(title string, subtitle string, groupcol string, aggs []Aggregation) Chart {
+// It takes a title, subtitle, group column, one or more aggregations and optional ChartOptions.
+func (df *DataFrame) ColumnChart(title string, subtitle string, groupcol string, aggs []Aggregation, opts ...ChartOptions) Chart {
 	// Group the DataFrame by the specified column and apply the aggregations.
-	df = df.GroupBy(groupcol, aggs...)
-	// df.Show(25)
-
-	// Extract categories and series data.
-	categories := []string{}
-	for _, val := range df.Data[groupcol] {
-		categories = append(categories, fmt.Sprintf("%v", val))
-	}
-
-	series := []map[string]interface{}{}
-	for _, agg := range aggs {
-		data := []interface{}{}
-		for _, val := range df.Data[agg.ColumnName] {
-			data = append(data, val)
-		}
-		series = append(series, map[string]interface{}{
-			"name": agg.ColumnName,
-			"data": data,
-		})
+	categories, series := df.aggregatedSeries(groupcol, aggs)
+
+	config := map[string]interface{}{
+		"chart":    map[string]interface{}{"type": "column"},
+		"title":    map[string]interface{}{"text": title},
+		"subtitle": map[string]interface{}{"text": subtitle},
+		"xAxis": map[string]interface{}{
+			"categories":    categories,
+			"title":         map[string]interface{}{"text": groupcol},
+			"gridLineWidth": 1,
+			"lineWidth":     0,
+		},
+		"yAxis": map[string]interface{}{
+			"min":           0,
+			"title":         map[string]interface{}{"text": "", "align": "middle"},
+			"labels":        map[string]interface{}{"overflow": "justify"},
+			"gridLineWidth": 0,
+		},
+		"tooltip": map[string]interface{}{"valueSuffix": ""},
+		"plotOptions": map[string]interface{}{
+			"column": map[string]interface{}{
+				"borderRadius": "50%",
+				"dataLabels":   map[string]interface{}{"enabled": true},
+				"groupPadding": 0.1,
+			},
+		},
+		"credits": map[string]interface{}{"enabled": false},
+		"series":  series,
 	}
-
-	// Convert categories and series to JSON.
-	categoriesJSON, _ := json.Marshal(categories)
-	seriesJSON, _ := json.Marshal(series)
-
-	// Build the HTML and JavaScript for the chart.
-	Htmlpreid := `<div id="`
-	Htmldivid := `columnchart`
-	Htmlpostid := `" class="flex justify-center mx-auto p-4"></div>`
-	Jspreid := `Highcharts.chart('`
-	Jspostid := fmt.Sprintf(`', {
-    chart: {
-        type: 'column'
-    },
-    title: {
-        text: '%s'
-    },
-    subtitle: {
-        text: '%s'
-    },
-    xAxis: {
-        categories: %s,
-        title: {
-            text: '%s'
-        },
-        gridLineWidth: 1,
-        lineWidth: 0
-    },
-    yAxis: {
-        min: 0,
-        title: {
-            text: '',
-            align: 'middle'
-        },
-        labels: {
-            overflow: 'justify'
-        },
-        gridLineWidth: 0
-    },
-    tooltip: {
-        valueSuffix: ''
-    },
-    plotOptions: {
-        bar: {
-            borderRadius: '50%%',
-            dataLabels: {
-                enabled: true
-            },
-            groupPadding: 0.1
-        }
-    },
-    credits: {
-        enabled: false
-    },
-    series: %s
-});`, title, subtitle, categoriesJSON, groupcol, seriesJSON)
-
-	newChart := Chart{Htmlpreid, Htmldivid, Htmlpostid, Jspreid, Jspostid}
-	return newChart
+	chart := newChart("columnchart", config, opts)
+	chart.Spec = df.chartSpec("column", []string{groupcol}, opts, aggs...)
+	return chart
 }
 
 func (df *DataFrame) GroupedSeriesJSON(x string, y string) string {
@@ -204,580 +116,873 @@ func (df *DataFrame) GroupedSeriesJSON(x string, y string) string {
 }
 
 // StackedBarChart returns Stacked Bar Chart HTML for the DataFrame.
-// It takes a title, subtitle, group column, and one or more aggregations.
-func (df *DataFrame) StackedBarChart(title string, subtitle string, groupcol string, aggs []Aggregation) Chart {
+// It takes a title, subtitle, group column, one or more aggregations and optional ChartOptions.
+func (df *DataFrame) StackedBarChart(title string, subtitle string, groupcol string, aggs []Aggregation, opts ...ChartOptions) Chart {
 	// Group the DataFrame by the specified column and apply the aggregations.
-	df = df.GroupBy(groupcol, aggs...)
-	// df.Show(25)
+	categories, series := df.aggregatedSeries(groupcol, aggs)
+
+	config := map[string]interface{}{
+		"chart":    map[string]interface{}{"type": "bar"},
+		"title":    map[string]interface{}{"text": title},
+		"subtitle": map[string]interface{}{"text": subtitle},
+		"xAxis": map[string]interface{}{
+			"categories":    categories,
+			"title":         map[string]interface{}{"text": groupcol},
+			"gridLineWidth": 1,
+			"lineWidth":     0,
+		},
+		"yAxis": map[string]interface{}{
+			"min":   0,
+			"title": map[string]interface{}{"text": "", "align": "middle"},
+		},
+		"plotOptions": map[string]interface{}{
+			"series": map[string]interface{}{
+				"stacking":   "normal",
+				"dataLabels": map[string]interface{}{"enabled": true},
+			},
+		},
+		"series": series,
+	}
+	chart := newChart("stackedbarchart", config, opts)
+	chart.Spec = df.chartSpec("stackedbar", []string{groupcol}, opts, aggs...)
+	return chart
+}
 
-	// Extract categories and series data.
-	categories := []string{}
-	for _, val := range df.Data[groupcol] {
-		categories = append(categories, fmt.Sprintf("%v", val))
+// StackedPercentChart returns Stacked Percent Column Chart HTML for the DataFrame.
+// It takes a title, subtitle, group column, one or more aggregations and optional ChartOptions.
+func (df *DataFrame) StackedPercentChart(title string, subtitle string, groupcol string, aggs []Aggregation, opts ...ChartOptions) Chart {
+	// Group the DataFrame by the specified column and apply the aggregations.
+	categories, series := df.aggregatedSeries(groupcol, aggs)
+
+	config := map[string]interface{}{
+		"chart":    map[string]interface{}{"type": "column"},
+		"title":    map[string]interface{}{"text": title},
+		"subtitle": map[string]interface{}{"text": subtitle},
+		"xAxis": map[string]interface{}{
+			"categories":    categories,
+			"title":         map[string]interface{}{"text": groupcol},
+			"gridLineWidth": 1,
+			"lineWidth":     0,
+		},
+		"yAxis": map[string]interface{}{
+			"min":   0,
+			"title": map[string]interface{}{"text": "Percent", "align": "middle"},
+		},
+		"tooltip": map[string]interface{}{
+			"pointFormat": `<span style="color:{series.color}">{series.name}</span>: <b>{point.y}</b> ({point.percentage:.0f}%)<br/>`,
+			"shared":      true,
+		},
+		"plotOptions": map[string]interface{}{
+			"column": map[string]interface{}{
+				"stacking":   "percent",
+				"dataLabels": map[string]interface{}{"enabled": true, "format": "{point.percentage:.0f}%"},
+			},
+		},
+		"series": series,
 	}
+	chart := newChart("stackedpercentchart", config, opts)
+	chart.Spec = df.chartSpec("stackedpercent", []string{groupcol}, opts, aggs...)
+	return chart
+}
+
+// func (df *DataFrame) MixedBarChart(x string, y string, avg string) string {
+// 	html := ``
+// 	return html
+// }
+
+// PieChart returns Pie Chart HTML for the DataFrame.
+// Each slice is a value of namecol, sized by the aggregation of its rows.
+func (df *DataFrame) PieChart(title string, subtitle string, namecol string, agg Aggregation, opts ...ChartOptions) Chart {
+	spec := df.chartSpec("pie", []string{namecol}, opts, agg)
+	df = df.GroupBy(namecol, agg)
+	value := outputNames([]string{namecol}, []Aggregation{agg})[1]
+
+	data := []map[string]interface{}{}
+	for i, val := range df.Data[namecol] {
+		data = append(data, map[string]interface{}{
+			"name": chartLabel(val),
+			"y":    chartValue(df.Data[value][i]),
+		})
+	}
+
+	config := map[string]interface{}{
+		"chart":    map[string]interface{}{"type": "pie"},
+		"title":    map[string]interface{}{"text": title},
+		"subtitle": map[string]interface{}{"text": subtitle},
+		"tooltip":  map[string]interface{}{"pointFormat": "{series.name}: <b>{point.y}</b> ({point.percentage:.1f}%)"},
+		"plotOptions": map[string]interface{}{
+			"pie": map[string]interface{}{
+				"allowPointSelect": true,
+				"cursor":           "pointer",
+				"dataLabels":       map[string]interface{}{"enabled": true, "format": "{point.name}: {point.percentage:.1f}%"},
+			},
+		},
+		"credits": map[string]interface{}{"enabled": false},
+		"series": []map[string]interface{}{{
+			"name":         value,
+			"colorByPoint": true,
+			"data":         data,
+		}},
+	}
+	chart := newChart("piechart", config, opts)
+	chart.Spec = spec
+	return chart
+}
+
+// LineChart returns Line Chart HTML for the DataFrame with one line per y column.
+// Date/time x columns (time.Time or date strings) get a datetime axis, numeric x
+// columns a linear axis (both sorted by x); anything else is used as categories.
+// An unknown x or y column prints an error and returns an empty Chart.
+func (df *DataFrame) LineChart(title string, subtitle string, xcol string, ycols []string, opts ...ChartOptions) Chart {
+	return df.xyChart("line", "linechart", title, subtitle, xcol, ycols, opts)
+}
+
+// AreaChart returns Area Chart HTML for the DataFrame with one area per y column.
+// The x axis is handled as in LineChart.
+func (df *DataFrame) AreaChart(title string, subtitle string, xcol string, ycols []string, opts ...ChartOptions) Chart {
+	return df.xyChart("area", "areachart", title, subtitle, xcol, ycols, opts)
+}
+
+func (df *DataFrame) xyChart(kind string, divid string, title string, subtitle string, xcol string, ycols []string, opts []ChartOptions) Chart {
+	if err := df.chartColumnError(append([]string{xcol}, ycols...)...); err != nil {
+		fmt.Printf("%s chart error: %v\n", kind, err)
+		return Chart{}
+	}
+	axis, categories, order := chartXAxis(df.Data[xcol], df.Rows)
 
 	series := []map[string]interface{}{}
-	for _, agg := range aggs {
+	for _, ycol := range ycols {
+		ys := df.Data[ycol]
 		data := []interface{}{}
-		for _, val := range df.Data[agg.ColumnName] {
-			data = append(data, val)
+		for _, i := range order {
+			var y interface{}
+			if i < len(ys) {
+				y = chartValue(ys[i])
+			}
+			if axis.kind == "category" {
+				data = append(data, y)
+			} else {
+				data = append(data, []interface{}{axis.values[i], y})
+			}
 		}
 		series = append(series, map[string]interface{}{
-			"name": agg.ColumnName,
+			"name": ycol,
 			"data": data,
 		})
 	}
 
-	// Convert categories and series to JSON.
-	categoriesJSON, _ := json.Marshal(categories)
-	seriesJSON, _ := json.Marshal(series)
-
-	// Build the HTML and JavaScript for the chart.
-	Htmlpreid := `<div id="`
-	Htmldivid := `stackedbarchart`
-	Htmlpostid := `" class="flex justify-center mx-auto p-4"></div>`
-	Jspreid := `Highcharts.chart('`
-	Jspostid := fmt.Sprintf(`', {
-    chart: {
-        type: 'bar'
-    },
-    title: {
-        text: '%s'
-    },
-    subtitle: {
-        text: '%s'
-    },
-    xAxis: {
-        categories: %s,
-        title: {
-            text: '%s'
-        },
-        gridLineWidth: 1,
-        lineWidth: 0
-    },
-    yAxis: {
-        min: 0,
-        title: {
-            text: '',
-            align: 'middle'
-        },
-    },
-    plotOptions: {
-        series: {
-            stacking: 'normal',
-            dataLabels: {
-                enabled: true
-            }
-        }
-    },
-    series: %s
-});`, title, subtitle, categoriesJSON, groupcol, seriesJSON)
-
-	newChart := Chart{Htmlpreid, Htmldivid, Htmlpostid, Jspreid, Jspostid}
-	return newChart
+	config := map[string]interface{}{
+		"chart":    map[string]interface{}{"type": kind, "zoomType": "x"},
+		"title":    map[string]interface{}{"text": title},
+		"subtitle": map[string]interface{}{"text": subtitle},
+		"xAxis":    axis.config(categories, xcol),
+		"yAxis":    map[string]interface{}{"title": map[string]interface{}{"text": ""}},
+		"tooltip":  map[string]interface{}{"shared": true},
+		"plotOptions": map[string]interface{}{
+			"series": map[string]interface{}{"marker": map[string]interface{}{"enabled": false}},
+		},
+		"credits": map[string]interface{}{"enabled": false},
+		"series":  series,
+	}
+	chart := newChart(divid, config, opts)
+	if chart.Spec = df.chartSpec(kind, append([]string{xcol}, ycols...), opts); chart.Spec != nil {
+		chart.Spec.Axis = axis.kind
+	}
+	return chart
 }
 
-// StackedPercentChart returns Stacked Percent Column Chart HTML for the DataFrame.
-// It takes a title, subtitle, group column, and one or more aggregations.
-func (df *DataFrame) StackedPercentChart(title string, subtitle string, groupcol string, aggs []Aggregation) Chart {
-	// Group the DataFrame by the specified column and apply the aggregations.
+// ScatterPlot returns Scatter Plot HTML for the DataFrame. Points are
+// (xcol, ycol) pairs; a non-empty groupcol splits them into one series per group.
+// An unknown column prints an error and returns an empty Chart.
+func (df *DataFrame) ScatterPlot(title string, subtitle string, xcol string, ycol string, groupcol string, opts ...ChartOptions) Chart {
+	return df.pointChart("scatter", "scatterplot", title, subtitle, xcol, ycol, "", groupcol, opts)
+}
+
+// BubbleChart returns Bubble Chart HTML for the DataFrame. Bubbles sit at
+// (xcol, ycol) and are sized by sizecol; a non-empty groupcol gives one series per group.
+func (df *DataFrame) BubbleChart(title string, subtitle string, xcol string, ycol string, sizecol string, groupcol string, opts ...ChartOptions) Chart {
+	return df.pointChart("bubble", "bubblechart", title, subtitle, xcol, ycol, sizecol, groupcol, opts)
+}
+
+func (df *DataFrame) pointChart(kind string, divid string, title string, subtitle string, xcol string, ycol string, sizecol string, groupcol string, opts []ChartOptions) Chart {
+	cols := []string{xcol, ycol}
+	if kind == "bubble" {
+		cols = append(cols, sizecol)
+	}
+	if groupcol != "" {
+		cols = append(cols, groupcol)
+	}
+	if err := df.chartColumnError(cols...); err != nil {
+		fmt.Printf("%s chart error: %v\n", kind, err)
+		return Chart{}
+	}
+	axis, categories, _ := chartXAxis(df.Data[xcol], df.Rows)
+	ys := df.Data[ycol]
+	sizes := df.Data[sizecol]
+	groups := df.Data[groupcol]
+
+	// one series per group, in first-appearance order
+	index := map[string]int{}
+	series := []map[string]interface{}{}
+	for i := 0; i < df.Rows; i++ {
+		name := ycol
+		if groupcol != "" && i < len(groups) {
+			name = chartLabel(groups[i])
+		}
+		si, ok := index[name]
+		if !ok {
+			si = len(series)
+			index[name] = si
+			series = append(series, map[string]interface{}{"name": name, "data": []interface{}{}})
+		}
+		point := []interface{}{axis.values[i], nil}
+		if i < len(ys) {
+			point[1] = chartValue(ys[i])
+		}
+		if kind == "bubble" {
+			var z interface{}
+			if i < len(sizes) {
+				z = chartValue(sizes[i])
+			}
+			point = append(point, z)
+		}
+		series[si]["data"] = append(series[si]["data"].([]interface{}), point)
+	}
+
+	xAxis := axis.config(categories, xcol)
+	xAxis["gridLineWidth"] = 1
+	config := map[string]interface{}{
+		"chart":    map[string]interface{}{"type": kind, "zoomType": "xy"},
+		"title":    map[string]interface{}{"text": title},
+		"subtitle": map[string]interface{}{"text": subtitle},
+		"xAxis":    xAxis,
+		"yAxis":    map[string]interface{}{"title": map[string]interface{}{"text": ycol}},
+		"credits":  map[string]interface{}{"enabled": false},
+		"series":   series,
+	}
+	chart := newChart(divid, config, opts)
+	if chart.Spec = df.chartSpec(kind, []string{xcol, ycol, sizecol, groupcol}, opts); chart.Spec != nil {
+		chart.Spec.Axis = axis.kind
+	}
+	return chart
+}
+
+// TreeMap returns Tree Map HTML for the DataFrame. groupcols are the levels of
+// the hierarchy (outermost first) and each leaf is sized by the aggregation.
+func (df *DataFrame) TreeMap(title string, subtitle string, groupcols []string, agg Aggregation, opts ...ChartOptions) Chart {
+	spec := df.chartSpec("treemap", groupcols, opts, agg)
+	df = df.GroupBy(groupcols, agg)
+	value := outputNames(groupcols, []Aggregation{agg})[len(groupcols)]
+
+	// parent nodes are keyed by their path so equal names under different parents stay apart
+	data := []map[string]interface{}{}
+	seen := map[string]bool{}
+	for i := 0; i < df.Rows; i++ {
+		parent := ""
+		for level, col := range groupcols {
+			name := chartLabel(df.Data[col][i])
+			id := parent + "/" + name
+			if level == len(groupcols)-1 {
+				point := map[string]interface{}{"id": id, "name": name, "value": chartValue(df.Data[value][i])}
+				if parent != "" {
+					point["parent"] = parent
+				}
+				data = append(data, point)
+			} else if !seen[id] {
+				seen[id] = true
+				point := map[string]interface{}{"id": id, "name": name}
+				if parent != "" {
+					point["parent"] = parent
+				}
+				data = append(data, point)
+			}
+			parent = id
+		}
+	}
+
+	config := map[string]interface{}{
+		"title":    map[string]interface{}{"text": title},
+		"subtitle": map[string]interface{}{"text": subtitle},
+		"credits":  map[string]interface{}{"enabled": false},
+		"series": []map[string]interface{}{{
+			"type":                "treemap",
+			"name":                value,
+			"layoutAlgorithm":     "squarified",
+			"allowTraversingTree": true,
+			"levels": []map[string]interface{}{{
+				"level":        1,
+				"colorByPoint": true,
+				"dataLabels":   map[string]interface{}{"enabled": true},
+				"borderWidth":  3,
+			}},
+			"data": data,
+		}},
+	}
+	chart := newChart("treemap", config, opts)
+	chart.Spec = spec
+	return chart
+}
+
+// stacked area chart
+
+// stacked percent area chart
+
+// donut chart
+
+// DataTable returns the given columns (all when none are given) as a static
+// HTML table. It has no script, so AddChart and DisplayChart only place the HTML.
+func (df *DataFrame) DataTable(columns ...string) Chart {
+	spec := &ChartSpec{Kind: "datatable", Cols: columns, Source: df}
+	if len(columns) == 0 {
+		columns = df.Cols
+	}
+	var b strings.Builder
+	b.WriteString(`" class="overflow-x-auto mx-auto p-4"><table class="table table-zebra table-sm"><thead><tr>`)
+	for _, col := range columns {
+		b.WriteString("<th>" + html.EscapeString(col) + "</th>")
+	}
+	b.WriteString("</tr></thead><tbody>")
+	for i := 0; i < df.Rows; i++ {
+		b.WriteString("<tr>")
+		for _, col := range columns {
+			cell := ""
+			if vals := df.Data[col]; i < len(vals) && vals[i] != nil {
+				cell = fastToString(vals[i])
+			}
+			b.WriteString("<td>" + html.EscapeString(cell) + "</td>")
+		}
+		b.WriteString("</tr>")
+	}
+	b.WriteString("</tbody></table></div>")
+
+	return Chart{Htmlpreid: `<div id="`, Htmldivid: `datatable`, Htmlpostid: b.String(), Spec: spec}
+}
+
+// chartColumnError reports the first of cols that is not a column of df.
+func (df *DataFrame) chartColumnError(cols ...string) error {
+	for _, c := range cols {
+		if _, ok := df.Data[c]; !ok {
+			return fmt.Errorf("column %q does not exist", c)
+		}
+	}
+	return nil
+}
+
+// chartLabel names a category, series or point after a cell, so null reads
+// "null" on every chart.
+func chartLabel(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// chartValue converts a cell to a number for a chart series (nil when it is
+// not numeric, NaN or ±Inf, none of which JSON can carry).
+func chartValue(v interface{}) interface{} {
+	if f, err := toFloat64(v); err == nil {
+		return chartNumber(f)
+	}
+	if s, ok := v.(string); ok {
+		if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			return chartNumber(f)
+		}
+	}
+	return nil
+}
+
+// chartNumber returns f, or nil when f is NaN or ±Inf.
+func chartNumber(f float64) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return f
+}
+
+// chartAxis describes an x axis: datetime (values in epoch milliseconds),
+// linear (numeric values) or category (values are indexes into categories).
+type chartAxis struct {
+	kind   string
+	values []interface{}
+}
+
+// chartXAxis picks the axis type for an x column and returns the row order to
+// plot: sorted by x for datetime and linear axes, row order for categories.
+func chartXAxis(xs []interface{}, n int) (chartAxis, []string, []int) {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	values := make([]interface{}, n)
+	keys := make([]float64, n)
+
+	isTime, isNum := n > 0, n > 0
+	for i := 0; i < n && (isTime || isNum); i++ {
+		var v interface{}
+		if i < len(xs) {
+			v = xs[i]
+		}
+		if v == nil {
+			continue
+		}
+		if isTime {
+			switch t := v.(type) {
+			case time.Time:
+				keys[i] = float64(t.UnixMilli())
+			case string:
+				if pt, ok := parseTimeAny(t); ok {
+					keys[i] = float64(pt.UnixMilli())
+				} else {
+					isTime = false
+				}
+			default:
+				isTime = false
+			}
+		}
+		if isNum && !isTime {
+			if f, ok := chartValue(v).(float64); ok {
+				keys[i] = f
+			} else {
+				isNum = false
+			}
+		}
+	}
+	if isNum && !isTime {
+		// keys may hold timestamps from rows checked before isTime failed
+		for i := 0; i < n && i < len(xs); i++ {
+			if f, ok := chartValue(xs[i]).(float64); ok {
+				keys[i] = f
+			}
+		}
+	}
+
+	if isTime || isNum {
+		kind := "linear"
+		if isTime {
+			kind = "datetime"
+		}
+		for i := 0; i < n; i++ {
+			if i < len(xs) && xs[i] != nil {
+				values[i] = keys[i]
+			}
+		}
+		sort.SliceStable(order, func(a, b int) bool { return keys[order[a]] < keys[order[b]] })
+		return chartAxis{kind: kind, values: values}, nil, order
+	}
+
+	categories := make([]string, n)
+	for i := 0; i < n; i++ {
+		var v interface{}
+		if i < len(xs) {
+			v = xs[i]
+		}
+		categories[i] = chartLabel(v)
+		values[i] = i
+	}
+	return chartAxis{kind: "category", values: values}, categories, order
+}
+
+// config returns the Highcharts xAxis block for the axis.
+func (a chartAxis) config(categories []string, title string) map[string]interface{} {
+	axis := map[string]interface{}{"title": map[string]interface{}{"text": title}}
+	if a.kind == "category" {
+		axis["categories"] = categories
+	} else {
+		axis["type"] = a.kind
+	}
+	return axis
+}
+
+// Histogram returns Histogram HTML for a numeric column. Values are binned in Go
+// into bins equal-width buckets (Sturges' rule when bins <= 0); nulls,
+// non-numeric values, NaN and ±Inf are skipped.
+func (df *DataFrame) Histogram(col string, bins int, opts ...ChartOptions) Chart {
+	spec := df.chartSpec("histogram", []string{col}, opts)
+	if spec != nil {
+		spec.Bins = bins
+	}
+	values := chartFloats(df.Data[col])
+
+	categories := []string{}
+	counts := []int{}
+	if len(values) > 0 {
+		if bins <= 0 {
+			bins = int(math.Ceil(math.Log2(float64(len(values))))) + 1
+		}
+		lo, hi := values[0], values[0]
+		for _, v := range values {
+			lo = math.Min(lo, v)
+			hi = math.Max(hi, v)
+		}
+		width := (hi - lo) / float64(bins)
+		if width == 0 {
+			bins, width = 1, 1
+		}
+		counts = make([]int, bins)
+		for _, v := range values {
+			b := int((v - lo) / width)
+			if b >= bins {
+				// the maximum belongs to the last (closed) bin
+				b = bins - 1
+			}
+			counts[b]++
+		}
+		for b := 0; b < bins; b++ {
+			from := lo + float64(b)*width
+			categories = append(categories, fmt.Sprintf("%s - %s", strconv.FormatFloat(from, 'g', 6, 64), strconv.FormatFloat(from+width, 'g', 6, 64)))
+		}
+	}
+
+	config := map[string]interface{}{
+		"chart":    map[string]interface{}{"type": "column"},
+		"title":    map[string]interface{}{"text": col},
+		"subtitle": map[string]interface{}{"text": fmt.Sprintf("%d values", len(values))},
+		"xAxis": map[string]interface{}{
+			"categories": categories,
+			"title":      map[string]interface{}{"text": col},
+		},
+		"yAxis": map[string]interface{}{
+			"min":   0,
+			"title": map[string]interface{}{"text": "Count"},
+		},
+		"legend": map[string]interface{}{"enabled": false},
+		"plotOptions": map[string]interface{}{
+			"column": map[string]interface{}{"pointPadding": 0, "groupPadding": 0, "borderWidth": 1},
+		},
+		"credits": map[string]interface{}{"enabled": false},
+		"series": []map[string]interface{}{{
+			"name": col,
+			"data": counts,
+		}},
+	}
+	chart := newChart("histogram", config, opts)
+	chart.Spec = spec
+	return chart
+}
+
+// BoxPlot returns Box Plot HTML for valueCol with one box per value of groupCol
+// (a single box when groupCol is empty). Quartiles are interpolated, whiskers
+// reach the furthest values within 1.5 IQR and values beyond them are plotted
+// as outliers.
+func (df *DataFrame) BoxPlot(valueCol string, groupCol string, opts ...ChartOptions) Chart {
+	// collect values per group in first-appearance order
+	categories := []string{}
+	index := map[string]int{}
+	groups := [][]float64{}
+	values := df.Data[valueCol]
+	for i := 0; i < df.Rows && i < len(values); i++ {
+		v, ok := chartValue(values[i]).(float64)
+		if !ok {
+			continue
+		}
+		name := valueCol
+		if groupCol != "" && i < len(df.Data[groupCol]) {
+			name = chartLabel(df.Data[groupCol][i])
+		}
+		gi, seen := index[name]
+		if !seen {
+			gi = len(groups)
+			index[name] = gi
+			categories = append(categories, name)
+			groups = append(groups, nil)
+		}
+		groups[gi] = append(groups[gi], v)
+	}
+
+	boxes := [][]interface{}{}
+	outliers := [][]interface{}{}
+	for gi, vals := range groups {
+		sort.Float64s(vals)
+		q1, median, q3 := quantile(vals, 0.25), quantile(vals, 0.5), quantile(vals, 0.75)
+		iqr := q3 - q1
+		lowFence, highFence := q1-1.5*iqr, q3+1.5*iqr
+		low, high := median, median
+		for _, v := range vals {
+			if v < lowFence || v > highFence {
+				outliers = append(outliers, []interface{}{gi, v})
+				continue
+			}
+			low = math.Min(low, v)
+			high = math.Max(high, v)
+		}
+		boxes = append(boxes, []interface{}{chartNumber(low), chartNumber(q1), chartNumber(median), chartNumber(q3), chartNumber(high)})
+	}
+
+	config := map[string]interface{}{
+		"chart":    map[string]interface{}{"type": "boxplot"},
+		"title":    map[string]interface{}{"text": valueCol},
+		"subtitle": map[string]interface{}{"text": groupCol},
+		"xAxis": map[string]interface{}{
+			"categories": categories,
+			"title":      map[string]interface{}{"text": groupCol},
+		},
+		"yAxis":   map[string]interface{}{"title": map[string]interface{}{"text": valueCol}},
+		"legend":  map[string]interface{}{"enabled": false},
+		"credits": map[string]interface{}{"enabled": false},
+		"series": []map[string]interface{}{
+			{
+				"name": valueCol,
+				"data": boxes,
+			},
+			{
+				"name":    "Outliers",
+				"type":    "scatter",
+				"data":    outliers,
+				"marker":  map[string]interface{}{"fillColor": "white", "lineWidth": 1, "lineColor": "#e06666"},
+				"tooltip": map[string]interface{}{"pointFormat": "{point.y}"},
+			},
+		},
+	}
+	chart := newChart("boxplot", config, opts)
+	chart.Spec = df.chartSpec("boxplot", []string{valueCol, groupCol}, opts)
+	return chart
+}
+
+// Heatmap returns Heatmap HTML with xCol values across, yCol values down and
+// each cell colored by agg applied to the valueCol values of that pair. As in
+// Pivot, the aggregation's own ColumnName is ignored.
+// Usage: df.Heatmap("weekday", "hour", "sales", Sum("sales"))
+func (df *DataFrame) Heatmap(xCol string, yCol string, valueCol string, agg Aggregation, opts ...ChartOptions) Chart {
+	a := agg
+	a.ColumnName, a.OutputName = valueCol, ""
+	grouped := df.GroupBy([]string{xCol, yCol}, a)
+	value := outputNames([]string{xCol, yCol}, []Aggregation{a})[2]
+
+	xIndex, yIndex := map[string]int{}, map[string]int{}
+	xCategories, yCategories := []string{}, []string{}
+	position := func(index map[string]int, categories *[]string, v interface{}) int {
+		key := chartLabel(v)
+		i, ok := index[key]
+		if !ok {
+			i = len(*categories)
+			index[key] = i
+			*categories = append(*categories, key)
+		}
+		return i
+	}
+
+	data := [][]interface{}{}
+	for i := 0; i < grouped.Rows; i++ {
+		x := position(xIndex, &xCategories, grouped.Data[xCol][i])
+		y := position(yIndex, &yCategories, grouped.Data[yCol][i])
+		data = append(data, []interface{}{x, y, chartValue(grouped.Data[value][i])})
+	}
+
+	config := map[string]interface{}{
+		"chart":    map[string]interface{}{"type": "heatmap"},
+		"title":    map[string]interface{}{"text": value},
+		"subtitle": map[string]interface{}{"text": xCol + " by " + yCol},
+		"xAxis": map[string]interface{}{
+			"categories": xCategories,
+			"title":      map[string]interface{}{"text": xCol},
+		},
+		"yAxis": map[string]interface{}{
+			"categories": yCategories,
+			"title":      map[string]interface{}{"text": yCol},
+			"reversed":   true,
+		},
+		"colorAxis": map[string]interface{}{"minColor": "#FFFFFF", "maxColor": "#1e3a8a"},
+		"legend":    map[string]interface{}{"align": "right", "layout": "vertical", "verticalAlign": "middle"},
+		"tooltip":   map[string]interface{}{"pointFormat": "{point.value}"},
+		"credits":   map[string]interface{}{"enabled": false},
+		"series": []map[string]interface{}{{
+			"name":        value,
+			"borderWidth": 1,
+			"data":        data,
+			"dataLabels":  map[string]interface{}{"enabled": len(data) <= 400},
+		}},
+	}
+	chart := newChart("heatmap", config, opts)
+	chart.Spec = df.chartSpec("heatmap", []string{xCol, yCol, valueCol}, opts, a)
+	return chart
+}
+
+// aggregatedSeries groups the DataFrame by groupcol and returns the group values
+// as categories with one series per aggregation.
+func (df *DataFrame) aggregatedSeries(groupcol string, aggs []Aggregation) ([]string, []map[string]interface{}) {
 	df = df.GroupBy(groupcol, aggs...)
-	// df.Show(25)
+	names := outputNames([]string{groupcol}, aggs)[1:]
 
-	// Extract categories and series data.
 	categories := []string{}
 	for _, val := range df.Data[groupcol] {
-		categories = append(categories, fmt.Sprintf("%v", val))
+		categories = append(categories, chartLabel(val))
 	}
 
 	series := []map[string]interface{}{}
-	for _, agg := range aggs {
+	for _, name := range names {
 		data := []interface{}{}
-		for _, val := range df.Data[agg.ColumnName] {
-			data = append(data, val)
+		for _, val := range df.Data[name] {
+			data = append(data, chartValue(val))
 		}
 		series = append(series, map[string]interface{}{
-			"name": agg.ColumnName,
+			"name": name,
 			"data": data,
 		})
 	}
+	return categories, series
+}
 
-	// Convert categories and series to JSON.
-	categoriesJSON, _ := json.Marshal(categories)
-	seriesJSON, _ := json.Marshal(series)
-
-	// Build the HTML and JavaScript for the chart.
-	Htmlpreid := `<div id="`
-	Htmldivid := `stackedpercentchart`
-	Htmlpostid := `" class="flex justify-center mx-auto p-4"></div>`
-	Jspreid := `Highcharts.chart('`
-	Jspostid := fmt.Sprintf(`', {
-    chart: {
-        type: 'column'
-    },
-    title: {
-        text: '%s'
-    },
-    subtitle: {
-        text: '%s'
-    },
-    xAxis: {
-        categories: %s,
-        title: {
-            text: '%s'
-        },
-        gridLineWidth: 1,
-        lineWidth: 0
-    },
-    yAxis: {
-        min: 0,
-        title: {
-            text: 'Percent',
-            align: 'middle'
-        },
-    },
-    tooltip: {
-        pointFormat: '<span style="color:{series.color}">{series.name}</span>' +
-            ': <b>{point.y}</b> ({point.percentage:.0f}%%)<br/>',
-        shared: true
-    },
-    plotOptions: {
-        column: {
-            stacking: 'percent',
-            dataLabels: {
-                enabled: true,
-                format: '{point.percentage:.0f}%%'
-            }
-        }
-    },
-    series: %s
-});`, title, subtitle, categoriesJSON, groupcol, seriesJSON)
-
-	newChart := Chart{Htmlpreid, Htmldivid, Htmlpostid, Jspreid, Jspostid}
-	return newChart
+// chartSpec records a builder call for ChartSpec. It returns nil when an
+// aggregation has no browser equivalent (custom Fn, CollectList, CollectSet).
+func (df *DataFrame) chartSpec(kind string, cols []string, opts []ChartOptions, aggs ...Aggregation) *ChartSpec {
+	spec := &ChartSpec{Kind: kind, Cols: cols, Source: df}
+	if len(opts) > 0 {
+		o := opts[0]
+		spec.Options = &o
+	}
+	for _, agg := range aggs {
+		switch agg.kind {
+		case "sum", "max", "min", "mean", "median", "mode", "unique", "first":
+			spec.Aggs = append(spec.Aggs, ChartSpecAgg{Fn: agg.kind, Column: agg.ColumnName, Name: agg.OutputName})
+		default:
+			return nil
+		}
+	}
+	return spec
 }
 
-// func (df *DataFrame) MixedBarChart(x string, y string, avg string) string {
-// 	html := ``
-// 	return html
-// }
+// newChart applies the options to a Highcharts config and splits it into the
+// Chart fragments that AddChart and DisplayChart join around a div id.
+func newChart(divid string, config map[string]interface{}, opts []ChartOptions) Chart {
+	for _, o := range opts {
+		o.apply(config)
+	}
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		fmt.Printf("chart %s: %v\n", divid, err)
+		msg := html.EscapeString(fmt.Sprintf("chart %s: %v", divid, err))
+		return Chart{Htmlpreid: `<div id="`, Htmldivid: divid, Htmlpostid: `" class="flex justify-center mx-auto p-4 text-red-600">` + msg + `</div>`}
+	}
 
-func (df *DataFrame) PieChart(columns ...string) string {
-	html := `
-Highcharts.chart('container', {
-    chart: {
-        type: 'pie'
-    },
-    title: {
-        text: 'Egg Yolk Composition'
-    },
-    tooltip: {
-        valueSuffix: '%'
-    },
-    subtitle: {
-        text:
-        'Source:<a href="https://www.mdpi.com/2072-6643/11/3/684/htm" target="_default">MDPI</a>'
-    },
-    plotOptions: {
-        series: {
-            allowPointSelect: true,
-            cursor: 'pointer',
-            dataLabels: [{
-                enabled: true,
-                distance: 20
-            }, {
-                enabled: true,
-                distance: -40,
-                format: '{point.percentage:.1f}%',
-                style: {
-                    fontSize: '1.2em',
-                    textOutline: 'none',
-                    opacity: 0.7
-                },
-                filter: {
-                    operator: '>',
-                    property: 'percentage',
-                    value: 10
-                }
-            }]
-        }
-    },
-    series: [
-        {
-            name: 'Percentage',
-            colorByPoint: true,
-            data: [
-                {
-                    name: 'Water',
-                    y: 55.02
-                },
-                {
-                    name: 'Fat',
-                    sliced: true,
-                    selected: true,
-                    y: 26.71
-                },
-                {
-                    name: 'Carbohydrates',
-                    y: 1.09
-                },
-                {
-                    name: 'Protein',
-                    y: 15.5
-                },
-                {
-                    name: 'Ash',
-                    y: 1.68
-                }
-            ]
-        }
-    ]
-});
-    `
-	return html
-}
-
-// *
-func (df *DataFrame) LineChart(x string, y string) string {
-	html := `
-Highcharts.chart('container', {
-
-    title: {
-        text: 'U.S Solar Employment Growth',
-        align: 'left'
-    },
-
-    subtitle: {
-        text: 'By Job Category. Source: <a href="https://irecusa.org/programs/solar-jobs-census/" target="_blank">IREC</a>.',
-        align: 'left'
-    },
-
-    yAxis: {
-        title: {
-            text: 'Number of Employees'
-        }
-    },
-
-    xAxis: {
-        accessibility: {
-            rangeDescription: 'Range: 2010 to 2022'
-        }
-    },
-
-    legend: {
-        layout: 'vertical',
-        align: 'right',
-        verticalAlign: 'middle'
-    },
-
-    plotOptions: {
-        series: {
-            label: {
-                connectorAllowed: false
-            },
-            pointStart: 2010
-        }
-    },
-
-    series: [{
-        name: 'Installation & Developers',
-        data: [
-            43934, 48656, 65165, 81827, 112143, 142383,
-            171533, 165174, 155157, 161454, 154610, 168960, 171558
-        ]
-    }, {
-        name: 'Manufacturing',
-        data: [
-            24916, 37
-    `
-	return html
-}
-
-// *
-func (df *DataFrame) ScatterPlot(x string, y string) string {
-	html := `
-Highcharts.setOptions({
-    colors: [
-        'rgba(5,141,199,0.5)', 'rgba(80,180,50,0.5)', 'rgba(237,86,27,0.5)'
-    ]
-});
-
-const series = [{
-    name: 'Basketball',
-    id: 'basketball',
-    marker: {
-        symbol: 'circle'
-    }
-},
-{
-    name: 'Triathlon',
-    id: 'triathlon',
-    marker: {
-        symbol: 'triangle'
-    }
-},
-{
-    name: 'Volleyball',
-    id: 'volleyball',
-    marker: {
-        symbol: 'square'
-    }
-}];
-
-
-async function getData() {
-    const response = await fetch(
-        'https://www.highcharts.com/samples/data/olympic2012.json'
-    );
-    return response.json();
-}
-
-
-getData().then(data => {
-    const getData = sportName => {
-        const temp = [];
-        data.forEach(elm => {
-            if (elm.sport === sportName && elm.weight > 0 && elm.height > 0) {
-                temp.push([elm.height, elm.weight]);
-            }
-        });
-        return temp;
-    };
-    series.forEach(s => {
-        s.data = getData(s.id);
-    });
-
-    Highcharts.chart('container', {
-        chart: {
-            type: 'scatter',
-            zooming: {
-                type: 'xy'
-            }
-        },
-        title: {
-            text: 'Olympics athletes by height and weight'
-        },
-        subtitle: {
-            text:
-          'Source: <a href="https://www.theguardian.com/sport/datablog/2012/aug/07/olympics-2012-athletes-age-weight-height">The Guardian</a>'
-        },
-        xAxis: {
-            title: {
-                text: 'Height'
-            },
-            labels: {
-                format: '{value} m'
-            },
-            startOnTick: true,
-            endOnTick: true,
-            showLastLabel: true
-        },
-        yAxis: {
-            
-    `
-	return html
-}
-
-// *
-func (df *DataFrame) BubbleChart(x string, y string) string {
-	html := `
-Highcharts.chart('container', {
-
-    chart: {
-        type: 'bubble',
-        plotBorderWidth: 1,
-        zooming: {
-            type: 'xy'
-        }
-    },
-
-    legend: {
-        enabled: false
-    },
-
-    title: {
-        text: 'Sugar and fat intake per country'
-    },
-
-    subtitle: {
-        text: 'Source: <a href="http://www.euromonitor.com/">Euromonitor</a> and <a href="https://data.oecd.org/">OECD</a>'
-    },
-
-    accessibility: {
-        point: {
-            valueDescriptionFormat: '{index}. {point.name}, fat: {point.x}g, ' +
-                'sugar: {point.y}g, obesity: {point.z}%.'
-        }
-    },
-
-    xAxis: {
-        gridLineWidth: 1,
-        title: {
-            text: 'Daily fat intake'
-        },
-        labels: {
-            format: '{value} gr'
-        },
-        plotLines: [{
-            color: 'black',
-            dashStyle: 'dot',
-            width: 2,
-            value: 65,
-            label: {
-                rotation: 0,
-                y: 15,
-                style: {
-                    fontStyle: 'italic'
-                },
-                text: 'Safe fat intake 65g/day'
-            },
-            zIndex: 3
-        }],
-        accessibility: {
-            rangeDescription: 'Range: 60 to 100 grams.'
-        }
-    },
-
-    yAxis: {
-        startOnTick: false,
-        endOnTick: false,
-        title: {
-            text: 'Daily sugar intake'
-        },
-        labels: {
-            format: '{value} gr'
-        },
-        maxPadding: 0.2,
-        plotLines: [{
-            color: 'black',
-            dashStyle: 'dot',
-            width: 2,
-            value: 50,
-            label: {
-                align: 'right',
-                style: {
-        
-    `
-	return html
-}
-
-// *
-func (df *DataFrame) TreeMap(columns ...string) string {
-	html := ``
-	return html
-}
-
-// *
-func (df *DataFrame) AreaChart(x string, y string) string {
-	html := `
-// Data retrieved from https://fas.org/issues/nuclear-weapons/status-world-nuclear-forces/
-Highcharts.chart('container', {
-    chart: {
-        type: 'area'
-    },
-    accessibility: {
-        description: 'Image description: An area chart compares the nuclear ' +
-            'stockpiles of the USA and the USSR/Russia between 1945 and ' +
-            '2024. The number of nuclear weapons is plotted on the Y-axis ' +
-            'and the years on the X-axis. The chart is interactive, and the ' +
-            'year-on-year stockpile levels can be traced for each country. ' +
-            'The US has a stockpile of 2 nuclear weapons at the dawn of the ' +
-            'nuclear age in 1945. This number has gradually increased to 170 ' +
-            'by 1949 when the USSR enters the arms race with one weapon. At ' +
-            'this point, the US starts to rapidly build its stockpile ' +
-            'culminating in 31,255 warheads by 1966 compared to the USSR’s 8,' +
-            '400. From this peak in 1967, the US stockpile gradually ' +
-            'decreases as the USSR’s stockpile expands. By 1978 the USSR has ' +
-            'closed the nuclear gap at 25,393. The USSR stockpile continues ' +
-            'to grow until it reaches a peak of 40,159 in 1986 compared to ' +
-            'the US arsenal of 24,401. From 1986, the nuclear stockpiles of ' +
-            'both countries start to fall. By 2000, the numbers have fallen ' +
-            'to 10,577 and 12,188 for the US and Russia, respectively. The ' +
-            'decreases continue slowly after plateauing in the 2010s, and in ' +
-            '2024 the US has 3,708 weapons compared to Russia’s 4,380.'
-    },
-    title: {
-        text: 'US and USSR nuclear stockpiles'
-    },
-    subtitle: {
-        text: 'Source: <a href="https://fas.org/issues/nuclear-weapons/status-world-nuclear-forces/" ' +
-            'target="_blank">FAS</a>'
-    },
-    xAxis: {
-        allowDecimals: false,
-        accessibility: {
-            rangeDescription: 'Range: 1940 to 2024.'
-        }
-    },
-    yAxis: {
-        title: {
-            text: 'Nuclear weapon states'
-        }
-    },
-    tooltip: {
-        pointFormat: '{series.name} had stockpiled <b>{point.y:,.0f}</b><br/>' +
-            'warheads in {point.x}'
-    },
-    plotOptions: {
-        area: {
-            pointStart: 1940,
-            marker: {
-                enabled: false,
-                symbol: 'circle',
-                radius: 2,
-                states: {
-                    hover: {
-                        enabled: true
-                    }
-                }
-            }
-        }
-    },
-    series: [{
-        name: 'USA',
-        data: [
-            null, null, null, null, null, 2, 9, 13, 50, 170, 299, 438, 841,
-            1169, 1703, 2422, 3692, 5543, 7345, 12298, 18638, 22229, 25540,
-            28133, 29463, 31139, 31175, 31255, 29561, 27552, 26008, 25830,
-            26516, 27835, 28537, 27519, 25914, 25542, 24418, 24138, 24104,
-            23208, 22886, 23305, 23459, 23368, 23317, 23575, 23205, 22217,
-            21392, 19008, 13708, 11511, 10979, 10904, 11011, 10903, 10732,
-            10685, 10577, 10526, 10457, 10027, 8570, 8360, 7853, 5709, 5273,
-            5113, 5066, 4897, 4881, 4804, 4717, 4571, 4018, 3822, 3785, 3805,
-            3750, 3708, 3708, 3708, 3708
-        ]
-    }, {
-        name: 'USSR/Russia',
-        data: [
-            null, null, null, null, null, null, null, null, null,
-            1, 5, 25, 50, 120, 150, 200, 426, 660, 863, 1048, 1627, 2492,
-            3346, 4259, 5242, 6144, 7091, 8400, 9490, 10671, 11736, 13279,
-            14600, 15878, 17286, 19235, 22165, 24281, 26169, 28258, 30665,
-            32146, 33486, 35130, 36825, 38582, 40159, 38107, 36538, 35078,
-            32980, 29154, 26734, 24403, 21339, 18179, 15942, 15442, 14368,
-            13188, 12188, 11152, 10114, 9076, 8038, 7000, 6643, 6286, 5929,
-            5527, 5215, 4858, 4750, 4650, 4600, 4500, 4490, 4300, 4350, 4330,
-            4310, 4495, 4477, 4489, 4380
-        ]
-    }]
-});
-
-    `
-	return html
+	return Chart{
+		Htmlpreid:  `<div id="`,
+		Htmldivid:  divid,
+		Htmlpostid: `" class="flex justify-center mx-auto p-4"></div>`,
+		Jspreid:    `Highcharts.chart('`,
+		Jspostid:   `', ` + string(configJSON) + `);`,
+	}
 }
 
-// stacked area chart
+// apply writes the options that are set into a Highcharts config.
+func (o ChartOptions) apply(config map[string]interface{}) {
+	chart := configSection(config, "chart")
+	if o.Height > 0 {
+		chart["height"] = o.Height
+	}
+	if o.Width > 0 {
+		chart["width"] = o.Width
+	}
+	if o.BackgroundColor != "" {
+		chart["backgroundColor"] = o.BackgroundColor
+	}
+	if o.FontFamily != "" {
+		chart["style"] = map[string]interface{}{"fontFamily": o.FontFamily}
+	}
+	if len(o.Palette) > 0 {
+		config["colors"] = o.Palette
+	}
 
-// stacked percent area chart
+	o.applyAxis(config, "xAxis", o.XAxisTitle, o.XMin, o.XMax, o.XLog)
+	o.applyAxis(config, "yAxis", o.YAxisTitle, o.YMin, o.YMax, o.YLog)
 
-// donut chart
+	switch o.Legend {
+	case "none":
+		config["legend"] = map[string]interface{}{"enabled": false}
+	case "top", "bottom":
+		config["legend"] = map[string]interface{}{"enabled": true, "layout": "horizontal", "align": "center", "verticalAlign": o.Legend}
+	case "left", "right":
+		config["legend"] = map[string]interface{}{"enabled": true, "layout": "vertical", "align": o.Legend, "verticalAlign": "middle"}
+	}
+
+	// series-level settings also override the chart-type blocks (plotOptions.bar, ...)
+	if o.DataLabels != nil || o.Stacking != "" {
+		plotOptions := configSection(config, "plotOptions")
+		configSection(plotOptions, "series")
+		for _, v := range plotOptions {
+			block, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if o.DataLabels != nil {
+				configSection(block, "dataLabels")["enabled"] = *o.DataLabels
+			}
+			switch o.Stacking {
+			case "":
+			case "none":
+				block["stacking"] = nil
+			default:
+				block["stacking"] = o.Stacking
+			}
+		}
+	}
+
+	if o.Exporting != nil {
+		config["exporting"] = map[string]interface{}{"enabled": *o.Exporting}
+	}
+}
+
+func (o ChartOptions) applyAxis(config map[string]interface{}, key string, title string, min *float64, max *float64, log bool) {
+	if title == "" && min == nil && max == nil && !log && o.GridLines == nil {
+		return
+	}
+	axis := configSection(config, key)
+	if title != "" {
+		configSection(axis, "title")["text"] = title
+	}
+	if min != nil {
+		axis["min"] = *min
+	}
+	if max != nil {
+		axis["max"] = *max
+	}
+	if log {
+		axis["type"] = "logarithmic"
+	}
+	if o.GridLines != nil {
+		width := 0
+		if *o.GridLines {
+			width = 1
+		}
+		axis["gridLineWidth"] = width
+	}
+}
 
-// *
-func (df *DataFrame) DataTable(columns ...string) string {
-	html := `` // call display with only html return
-	return html
+// configSection returns the nested object config[key], creating it when missing.
+func configSection(config map[string]interface{}, key string) map[string]interface{} {
+	if section, ok := config[key].(map[string]interface{}); ok {
+		return section
+	}
+	section := map[string]interface{}{}
+	config[key] = section
+	return section
 }
 
-// histogram *
+// chartFloats returns the numeric values of a column, skipping nulls, non-numbers, NaN and ±Inf.
+func chartFloats(vals []interface{}) []float64 {
+	out := make([]float64, 0, len(vals))
+	for _, v := range vals {
+		if f, ok := chartValue(v).(float64); ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// quantile returns the q-th quantile of sorted values using linear interpolation.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	if lo == hi {
+		return sorted[lo]
+	}
+	// weighted sum rather than lo + (hi-lo)*frac, which overflows to NaN
+	// when the values span more than the float64 range
+	frac := pos - float64(lo)
+	return sorted[lo]*(1-frac) + sorted[hi]*frac
+}
 
 //
@@ -0,0 +1,630 @@
+package gophers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// columnKind is the physical layout of a typed column.
+type columnKind int
+
+const (
+	kindNull columnKind = iota
+	kindInt64
+	kindFloat64
+	kindString
+	kindBool
+	kindTimestamp
+	kindNested
+)
+
+// bitmap is a validity bitmap; bit i is set when row i holds a value.
+type bitmap []uint64
+
+func newBitmap(n int) bitmap { return make(bitmap, (n+63)/64) }
+
+func (b bitmap) set(i int)      { b[i>>6] |= 1 << uint(i&63) }
+func (b bitmap) get(i int) bool { return b[i>>6]&(1<<uint(i&63)) != 0 }
+
+// typedColumn stores one DataFrame column unboxed. Only the slice matching
+// kind is populated; valid marks non-null rows. The boxed DataFrame.Data
+// slice stays the compatibility view; typed columns are built from it once
+// and kept on the DataFrame (see typedCache).
+type typedColumn struct {
+	kind   columnKind
+	n      int
+	valid  bitmap
+	ints   []int64
+	floats []float64
+	strs   []string
+	bools  []bool
+	times  []time.Time
+	nested []interface{}
+	boxed  []interface{} // the compatibility view the column was built from
+	mixed  bool          // values were coerced from more than one Go type
+}
+
+// columnKindOf maps the inferred column type to a physical layout.
+func columnKindOf(values []interface{}) columnKind {
+	t, _ := inferColumnType(values)
+	switch {
+	case t == "null":
+		return kindNull
+	case t == "int":
+		return kindInt64
+	case t == "float":
+		return kindFloat64
+	case t == "boolean":
+		return kindBool
+	case t == "string":
+		return kindString
+	case t == "timestamp" || (t == "any" && allTimes(values)):
+		return kindTimestamp
+	default:
+		return kindNested
+	}
+}
+
+// newTypedColumn builds a typed column from boxed values, converting chunks in parallel.
+func newTypedColumn(values []interface{}) *typedColumn {
+	n := len(values)
+	c := &typedColumn{kind: columnKindOf(values), n: n, valid: newBitmap(n), boxed: values}
+	switch c.kind {
+	case kindInt64:
+		c.ints = make([]int64, n)
+	case kindFloat64:
+		c.floats = make([]float64, n)
+	case kindString:
+		c.strs = make([]string, n)
+	case kindBool:
+		c.bools = make([]bool, n)
+	case kindTimestamp:
+		c.times = make([]time.Time, n)
+	case kindNested:
+		c.nested = values
+	}
+
+	// chunks are multiples of 64 so goroutines never share a bitmap word
+	w := runtime.GOMAXPROCS(0)
+	chunk := ((n+w-1)/w + 63) &^ 63
+	if chunk == 0 {
+		chunk = 64
+	}
+	mixed := make([]bool, (n+chunk-1)/chunk)
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunk {
+		end := start + chunk
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(idx, s, e int) {
+			defer wg.Done()
+			for i := s; i < e; i++ {
+				v := values[i]
+				if v == nil {
+					continue
+				}
+				c.valid.set(i)
+				switch c.kind {
+				case kindInt64:
+					switch x := v.(type) {
+					case int:
+						c.ints[i] = int64(x)
+					case int32:
+						c.ints[i] = int64(x)
+					case int64:
+						c.ints[i] = x
+					}
+				case kindFloat64:
+					f, _ := toFloat64(v)
+					c.floats[i] = f
+				case kindString:
+					s, ok := v.(string)
+					if !ok {
+						s = fmt.Sprint(v)
+						mixed[idx] = true
+					}
+					c.strs[i] = s
+				case kindBool:
+					c.bools[i], _ = v.(bool)
+				case kindTimestamp:
+					c.times[i], _ = v.(time.Time)
+				}
+			}
+		}(start/chunk, start, end)
+	}
+	wg.Wait()
+	for _, m := range mixed {
+		c.mixed = c.mixed || m
+	}
+	return c
+}
+
+// typedCache keeps the typed columns of a DataFrame. An entry is reused while
+// Data still holds the slice it was built from and every value still matches,
+// so replacing, resizing or editing a column in place rebuilds it; methods
+// that edit a column themselves call dropTyped to skip the check.
+type typedCache struct {
+	mu   sync.Mutex
+	cols map[string]typedEntry
+}
+
+type typedEntry struct {
+	src  []interface{} // the Data slice the column was built from
+	rows int
+	col  *typedColumn
+}
+
+// typedCacheInit guards the lazy creation of DataFrame.typed.
+var typedCacheInit sync.Mutex
+
+func (df *DataFrame) typedColumns() *typedCache {
+	typedCacheInit.Lock()
+	defer typedCacheInit.Unlock()
+	if df.typed == nil {
+		df.typed = &typedCache{cols: make(map[string]typedEntry)}
+	}
+	return df.typed
+}
+
+// sameSlice reports whether a and b are the same slice of the same array.
+func sameSlice(a, b []interface{}) bool {
+	return len(a) == len(b) && (len(a) == 0 || &a[0] == &b[0])
+}
+
+// typedColumn returns the typed view of a column (nil if it does not exist),
+// building it on first use and reusing it until the column changes.
+func (df *DataFrame) typedColumn(name string) *typedColumn {
+	values, ok := df.Data[name]
+	if !ok {
+		return nil
+	}
+	cache := df.typedColumns()
+	cache.mu.Lock()
+	e, hit := cache.cols[name]
+	cache.mu.Unlock()
+	if hit && e.rows == df.Rows && sameSlice(e.src, values) && e.col.matches(values) {
+		return e.col
+	}
+
+	src := values
+	if len(values) < df.Rows {
+		padded := make([]interface{}, df.Rows)
+		copy(padded, values)
+		values = padded
+	}
+	c := newTypedColumn(values[:df.Rows])
+	cache.mu.Lock()
+	cache.cols[name] = typedEntry{src: src, rows: df.Rows, col: c}
+	cache.mu.Unlock()
+	return c
+}
+
+// dropTyped forgets the typed views of cols after their values were edited in place.
+func (df *DataFrame) dropTyped(cols ...string) {
+	cache := df.typedColumns()
+	cache.mu.Lock()
+	for _, c := range cols {
+		delete(cache.cols, c)
+	}
+	cache.mu.Unlock()
+}
+
+// matches reports whether values still hold what the column was built from,
+// so writes made directly to DataFrame.Data invalidate the typed view.
+func (c *typedColumn) matches(values []interface{}) bool {
+	for i := 0; i < c.n; i++ {
+		var v interface{}
+		if i < len(values) {
+			v = values[i]
+		}
+		if v == nil {
+			if c.isValid(i) {
+				return false
+			}
+			continue
+		}
+		if !c.isValid(i) {
+			return false
+		}
+		switch c.kind {
+		case kindInt64:
+			var x int64
+			switch n := v.(type) {
+			case int:
+				x = int64(n)
+			case int32:
+				x = int64(n)
+			case int64:
+				x = n
+			default:
+				return false
+			}
+			if x != c.ints[i] {
+				return false
+			}
+		case kindFloat64:
+			f, err := toFloat64(v)
+			if err != nil || math.Float64bits(f) != math.Float64bits(c.floats[i]) {
+				return false
+			}
+		case kindString:
+			s, ok := v.(string)
+			if !ok {
+				if !c.mixed {
+					return false
+				}
+				s = fmt.Sprint(v)
+			}
+			if s != c.strs[i] {
+				return false
+			}
+		case kindBool:
+			if b, ok := v.(bool); !ok || b != c.bools[i] {
+				return false
+			}
+		case kindTimestamp:
+			if t, ok := v.(time.Time); !ok || t != c.times[i] {
+				return false
+			}
+		case kindNull:
+			return false
+		}
+	}
+	return true
+}
+
+// isValid reports whether row i holds a non-null value.
+func (c *typedColumn) isValid(i int) bool { return c.valid.get(i) }
+
+// value returns row i boxed the way DataFrame.Data stores it.
+func (c *typedColumn) value(i int) interface{} {
+	if !c.isValid(i) {
+		return nil
+	}
+	switch c.kind {
+	case kindInt64:
+		return int(c.ints[i])
+	case kindFloat64:
+		return c.floats[i]
+	case kindString:
+		return c.strs[i]
+	case kindBool:
+		return c.bools[i]
+	case kindTimestamp:
+		return c.times[i]
+	case kindNested:
+		return c.nested[i]
+	}
+	return nil
+}
+
+// float returns row i as a float64 for numeric and timestamp columns.
+func (c *typedColumn) float(i int) (float64, bool) {
+	if !c.isValid(i) {
+		return 0, false
+	}
+	switch c.kind {
+	case kindInt64:
+		return float64(c.ints[i]), true
+	case kindFloat64:
+		return c.floats[i], true
+	case kindTimestamp:
+		return float64(c.times[i].UnixNano()), true
+	}
+	return 0, false
+}
+
+// compare orders rows i and j; NaN sorts after every number and nulls after
+// every value.
+func (c *typedColumn) compare(i, j int) int {
+	vi, vj := c.isValid(i), c.isValid(j)
+	switch {
+	case !vi && !vj:
+		return 0
+	case !vi:
+		return 1
+	case !vj:
+		return -1
+	}
+	switch c.kind {
+	case kindInt64:
+		return cmpOrdered(c.ints[i], c.ints[j])
+	case kindFloat64:
+		return cmpOrdered(c.floats[i], c.floats[j])
+	case kindString:
+		return strings.Compare(c.strs[i], c.strs[j])
+	case kindBool:
+		if c.bools[i] == c.bools[j] {
+			return 0
+		}
+		if !c.bools[i] {
+			return -1
+		}
+		return 1
+	case kindTimestamp:
+		return c.times[i].Compare(c.times[j])
+	case kindNested:
+		return strings.Compare(fmt.Sprint(c.nested[i]), fmt.Sprint(c.nested[j]))
+	}
+	return 0
+}
+
+// cmpOrdered orders a and b, placing NaN after every number.
+func cmpOrdered[T int64 | float64](a, b T) int {
+	// a != a only holds for NaN
+	switch an, bn := a != a, b != b; {
+	case an && bn:
+		return 0
+	case an:
+		return 1
+	case bn:
+		return -1
+	}
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// key returns a comparable grouping key for row i without boxing numbers
+// through interface comparisons; rows with equal keys belong to one group.
+func (c *typedColumn) key(i int) typedKey {
+	if !c.isValid(i) {
+		return typedKey{}
+	}
+	if c.mixed {
+		return typedKey{valid: true, s: canonicalKey(c.boxed[i])}
+	}
+	switch c.kind {
+	case kindInt64:
+		return typedKey{valid: true, i: c.ints[i]}
+	case kindFloat64:
+		if f := c.floats[i]; f != f {
+			// NaN never equals itself, so all NaNs share one key
+			return typedKey{valid: true, s: "NaN"}
+		}
+		return typedKey{valid: true, f: c.floats[i]}
+	case kindString:
+		return typedKey{valid: true, s: c.strs[i]}
+	case kindBool:
+		if c.bools[i] {
+			return typedKey{valid: true, i: 1}
+		}
+		return typedKey{valid: true}
+	case kindTimestamp:
+		return typedKey{valid: true, i: c.times[i].UnixNano()}
+	}
+	return typedKey{valid: true, s: canonicalKey(c.nested[i])}
+}
+
+// typedKey is a hashable, unboxed value of a single column.
+type typedKey struct {
+	valid bool
+	i     int64
+	f     float64
+	s     string
+}
+
+// typedGroup is one group of rows sharing the same key, in first-appearance order.
+type typedGroup struct {
+	first int // first row of the group, used to recover the boxed key
+	rows  []int
+}
+
+// groupRows partitions row indexes by the typed keys of cols. Shards are
+// grouped in parallel and merged in shard order so groups keep the order
+// in which their keys first appear.
+func groupRows(keys []*typedColumn, n int) []*typedGroup {
+	if len(keys) == 1 {
+		return groupRowsBy(n, keys[0].key)
+	}
+	return groupRowsBy(n, func(i int) string {
+		var b strings.Builder
+		for _, k := range keys {
+			tk := k.key(i)
+			fmt.Fprintf(&b, "%t|%d|%g|%s\x00", tk.valid, tk.i, tk.f, tk.s)
+		}
+		return b.String()
+	})
+}
+
+func groupRowsBy[K comparable](n int, keyOf func(int) K) []*typedGroup {
+	w := runtime.GOMAXPROCS(0)
+	chunk := (n + w - 1) / w
+	if chunk < 1 {
+		chunk = 1
+	}
+	type shard struct {
+		order []K
+		group []*typedGroup // group[i] holds the rows of order[i]
+		index map[K]*typedGroup
+	}
+	shards := make([]shard, (n+chunk-1)/chunk)
+	var wg sync.WaitGroup
+	for start := 0; start < n; start += chunk {
+		end := start + chunk
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(idx, s, e int) {
+			defer wg.Done()
+			local := shard{index: make(map[K]*typedGroup)}
+			for i := s; i < e; i++ {
+				k := keyOf(i)
+				g, ok := local.index[k]
+				if !ok {
+					g = &typedGroup{first: i}
+					local.index[k] = g
+					local.order = append(local.order, k)
+					local.group = append(local.group, g)
+				}
+				g.rows = append(g.rows, i)
+			}
+			shards[idx] = local
+		}(start/chunk, start, end)
+	}
+	wg.Wait()
+
+	master := make(map[K]*typedGroup)
+	var groups []*typedGroup
+	for _, sh := range shards {
+		for j, k := range sh.order {
+			g := sh.group[j]
+			if dst, ok := master[k]; ok {
+				dst.rows = append(dst.rows, g.rows...)
+				continue
+			}
+			master[k] = g
+			groups = append(groups, g)
+		}
+	}
+	return groups
+}
+
+// gather returns the boxed values of col at the given rows.
+func gather(col []interface{}, rows []int) []interface{} {
+	out := make([]interface{}, len(rows))
+	for i, r := range rows {
+		if r < len(col) {
+			out[i] = col[r]
+		}
+	}
+	return out
+}
+
+// vectorMask evaluates simple ColumnExpr predicates (column vs literal
+// comparisons, null checks and and/or of those) directly on typed columns.
+// It returns false when the expression needs the row-wise Compile path.
+func vectorMask(e ColumnExpr, df *DataFrame) ([]bool, bool) {
+	n := df.Rows
+	switch e.Type {
+	case "and", "or":
+		var l, r ColumnExpr
+		if json.Unmarshal(e.Left, &l) != nil || json.Unmarshal(e.Right, &r) != nil {
+			return nil, false
+		}
+		lm, ok := vectorMask(l, df)
+		if !ok {
+			return nil, false
+		}
+		rm, ok := vectorMask(r, df)
+		if !ok {
+			return nil, false
+		}
+		for i := range lm {
+			if e.Type == "and" {
+				lm[i] = lm[i] && rm[i]
+			} else {
+				lm[i] = lm[i] || rm[i]
+			}
+		}
+		return lm, true
+	case "isnull", "isnotnull":
+		var sub ColumnExpr
+		if json.Unmarshal(e.Expr, &sub) != nil || sub.Type != "col" {
+			return nil, false
+		}
+		c := df.typedColumn(sub.Name)
+		if c == nil {
+			return nil, false
+		}
+		mask := make([]bool, n)
+		for i := 0; i < n; i++ {
+			null := !c.isValid(i)
+			if !null && c.kind == kindString {
+				null = c.strs[i] == "" || strings.ToLower(c.strs[i]) == "null"
+			}
+			mask[i] = null == (e.Type == "isnull")
+		}
+		return mask, true
+	case "col":
+		c := df.typedColumn(e.Name)
+		if c == nil || c.kind != kindBool {
+			return nil, false
+		}
+		mask := make([]bool, n)
+		for i := 0; i < n; i++ {
+			mask[i] = c.isValid(i) && c.bools[i]
+		}
+		return mask, true
+	case "gt", "ge", "lt", "le", "eq", "ne":
+	default:
+		return nil, false
+	}
+
+	var l, r ColumnExpr
+	if json.Unmarshal(e.Left, &l) != nil || json.Unmarshal(e.Right, &r) != nil {
+		return nil, false
+	}
+	op := e.Type
+	if l.Type == "lit" && r.Type == "col" {
+		l, r = r, l
+		op = map[string]string{"gt": "lt", "ge": "le", "lt": "gt", "le": "ge", "eq": "eq", "ne": "ne"}[op]
+	}
+	if l.Type != "col" || r.Type != "lit" {
+		return nil, false
+	}
+	c := df.typedColumn(l.Name)
+	if c == nil {
+		return nil, false
+	}
+	mask := make([]bool, n)
+	lit, litErr := toFloat64(r.Value)
+
+	switch {
+	case c.kind == kindInt64 || c.kind == kindFloat64:
+		if litErr != nil {
+			if op == "eq" || op == "ne" {
+				return nil, false
+			}
+			return mask, true // numeric-only comparisons never match
+		}
+		for i := 0; i < n; i++ {
+			f, ok := c.float(i)
+			if !ok {
+				mask[i] = op == "ne"
+				continue
+			}
+			switch op {
+			case "gt":
+				mask[i] = f > lit
+			case "ge":
+				mask[i] = f >= lit
+			case "lt":
+				mask[i] = f < lit
+			case "le":
+				mask[i] = f <= lit
+			case "eq":
+				mask[i] = f == lit
+			case "ne":
+				mask[i] = f != lit
+			}
+		}
+		return mask, true
+	case c.kind == kindString && !c.mixed, c.kind == kindNull:
+		if op != "eq" && op != "ne" {
+			return mask, true
+		}
+		rs, _ := toString(r.Value)
+		for i := 0; i < n; i++ {
+			var s string
+			if c.isValid(i) {
+				s = c.strs[i]
+			}
+			mask[i] = (s == rs) == (op == "eq")
+		}
+		return mask, true
+	}
+	return nil, false
+}
@@ -0,0 +1,414 @@
+package gophers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	// embeds the IANA time zone database so FromUTC and ToUTC work where the
+	// system has none, such as WASM builds and slim containers
+	_ "time/tzdata"
+)
+
+// dateLayouts are the layouts tried when a date function reads a string and
+// no formats are given. Slashed dates are read month first.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"01/02/2006 15:04:05",
+	"01/02/2006",
+	"Jan 2, 2006",
+	"2 Jan 2006",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// formatTokens maps the user-friendly format tokens to Go layout elements,
+// longest first so "MMM" is not read as "MM".
+var formatTokens = []struct{ token, layout string }{
+	{"yyyy", "2006"}, {"yy", "06"},
+	{"MMMM", "January"}, {"MMM", "Jan"}, {"MM", "01"},
+	{"dd", "02"},
+	{"EEEE", "Monday"}, {"EEE", "Mon"},
+	{"HH", "15"}, {"hh", "15"}, {"mm", "04"}, {"ss", "05"},
+	{"SSSSSS", "000000"}, {"SSSS", "0000"}, {"SSS", "000"},
+	{"XXX", "Z07:00"},
+}
+
+// parseTimeLayouts parses s with the given layouts, or dateLayouts when none.
+func parseTimeLayouts(s string, layouts []string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if len(layouts) == 0 {
+		layouts = dateLayouts
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// timeValue reads v as a time: a time.Time as is, or a string parsed with
+// layouts. With epoch set, numbers are Unix seconds. Nulls and anything that
+// does not parse give false.
+func timeValue(v interface{}, layouts []string, epoch bool) (time.Time, bool) {
+	switch x := v.(type) {
+	case time.Time:
+		return x, true
+	case *time.Time:
+		if x != nil {
+			return *x, true
+		}
+	case string:
+		if isNullValue(x) {
+			return time.Time{}, false
+		}
+		if t, ok := parseTimeLayouts(x, layouts); ok {
+			return t, true
+		}
+		if epoch {
+			if n, err := strconv.ParseFloat(strings.TrimSpace(x), 64); err == nil {
+				return epochTime(n), true
+			}
+		}
+	default:
+		if f, ok := asFloat(v); ok && epoch {
+			return epochTime(f), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// epochTime converts Unix seconds, with a fraction, to a UTC time.
+func epochTime(secs float64) time.Time {
+	whole := int64(secs)
+	return time.Unix(whole, int64((secs-float64(whole))*1e9)).UTC()
+}
+
+// dateLayoutsOf converts user formats to Go layouts.
+func dateLayoutsOf(formats []string) []string {
+	layouts := make([]string, len(formats))
+	for i, f := range formats {
+		layouts[i] = convertFormat(f)
+	}
+	return layouts
+}
+
+// timeOp applies f to the column's value read as a time (see timeValue).
+// Values that are not times give null.
+func (c Column) timeOp(name string, f func(t time.Time) interface{}) Column {
+	return Column{
+		Name: fmt.Sprintf("%s(%s)", name, c.Name),
+		Fn: func(row map[string]interface{}) interface{} {
+			t, ok := timeValue(c.Fn(row), nil, false)
+			if !ok {
+				return nil
+			}
+			return f(t)
+		},
+	}
+}
+
+// isDate is the date func of a column that always gives dates.
+func isDate(*DataFrame) bool { return true }
+
+// dateIn reports whether c gives a date column when added to df.
+func (c Column) dateIn(df *DataFrame) bool {
+	return c.date != nil && c.date(df)
+}
+
+// allDates returns a date func reporting whether every one of cols gives
+// dates, so If and Coalesce over date columns give a date column.
+func allDates(cols ...Column) func(df *DataFrame) bool {
+	for _, c := range cols {
+		if c.date == nil {
+			return nil
+		}
+	}
+	return func(df *DataFrame) bool {
+		for _, c := range cols {
+			if !c.date(df) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// ToTimestamp parses the column into a time.Time. Strings are tried against
+// each format in turn (e.g. "yyyy-MM-dd HH:mm:ss", "dd/MM/yyyy", or a Go
+// layout), or common ISO-8601 and US layouts when none are given; numbers are
+// Unix seconds. Values that do not parse give null. The column is a
+// timestamp column, even where every time is midnight.
+func (c Column) ToTimestamp(formats ...string) Column {
+	layouts := dateLayoutsOf(formats)
+	return Column{
+		Name: fmt.Sprintf("to_timestamp(%s)", c.Name),
+		Fn: func(row map[string]interface{}) interface{} {
+			t, ok := timeValue(c.Fn(row), layouts, true)
+			if !ok {
+				return nil
+			}
+			return t
+		},
+	}
+}
+
+// ToDate parses the column like ToTimestamp and drops the time of day and
+// zone, giving UTC midnight of the calendar date as a time.Time. The column
+// is a date column (see DataFrame.Dates).
+func (c Column) ToDate(formats ...string) Column {
+	ts := c.ToTimestamp(formats...)
+	return Column{
+		Name: fmt.Sprintf("to_date(%s)", c.Name),
+		Fn: func(row map[string]interface{}) interface{} {
+			t, ok := ts.Fn(row).(time.Time)
+			if !ok {
+				return nil
+			}
+			y, m, d := t.Date()
+			return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		},
+		date: isDate,
+	}
+}
+
+// DateTrunc truncates the time to the start of its "year", "quarter",
+// "month", "week" (Monday), "day", "hour", "minute" or "second". An unknown
+// unit gives null. A date column stays a date column and a timestamp column
+// a timestamp column.
+func (c Column) DateTrunc(unit string) Column {
+	unit = strings.ToLower(unit)
+	out := c.timeOp("date_trunc", func(t time.Time) interface{} {
+		if out, ok := truncateTime(t, unit); ok {
+			return out
+		}
+		return nil
+	})
+	out.date = c.date
+	return out
+}
+
+// truncateTime truncates t to the start of unit in t's location.
+func truncateTime(t time.Time, unit string) (time.Time, bool) {
+	y, m, d := t.Date()
+	loc := t.Location()
+	switch strings.TrimSuffix(unit, "s") {
+	case "year":
+		return time.Date(y, 1, 1, 0, 0, 0, 0, loc), true
+	case "quarter":
+		return time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, loc), true
+	case "month":
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc), true
+	case "week":
+		back := (int(t.Weekday()) + 6) % 7 // days since Monday
+		return time.Date(y, m, d-back, 0, 0, 0, 0, loc), true
+	case "day":
+		return time.Date(y, m, d, 0, 0, 0, 0, loc), true
+	case "hour":
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, loc), true
+	case "minute":
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, loc), true
+	case "second":
+		return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, loc), true
+	}
+	return time.Time{}, false
+}
+
+// DateAdd adds n units ("year", "quarter", "month", "week", "day", "hour",
+// "minute" or "second"; n may be negative) to the time. Adding months keeps
+// the day where it can and clamps it to the month's last day otherwise, so
+// Jan 31 plus one month is Feb 28 (29). An unknown unit gives null. A date
+// column stays a date column and a timestamp column a timestamp column.
+func (c Column) DateAdd(unit string, n int) Column {
+	unit = strings.ToLower(unit)
+	out := c.timeOp("date_add", func(t time.Time) interface{} {
+		switch strings.TrimSuffix(unit, "s") {
+		case "year":
+			return addMonths(t, 12*n)
+		case "quarter":
+			return addMonths(t, 3*n)
+		case "month":
+			return addMonths(t, n)
+		case "week":
+			return t.AddDate(0, 0, 7*n)
+		case "day":
+			return t.AddDate(0, 0, n)
+		case "hour":
+			return t.Add(time.Duration(n) * time.Hour)
+		case "minute":
+			return t.Add(time.Duration(n) * time.Minute)
+		case "second":
+			return t.Add(time.Duration(n) * time.Second)
+		}
+		return nil
+	})
+	out.date = c.date
+	return out
+}
+
+// addMonths adds n months to t, clamping the day to the target month's length.
+func addMonths(t time.Time, n int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); d > last {
+		d = last
+	}
+	return first.AddDate(0, 0, d-1)
+}
+
+// Year returns the year of the time.
+func (c Column) Year() Column {
+	return c.timeOp("year", func(t time.Time) interface{} { return t.Year() })
+}
+
+// Quarter returns the quarter of the year, 1 to 4.
+func (c Column) Quarter() Column {
+	return c.timeOp("quarter", func(t time.Time) interface{} { return (int(t.Month())-1)/3 + 1 })
+}
+
+// Month returns the month, 1 to 12.
+func (c Column) Month() Column {
+	return c.timeOp("month", func(t time.Time) interface{} { return int(t.Month()) })
+}
+
+// Day returns the day of the month, 1 to 31.
+func (c Column) Day() Column {
+	return c.timeOp("day", func(t time.Time) interface{} { return t.Day() })
+}
+
+// DayOfWeek returns the ISO-8601 day of the week, 1 (Monday) to 7 (Sunday).
+func (c Column) DayOfWeek() Column {
+	return c.timeOp("dayofweek", func(t time.Time) interface{} { return (int(t.Weekday())+6)%7 + 1 })
+}
+
+// WeekOfYear returns the ISO-8601 week number, 1 to 53.
+func (c Column) WeekOfYear() Column {
+	return c.timeOp("weekofyear", func(t time.Time) interface{} {
+		_, week := t.ISOWeek()
+		return week
+	})
+}
+
+// Hour returns the hour of the day, 0 to 23.
+func (c Column) Hour() Column {
+	return c.timeOp("hour", func(t time.Time) interface{} { return t.Hour() })
+}
+
+// FromUTC returns the same instant in the IANA zone (e.g. "America/New_York"),
+// so its wall clock shows local time. Times parsed without an offset are UTC.
+// An unknown zone gives null.
+func (c Column) FromUTC(zone string) Column {
+	loc, err := time.LoadLocation(zone)
+	return c.timeOp("from_utc", func(t time.Time) interface{} {
+		if err != nil {
+			return nil
+		}
+		return t.In(loc)
+	})
+}
+
+// ToUTC reads the time's wall clock as local time in the IANA zone and
+// returns that instant in UTC. An unknown zone gives null.
+func (c Column) ToUTC(zone string) Column {
+	loc, err := time.LoadLocation(zone)
+	return c.timeOp("to_utc", func(t time.Time) interface{} {
+		if err != nil {
+			return nil
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc).UTC()
+	})
+}
+
+// FormatDate formats the time as a string with a user-friendly format such as
+// "yyyy-MM-dd HH:mm" or "EEE, dd MMM yyyy" (see convertFormat), or a Go layout.
+func (c Column) FormatDate(format string) Column {
+	layout := convertFormat(format)
+	return c.timeOp("format_date", func(t time.Time) interface{} { return t.Format(layout) })
+}
+
+// isoTime formats t for the sinks: "2006-01-02" when t belongs to a date
+// column (see DataFrame.Dates) and RFC 3339 with any fraction of a second
+// otherwise. A non-empty format (a user-friendly format as in FormatDate, or a
+// Go layout) is used instead.
+func isoTime(t time.Time, format string, date bool) string {
+	switch {
+	case format != "":
+		return t.Format(convertFormat(format))
+	case date:
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// specDate reports whether spec, a Column or ColumnExpr as DataFrame.Column
+// takes, gives a date column in df.
+func specDate(df *DataFrame, spec interface{}) bool {
+	var c Column
+	switch v := spec.(type) {
+	case Column:
+		c = v
+	case ColumnExpr:
+		if strings.EqualFold(v.Type, "window") {
+			return false
+		}
+		c = Compile(v)
+	}
+	return c.dateIn(df)
+}
+
+// setDate records whether col is a date column (see DataFrame.Dates).
+func (df *DataFrame) setDate(col string, date bool) {
+	if date {
+		if df.Dates == nil {
+			df.Dates = make(map[string]bool)
+		}
+		df.Dates[col] = true
+	} else {
+		delete(df.Dates, col)
+	}
+}
+
+// datesOf returns the date columns of df among cols, or nil when there are
+// none, for a frame built from those columns.
+func (df *DataFrame) datesOf(cols []string) map[string]bool {
+	var dates map[string]bool
+	for _, col := range cols {
+		if df.Dates[col] {
+			if dates == nil {
+				dates = make(map[string]bool)
+			}
+			dates[col] = true
+		}
+	}
+	return dates
+}
+
+// dateColumns reports the date columns of df (see DataFrame.Dates), so the
+// sinks write a date column without times of day and every value of a
+// timestamp column, midnight included, as a full timestamp.
+func dateColumns(df *DataFrame) map[string]bool {
+	return df.datesOf(df.Cols)
+}
+
+// displayTime formats t for Show, Head, Tail, Vertical and Display: a value
+// of a date column as "2006-01-02", otherwise "2006-01-02 15:04:05" with any
+// fraction of a second and the zone when it is not UTC.
+func displayTime(t time.Time, date bool) string {
+	if date {
+		return t.Format(time.DateOnly)
+	}
+	s := t.Format("2006-01-02 15:04:05.999999999")
+	if t.Location() != time.UTC {
+		s += t.Format(" MST")
+	}
+	return s
+}
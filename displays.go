@@ -8,6 +8,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
@@ -57,6 +58,8 @@ func (df *DataFrame) Show(chars, recordCount int) string {
 					s = strconv.FormatBool(v)
 				case string:
 					s = v
+				case time.Time:
+					s = displayTime(v, df.Dates[col])
 				default:
 					s = fmt.Sprintf("%v", v)
 				}
@@ -155,6 +158,8 @@ func (df *DataFrame) Head(chars int) string {
 				s = strconv.FormatBool(v)
 			case string:
 				s = v
+			case time.Time:
+				s = displayTime(v, df.Dates[col])
 			default:
 				s = fmt.Sprintf("%v", v)
 			}
@@ -216,6 +221,8 @@ func (df *DataFrame) Tail(chars int) string {
 					s = strconv.FormatBool(v)
 				case string:
 					s = v
+				case time.Time:
+					s = displayTime(v, df.Dates[col])
 				default:
 					s = fmt.Sprintf("%v", v)
 				}
@@ -285,6 +292,8 @@ func (df *DataFrame) Vertical(chars int, recordCount int) string {
 					v = strconv.FormatBool(t)
 				case string:
 					v = t
+				case time.Time:
+					v = displayTime(t, df.Dates[col])
 				default:
 					v = fmt.Sprintf("%v", t)
 				}
@@ -303,7 +312,10 @@ func (df *DataFrame) Vertical(chars int, recordCount int) string {
 }
 
 func DisplayChart(chart Chart) map[string]interface{} {
-	html := chart.Htmlpreid + chart.Htmldivid + chart.Htmlpostid + chart.Jspreid + chart.Htmldivid + chart.Jspostid
+	html := chart.Htmlpreid + chart.Htmldivid + chart.Htmlpostid
+	if chart.Jspreid != "" {
+		html += chart.Jspreid + chart.Htmldivid + chart.Jspostid
+	}
 	return map[string]interface{}{
 		"text/html": html,
 	}
@@ -348,6 +360,8 @@ func mapToString(data map[string][]interface{}) string {
 				builder.WriteString(fmt.Sprintf("%v", v))
 			case string:
 				builder.WriteString(fmt.Sprintf("%q", v))
+			case time.Time:
+				builder.WriteString(fmt.Sprintf("%q", displayTime(v, false)))
 			default:
 				builder.WriteString(fmt.Sprintf("%q", fmt.Sprintf("%v", v)))
 			}
@@ -372,6 +386,9 @@ func rowsJSONString(df *DataFrame) string {
 			} else {
 				v = nil
 			}
+			if t, ok := v.(time.Time); ok {
+				v = displayTime(t, df.Dates[col])
+			}
 			row[col] = v
 		}
 		rows[i] = row
@@ -380,8 +397,9 @@ func rowsJSONString(df *DataFrame) string {
 	return string(b)
 }
 
-// DisplayHTML returns a value that gophernotes recognizes as rich HTML output.
-func (df *DataFrame) DisplayBrowser() error {
+// DisplayBrowser opens an html table of the dataframe in the default browser.
+// SaveOptions{Offline: true} embeds the CDN assets in the page.
+func (df *DataFrame) DisplayBrowser(opts ...SaveOptions) error {
 	// display an html table of the dataframe for analysis, filtering, sorting, etc
 	html := `
     <!DOCTYPE html>
@@ -391,6 +409,9 @@ func (df *DataFrame) DisplayBrowser() error {
         <link href="https://cdn.jsdelivr.net/npm/daisyui@5" rel="stylesheet" type="text/css" />
         <script src="https://cdn.jsdelivr.net/npm/@tailwindcss/browser@4"></script>
         <script src="https://code.highcharts.com/highcharts.js"></script>
+        <script src="https://code.highcharts.com/highcharts-more.js"></script>
+        <script src="https://code.highcharts.com/modules/treemap.js"></script>
+        <script src="https://code.highcharts.com/modules/heatmap.js"></script>
         <script src="https://code.highcharts.com/modules/boost.js"></script>
         <script src="https://code.highcharts.com/modules/exporting.js"></script>
 
@@ -617,6 +638,12 @@ func (df *DataFrame) DisplayBrowser() error {
     </html>
 
 		`
+	if len(opts) > 0 && opts[0].Offline {
+		var err error
+		if html, err = inlineAssets(html); err != nil {
+			return err
+		}
+	}
 	// Create a temporary file
 	tmpFile, err := os.CreateTemp(os.TempDir(), "temp-*.html")
 	if err != nil {
@@ -657,6 +684,9 @@ func (df *DataFrame) Display() map[string]interface{} {
         <link href="https://cdn.jsdelivr.net/npm/daisyui@5" rel="stylesheet" type="text/css" />
         <script src="https://cdn.jsdelivr.net/npm/@tailwindcss/browser@4"></script>
         <script src="https://code.highcharts.com/highcharts.js"></script>
+        <script src="https://code.highcharts.com/highcharts-more.js"></script>
+        <script src="https://code.highcharts.com/modules/treemap.js"></script>
+        <script src="https://code.highcharts.com/modules/heatmap.js"></script>
         <script src="https://code.highcharts.com/modules/boost.js"></script>
         <script src="https://code.highcharts.com/modules/exporting.js"></script>
 
@@ -887,12 +917,19 @@ func (df *DataFrame) Display() map[string]interface{} {
 }
 
 // write an html display, chart, or report to a file
-func (df *DataFrame) DisplayToFile(path string) error {
+// SaveOptions{Offline: true} embeds the CDN assets in the file.
+func (df *DataFrame) DisplayToFile(path string, opts ...SaveOptions) error {
 	// Ensure the path ends with .html
 	if !strings.HasSuffix(path, ".html") {
 		path += ".html"
 	}
 	html := df.Display()["text/html"].(string)
+	if len(opts) > 0 && opts[0].Offline {
+		var err error
+		if html, err = inlineAssets(html); err != nil {
+			return err
+		}
+	}
 
 	// Write the HTML string to the specified file path
 	err := os.WriteFile(path, []byte(html), 0644)
@@ -8,11 +8,14 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
+	"math"
 	"net/http"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 
 	"github.com/openai/openai-go/v3"
@@ -302,6 +305,7 @@ func Col(name string) Column {
 		Fn: func(row map[string]interface{}) interface{} {
 			return row[name]
 		},
+		date: func(df *DataFrame) bool { return df.Dates[name] },
 	}
 }
 
@@ -397,22 +401,26 @@ func CurrentDate() Column {
 }
 
 // convertFormat converts user-friendly format strings (e.g., "yyyy-MM-dd hh:mm:ss.SSSS")
-// to Go's reference time layout (e.g., "2006-01-02 15:04:05.0000").
+// to Go's reference time layout (e.g., "2006-01-02 15:04:05.0000"). The tokens
+// are listed in formatTokens; other text, including a Go layout, is kept.
 func convertFormat(userFmt string) string {
-	replacements := map[string]string{
-		"yyyy": "2006",
-		"MM":   "01",
-		"dd":   "02",
-		"hh":   "15",
-		"mm":   "04",
-		"ss":   "05",
-		"SSSS": "0000",
-	}
-	result := userFmt
-	for k, v := range replacements {
-		result = strings.ReplaceAll(result, k, v)
+	var b strings.Builder
+	for i := 0; i < len(userFmt); {
+		matched := false
+		for _, t := range formatTokens {
+			if strings.HasPrefix(userFmt[i:], t.token) {
+				b.WriteString(t.layout)
+				i += len(t.token)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(userFmt[i])
+			i++
+		}
 	}
-	return result
+	return b.String()
 }
 
 // DateDiff returns a Column that computes the number of days between two date columns.
@@ -1031,6 +1039,317 @@ func sqlLikeToRegex(pat string) string {
 	return b.String()
 }
 
+// IsIn returns true when the value equals one of values, which may be literals
+// or Columns. Numbers compare numerically, other values as strings; null never matches.
+func (c Column) IsIn(values ...interface{}) Column {
+	return Column{
+		Name: c.Name + ".isin",
+		Fn: func(row map[string]interface{}) interface{} {
+			v := c.Fn(row)
+			if v == nil {
+				return false
+			}
+			for _, x := range values {
+				x = operandValue(x, row)
+				if x != nil && eqValues(v, x) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// IsBetween returns true when lower <= value <= upper. The bounds may be
+// literals or Columns. Numbers compare numerically, strings (e.g. ISO dates)
+// lexicographically; null never matches.
+func (c Column) IsBetween(lower, upper interface{}) Column {
+	return Column{
+		Name: c.Name + ".isbetween",
+		Fn: func(row map[string]interface{}) interface{} {
+			v := c.Fn(row)
+			lo, hi := operandValue(lower, row), operandValue(upper, row)
+			if v == nil || lo == nil || hi == nil {
+				return false
+			}
+			if f, ok := asFloat(v); ok {
+				fl, okl := asFloat(lo)
+				fh, okh := asFloat(hi)
+				return okl && okh && fl <= f && f <= fh
+			}
+			s, sl, sh := fastToString(v), fastToString(lo), fastToString(hi)
+			return sl <= s && s <= sh
+		},
+	}
+}
+
+// operandColumn wraps a literal operand as a Column.
+func operandColumn(v interface{}) Column {
+	if c, ok := v.(Column); ok {
+		return c
+	}
+	return Lit(v)
+}
+
+// Add returns the column plus other, a literal or Column. Numeric strings, such
+// as CSV cells, are parsed first. Two integers give an int64 (a float64 when the
+// result overflows), anything involving a float gives a float64, and a null or
+// non-numeric operand gives null.
+func (c Column) Add(other interface{}) Column { return arithmetic("add", c, operandColumn(other)) }
+
+// Sub returns the column minus other, with the promotion rules of Add.
+func (c Column) Sub(other interface{}) Column { return arithmetic("sub", c, operandColumn(other)) }
+
+// Mul returns the column times other, with the promotion rules of Add.
+func (c Column) Mul(other interface{}) Column { return arithmetic("mul", c, operandColumn(other)) }
+
+// Div returns the column divided by other as a float64; dividing by zero gives null.
+func (c Column) Div(other interface{}) Column { return arithmetic("div", c, operandColumn(other)) }
+
+// Mod returns the remainder of the column divided by other, with the promotion
+// rules of Add; a zero divisor gives null.
+func (c Column) Mod(other interface{}) Column { return arithmetic("mod", c, operandColumn(other)) }
+
+// numericValue returns v as an int64 or float64, or false for nulls and
+// non-numeric values. Strings are parsed as chartValue does: whole numbers give
+// an int64, others a float64, and "NaN" or "Inf" is not numeric.
+func numericValue(v interface{}) (interface{}, bool) {
+	if i, ok := asInt64(v); ok {
+		return i, true
+	}
+	if f, ok := asFloat(v); ok {
+		return f, true
+	}
+	if s, ok := v.(string); ok {
+		s = strings.TrimSpace(s)
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return i, true
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+// numericFloat returns v as a float64 when numericValue accepts it.
+func numericFloat(v interface{}) (float64, bool) {
+	n, ok := numericValue(v)
+	if !ok {
+		return 0, false
+	}
+	return asFloat(n)
+}
+
+// mathOp applies f to the column's numeric value as a float64. Nulls,
+// non-numeric values and NaN results (e.g. Sqrt(-1)) give null.
+func (c Column) mathOp(name string, f func(x float64) float64) Column {
+	return Column{
+		Name: fmt.Sprintf("%s(%s)", name, c.Name),
+		Fn: func(row map[string]interface{}) interface{} {
+			x, ok := numericFloat(c.Fn(row))
+			if !ok {
+				return nil
+			}
+			if y := f(x); !math.IsNaN(y) && !math.IsInf(y, 0) {
+				return y
+			}
+			return nil
+		},
+	}
+}
+
+// Pow returns the column raised to the power other, a literal or Column, as a float64.
+func (c Column) Pow(other interface{}) Column {
+	o := operandColumn(other)
+	return Column{
+		Name: fmt.Sprintf("pow(%s, %s)", c.Name, o.Name),
+		Fn: func(row map[string]interface{}) interface{} {
+			x, okx := numericFloat(c.Fn(row))
+			y, oky := numericFloat(o.Fn(row))
+			if !okx || !oky {
+				return nil
+			}
+			if z := math.Pow(x, y); !math.IsNaN(z) && !math.IsInf(z, 0) {
+				return z
+			}
+			return nil
+		},
+	}
+}
+
+// Abs returns the absolute value, keeping integers as int64 except for the
+// smallest int64, whose absolute value only fits a float64.
+func (c Column) Abs() Column {
+	return Column{
+		Name: fmt.Sprintf("abs(%s)", c.Name),
+		Fn: func(row map[string]interface{}) interface{} {
+			switch x, _ := numericValue(c.Fn(row)); v := x.(type) {
+			case int64:
+				if v == math.MinInt64 {
+					return -float64(v)
+				}
+				if v < 0 {
+					return -v
+				}
+				return v
+			case float64:
+				return math.Abs(v)
+			}
+			return nil
+		},
+	}
+}
+
+// Round rounds half away from zero to n decimal places; a negative n rounds to
+// tens, hundreds, ... Integers stay int64.
+func (c Column) Round(n int) Column {
+	scale := math.Pow(10, float64(n))
+	return Column{
+		Name: fmt.Sprintf("round(%s, %d)", c.Name, n),
+		Fn: func(row map[string]interface{}) interface{} {
+			switch x, _ := numericValue(c.Fn(row)); v := x.(type) {
+			case int64:
+				if n >= 0 {
+					return v
+				}
+				return int64(math.Round(float64(v)*scale) / scale)
+			case float64:
+				if math.IsNaN(v) || math.IsInf(v, 0) {
+					return v
+				}
+				if r := math.Round(v*scale) / scale; r != 0 {
+					return r
+				}
+				return 0.0 // not -0
+			}
+			return nil
+		},
+	}
+}
+
+// Floor rounds down to a whole number, keeping integers as int64.
+func (c Column) Floor() Column {
+	return c.wholeOp("floor", math.Floor)
+}
+
+// Ceil rounds up to a whole number, keeping integers as int64.
+func (c Column) Ceil() Column {
+	return c.wholeOp("ceil", math.Ceil)
+}
+
+// wholeOp applies floor or ceil to floats and passes integers through.
+func (c Column) wholeOp(name string, f func(float64) float64) Column {
+	return Column{
+		Name: fmt.Sprintf("%s(%s)", name, c.Name),
+		Fn: func(row map[string]interface{}) interface{} {
+			switch x, _ := numericValue(c.Fn(row)); v := x.(type) {
+			case int64:
+				return v
+			case float64:
+				return f(v)
+			}
+			return nil
+		},
+	}
+}
+
+// Sqrt returns the square root as a float64; negative values give null.
+func (c Column) Sqrt() Column { return c.mathOp("sqrt", math.Sqrt) }
+
+// Log returns the natural logarithm as a float64; zero and negative values give null.
+func (c Column) Log() Column { return c.mathOp("log", math.Log) }
+
+// Exp returns e raised to the value as a float64.
+func (c Column) Exp() Column { return c.mathOp("exp", math.Exp) }
+
+// Greatest returns the largest non-null value of cols. Numbers and numeric
+// strings compare numerically and give an int64 when all are integers, else a float64; when
+// any value is not a number, values compare as strings. All null gives null.
+func Greatest(cols ...Column) Column { return extremum("greatest", 1, cols) }
+
+// Least returns the smallest non-null value of cols, as Greatest does.
+func Least(cols ...Column) Column { return extremum("least", -1, cols) }
+
+// extremum picks the value of cols that compares as sign (1 largest, -1 smallest).
+func extremum(name string, sign int, cols []Column) Column {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	return Column{
+		Name: fmt.Sprintf("%s(%s)", name, strings.Join(names, ", ")),
+		Fn: func(row map[string]interface{}) interface{} {
+			vals := make([]interface{}, 0, len(cols))
+			nums := make([]interface{}, 0, len(cols))
+			numeric, ints := true, true
+			for _, c := range cols {
+				v := c.Fn(row)
+				if isNullValue(v) {
+					continue
+				}
+				n, ok := numericValue(v)
+				if !ok {
+					numeric = false
+				} else if _, isInt := n.(int64); !isInt {
+					ints = false
+				}
+				vals = append(vals, v)
+				nums = append(nums, n)
+			}
+			if len(vals) == 0 {
+				return nil
+			}
+			if !numeric {
+				best := fastToString(vals[0])
+				for _, v := range vals[1:] {
+					if s := fastToString(v); strings.Compare(s, best) == sign {
+						best = s
+					}
+				}
+				return best
+			}
+			if ints {
+				best := nums[0].(int64)
+				for _, v := range nums[1:] {
+					if x := v.(int64); (x > best && sign > 0) || (x < best && sign < 0) {
+						best = x
+					}
+				}
+				return best
+			}
+			best, _ := asFloat(nums[0])
+			for _, v := range nums[1:] {
+				if x, _ := asFloat(v); (x > best && sign > 0) || (x < best && sign < 0) {
+					best = x
+				}
+			}
+			return best
+		},
+	}
+}
+
+// Coalesce returns the first value of cols that is not null, where null
+// means what IsNull means (nil, "" or "null").
+func Coalesce(cols ...Column) Column {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+	}
+	return Column{
+		Name: fmt.Sprintf("coalesce(%s)", strings.Join(names, ", ")),
+		Fn: func(row map[string]interface{}) interface{} {
+			for _, c := range cols {
+				if v := c.Fn(row); !isNullValue(v) {
+					return v
+				}
+			}
+			return nil
+		},
+		date: allDates(cols...),
+	}
+}
+
 // regexp
 
 // sort_values()
@@ -1043,8 +1362,6 @@ func sqlLikeToRegex(pat string) string {
 
 // rolling()
 
-// isin()
-
 // astype()
 
 // DateFormat() ? *
@@ -1053,8 +1370,6 @@ func sqlLikeToRegex(pat string) string {
 
 // FromEpoch()
 
-// sql?
-
 // Lower returns a Column that lowercases the input (column or expression).
 func (c Column) Lower() Column {
 	return Column{
@@ -1231,6 +1546,265 @@ func (c Column) Length() Column {
 	}
 }
 
+// stringOp applies f to the string form of the column's value. Null stays null.
+func (c Column) stringOp(name string, f func(s string) interface{}) Column {
+	return Column{
+		Name: c.Name + "." + name,
+		Fn: func(row map[string]interface{}) interface{} {
+			val := c.Fn(row)
+			if val == nil {
+				return nil
+			}
+			s, err := toString(val)
+			if err != nil {
+				s = fastToString(val)
+			}
+			return f(s)
+		},
+	}
+}
+
+// Substr returns length characters starting at the 1-based position start, as
+// SQL SUBSTRING does. A negative start counts from the end of the string.
+func (c Column) Substr(start, length int) Column {
+	return c.stringOp("substr", func(s string) interface{} {
+		rs := []rune(s)
+		from := start - 1
+		if start < 0 {
+			from = len(rs) + start
+		}
+		if from < 0 {
+			from = 0
+		}
+		if from > len(rs) || length <= 0 {
+			return ""
+		}
+		to := from + length
+		if to > len(rs) {
+			to = len(rs)
+		}
+		return string(rs[from:to])
+	})
+}
+
+// Title upper-cases the first letter of every word and lower-cases the rest,
+// as Python's str.title does; any non-letter starts a new word ("o'neil" -> "O'Neil").
+func (c Column) Title() Column {
+	return c.stringOp("title", func(s string) interface{} {
+		return capitalizeWords(s, func(r rune) bool { return !unicode.IsLetter(r) })
+	})
+}
+
+// InitCap upper-cases the first letter of every whitespace-separated word and
+// lower-cases the rest, as SQL INITCAP does ("o'neil smith" -> "O'neil Smith").
+func (c Column) InitCap() Column {
+	return c.stringOp("initcap", func(s string) interface{} {
+		return capitalizeWords(s, unicode.IsSpace)
+	})
+}
+
+// capitalizeWords title-cases the words of s separated by runes matching sep.
+func capitalizeWords(s string, sep func(rune) bool) string {
+	var b strings.Builder
+	start := true
+	for _, r := range s {
+		switch {
+		case sep(r):
+			b.WriteRune(r)
+			start = true
+		case start:
+			b.WriteRune(unicode.ToUpper(r))
+			start = false
+		default:
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// HtmlUnescape decodes HTML entities such as "&amp;" and "&#39;".
+func (c Column) HtmlUnescape() Column {
+	return c.stringOp("html_unescape", func(s string) interface{} { return html.UnescapeString(s) })
+}
+
+// LPad left-pads the value with pad to length characters, truncating longer
+// values to length, as SQL LPAD does.
+func (c Column) LPad(length int, pad string) Column {
+	return c.stringOp("lpad", func(s string) interface{} { return padString(s, length, pad, true) })
+}
+
+// RPad right-pads the value with pad to length characters, truncating longer
+// values to length, as SQL RPAD does.
+func (c Column) RPad(length int, pad string) Column {
+	return c.stringOp("rpad", func(s string) interface{} { return padString(s, length, pad, false) })
+}
+
+// padString pads or truncates s to n runes.
+func padString(s string, n int, pad string, left bool) string {
+	rs, ps := []rune(s), []rune(pad)
+	if n <= 0 {
+		return ""
+	}
+	if len(rs) >= n {
+		return string(rs[:n])
+	}
+	if len(ps) == 0 {
+		return s
+	}
+	fill := make([]rune, 0, n-len(rs))
+	for i := 0; len(fill) < n-len(rs); i++ {
+		fill = append(fill, ps[i%len(ps)])
+	}
+	if left {
+		return string(fill) + s
+	}
+	return s + string(fill)
+}
+
+// Reverse reverses the characters of the value.
+func (c Column) Reverse() Column {
+	return c.stringOp("reverse", func(s string) interface{} {
+		rs := []rune(s)
+		for i, j := 0, len(rs)-1; i < j; i, j = i+1, j-1 {
+			rs[i], rs[j] = rs[j], rs[i]
+		}
+		return string(rs)
+	})
+}
+
+// Repeat repeats the value n times; n <= 0 gives "".
+func (c Column) Repeat(n int) Column {
+	return c.stringOp("repeat", func(s string) interface{} {
+		if n <= 0 {
+			return ""
+		}
+		return strings.Repeat(s, n)
+	})
+}
+
+// Translate replaces each character of from with the character at the same
+// position in to, as SQL TRANSLATE does. Characters of from past the end of
+// to are removed.
+func (c Column) Translate(from, to string) Column {
+	fs, ts := []rune(from), []rune(to)
+	mapping := make(map[rune]rune, len(fs))
+	for i, r := range fs {
+		if _, ok := mapping[r]; ok {
+			continue // the first occurrence wins
+		}
+		mapping[r] = -1
+		if i < len(ts) {
+			mapping[r] = ts[i]
+		}
+	}
+	return c.stringOp("translate", func(s string) interface{} {
+		return strings.Map(func(r rune) rune {
+			if m, ok := mapping[r]; ok {
+				return m
+			}
+			return r
+		}, s)
+	})
+}
+
+// Levenshtein returns the edit distance between the value and other, which may
+// be a literal or a Column. It is null when either side is null.
+func (c Column) Levenshtein(other interface{}) Column {
+	return Column{
+		Name: c.Name + ".levenshtein",
+		Fn: func(row map[string]interface{}) interface{} {
+			a, b := c.Fn(row), operandValue(other, row)
+			if a == nil || b == nil {
+				return nil
+			}
+			return levenshtein([]rune(fastToString(a)), []rune(fastToString(b)))
+		},
+	}
+}
+
+// levenshtein computes the edit distance of a and b with two rows of the DP table.
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// Soundex returns the four-character American Soundex code of the value
+// ("Robert" -> "R163"), or "" when it has no letters.
+func (c Column) Soundex() Column {
+	return c.stringOp("soundex", func(s string) interface{} { return soundex(s) })
+}
+
+func soundex(s string) string {
+	codes := map[rune]byte{
+		'B': '1', 'F': '1', 'P': '1', 'V': '1',
+		'C': '2', 'G': '2', 'J': '2', 'K': '2', 'Q': '2', 'S': '2', 'X': '2', 'Z': '2',
+		'D': '3', 'T': '3', 'L': '4', 'M': '5', 'N': '5', 'R': '6',
+	}
+	out := make([]byte, 0, 4)
+	var last byte
+	for _, r := range strings.ToUpper(s) {
+		if r < 'A' || r > 'Z' {
+			continue
+		}
+		code := codes[r]
+		if len(out) == 0 {
+			out = append(out, byte(r))
+			last = code
+			continue
+		}
+		switch {
+		case r == 'H' || r == 'W':
+			// H and W do not separate letters with the same code
+		case code == 0:
+			last = 0 // vowels do
+		case code != last:
+			out = append(out, code)
+			last = code
+		}
+		if len(out) == 4 {
+			break
+		}
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	for len(out) < 4 {
+		out = append(out, '0')
+	}
+	return string(out)
+}
+
+// SplitPart splits the value on delimiter and returns the 1-based part; a
+// negative part counts from the end. Out of range parts give "".
+func (c Column) SplitPart(delimiter string, part int) Column {
+	return c.stringOp("split_part", func(s string) interface{} {
+		parts := strings.Split(s, delimiter)
+		i := part - 1
+		if part < 0 {
+			i = len(parts) + part
+		}
+		if part == 0 || i < 0 || i >= len(parts) {
+			return ""
+		}
+		return parts[i]
+	})
+}
+
 // ArrayJoin joins elements of an array column with a delimiter.
 // If nullReplacement is provided, nulls are replaced with it; otherwise nulls are skipped.
 func (c Column) ArrayJoin(delim string, nullReplacement ...string) Column {
@@ -1291,7 +1865,10 @@ func (c Column) ExtractHTML(field string) Column {
 				return []string{}
 			}
 
-			df := ReadHTML(htmlStr)
+			df, err := ReadHTML(htmlStr)
+			if err != nil {
+				return []string{}
+			}
 
 			// Direct column return if field is known
 			if _, ok := known[field]; ok {
@@ -1351,7 +1928,10 @@ func (c Column) ExtractHTMLTop(field string) Column {
 				return []string{}
 			}
 
-			df := ReadHTMLTop(htmlStr)
+			df, err := ReadHTMLTop(htmlStr)
+			if err != nil {
+				return []string{}
+			}
 
 			if _, ok := known[field]; ok {
 				col := df.Data[field]
@@ -8,7 +8,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"runtime"
-	"strconv"
 	"strings"
 	"sync"
 	"syscall/js"
@@ -959,44 +958,130 @@ func dfObject(id int) js.Value {
 		out := df.GroupBy(key, aggs...)
 		newID := put(out)
 		return dfObject(newID)
-	})) // df.Join(otherDf, leftOn, rightOn, joinType?) -> new DataFrame
-	// joinType: "inner" | "left" | "right" | "outer" (default "inner")
+	}))
+	// df.Pivot(index, pivotCol, valueCol, agg) -> new DataFrame (wide form)
+	// index: column name or array of names; agg: Sum("x") style descriptor, applied to valueCol
+	obj.Set("Pivot", js.FuncOf(func(this js.Value, args []js.Value) any {
+		df := get(id)
+		if df == nil {
+			return "error: dataframe handle invalid"
+		}
+		if len(args) < 4 || args[1].Type() != js.TypeString || args[2].Type() != js.TypeString {
+			return "error: Pivot(index, pivotCol, valueCol, agg)"
+		}
+		var index []string
+		if args[0].Type() == js.TypeString {
+			index = []string{args[0].String()}
+		} else if keys, ok := stringsFromJS(args[0]); ok {
+			index = keys
+		} else {
+			return "error: index must be a string or an array of strings"
+		}
+		agg, err := aggFromJS(args[3])
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		out := df.Pivot(index, args[1].String(), args[2].String(), agg)
+		newID := put(out)
+		return dfObject(newID)
+	}))
+	// df.Unpivot(idCols, valueCols?, varName?, valueName?) -> new DataFrame (long form)
+	obj.Set("Unpivot", js.FuncOf(func(this js.Value, args []js.Value) any {
+		df := get(id)
+		if df == nil {
+			return "error: dataframe handle invalid"
+		}
+		if len(args) < 1 {
+			return "error: Unpivot(idCols, valueCols?, varName?, valueName?)"
+		}
+		idCols, ok := stringsFromJS(args[0])
+		if !ok {
+			return "error: idCols must be an array of strings"
+		}
+		var valueCols []string
+		if len(args) > 1 && args[1].Truthy() {
+			if valueCols, ok = stringsFromJS(args[1]); !ok {
+				return "error: valueCols must be an array of strings"
+			}
+		}
+		varName, valueName := "", ""
+		if len(args) > 2 && args[2].Type() == js.TypeString {
+			varName = args[2].String()
+		}
+		if len(args) > 3 && args[3].Type() == js.TypeString {
+			valueName = args[3].String()
+		}
+		out := df.Unpivot(idCols, valueCols, varName, valueName)
+		newID := put(out)
+		return dfObject(newID)
+	})) // df.Join(otherDf, leftOn, rightOn, joinType?, suffixes?) -> new DataFrame
+	// leftOn/rightOn: column name or array of names (composite keys)
+	// joinType: "inner" | "left" | "right" | "outer" | "semi" | "anti" | "cross" (default "inner")
+	// suffixes: ["_l", "_r"] by default
 	obj.Set("Join", js.FuncOf(func(this js.Value, args []js.Value) any {
 		left := get(id)
 		if left == nil {
 			return "error: invalid left dataframe handle"
 		}
 		if len(args) < 3 {
-			return "error: usage Join(otherDf, leftOn, rightOn, joinType?)"
-		}
-		otherObj := args[0]
-		if otherObj.Type() != js.TypeObject {
-			return "error: first arg must be a DataFrame object"
-		}
-		otherHandle := otherObj.Get("handle")
-		if !otherHandle.Truthy() {
-			return "error: otherDf missing handle"
+			return "error: usage Join(otherDf, leftOn, rightOn, joinType?, suffixes?)"
 		}
-		right := get(otherHandle.Int())
-		if right == nil {
-			return "error: invalid right dataframe handle"
+		right, errStr := joinRight(args[0])
+		if errStr != "" {
+			return errStr
 		}
-		if args[1].Type() != js.TypeString || args[2].Type() != js.TypeString {
-			return "error: leftOn and rightOn must be strings"
+		leftOn, ok1 := joinKeysFromJS(args[1])
+		rightOn, ok2 := joinKeysFromJS(args[2])
+		if !ok1 || !ok2 {
+			return "error: leftOn and rightOn must be strings or arrays of strings"
 		}
-		leftOn := args[1].String()
-		rightOn := args[2].String()
 		joinType := "inner"
 		if len(args) >= 4 && args[3].Type() == js.TypeString {
 			jt := strings.ToLower(args[3].String())
 			switch jt {
-			case "inner", "left", "right", "outer":
+			case "inner", "left", "right", "outer", "semi", "anti", "cross":
 				joinType = jt
 			default:
-				return "error: joinType must be inner|left|right|outer"
+				return "error: joinType must be inner|left|right|outer|semi|anti|cross"
 			}
 		}
-		out := left.Join(right, leftOn, rightOn, joinType)
+		var suffixes []string
+		if len(args) >= 5 {
+			suffixes, _ = stringsFromJS(args[4])
+		}
+		out := left.Join(right, leftOn, rightOn, joinType, suffixes...)
+		if out == nil {
+			return "error: join failed"
+		}
+		newID := put(out)
+		return dfObject(newID)
+	}))
+	// df.JoinOn(otherDf, predicateExpr, joinType?, suffixes?) -> new DataFrame (non-equi joins)
+	obj.Set("JoinOn", js.FuncOf(func(this js.Value, args []js.Value) any {
+		left := get(id)
+		if left == nil {
+			return "error: invalid left dataframe handle"
+		}
+		if len(args) < 2 {
+			return "error: usage JoinOn(otherDf, predicate, joinType?, suffixes?)"
+		}
+		right, errStr := joinRight(args[0])
+		if errStr != "" {
+			return errStr
+		}
+		on, err := exprFromJS(args[1])
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		joinType := "inner"
+		if len(args) >= 3 && args[2].Type() == js.TypeString {
+			joinType = strings.ToLower(args[2].String())
+		}
+		var suffixes []string
+		if len(args) >= 4 {
+			suffixes, _ = stringsFromJS(args[3])
+		}
+		out := left.JoinOn(right, on, joinType, suffixes...)
 		if out == nil {
 			return "error: join failed"
 		}
@@ -1154,6 +1239,28 @@ func dfObject(id int) js.Value {
 		js.Global().Get("console").Call("log", n) // print count
 		return n
 	}))
+	// df.Describe(...percentiles) -> new DataFrame of per-column statistics
+	obj.Set("Describe", js.FuncOf(func(this js.Value, args []js.Value) any {
+		df := get(id)
+		if df == nil {
+			return "error: invalid handle"
+		}
+		percentiles := make([]float64, 0, len(args))
+		for _, a := range args {
+			if a.Type() == js.TypeNumber {
+				percentiles = append(percentiles, a.Float())
+			}
+		}
+		return dfObject(put(df.Describe(percentiles...)))
+	}))
+	// df.Profile() -> Report with an overview page and one page per column
+	obj.Set("Profile", js.FuncOf(func(this js.Value, args []js.Value) any {
+		df := get(id)
+		if df == nil {
+			return "error: invalid handle"
+		}
+		return reportObject(putReport(df.Profile()))
+	}))
 	// ---------- Displays ----------
 
 	// df.Display() -> returns helper with .ElementID(id) to mount the generated HTML
@@ -1326,8 +1433,12 @@ func dfObject(id int) js.Value {
 		if df == nil {
 			return "error: invalid handle"
 		}
+		args, opts, err := chartOptionsFromJS(args)
+		if err != nil {
+			return "error: " + err.Error()
+		}
 		if len(args) < 4 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString || args[2].Type() != js.TypeString {
-			return "error: usage BarChart(title, subtitle, groupcol, aggs...)"
+			return "error: usage BarChart(title, subtitle, groupcol, aggs..., options?)"
 		}
 		title, subtitle, groupcol := args[0].String(), args[1].String(), args[2].String()
 		aggs, err := aggsFromJS(args[3:])
@@ -1335,7 +1446,7 @@ func dfObject(id int) js.Value {
 			return "error: " + err.Error()
 		}
 
-		ch := df.BarChart(title, subtitle, groupcol, aggs)
+		ch := df.BarChart(title, subtitle, groupcol, aggs, opts)
 		chID := putChart(ch)
 		chartSeq++
 		divID := fmt.Sprintf("%s_%d", ch.Htmldivid, chartSeq)
@@ -1346,6 +1457,13 @@ func dfObject(id int) js.Value {
 		helper.Set("chartHandle", chID)
 		helper.Set("HTML", js.FuncOf(func(this js.Value, a []js.Value) any { return html }))
 		helper.Set("JS", js.FuncOf(func(this js.Value, a []js.Value) any { return jsText }))
+		helper.Set("SVG", js.FuncOf(func(this js.Value, a []js.Value) any {
+			svg, err := ch.SVG()
+			if err != nil {
+				return "error: " + err.Error()
+			}
+			return svg
+		}))
 		helper.Set("ElementID", js.FuncOf(func(this js.Value, a []js.Value) any {
 			if len(a) < 1 || a[0].Type() != js.TypeString {
 				return "error: ElementID(id)"
@@ -1370,8 +1488,12 @@ func dfObject(id int) js.Value {
 		if df == nil {
 			return "error: invalid handle"
 		}
+		args, opts, err := chartOptionsFromJS(args)
+		if err != nil {
+			return "error: " + err.Error()
+		}
 		if len(args) < 4 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString || args[2].Type() != js.TypeString {
-			return "error: usage ColumnChart(title, subtitle, groupcol, aggs...)"
+			return "error: usage ColumnChart(title, subtitle, groupcol, aggs..., options?)"
 		}
 		title, subtitle, groupcol := args[0].String(), args[1].String(), args[2].String()
 		aggs, err := aggsFromJS(args[3:])
@@ -1379,7 +1501,7 @@ func dfObject(id int) js.Value {
 			return "error: " + err.Error()
 		}
 
-		ch := df.ColumnChart(title, subtitle, groupcol, aggs)
+		ch := df.ColumnChart(title, subtitle, groupcol, aggs, opts)
 		chID := putChart(ch)
 		chartSeq++
 		divID := fmt.Sprintf("%s_%d", ch.Htmldivid, chartSeq)
@@ -1390,6 +1512,13 @@ func dfObject(id int) js.Value {
 		helper.Set("chartHandle", chID)
 		helper.Set("HTML", js.FuncOf(func(this js.Value, a []js.Value) any { return html }))
 		helper.Set("JS", js.FuncOf(func(this js.Value, a []js.Value) any { return jsText }))
+		helper.Set("SVG", js.FuncOf(func(this js.Value, a []js.Value) any {
+			svg, err := ch.SVG()
+			if err != nil {
+				return "error: " + err.Error()
+			}
+			return svg
+		}))
 		helper.Set("ElementID", js.FuncOf(func(this js.Value, a []js.Value) any {
 			if len(a) < 1 || a[0].Type() != js.TypeString {
 				return "error: ElementID(id)"
@@ -1414,8 +1543,12 @@ func dfObject(id int) js.Value {
 		if df == nil {
 			return "error: invalid handle"
 		}
+		args, opts, err := chartOptionsFromJS(args)
+		if err != nil {
+			return "error: " + err.Error()
+		}
 		if len(args) < 4 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString || args[2].Type() != js.TypeString {
-			return "error: usage StackedBarChart(title, subtitle, groupcol, aggs...)"
+			return "error: usage StackedBarChart(title, subtitle, groupcol, aggs..., options?)"
 		}
 		title, subtitle, groupcol := args[0].String(), args[1].String(), args[2].String()
 		aggs, err := aggsFromJS(args[3:])
@@ -1423,7 +1556,7 @@ func dfObject(id int) js.Value {
 			return "error: " + err.Error()
 		}
 
-		ch := df.StackedBarChart(title, subtitle, groupcol, aggs)
+		ch := df.StackedBarChart(title, subtitle, groupcol, aggs, opts)
 		chID := putChart(ch)
 		chartSeq++
 		divID := fmt.Sprintf("%s_%d", ch.Htmldivid, chartSeq)
@@ -1434,6 +1567,13 @@ func dfObject(id int) js.Value {
 		helper.Set("chartHandle", chID)
 		helper.Set("HTML", js.FuncOf(func(this js.Value, a []js.Value) any { return html }))
 		helper.Set("JS", js.FuncOf(func(this js.Value, a []js.Value) any { return jsText }))
+		helper.Set("SVG", js.FuncOf(func(this js.Value, a []js.Value) any {
+			svg, err := ch.SVG()
+			if err != nil {
+				return "error: " + err.Error()
+			}
+			return svg
+		}))
 		helper.Set("ElementID", js.FuncOf(func(this js.Value, a []js.Value) any {
 			if len(a) < 1 || a[0].Type() != js.TypeString {
 				return "error: ElementID(id)"
@@ -1458,8 +1598,12 @@ func dfObject(id int) js.Value {
 		if df == nil {
 			return "error: invalid handle"
 		}
+		args, opts, err := chartOptionsFromJS(args)
+		if err != nil {
+			return "error: " + err.Error()
+		}
 		if len(args) < 4 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString || args[2].Type() != js.TypeString {
-			return "error: usage StackedPercentChart(title, subtitle, groupcol, aggs...)"
+			return "error: usage StackedPercentChart(title, subtitle, groupcol, aggs..., options?)"
 		}
 		title, subtitle, groupcol := args[0].String(), args[1].String(), args[2].String()
 		aggs, err := aggsFromJS(args[3:])
@@ -1467,7 +1611,7 @@ func dfObject(id int) js.Value {
 			return "error: " + err.Error()
 		}
 
-		ch := df.StackedPercentChart(title, subtitle, groupcol, aggs)
+		ch := df.StackedPercentChart(title, subtitle, groupcol, aggs, opts)
 		chID := putChart(ch)
 		chartSeq++
 		divID := fmt.Sprintf("%s_%d", ch.Htmldivid, chartSeq)
@@ -1478,6 +1622,13 @@ func dfObject(id int) js.Value {
 		helper.Set("chartHandle", chID)
 		helper.Set("HTML", js.FuncOf(func(this js.Value, a []js.Value) any { return html }))
 		helper.Set("JS", js.FuncOf(func(this js.Value, a []js.Value) any { return jsText }))
+		helper.Set("SVG", js.FuncOf(func(this js.Value, a []js.Value) any {
+			svg, err := ch.SVG()
+			if err != nil {
+				return "error: " + err.Error()
+			}
+			return svg
+		}))
 		helper.Set("ElementID", js.FuncOf(func(this js.Value, a []js.Value) any {
 			if len(a) < 1 || a[0].Type() != js.TypeString {
 				return "error: ElementID(id)"
@@ -1508,88 +1659,257 @@ func dfObject(id int) js.Value {
 		return df.GroupedSeriesJSON(args[0].String(), args[1].String())
 	}))
 
-	// Helpers for string-based charts (PieChart/LineChart/ScatterPlot/BubbleChart/AreaChart)
-	makeSimpleChart := func(gen func(*g.DataFrame, string, string) string) js.Func {
+	// chartHelper stores a chart and returns the same helper object BarChart does.
+	// Charts without a script (DataTable) only set the element's HTML.
+	chartHelper := func(ch g.Chart) js.Value {
+		chID := putChart(ch)
+		chartSeq++
+		divID := fmt.Sprintf("%s_%d", ch.Htmldivid, chartSeq)
+		html := ch.Htmlpreid + divID + ch.Htmlpostid
+		jsText := ""
+		if ch.Jspreid != "" {
+			jsText = ch.Jspreid + divID + ch.Jspostid
+		}
+
+		helper := js.Global().Get("Object").New()
+		helper.Set("chartHandle", chID)
+		helper.Set("HTML", js.FuncOf(func(this js.Value, a []js.Value) any { return html }))
+		helper.Set("JS", js.FuncOf(func(this js.Value, a []js.Value) any { return jsText }))
+		helper.Set("SVG", js.FuncOf(func(this js.Value, a []js.Value) any {
+			svg, err := ch.SVG()
+			if err != nil {
+				return "error: " + err.Error()
+			}
+			return svg
+		}))
+		helper.Set("ElementID", js.FuncOf(func(this js.Value, a []js.Value) any {
+			if len(a) < 1 || a[0].Type() != js.TypeString {
+				return "error: ElementID(id)"
+			}
+			doc := js.Global().Get("document")
+			el := doc.Call("getElementById", a[0].String())
+			if !el.Truthy() {
+				return "error: element not found"
+			}
+			el.Set("innerHTML", html)
+			if jsText != "" {
+				s := doc.Call("createElement", "script")
+				s.Set("textContent", jsText)
+				el.Call("appendChild", s)
+			}
+			return "ok"
+		}))
+		return helper
+	}
+
+	// df.PieChart(title, subtitle, namecol, agg) -> helper (ElementID)
+	obj.Set("PieChart", js.FuncOf(func(this js.Value, args []js.Value) any {
+		df := get(id)
+		if df == nil {
+			return "error: invalid handle"
+		}
+		args, opts, err := chartOptionsFromJS(args)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		if len(args) < 4 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString || args[2].Type() != js.TypeString {
+			return "error: usage PieChart(title, subtitle, namecol, agg, options?)"
+		}
+		aggs, err := aggsFromJS(args[3:4])
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		if len(aggs) == 0 {
+			return "error: PieChart needs an aggregation"
+		}
+		return chartHelper(df.PieChart(args[0].String(), args[1].String(), args[2].String(), aggs[0], opts))
+	}))
+
+	// xyChart parses (title, subtitle, xcol, ycols...) for LineChart and AreaChart.
+	xyChart := func(name string, gen func(df *g.DataFrame, title, subtitle, xcol string, ycols []string, opts ...g.ChartOptions) g.Chart) js.Func {
 		return js.FuncOf(func(this js.Value, args []js.Value) any {
 			df := get(id)
 			if df == nil {
 				return "error: invalid handle"
 			}
-			if len(args) < 2 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString {
-				return "error: usage ChartFunc(x, y) or appropriate args"
+			args, opts, err := chartOptionsFromJS(args)
+			if err != nil {
+				return "error: " + err.Error()
 			}
-			// Generate JS snippet (expects Highcharts.chart('container', ...))
-			code := gen(df, args[0].String(), args[1].String())
-
-			// Unique chart div id and patched code
-			chartSeq++
-			innerID := fmt.Sprintf("chart_%d", chartSeq)
-			replaced := strings.ReplaceAll(code, "Highcharts.chart('container'", "Highcharts.chart('"+innerID+"'")
-
-			helper := js.Global().Get("Object").New()
-			helper.Set("ChartID", js.FuncOf(func(this js.Value, a []js.Value) any {
-				if len(a) < 1 || a[0].Type() != js.TypeString {
-					return "error: ChartID(id)"
-				}
-				custom := a[0].String()
-				replacedCustom := strings.ReplaceAll(code, "Highcharts.chart('container'", "Highcharts.chart('"+custom+"'")
-				obj := js.Global().Get("Object").New()
-				obj.Set("ElementID", js.FuncOf(func(this js.Value, b []js.Value) any {
-					if len(b) < 1 || b[0].Type() != js.TypeString {
-						return "error: ElementID(containerId)"
-					}
-					doc := js.Global().Get("document")
-					el := doc.Call("getElementById", b[0].String())
-					if !el.Truthy() {
-						return "error: element not found"
-					}
-					// create target div
-					div := doc.Call("createElement", "div")
-					div.Call("setAttribute", "id", custom)
-					el.Call("appendChild", div)
-					// append script
-					s := doc.Call("createElement", "script")
-					s.Set("textContent", replacedCustom)
-					el.Call("appendChild", s)
-					return "ok"
-				}))
-				return obj
-			}))
-			helper.Set("ElementID", js.FuncOf(func(this js.Value, a []js.Value) any {
-				if len(a) < 1 || a[0].Type() != js.TypeString {
-					return "error: ElementID(containerId)"
-				}
-				doc := js.Global().Get("document")
-				el := doc.Call("getElementById", a[0].String())
-				if !el.Truthy() {
-					return "error: element not found"
-				}
-				// create target div
-				div := doc.Call("createElement", "div")
-				div.Call("setAttribute", "id", innerID)
-				el.Call("appendChild", div)
-				// append script
-				s := doc.Call("createElement", "script")
-				s.Set("textContent", replaced)
-				el.Call("appendChild", s)
-				return "ok"
-			}))
-			helper.Set("JS", js.FuncOf(func(this js.Value, a []js.Value) any { return replaced }))
-			helper.Set("ChartDivID", innerID)
-			return helper
+			if len(args) < 4 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString || args[2].Type() != js.TypeString {
+				return "error: usage " + name + "(title, subtitle, xcol, ycols..., options?)"
+			}
+			ycols, err := colsFromJS(args[3:])
+			if err != nil {
+				return "error: " + err.Error()
+			}
+			if err := columnsError(df, append([]string{args[2].String()}, ycols...)...); err != nil {
+				return "error: " + err.Error()
+			}
+			return chartHelper(gen(df, args[0].String(), args[1].String(), args[2].String(), ycols, opts))
 		})
 	}
+	// df.LineChart(title, subtitle, xcol, ycols..., options?) -> helper (ElementID)
+	obj.Set("LineChart", xyChart("LineChart", (*g.DataFrame).LineChart))
+	// df.AreaChart(title, subtitle, xcol, ycols..., options?) -> helper (ElementID)
+	obj.Set("AreaChart", xyChart("AreaChart", (*g.DataFrame).AreaChart))
 
-	// df.PieChart(name, value) -> helper (ElementID)
-	obj.Set("PieChart", makeSimpleChart(func(df *g.DataFrame, x, y string) string { return df.PieChart(x, y) }))
-	// df.LineChart(x, y) -> helper (ElementID)
-	obj.Set("LineChart", makeSimpleChart(func(df *g.DataFrame, x, y string) string { return df.LineChart(x, y) }))
-	// df.ScatterPlot(x, y) -> helper (ElementID)
-	obj.Set("ScatterPlot", makeSimpleChart(func(df *g.DataFrame, x, y string) string { return df.ScatterPlot(x, y) }))
-	// df.BubbleChart(x, y) -> helper (ElementID)
-	obj.Set("BubbleChart", makeSimpleChart(func(df *g.DataFrame, x, y string) string { return df.BubbleChart(x, y) }))
-	// df.AreaChart(x, y) -> helper (ElementID)
-	obj.Set("AreaChart", makeSimpleChart(func(df *g.DataFrame, x, y string) string { return df.AreaChart(x, y) }))
+	// df.ScatterPlot(title, subtitle, xcol, ycol, groupcol?) -> helper (ElementID)
+	obj.Set("ScatterPlot", js.FuncOf(func(this js.Value, args []js.Value) any {
+		df := get(id)
+		if df == nil {
+			return "error: invalid handle"
+		}
+		args, opts, err := chartOptionsFromJS(args)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		if len(args) < 4 {
+			return "error: usage ScatterPlot(title, subtitle, xcol, ycol, groupcol?, options?)"
+		}
+		groupcol := ""
+		if len(args) > 4 && args[4].Type() == js.TypeString {
+			groupcol = args[4].String()
+		}
+		cols := []string{args[2].String(), args[3].String()}
+		if groupcol != "" {
+			cols = append(cols, groupcol)
+		}
+		if err := columnsError(df, cols...); err != nil {
+			return "error: " + err.Error()
+		}
+		return chartHelper(df.ScatterPlot(args[0].String(), args[1].String(), args[2].String(), args[3].String(), groupcol, opts))
+	}))
+
+	// df.BubbleChart(title, subtitle, xcol, ycol, sizecol, groupcol?) -> helper (ElementID)
+	obj.Set("BubbleChart", js.FuncOf(func(this js.Value, args []js.Value) any {
+		df := get(id)
+		if df == nil {
+			return "error: invalid handle"
+		}
+		args, opts, err := chartOptionsFromJS(args)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		if len(args) < 5 {
+			return "error: usage BubbleChart(title, subtitle, xcol, ycol, sizecol, groupcol?, options?)"
+		}
+		groupcol := ""
+		if len(args) > 5 && args[5].Type() == js.TypeString {
+			groupcol = args[5].String()
+		}
+		cols := []string{args[2].String(), args[3].String(), args[4].String()}
+		if groupcol != "" {
+			cols = append(cols, groupcol)
+		}
+		if err := columnsError(df, cols...); err != nil {
+			return "error: " + err.Error()
+		}
+		return chartHelper(df.BubbleChart(args[0].String(), args[1].String(), args[2].String(), args[3].String(), args[4].String(), groupcol, opts))
+	}))
+
+	// df.TreeMap(title, subtitle, groupcols, agg) -> helper (ElementID)
+	obj.Set("TreeMap", js.FuncOf(func(this js.Value, args []js.Value) any {
+		df := get(id)
+		if df == nil {
+			return "error: invalid handle"
+		}
+		args, opts, err := chartOptionsFromJS(args)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		if len(args) < 4 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString {
+			return "error: usage TreeMap(title, subtitle, groupcols, agg, options?)"
+		}
+		groupcols, err := colsFromJS(args[2:3])
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		aggs, err := aggsFromJS(args[3:4])
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		if len(aggs) == 0 {
+			return "error: TreeMap needs an aggregation"
+		}
+		return chartHelper(df.TreeMap(args[0].String(), args[1].String(), groupcols, aggs[0], opts))
+	}))
+
+	// df.Histogram(col, bins?) -> helper (ElementID)
+	obj.Set("Histogram", js.FuncOf(func(this js.Value, args []js.Value) any {
+		df := get(id)
+		if df == nil {
+			return "error: invalid handle"
+		}
+		args, opts, err := chartOptionsFromJS(args)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		if len(args) < 1 || args[0].Type() != js.TypeString {
+			return "error: usage Histogram(col, bins?, options?)"
+		}
+		bins := 0
+		if len(args) > 1 && args[1].Type() == js.TypeNumber {
+			bins = args[1].Int()
+		}
+		return chartHelper(df.Histogram(args[0].String(), bins, opts))
+	}))
+
+	// df.BoxPlot(valueCol, groupCol?) -> helper (ElementID)
+	obj.Set("BoxPlot", js.FuncOf(func(this js.Value, args []js.Value) any {
+		df := get(id)
+		if df == nil {
+			return "error: invalid handle"
+		}
+		args, opts, err := chartOptionsFromJS(args)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		if len(args) < 1 || args[0].Type() != js.TypeString {
+			return "error: usage BoxPlot(valueCol, groupCol?, options?)"
+		}
+		groupCol := ""
+		if len(args) > 1 && args[1].Type() == js.TypeString {
+			groupCol = args[1].String()
+		}
+		return chartHelper(df.BoxPlot(args[0].String(), groupCol, opts))
+	}))
+
+	// df.Heatmap(xCol, yCol, valueCol, agg) -> helper (ElementID)
+	obj.Set("Heatmap", js.FuncOf(func(this js.Value, args []js.Value) any {
+		df := get(id)
+		if df == nil {
+			return "error: invalid handle"
+		}
+		args, opts, err := chartOptionsFromJS(args)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		if len(args) < 4 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString || args[2].Type() != js.TypeString {
+			return "error: usage Heatmap(xCol, yCol, valueCol, agg, options?)"
+		}
+		aggs, err := aggsFromJS(args[3:4])
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		if len(aggs) == 0 {
+			return "error: Heatmap needs an aggregation"
+		}
+		return chartHelper(df.Heatmap(args[0].String(), args[1].String(), args[2].String(), aggs[0], opts))
+	}))
+
+	// df.DataTable(cols...) -> helper (ElementID)
+	obj.Set("DataTable", js.FuncOf(func(this js.Value, args []js.Value) any {
+		df := get(id)
+		if df == nil {
+			return "error: invalid handle"
+		}
+		cols, err := colsFromJS(args)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		return chartHelper(df.DataTable(cols...))
+	}))
 	// ---------- Other ----------
 	// df.free()
 	obj.Set("free", js.FuncOf(func(this js.Value, args []js.Value) any {
@@ -1677,7 +1997,11 @@ func readJSON(this js.Value, args []js.Value) any {
 			promiseReadBlobText(v).Call("then",
 				js.FuncOf(func(this js.Value, a []js.Value) any {
 					text := a[0].String()
-					df := g.ReadJSON(text)
+					df, readErr := g.ReadJSON(text)
+					if readErr != nil {
+						reject.Invoke(jsError(readErr))
+						return nil
+					}
 					id := put(df)
 					resolve.Invoke(dfObject(id))
 					return nil
@@ -1696,7 +2020,10 @@ func readJSON(this js.Value, args []js.Value) any {
 	arrCtor := js.Global().Get("Array")
 	if arrCtor.Truthy() && v.InstanceOf(arrCtor) {
 		text := js.Global().Get("JSON").Call("stringify", v).String()
-		df := g.ReadJSON(text)
+		df, readErr := g.ReadJSON(text)
+		if readErr != nil {
+			return rejected(readErr)
+		}
 		id := put(df)
 		return dfObject(id)
 	}
@@ -1772,7 +2099,10 @@ func readJSON(this js.Value, args []js.Value) any {
 					rows[i] = r
 				}
 				b, _ := json.Marshal(rows)
-				df := g.ReadJSON(string(b))
+				df, readErr := g.ReadJSON(string(b))
+				if readErr != nil {
+					return rejected(readErr)
+				}
 				id := put(df)
 				return dfObject(id)
 			}
@@ -1780,7 +2110,10 @@ func readJSON(this js.Value, args []js.Value) any {
 
 		// Fallback: stringify object as-is
 		text := js.Global().Get("JSON").Call("stringify", v).String()
-		df := g.ReadJSON(text)
+		df, readErr := g.ReadJSON(text)
+		if readErr != nil {
+			return rejected(readErr)
+		}
 		id := put(df)
 		return dfObject(id)
 	}
@@ -1790,19 +2123,52 @@ func readJSON(this js.Value, args []js.Value) any {
 	if err != "" {
 		return err
 	}
-	df := g.ReadJSON(jsonText)
+	df, readErr := g.ReadJSON(jsonText)
+	if readErr != nil {
+		return rejected(readErr)
+	}
 	id := put(df)
 	return dfObject(id)
 }
 
 // ...existing code...
 
-// ReadCSV takes CSV (string/Uint8Array/ArrayBuffer|File|Blob) and returns a DataFrame object.
+// jsError wraps a Go error in a JS Error.
+func jsError(err error) js.Value {
+	return js.Global().Get("Error").New(err.Error())
+}
+
+// rejected returns a Promise rejected with err, so reader failures surface as
+// JS rejections instead of aborting the Go runtime.
+func rejected(err error) js.Value {
+	return js.Global().Get("Promise").Call("reject", jsError(err))
+}
+
+// csvOptionsFromJS parses an optional options object, e.g. {delimiter: ";", infer_types: true}.
+func csvOptionsFromJS(args []js.Value, i int) (g.CSVOptions, error) {
+	var opts g.CSVOptions
+	if len(args) <= i || args[i].Type() != js.TypeObject {
+		return opts, nil
+	}
+	j := js.Global().Get("JSON").Call("stringify", args[i]).String()
+	if err := json.Unmarshal([]byte(j), &opts); err != nil {
+		return opts, fmt.Errorf("unmarshal csv options: %w", err)
+	}
+	return opts, nil
+}
+
+// ReadCSV takes CSV (string/Uint8Array/ArrayBuffer|File|Blob) and an optional
+// options object ({delimiter, no_header, comment, null_values, infer_types, lazy_quotes})
+// and returns a DataFrame object.
 func readCSV(this js.Value, args []js.Value) any {
 	if len(args) < 1 {
-		return "error: usage ReadCSV(text|Uint8Array|ArrayBuffer|File|Blob)"
+		return "error: usage ReadCSV(text|Uint8Array|ArrayBuffer|File|Blob, options?)"
 	}
 	v := args[0]
+	opts, optErr := csvOptionsFromJS(args, 1)
+	if optErr != nil {
+		return rejected(optErr)
+	}
 
 	if isBlobOrFile(v) {
 		return js.Global().Get("Promise").New(js.FuncOf(func(this js.Value, prArgs []js.Value) any {
@@ -1810,7 +2176,11 @@ func readCSV(this js.Value, args []js.Value) any {
 			promiseReadBlobText(v).Call("then",
 				js.FuncOf(func(this js.Value, a []js.Value) any {
 					text := normalizeNewlines(a[0].String())
-					df := g.ReadCSV(text)
+					df, readErr := g.ReadCSV(text, opts)
+					if readErr != nil {
+						reject.Invoke(jsError(readErr))
+						return nil
+					}
 					id := put(df)
 					resolve.Invoke(dfObject(id))
 					return nil
@@ -1829,7 +2199,10 @@ func readCSV(this js.Value, args []js.Value) any {
 		return err
 	}
 	csvText = normalizeNewlines(csvText)
-	df := g.ReadCSV(csvText)
+	df, readErr := g.ReadCSV(csvText, opts)
+	if readErr != nil {
+		return rejected(readErr)
+	}
 	id := put(df)
 	return dfObject(id)
 }
@@ -1864,7 +2237,11 @@ func readHTML(this js.Value, args []js.Value) any {
 			promiseReadBlobText(v).Call("then",
 				js.FuncOf(func(this js.Value, a []js.Value) any {
 					text := a[0].String()
-					df := g.ReadHTML(text)
+					df, readErr := g.ReadHTML(text)
+					if readErr != nil {
+						reject.Invoke(jsError(readErr))
+						return nil
+					}
 					id := put(df)
 					resolve.Invoke(dfObject(id))
 					return nil
@@ -1881,7 +2258,10 @@ func readHTML(this js.Value, args []js.Value) any {
 	if err != "" {
 		return err
 	}
-	df := g.ReadHTML(text)
+	df, readErr := g.ReadHTML(text)
+	if readErr != nil {
+		return rejected(readErr)
+	}
 	id := put(df)
 	return dfObject(id)
 }
@@ -1898,7 +2278,11 @@ func readHTMLTop(this js.Value, args []js.Value) any {
 			promiseReadBlobText(v).Call("then",
 				js.FuncOf(func(this js.Value, a []js.Value) any {
 					text := a[0].String()
-					df := g.ReadHTMLTop(text)
+					df, readErr := g.ReadHTMLTop(text)
+					if readErr != nil {
+						reject.Invoke(jsError(readErr))
+						return nil
+					}
 					id := put(df)
 					resolve.Invoke(dfObject(id))
 					return nil
@@ -1915,7 +2299,10 @@ func readHTMLTop(this js.Value, args []js.Value) any {
 	if err != "" {
 		return err
 	}
-	df := g.ReadHTMLTop(text)
+	df, readErr := g.ReadHTMLTop(text)
+	if readErr != nil {
+		return rejected(readErr)
+	}
 	id := put(df)
 	return dfObject(id)
 }
@@ -1930,29 +2317,198 @@ func getAPI(this js.Value, args []js.Value) any {
 	if len(args) >= 2 && args[1].Type() == js.TypeObject {
 		headers = jsObjToStringMap(args[1])
 	}
-	if len(args) >= 3 && args[2].Type() == js.TypeObject {
-		query = jsObjToStringMap(args[2])
+	if len(args) >= 3 && args[2].Type() == js.TypeObject {
+		query = jsObjToStringMap(args[2])
+	}
+
+	df, err := g.GetAPI(url, headers, query)
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	id := put(df)
+	return dfObject(id)
+}
+
+// ...in main(), expose JS constructors for convenience:
+func aggCtor(op string) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) < 1 || args[0].Type() != js.TypeString {
+			return "error: " + strings.Title(op) + "(column)"
+		}
+		col := args[0].String()
+		o := js.Global().Get("Object").New()
+		o.Set("op", op)
+		o.Set("col", col)
+		// Sum("amount").Over(Window().PartitionBy("id").OrderBy("day"))
+		o.Set("Over", js.FuncOf(func(this js.Value, a []js.Value) any {
+			if len(a) < 1 {
+				return "error: Over(window)"
+			}
+			return windowExpr(map[string]any{"func": op, "col": col}, a[0])
+		}))
+		return o
+	})
+}
+
+// windowSpec builds a JS window spec. Methods return new specs so a base
+// window can be shared: Window().PartitionBy("store").OrderBy("day", true)
+func windowSpec(partition, order []any, frame map[string]any) js.Value {
+	o := js.Global().Get("Object").New()
+	o.Set("partition_by", js.ValueOf(partition))
+	o.Set("order_by", js.ValueOf(order))
+	if frame != nil {
+		o.Set("frame", js.ValueOf(frame))
+	}
+	o.Set("PartitionBy", js.FuncOf(func(this js.Value, a []js.Value) any {
+		p := append([]any(nil), partition...)
+		for _, c := range a {
+			p = append(p, c.String())
+		}
+		return windowSpec(p, order, frame)
+	}))
+	o.Set("OrderBy", js.FuncOf(func(this js.Value, a []js.Value) any {
+		if len(a) < 1 || a[0].Type() != js.TypeString {
+			return "error: OrderBy(column, asc)"
+		}
+		asc := len(a) < 2 || a[1].Truthy()
+		ord := append(append([]any(nil), order...), map[string]any{"col": a[0].String(), "asc": asc})
+		return windowSpec(partition, ord, frame)
+	}))
+	between := func(unit string) js.Func {
+		// null/undefined bounds are unbounded; range bounds may be "-7d" style durations
+		return js.FuncOf(func(this js.Value, a []js.Value) any {
+			f := map[string]any{"unit": unit, "start": nil, "end": 0}
+			if len(a) > 0 {
+				f["start"] = jsValToAny(a[0])
+			}
+			if len(a) > 1 {
+				f["end"] = jsValToAny(a[1])
+			}
+			return windowSpec(partition, order, f)
+		})
+	}
+	o.Set("RowsBetween", between("rows"))
+	o.Set("RangeBetween", between("range"))
+	return o
+}
+
+// windowFn returns a window function object exposing Over(window).
+func windowFn(data map[string]any) js.Value {
+	o := js.Global().Get("Object").New()
+	o.Set("Over", js.FuncOf(func(this js.Value, a []js.Value) any {
+		if len(a) < 1 {
+			return "error: Over(window)"
+		}
+		return windowExpr(data, a[0])
+	}))
+	return o
+}
+
+// windowExpr builds the {Type:"window", Data:{...}} ColumnExpr for a function over spec.
+func windowExpr(data map[string]any, spec js.Value) js.Value {
+	d := make(map[string]any, len(data)+3)
+	for k, v := range data {
+		d[k] = v
+	}
+	for _, k := range []string{"partition_by", "order_by", "frame"} {
+		if v := spec.Get(k); v.Truthy() {
+			d[k] = jsValToAny(v)
+		}
+	}
+	o := js.Global().Get("Object").New()
+	o.Set("Type", "window")
+	o.Set("Data", js.ValueOf(d))
+	return o
+}
+
+// joinRight resolves the DataFrame object passed to Join/JoinOn.
+func joinRight(otherObj js.Value) (*g.DataFrame, string) {
+	if otherObj.Type() != js.TypeObject {
+		return nil, "error: first arg must be a DataFrame object"
+	}
+	otherHandle := otherObj.Get("handle")
+	if !otherHandle.Truthy() {
+		return nil, "error: otherDf missing handle"
+	}
+	right := get(otherHandle.Int())
+	if right == nil {
+		return nil, "error: invalid right dataframe handle"
+	}
+	return right, ""
+}
+
+// stringsFromJS converts a JS array of strings.
+func stringsFromJS(v js.Value) ([]string, bool) {
+	if v.Type() != js.TypeObject || !js.Global().Get("Array").Call("isArray", v).Bool() {
+		return nil, false
+	}
+	out := make([]string, v.Length())
+	for i := range out {
+		if v.Index(i).Type() != js.TypeString {
+			return nil, false
+		}
+		out[i] = v.Index(i).String()
+	}
+	return out, true
+}
+
+// columnsError reports the first of cols that is not a column of df.
+func columnsError(df *g.DataFrame, cols ...string) error {
+	for _, c := range cols {
+		if _, ok := df.Data[c]; !ok {
+			return fmt.Errorf("column %q does not exist", c)
+		}
+	}
+	return nil
+}
+
+// colsFromJS reads column names given as varargs strings or a single array.
+func colsFromJS(args []js.Value) ([]string, error) {
+	if len(args) == 1 && args[0].Type() == js.TypeObject {
+		cols, ok := stringsFromJS(args[0])
+		if !ok {
+			return nil, fmt.Errorf("expected an array of column names")
+		}
+		return cols, nil
+	}
+	cols := make([]string, 0, len(args))
+	for _, a := range args {
+		if a.Type() != js.TypeString {
+			return nil, fmt.Errorf("expected column names")
+		}
+		cols = append(cols, a.String())
 	}
+	return cols, nil
+}
 
-	df, err := g.GetAPI(url, headers, query)
-	if err != nil {
-		return "error: " + err.Error()
+// chartOptionsFromJS strips a trailing ChartOptions object ({y_axis_title, palette,
+// legend, data_labels, ...}) from a chart call's arguments. Aggregation objects
+// ({op, col}) and arrays are left in place.
+func chartOptionsFromJS(args []js.Value) ([]js.Value, g.ChartOptions, error) {
+	var opts g.ChartOptions
+	if len(args) == 0 {
+		return args, opts, nil
 	}
-	id := put(df)
-	return dfObject(id)
+	last := args[len(args)-1]
+	if last.Type() != js.TypeObject || js.Global().Get("Array").Call("isArray", last).Bool() || last.Get("op").Truthy() {
+		return args, opts, nil
+	}
+	j := js.Global().Get("JSON").Call("stringify", last).String()
+	if err := json.Unmarshal([]byte(j), &opts); err != nil {
+		return args, opts, fmt.Errorf("unmarshal chart options: %w", err)
+	}
+	return args[:len(args)-1], opts, nil
 }
 
-// ...in main(), expose JS constructors for convenience:
-func aggCtor(op string) js.Func {
-	return js.FuncOf(func(this js.Value, args []js.Value) any {
-		if len(args) < 1 || args[0].Type() != js.TypeString {
-			return "error: " + strings.Title(op) + "(column)"
-		}
-		o := js.Global().Get("Object").New()
-		o.Set("op", op)
-		o.Set("col", args[0].String())
-		return o
-	})
+// joinKeysFromJS accepts a key column name or an array of names.
+func joinKeysFromJS(v js.Value) (interface{}, bool) {
+	if v.Type() == js.TypeString {
+		return v.String(), true
+	}
+	if keys, ok := stringsFromJS(v); ok {
+		return keys, true
+	}
+	return nil, false
 }
 
 // Helpers: detect Blob/File and read text via Promise
@@ -2024,7 +2580,11 @@ func readNDJSON(this js.Value, args []js.Value) any {
 			promiseReadBlobText(v).Call("then",
 				js.FuncOf(func(this js.Value, a []js.Value) any {
 					text := normalizeNewlines(a[0].String())
-					df := g.ReadNDJSON(text)
+					df, readErr := g.ReadNDJSON(text)
+					if readErr != nil {
+						reject.Invoke(jsError(readErr))
+						return nil
+					}
 					id := put(df)
 					resolve.Invoke(dfObject(id))
 					return nil
@@ -2043,7 +2603,10 @@ func readNDJSON(this js.Value, args []js.Value) any {
 		return err
 	}
 	text = normalizeNewlines(text)
-	df := g.ReadNDJSON(text)
+	df, readErr := g.ReadNDJSON(text)
+	if readErr != nil {
+		return rejected(readErr)
+	}
 	id := put(df)
 	return dfObject(id)
 }
@@ -2060,7 +2623,11 @@ func readYAML(this js.Value, args []js.Value) any {
 			promiseReadBlobText(v).Call("then",
 				js.FuncOf(func(this js.Value, a []js.Value) any {
 					text := a[0].String()
-					df := g.ReadYAML(text)
+					df, readErr := g.ReadYAML(text)
+					if readErr != nil {
+						reject.Invoke(jsError(readErr))
+						return nil
+					}
 					id := put(df)
 					resolve.Invoke(dfObject(id))
 					return nil
@@ -2078,7 +2645,56 @@ func readYAML(this js.Value, args []js.Value) any {
 	if err != "" {
 		return err
 	}
-	df := g.ReadYAML(text)
+	df, readErr := g.ReadYAML(text)
+	if readErr != nil {
+		return rejected(readErr)
+	}
+	id := put(df)
+	return dfObject(id)
+}
+
+// ReadXLSX(Uint8Array|ArrayBuffer|File|Blob, sheet?) -> DataFrame object or Promise<DataFrame>
+// sheet defaults to the first sheet of the workbook.
+func readXLSX(this js.Value, args []js.Value) any {
+	if len(args) < 1 {
+		return "error: usage ReadXLSX(Uint8Array|ArrayBuffer|File|Blob, sheet?)"
+	}
+	v := args[0]
+	sheet := ""
+	if len(args) >= 2 && args[1].Type() == js.TypeString {
+		sheet = args[1].String()
+	}
+	if isBlobOrFile(v) {
+		return js.Global().Get("Promise").New(js.FuncOf(func(this js.Value, prArgs []js.Value) any {
+			resolve, reject := prArgs[0], prArgs[1]
+			v.Call("arrayBuffer").Call("then",
+				js.FuncOf(func(this js.Value, a []js.Value) any {
+					data, _ := toText(a[0])
+					df, readErr := g.ReadXLSX(data, sheet)
+					if readErr != nil {
+						reject.Invoke(jsError(readErr))
+						return nil
+					}
+					id := put(df)
+					resolve.Invoke(dfObject(id))
+					return nil
+				}),
+				js.FuncOf(func(this js.Value, a []js.Value) any {
+					reject.Invoke(a[0])
+					return nil
+				}),
+			)
+			return nil
+		}))
+	}
+	data, err := toText(v)
+	if err != "" {
+		return err
+	}
+	df, readErr := g.ReadXLSX(data, sheet)
+	if readErr != nil {
+		return rejected(readErr)
+	}
 	id := put(df)
 	return dfObject(id)
 }
@@ -2201,6 +2817,21 @@ func reportObject(id int) js.Value {
 		return reportObject(id)
 	}))
 
+	// r.AddFilter(page, column, kind) – kind: select, multiselect, range, daterange
+	r.Set("AddFilter", js.FuncOf(func(this js.Value, args []js.Value) any {
+		rep := getReport(id)
+		if rep == nil {
+			return "error: invalid handle"
+		}
+		if len(args) < 3 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString || args[2].Type() != js.TypeString {
+			return "error: usage AddFilter(page, column, kind)"
+		}
+		if err := rep.AddFilter(args[0].String(), args[1].String(), args[2].String()); err != nil {
+			return "error: " + err.Error()
+		}
+		return reportObject(id)
+	}))
+
 	// r.AddSubText(page, text)
 	r.Set("AddSubText", js.FuncOf(func(this js.Value, args []js.Value) any {
 		rep := getReport(id)
@@ -2243,7 +2874,7 @@ func reportObject(id int) js.Value {
 		return reportObject(id)
 	}))
 
-	// r.Save(filename) -> downloads file
+	// r.Save(filename, options?) -> downloads file; options {svg: true, offline: true}
 	r.Set("Save", js.FuncOf(func(this js.Value, args []js.Value) any {
 		rep := getReport(id)
 		if rep == nil {
@@ -2254,6 +2885,17 @@ func reportObject(id int) js.Value {
 			filename = args[0].String()
 		}
 		html := buildReportHTML(rep)
+		if len(args) >= 2 && args[1].Type() == js.TypeObject {
+			var saveOpts g.SaveOptions
+			raw := js.Global().Get("JSON").Call("stringify", args[1]).String()
+			if err := json.Unmarshal([]byte(raw), &saveOpts); err != nil {
+				return "error: " + err.Error()
+			}
+			var err error
+			if html, err = rep.HTML(saveOpts); err != nil {
+				return "error: " + err.Error()
+			}
+		}
 		array := js.Global().Get("Array").New()
 		array.Call("push", js.ValueOf(html))
 		opts := js.Global().Get("Object").New()
@@ -2275,6 +2917,58 @@ func reportObject(id int) js.Value {
 		return "ok"
 	}))
 
+	// r.SavePDF(filename) -> downloads the report as an A4 PDF
+	r.Set("SavePDF", js.FuncOf(func(this js.Value, args []js.Value) any {
+		rep := getReport(id)
+		if rep == nil {
+			return "error: invalid handle"
+		}
+		filename := "report.pdf"
+		if len(args) >= 1 && args[0].Type() == js.TypeString && args[0].String() != "" {
+			filename = args[0].String()
+		}
+		data, err := rep.PDF()
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		u8 := js.Global().Get("Uint8Array").New(len(data))
+		_ = js.CopyBytesToJS(u8, data)
+		parts := js.Global().Get("Array").New()
+		parts.Call("push", u8)
+		blob := js.Global().Get("Blob").New(parts, map[string]any{"type": "application/pdf"})
+		url := js.Global().Get("URL").Call("createObjectURL", blob)
+		doc := js.Global().Get("document")
+		a := doc.Call("createElement", "a")
+		a.Set("href", url)
+		a.Set("download", filename)
+		a.Set("rel", "noopener")
+		doc.Get("body").Call("appendChild", a)
+		a.Call("click")
+		a.Get("parentNode").Call("removeChild", a)
+		js.Global().Get("setTimeout").Invoke(js.FuncOf(func(this js.Value, _ []js.Value) any {
+			js.Global().Get("URL").Call("revokeObjectURL", url)
+			return nil
+		}), 1000)
+		return "ok"
+	}))
+
+	// r.Spec() -> {spec, datasets}; RenderReport(spec, datasets) rebuilds the report
+	r.Set("Spec", js.FuncOf(func(this js.Value, args []js.Value) any {
+		rep := getReport(id)
+		if rep == nil {
+			return "error: invalid handle"
+		}
+		spec, datasets := rep.Spec()
+		out := js.Global().Get("Object").New()
+		out.Set("spec", js.Global().Get("JSON").Call("parse", spec.ToJSON()))
+		frames := js.Global().Get("Object").New()
+		for name, df := range datasets {
+			frames.Set(name, dfObject(put(df)))
+		}
+		out.Set("datasets", frames)
+		return out
+	}))
+
 	// Theme setters (expose Set*; keep old names as aliases)
 	// SetPrimary(color)
 	r.Set("SetPrimary", js.FuncOf(func(this js.Value, args []js.Value) any {
@@ -2446,103 +3140,9 @@ func reportObject(id int) js.Value {
 	return r
 }
 
-// buildReportHTML reproduces reports.go Open/Save assembly without touching the filesystem.
+// buildReportHTML returns the page Report.Save writes, without touching the filesystem.
 func buildReportHTML(report *g.Report) string {
-	html := report.Top +
-		report.Primary +
-		report.Secondary +
-		report.Accent +
-		report.Neutral +
-		report.Base100 +
-		report.Info +
-		report.Success +
-		report.Warning +
-		report.Err +
-		report.Htmlheading
-
-	if len(report.Pageshtml) > 1 {
-		html += `
-        <div id="app"  style="text-align: center;" class="drawer w-full lg:drawer-open">
-            <input id="my-drawer-2" type="checkbox" class="drawer-toggle" />
-            <div class="drawer-content flex flex-col">
-                <!-- Navbar -->
-                <div class="w-full navbar bg-neutral text-neutral-content shadow-lg ">
-            ` + fmt.Sprintf(`<div class="flex-1 px-2 mx-2 btn btn-sm btn-neutral normal-case text-xl shadow-none hover:bg-neutral hover:border-neutral flex content-center"><a class="lg:ml-0 ml-14 text-4xl">%s</a></div>`, report.Title) + `
-                <div class="flex-none lg:hidden">
-                    <label for="my-drawer-2" class="btn btn-neutral btn-square shadow-lg hover:shadow-xl hover:-translate-y-0.5 no-animation">
-                    <svg xmlns="http://www.w3.org/2000/svg" fill="none" viewBox="0 0 24 24"
-                        class="inline-block w-6 h-6 stroke-current">
-                        <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M4 6h16M4 12h16M4 18h16"></path>
-                    </svg>
-                    </label>
-                    </div>
-                </div>
-                <!-- content goes here! -->
-                <div  class="w-full lg:w-3/4 md:w-3/4 sm:w-5/6 mx-auto flex-col justify-self-center">
-            `
-	} else {
-		html += `
-        <div id="app"  style="text-align: center;">
-            <!-- Navbar -->
-            <div class="w-full navbar bg-neutral text-neutral-content shadow-lg ">
-        ` + fmt.Sprintf(`<div class="flex-1 px-2 mx-2 btn btn-sm btn-neutral normal-case text-xl shadow-none hover:bg-neutral hover:border-neutral flex content-center"><a class=" text-4xl">%s</a></div>
-            </div>`, report.Title) + `<div  class="w-full lg:w-3/4 md:w-3/4 sm:w-5/6 mx-auto flex-col justify-self-center">`
-	}
-	// pages html
-	for _, pageMap := range report.Pageshtml {
-		for i := 0; i < len(pageMap); i++ {
-			html += pageMap[strconv.Itoa(i)]
-		}
-	}
-	if len(report.Pageshtml) > 1 {
-		html += `
-            </div>
-        </div>
-        <!-- <br> -->
-        <div class="drawer-side">
-            <label for="my-drawer-2" class="drawer-overlay bg-neutral"></label>
-            <ul class="menu p-4 w-80 bg-neutral h-full overflow-y-auto min-h-screen text-base-content shadow-none space-y-2 ">
-            <div class="card w-72 bg-base-100 shadow-xl">
-                <div class="card-body">
-                    <div class="flex space-x-6 place-content-center">
-                        <h2 class="card-title black-text-shadow-sm flex justify">Pages</h2>
-                    </div>
-                <div class="flex flex-col w-full h-1px">
-                    <div class="divider"></div>
-                </div>
-                <div class="space-y-4">
-        `
-		for page := range report.Pageshtml {
-			html += fmt.Sprintf(`
-            <button v-if="page == '%s' " @click="page = '%s' " class="btn btn-block btn-sm btn-neutral text-white bg-neutral shadow-lg  hover:shadow-xl hover:-translate-y-0.5 no-animation " >%s</button>
-            <button v-else @click="page = '%s' " class="btn btn-block btn-sm bg-base-100 btn-outline btn-neutral hover:text-white shadow-lg hover:shadow-xl hover:-translate-y-0.5 no-animation " >%s</button>
-            `, page, page, page, page, page)
-		}
-	} else {
-		html += `
-            </div>
-        </div>
-        `
-	}
-	html += report.Scriptheading
-	pages := `pages: [`
-	count := 0
-	for page := range report.Pageshtml {
-		if count == 0 {
-			html += fmt.Sprintf("%q", page) + ","
-		}
-		pages += fmt.Sprintf("%q", page) + ", "
-		count++
-	}
-	pages = strings.TrimSuffix(pages, ", ") + `],`
-	html += pages
-	html += report.Scriptmiddle
-	for _, jsMap := range report.Pagesjs {
-		for i := 0; i < len(jsMap); i++ {
-			html += jsMap[strconv.Itoa(i)]
-		}
-	}
-	html += report.Bottom
+	html, _ := report.HTML()
 	return html
 }
 
@@ -2573,6 +3173,7 @@ func main() {
 	api.Set("ReadYAML", js.FuncOf(readYAML))
 	api.Set("ReadHTML", js.FuncOf(readHTML))
 	api.Set("ReadHTMLTop", js.FuncOf(readHTMLTop))
+	api.Set("ReadXLSX", js.FuncOf(readXLSX))
 	api.Set("GetAPI", js.FuncOf(getAPI))
 	// CloneJSON(dfJsonLike) -> string (JSON of cloned DataFrame)
 	// Accepts a string, object, array, Uint8Array, or ArrayBuffer.
@@ -2729,6 +3330,13 @@ func main() {
 		expr.Set("Ge", makeCmp("ge"))
 		expr.Set("Lt", makeCmp("lt"))
 		expr.Set("Le", makeCmp("le"))
+		// arithmetic shares the {Type, Left, Right} shape of the comparisons
+		expr.Set("Add", makeCmp("add"))
+		expr.Set("Sub", makeCmp("sub"))
+		expr.Set("Mul", makeCmp("mul"))
+		expr.Set("Div", makeCmp("div"))
+		expr.Set("Mod", makeCmp("mod"))
+		expr.Set("Pow", makeCmp("pow"))
 		expr.Set("Eq", js.FuncOf(func(this js.Value, a []js.Value) any {
 			if len(a) < 1 {
 				return "error: Eq(right)"
@@ -2999,6 +3607,220 @@ func main() {
 			}
 			return o
 		}))
+		// helpers without arguments: Title(), InitCap(), HtmlUnescape(), Reverse(), Soundex(),
+		// Abs(), Floor(), Ceil(), Sqrt(), Log(), Exp(), Year(), Quarter(), Month(), Day(),
+		// DayOfWeek(), WeekOfYear(), Hour()
+		for name, typ := range map[string]string{
+			"Title": "title", "InitCap": "initcap", "HtmlUnescape": "html_unescape",
+			"Reverse": "reverse", "Soundex": "soundex",
+			"Abs": "abs", "Floor": "floor", "Ceil": "ceil", "Sqrt": "sqrt", "Log": "log", "Exp": "exp",
+			"Year": "year", "Quarter": "quarter", "Month": "month", "Day": "day",
+			"DayOfWeek": "dayofweek", "WeekOfYear": "weekofyear", "Hour": "hour",
+		} {
+			typ := typ
+			expr.Set(name, js.FuncOf(func(this js.Value, a []js.Value) any {
+				o := js.Global().Get("Object").New()
+				o.Set("Type", typ)
+				o.Set("Expr", expr)
+				return o
+			}))
+		}
+		// ToTimestamp(format1, format2, ...) / ToDate(...) – formats are optional
+		makeParse := func(typ string) js.Func {
+			return js.FuncOf(func(this js.Value, a []js.Value) any {
+				formats := js.Global().Get("Array").New()
+				for _, f := range a {
+					if f.Type() == js.TypeString {
+						formats.Call("push", f.String())
+					}
+				}
+				o := js.Global().Get("Object").New()
+				o.Set("Type", typ)
+				o.Set("Expr", expr)
+				o.Set("Formats", formats)
+				return o
+			})
+		}
+		expr.Set("ToTimestamp", makeParse("to_timestamp"))
+		expr.Set("ToDate", makeParse("to_date"))
+		expr.Set("DateTrunc", js.FuncOf(func(this js.Value, a []js.Value) any {
+			if len(a) < 1 || a[0].Type() != js.TypeString {
+				return "error: DateTrunc(unit)"
+			}
+			o := js.Global().Get("Object").New()
+			o.Set("Type", "date_trunc")
+			o.Set("Expr", expr)
+			o.Set("Unit", a[0].String())
+			return o
+		}))
+		expr.Set("DateAdd", js.FuncOf(func(this js.Value, a []js.Value) any {
+			if len(a) < 2 || a[0].Type() != js.TypeString || a[1].Type() != js.TypeNumber {
+				return "error: DateAdd(unit, n)"
+			}
+			o := js.Global().Get("Object").New()
+			o.Set("Type", "date_add")
+			o.Set("Expr", expr)
+			o.Set("Unit", a[0].String())
+			o.Set("Count", a[1].Int())
+			return o
+		}))
+		makeZone := func(name, typ string) js.Func {
+			return js.FuncOf(func(this js.Value, a []js.Value) any {
+				if len(a) < 1 || a[0].Type() != js.TypeString {
+					return "error: " + name + "(zone)"
+				}
+				o := js.Global().Get("Object").New()
+				o.Set("Type", typ)
+				o.Set("Expr", expr)
+				o.Set("Zone", a[0].String())
+				return o
+			})
+		}
+		expr.Set("FromUTC", makeZone("FromUTC", "from_utc"))
+		expr.Set("ToUTC", makeZone("ToUTC", "to_utc"))
+		expr.Set("FormatDate", js.FuncOf(func(this js.Value, a []js.Value) any {
+			if len(a) < 1 || a[0].Type() != js.TypeString {
+				return "error: FormatDate(format)"
+			}
+			o := js.Global().Get("Object").New()
+			o.Set("Type", "format_date")
+			o.Set("Expr", expr)
+			o.Set("Format", a[0].String())
+			return o
+		}))
+		expr.Set("Round", js.FuncOf(func(this js.Value, a []js.Value) any {
+			o := js.Global().Get("Object").New()
+			o.Set("Type", "round")
+			o.Set("Expr", expr)
+			if len(a) >= 1 && a[0].Type() == js.TypeNumber {
+				o.Set("Index", a[0].Int()) // decimal places, default 0
+			}
+			return o
+		}))
+		expr.Set("Substr", js.FuncOf(func(this js.Value, a []js.Value) any {
+			if len(a) < 2 || a[0].Type() != js.TypeNumber || a[1].Type() != js.TypeNumber {
+				return "error: Substr(start, length)"
+			}
+			o := js.Global().Get("Object").New()
+			o.Set("Type", "substr")
+			o.Set("Expr", expr)
+			o.Set("Start", a[0].Int()) // 1-based start
+			o.Set("Length", a[1].Int())
+			return o
+		}))
+		makePad := func(name, typ string) js.Func {
+			return js.FuncOf(func(this js.Value, a []js.Value) any {
+				if len(a) < 1 || a[0].Type() != js.TypeNumber {
+					return "error: " + name + "(length[, pad])"
+				}
+				o := js.Global().Get("Object").New()
+				o.Set("Type", typ)
+				o.Set("Expr", expr)
+				o.Set("Length", a[0].Int())
+				if len(a) >= 2 && a[1].Type() == js.TypeString {
+					o.Set("Pad", a[1].String()) // default " "
+				}
+				return o
+			})
+		}
+		expr.Set("LPad", makePad("LPad", "lpad"))
+		expr.Set("RPad", makePad("RPad", "rpad"))
+		expr.Set("Repeat", js.FuncOf(func(this js.Value, a []js.Value) any {
+			if len(a) < 1 || a[0].Type() != js.TypeNumber {
+				return "error: Repeat(n)"
+			}
+			o := js.Global().Get("Object").New()
+			o.Set("Type", "repeat")
+			o.Set("Expr", expr)
+			o.Set("Count", a[0].Int())
+			return o
+		}))
+		expr.Set("Translate", js.FuncOf(func(this js.Value, a []js.Value) any {
+			if len(a) < 2 || a[0].Type() != js.TypeString || a[1].Type() != js.TypeString {
+				return "error: Translate(from, to)"
+			}
+			o := js.Global().Get("Object").New()
+			o.Set("Type", "translate")
+			o.Set("Expr", expr)
+			o.Set("From", a[0].String())
+			o.Set("To", a[1].String())
+			return o
+		}))
+		expr.Set("Levenshtein", js.FuncOf(func(this js.Value, a []js.Value) any {
+			if len(a) < 1 {
+				return "error: Levenshtein(other)"
+			}
+			o := js.Global().Get("Object").New()
+			o.Set("Type", "levenshtein")
+			o.Set("Expr", expr)
+			if a[0].Type() == js.TypeString {
+				// a string is a literal here, as in Eq; pass Col(name) for a column
+				r := js.Global().Get("Object").New()
+				r.Set("Type", "lit")
+				r.Set("Value", a[0].String())
+				o.Set("Right", r)
+			} else {
+				o.Set("Right", toExpr.Invoke(a[0]))
+			}
+			return o
+		}))
+		expr.Set("SplitPart", js.FuncOf(func(this js.Value, a []js.Value) any {
+			if len(a) < 2 || a[0].Type() != js.TypeString || a[1].Type() != js.TypeNumber {
+				return "error: SplitPart(delimiter, part)"
+			}
+			o := js.Global().Get("Object").New()
+			o.Set("Type", "split_part")
+			o.Set("Expr", expr)
+			o.Set("Delimiter", a[0].String())
+			o.Set("Part", a[1].Int()) // 1-based
+			return o
+		}))
+		// IsIn(v1, v2, ...) or IsIn([v1, v2, ...]); strings are literals
+		expr.Set("IsIn", js.FuncOf(func(this js.Value, a []js.Value) any {
+			vals := a
+			if len(a) == 1 && a[0].InstanceOf(js.Global().Get("Array")) {
+				vals = make([]js.Value, a[0].Length())
+				for i := range vals {
+					vals[i] = a[0].Index(i)
+				}
+			}
+			cols := js.Global().Get("Array").New()
+			for _, v := range vals {
+				if v.Type() == js.TypeString {
+					r := js.Global().Get("Object").New()
+					r.Set("Type", "lit")
+					r.Set("Value", v.String())
+					cols.Call("push", r)
+					continue
+				}
+				cols.Call("push", toExpr.Invoke(v))
+			}
+			o := js.Global().Get("Object").New()
+			o.Set("Type", "isin")
+			o.Set("Expr", expr)
+			o.Set("Cols", cols)
+			return o
+		}))
+		expr.Set("IsBetween", js.FuncOf(func(this js.Value, a []js.Value) any {
+			if len(a) < 2 {
+				return "error: IsBetween(lower, upper)"
+			}
+			bound := func(v js.Value) js.Value {
+				if v.Type() == js.TypeString {
+					r := js.Global().Get("Object").New()
+					r.Set("Type", "lit")
+					r.Set("Value", v.String())
+					return r
+				}
+				return toExpr.Invoke(v)
+			}
+			o := js.Global().Get("Object").New()
+			o.Set("Type", "isbetween")
+			o.Set("Expr", expr)
+			o.Set("Left", bound(a[0]))
+			o.Set("Right", bound(a[1]))
+			return o
+		}))
 		expr.Set("And", js.FuncOf(func(this js.Value, a []js.Value) any {
 			if len(a) < 1 {
 				return "error: And(...conds)"
@@ -3108,6 +3930,21 @@ func main() {
 	// ---------- Functions (builders that mirror functions.go) ----------
 	// Reuse toExpr helper defined above to wrap strings/numbers/objects into an expr.
 
+	// Greatest(expr1, expr2, ...), Least(...), Coalesce(...)
+	for name, typ := range map[string]string{"Greatest": "greatest", "Least": "least", "Coalesce": "coalesce"} {
+		typ := typ
+		api.Set(name, js.FuncOf(func(this js.Value, args []js.Value) any {
+			arr := js.Global().Get("Array").New()
+			for _, a := range args {
+				arr.Call("push", toExpr.Invoke(a))
+			}
+			o := js.Global().Get("Object").New()
+			o.Set("Type", typ)
+			o.Set("Cols", arr)
+			return o
+		}))
+	}
+
 	// SHA256(expr1, expr2, ...)
 	api.Set("SHA256", js.FuncOf(func(this js.Value, args []js.Value) any {
 		arr := js.Global().Get("Array").New()
@@ -3227,6 +4064,39 @@ func main() {
 		return o
 	}))
 
+	// ---------- Window functions ----------
+	// df.Column("rn", RowNumber().Over(Window().PartitionBy("store").OrderBy("day")))
+	api.Set("Window", js.FuncOf(func(this js.Value, args []js.Value) any {
+		return windowSpec(nil, nil, nil)
+	}))
+	api.Set("RowNumber", js.FuncOf(func(this js.Value, args []js.Value) any {
+		return windowFn(map[string]any{"func": "row_number"})
+	}))
+	api.Set("Rank", js.FuncOf(func(this js.Value, args []js.Value) any {
+		return windowFn(map[string]any{"func": "rank"})
+	}))
+	api.Set("DenseRank", js.FuncOf(func(this js.Value, args []js.Value) any {
+		return windowFn(map[string]any{"func": "dense_rank"})
+	}))
+	offsetFn := func(fn string) js.Func {
+		// Lag(col, offset = 1, default = null)
+		return js.FuncOf(func(this js.Value, args []js.Value) any {
+			if len(args) < 1 || args[0].Type() != js.TypeString {
+				return "error: " + strings.Title(fn) + "(column, offset, default)"
+			}
+			data := map[string]any{"func": fn, "col": args[0].String()}
+			if len(args) > 1 && args[1].Type() == js.TypeNumber {
+				data["offset"] = args[1].Int()
+			}
+			if len(args) > 2 {
+				data["default"] = jsValToAny(args[2])
+			}
+			return windowFn(data)
+		})
+	}
+	api.Set("Lag", offsetFn("lag"))
+	api.Set("Lead", offsetFn("lead"))
+
 	// UDF(fn, ...inputCols) -> returns a spec usable inside df.Column
 	// Signature change: fn comes first, then variadic columns
 	api.Set("UDF", js.FuncOf(func(this js.Value, args []js.Value) any {
@@ -3323,6 +4193,69 @@ func main() {
 		id := putReport(r)
 		return reportObject(id)
 	}))
+	// RenderReport(spec, {name: df, ...}) – spec is a JSON/YAML string or a spec object
+	api.Set("RenderReport", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) < 2 || args[1].Type() != js.TypeObject {
+			return "error: usage RenderReport(spec, datasets)"
+		}
+		input := ""
+		switch args[0].Type() {
+		case js.TypeString:
+			input = args[0].String()
+		case js.TypeObject:
+			input = js.Global().Get("JSON").Call("stringify", args[0]).String()
+		default:
+			return "error: usage RenderReport(spec, datasets)"
+		}
+		spec, err := g.ReadReportSpec(input)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		datasets := map[string]*g.DataFrame{}
+		keys := js.Global().Get("Object").Call("keys", args[1])
+		for i := 0; i < keys.Length(); i++ {
+			name := keys.Index(i).String()
+			dfh := args[1].Get(name).Get("handle")
+			if !dfh.Truthy() {
+				return "error: dataset " + name + " missing handle"
+			}
+			df := get(dfh.Int())
+			if df == nil {
+				return "error: invalid df handle for dataset " + name
+			}
+			datasets[name] = df
+		}
+		r, err := g.RenderReport(spec, datasets)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		return reportObject(putReport(r))
+	}))
+	// SQL(query, {name: df, ...}) -> DataFrame object
+	api.Set("SQL", js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) < 2 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeObject {
+			return "error: usage SQL(query, tables)"
+		}
+		tables := map[string]*g.DataFrame{}
+		keys := js.Global().Get("Object").Call("keys", args[1])
+		for i := 0; i < keys.Length(); i++ {
+			name := keys.Index(i).String()
+			dfh := args[1].Get(name).Get("handle")
+			if !dfh.Truthy() {
+				return "error: table " + name + " missing handle"
+			}
+			df := get(dfh.Int())
+			if df == nil {
+				return "error: invalid df handle for table " + name
+			}
+			tables[name] = df
+		}
+		df, err := g.SQL(args[0].String(), tables)
+		if err != nil {
+			return "error: " + err.Error()
+		}
+		return dfObject(put(df))
+	}))
 	// --------- Other ---------
 	api.Set("Free", js.FuncOf(free)) // legacy handle-based
 	if fr := js.Global().Get("FinalizationRegistry"); fr.Truthy() {
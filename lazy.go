@@ -0,0 +1,791 @@
+package gophers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// LazyFrame records DataFrame operations as a logical plan. Nothing runs until
+// Collect, which first optimizes the plan (predicate pushdown, projection
+// pushdown into readers and fusion of consecutive Column calls).
+type LazyFrame struct {
+	plan *lazyNode
+}
+
+// lazyNode is one operator of a logical plan.
+type lazyNode struct {
+	op    string // scan_df, scan_csv, scan_ndjson, scan_sqlite, filter, select, drop, column, groupby, join, orderby
+	input *lazyNode
+	right *lazyNode
+
+	df    *DataFrame    // scan_df source
+	path  string        // scan_csv / scan_ndjson / scan_sqlite source
+	table string        // scan_sqlite table
+	csv   CSVOptions    // scan_csv options
+	cols  []string      // scan projection, select and drop columns
+	preds []interface{} // predicates pushed into a scan
+
+	cond     interface{}   // filter
+	exprs    []lazyExpr    // column (fused)
+	keys     []string      // groupby
+	aggs     []Aggregation // groupby
+	leftOn   []string      // join keys
+	rightOn  []string      // join keys
+	on       interface{}   // join predicate (JoinOn)
+	how      string        // join
+	suffixes []string      // join
+	column   string        // orderby
+	asc      bool          // orderby
+}
+
+// lazyExpr is a single Column call: output name and ColumnExpr/Column spec.
+type lazyExpr struct {
+	name string
+	spec interface{}
+}
+
+// Lazy returns a LazyFrame whose plan starts from this DataFrame.
+func (df *DataFrame) Lazy() *LazyFrame {
+	return &LazyFrame{plan: &lazyNode{op: "scan_df", df: df}}
+}
+
+// ScanCSV returns a LazyFrame reading a CSV file (or raw CSV text) on Collect.
+// Only the columns the plan needs are kept and pushed-down filters run while reading.
+// An optional CSVOptions is applied as in ReadCSV.
+func ScanCSV(path string, opts ...CSVOptions) *LazyFrame {
+	n := &lazyNode{op: "scan_csv", path: path}
+	if len(opts) > 0 {
+		n.csv = opts[0]
+	}
+	return &LazyFrame{plan: n}
+}
+
+// ScanNDJSON returns a LazyFrame reading newline-delimited JSON (file or raw text)
+// on Collect. Pushed-down filters run while reading; see also LazyFrame.Batches.
+func ScanNDJSON(path string) *LazyFrame {
+	return &LazyFrame{plan: &lazyNode{op: "scan_ndjson", path: path}}
+}
+
+// ScanSqlite returns a LazyFrame reading a sqlite table on Collect.
+// The projection is pushed into the generated SELECT.
+func ScanSqlite(path, table string) *LazyFrame {
+	return &LazyFrame{plan: &lazyNode{op: "scan_sqlite", path: path, table: table}}
+}
+
+func (lf *LazyFrame) then(n *lazyNode) *LazyFrame {
+	n.input = lf.plan
+	return &LazyFrame{plan: n}
+}
+
+// Filter adds a row filter (ColumnExpr or Column) to the plan.
+func (lf *LazyFrame) Filter(cond interface{}) *LazyFrame {
+	return lf.then(&lazyNode{op: "filter", cond: cond})
+}
+
+// Select keeps only the given columns.
+func (lf *LazyFrame) Select(columns ...string) *LazyFrame {
+	return lf.then(&lazyNode{op: "select", cols: columns})
+}
+
+// Drop removes the given columns.
+func (lf *LazyFrame) Drop(columns ...string) *LazyFrame {
+	return lf.then(&lazyNode{op: "drop", cols: columns})
+}
+
+// Column adds or replaces a column (ColumnExpr or Column spec).
+func (lf *LazyFrame) Column(column string, spec interface{}) *LazyFrame {
+	return lf.then(&lazyNode{op: "column", exprs: []lazyExpr{{name: column, spec: spec}}})
+}
+
+// GroupBy groups by one or more key columns (string or []string) and applies the aggregations.
+func (lf *LazyFrame) GroupBy(groupcols interface{}, aggs ...Aggregation) *LazyFrame {
+	var keys []string
+	switch v := groupcols.(type) {
+	case string:
+		keys = []string{v}
+	case []string:
+		keys = v
+	}
+	return lf.then(&lazyNode{op: "groupby", keys: keys, aggs: aggs})
+}
+
+// Join joins with another LazyFrame on key columns (see DataFrame.Join).
+func (lf *LazyFrame) Join(right *LazyFrame, leftOn, rightOn interface{}, joinType string, suffixes ...string) *LazyFrame {
+	lk, _ := joinKeys(leftOn)
+	rk, _ := joinKeys(rightOn)
+	return lf.then(&lazyNode{op: "join", right: right.plan, leftOn: lk, rightOn: rk, how: strings.ToLower(joinType), suffixes: suffixes})
+}
+
+// JoinOn joins with another LazyFrame on a predicate (see DataFrame.JoinOn).
+func (lf *LazyFrame) JoinOn(right *LazyFrame, on interface{}, joinType string, suffixes ...string) *LazyFrame {
+	return lf.then(&lazyNode{op: "join", right: right.plan, on: on, how: strings.ToLower(joinType), suffixes: suffixes})
+}
+
+// OrderBy sorts by column.
+func (lf *LazyFrame) OrderBy(column string, asc bool) *LazyFrame {
+	return lf.then(&lazyNode{op: "orderby", column: column, asc: asc})
+}
+
+// Collect optimizes the plan and runs it, returning the resulting DataFrame or
+// the first error hit while reading a source.
+// A GroupBy over a CSV or NDJSON scan is aggregated batch by batch, so the
+// file never has to fit in memory.
+func (lf *LazyFrame) Collect() (*DataFrame, error) {
+	return optimizePlan(lf.plan).run()
+}
+
+// Explain returns the optimized plan, one operator per line.
+func (lf *LazyFrame) Explain() string {
+	var b strings.Builder
+	optimizePlan(lf.plan).explain(&b, 0)
+	return b.String()
+}
+
+// optimizePlan returns an optimized copy of the plan; the original is left untouched
+// so a LazyFrame can be collected more than once.
+func optimizePlan(n *lazyNode) *lazyNode {
+	n = pushPredicates(n.clone())
+	n = fuseColumns(n)
+	pruneColumns(n, nil)
+	return n
+}
+
+func (n *lazyNode) clone() *lazyNode {
+	if n == nil {
+		return nil
+	}
+	c := *n
+	c.cols = append([]string(nil), n.cols...)
+	c.preds = append([]interface{}(nil), n.preds...)
+	c.exprs = append([]lazyExpr(nil), n.exprs...)
+	c.keys = append([]string(nil), n.keys...)
+	c.input = n.input.clone()
+	c.right = n.right.clone()
+	return &c
+}
+
+// specRefs returns the columns a ColumnExpr or WindowColumn reads; ok is
+// false for compiled Column closures whose inputs cannot be inspected.
+func specRefs(spec interface{}) (map[string]struct{}, bool) {
+	switch e := spec.(type) {
+	case ColumnExpr:
+		return referencedCols(e, nil), true
+	case WindowColumn:
+		return toSet(e.refs()), true
+	}
+	return nil, false
+}
+
+// isWindow reports whether a Column spec is a window function, whose values
+// depend on the other rows and so must see the unfiltered input.
+func isWindow(spec interface{}) bool {
+	switch e := spec.(type) {
+	case WindowColumn:
+		return true
+	case ColumnExpr:
+		return strings.EqualFold(e.Type, "window")
+	}
+	return false
+}
+
+// schema returns the output columns of a node, or nil when unknown.
+func (n *lazyNode) schema() []string {
+	switch n.op {
+	case "scan_df":
+		return n.df.Cols
+	case "scan_csv":
+		return csvHeader(n.path, n.csv)
+	case "scan_ndjson":
+		return nil
+	case "scan_sqlite":
+		return sqliteColumns(n.path, n.table)
+	case "filter", "orderby":
+		return n.input.schema()
+	case "select":
+		return n.cols
+	case "drop":
+		in := n.input.schema()
+		if in == nil {
+			return nil
+		}
+		drop := toSet(n.cols)
+		var out []string
+		for _, c := range in {
+			if _, ok := drop[c]; !ok {
+				out = append(out, c)
+			}
+		}
+		return out
+	case "column":
+		in := n.input.schema()
+		if in == nil {
+			return nil
+		}
+		out := append([]string(nil), in...)
+		have := toSet(in)
+		for _, e := range n.exprs {
+			if _, ok := have[e.name]; !ok {
+				out = append(out, e.name)
+				have[e.name] = struct{}{}
+			}
+		}
+		return out
+	case "groupby":
+		return outputNames(n.keys, n.aggs)
+	case "join":
+		l, r := n.input.schema(), n.right.schema()
+		if n.how == "semi" || n.how == "anti" {
+			return l
+		}
+		if l == nil || r == nil {
+			return nil
+		}
+		lo, ro := n.joinNames(l, r)
+		out := make([]string, 0, len(l)+len(r))
+		for _, c := range l {
+			out = append(out, lo[c])
+		}
+		for _, c := range r {
+			out = append(out, ro[c])
+		}
+		return out
+	}
+	return nil
+}
+
+// joinNames mirrors the collision renaming done by DataFrame.Join.
+func (n *lazyNode) joinNames(left, right []string) (map[string]string, map[string]string) {
+	ls, rs := joinSuffixes(n.suffixes)
+	return joinOutputNames(left, right, ls, rs)
+}
+
+func toSet(cols []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(cols))
+	for _, c := range cols {
+		set[c] = struct{}{}
+	}
+	return set
+}
+
+// pushPredicates moves every filter as close to the scans as it can go.
+func pushPredicates(n *lazyNode) *lazyNode {
+	if n == nil {
+		return nil
+	}
+	n.input = pushPredicates(n.input)
+	n.right = pushPredicates(n.right)
+	if n.op == "filter" {
+		return pushFilter(n.input, n.cond)
+	}
+	return n
+}
+
+// pushFilter applies cond on top of n, or below it when that gives the same rows.
+func pushFilter(n *lazyNode, cond interface{}) *lazyNode {
+	refs, known := specRefs(cond)
+	subset := func(cols []string) bool {
+		if !known {
+			return false
+		}
+		set := toSet(cols)
+		for r := range refs {
+			if _, ok := set[r]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+	disjoint := func(cols []string) bool {
+		if !known {
+			return false
+		}
+		for _, c := range cols {
+			if _, ok := refs[c]; ok {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch n.op {
+	case "scan_df", "scan_csv", "scan_ndjson", "scan_sqlite":
+		n.preds = append(n.preds, cond)
+		return n
+	case "filter", "orderby":
+		n.input = pushFilter(n.input, cond)
+		return n
+	case "select":
+		if subset(n.cols) {
+			n.input = pushFilter(n.input, cond)
+			return n
+		}
+	case "drop":
+		if disjoint(n.cols) {
+			n.input = pushFilter(n.input, cond)
+			return n
+		}
+	case "column":
+		names := make([]string, len(n.exprs))
+		windowed := false
+		for i, e := range n.exprs {
+			names[i] = e.name
+			windowed = windowed || isWindow(e.spec)
+		}
+		if !windowed && disjoint(names) {
+			n.input = pushFilter(n.input, cond)
+			return n
+		}
+	case "groupby":
+		if subset(n.keys) {
+			n.input = pushFilter(n.input, cond)
+			return n
+		}
+	case "join":
+		l, r := n.input.schema(), n.right.schema()
+		if known && l != nil && r != nil {
+			lo, ro := n.joinNames(l, r)
+			if n.how == "semi" || n.how == "anti" {
+				// the output is the left side, unrenamed
+				if subset(l) {
+					n.input = pushFilter(n.input, cond)
+					return n
+				}
+				break
+			}
+			// only columns that keep their name can be pushed to a side
+			var plain []string
+			for c, out := range lo {
+				if c == out {
+					plain = append(plain, c)
+				}
+			}
+			if subset(plain) && (n.how == "inner" || n.how == "left" || n.how == "cross") {
+				n.input = pushFilter(n.input, cond)
+				return n
+			}
+			plain = plain[:0]
+			for c, out := range ro {
+				if c == out {
+					plain = append(plain, c)
+				}
+			}
+			if subset(plain) && (n.how == "inner" || n.how == "right" || n.how == "cross") {
+				n.right = pushFilter(n.right, cond)
+				return n
+			}
+		}
+	}
+	return &lazyNode{op: "filter", cond: cond, input: n}
+}
+
+// fuseColumns merges consecutive Column calls into one node evaluated in a single pass.
+func fuseColumns(n *lazyNode) *lazyNode {
+	if n == nil {
+		return nil
+	}
+	n.input = fuseColumns(n.input)
+	n.right = fuseColumns(n.right)
+	if n.op == "column" && n.input != nil && n.input.op == "column" {
+		in := n.input
+		in.exprs = append(in.exprs, n.exprs...)
+		return in
+	}
+	return n
+}
+
+// pruneColumns pushes the set of needed columns (nil = all) down to the scans,
+// dropping Column expressions whose output is never used.
+func pruneColumns(n *lazyNode, needed map[string]struct{}) {
+	if n == nil {
+		return
+	}
+	addRefs := func(set map[string]struct{}, spec interface{}) map[string]struct{} {
+		if set == nil {
+			return nil
+		}
+		refs, ok := specRefs(spec)
+		if !ok {
+			return nil
+		}
+		out := make(map[string]struct{}, len(set)+len(refs))
+		for c := range set {
+			out[c] = struct{}{}
+		}
+		for c := range refs {
+			out[c] = struct{}{}
+		}
+		return out
+	}
+
+	switch n.op {
+	case "scan_df", "scan_csv", "scan_ndjson", "scan_sqlite":
+		for _, p := range n.preds {
+			needed = addRefs(needed, p)
+		}
+		if needed == nil {
+			return
+		}
+		if n.op == "scan_ndjson" {
+			n.cols = ndjsonColumns(needed)
+			return
+		}
+		schema := n.schema()
+		if schema == nil {
+			return
+		}
+		n.cols = []string{}
+		for _, c := range schema {
+			if _, ok := needed[c]; ok {
+				n.cols = append(n.cols, c)
+			}
+		}
+	case "filter":
+		pruneColumns(n.input, addRefs(needed, n.cond))
+	case "orderby":
+		pruneColumns(n.input, addRefs(needed, ColumnExpr{Type: "col", Name: n.column}))
+	case "select":
+		if needed != nil {
+			kept := n.cols[:0:0]
+			for _, c := range n.cols {
+				if _, ok := needed[c]; ok {
+					kept = append(kept, c)
+				}
+			}
+			n.cols = kept
+		}
+		pruneColumns(n.input, toSet(n.cols))
+	case "drop":
+		pruneColumns(n.input, needed)
+	case "column":
+		var in map[string]struct{}
+		if needed != nil {
+			in = make(map[string]struct{}, len(needed))
+			for c := range needed {
+				in[c] = struct{}{}
+			}
+		}
+		var kept []lazyExpr
+		for i := len(n.exprs) - 1; i >= 0; i-- {
+			e := n.exprs[i]
+			if in != nil {
+				if _, ok := in[e.name]; !ok {
+					continue // output never read
+				}
+				delete(in, e.name)
+			}
+			kept = append([]lazyExpr{e}, kept...)
+			in = addRefs(in, e.spec)
+		}
+		n.exprs = kept
+		pruneColumns(n.input, in)
+	case "join":
+		l, r := n.input.schema(), n.right.schema()
+		if needed == nil || l == nil || r == nil {
+			pruneColumns(n.input, nil)
+			pruneColumns(n.right, nil)
+			return
+		}
+		var predRefs map[string]struct{}
+		if n.on != nil {
+			refs, ok := specRefs(n.on)
+			if !ok {
+				pruneColumns(n.input, nil)
+				pruneColumns(n.right, nil)
+				return
+			}
+			predRefs = refs
+		}
+		lo, ro := n.joinNames(l, r)
+		ln, rn := toSet(n.leftOn), toSet(n.rightOn)
+		existence := n.how == "semi" || n.how == "anti"
+		for _, c := range l {
+			// renamed (colliding) columns are kept so output names stay stable
+			out := lo[c]
+			if existence {
+				out = c
+			}
+			_, need := needed[out]
+			_, pred := predRefs[lo[c]]
+			if need || pred || (!existence && lo[c] != c) {
+				ln[c] = struct{}{}
+			}
+		}
+		for _, c := range r {
+			_, need := needed[ro[c]]
+			_, pred := predRefs[ro[c]]
+			if pred || (!existence && (need || ro[c] != c)) {
+				rn[c] = struct{}{}
+			}
+		}
+		pruneColumns(n.input, ln)
+		pruneColumns(n.right, rn)
+	case "groupby":
+		in := toSet(n.keys)
+		for _, a := range n.aggs {
+			in[a.ColumnName] = struct{}{}
+		}
+		pruneColumns(n.input, in)
+	}
+}
+
+// run executes an optimized plan.
+func (n *lazyNode) run() (*DataFrame, error) {
+	switch n.op {
+	case "scan_df":
+		df := &DataFrame{Cols: append([]string(nil), n.df.Cols...), Data: make(map[string][]interface{}, len(n.df.Cols)), Rows: n.df.Rows, Dates: n.df.datesOf(n.df.Cols)}
+		for k, v := range n.df.Data {
+			df.Data[k] = v
+		}
+		for _, p := range n.preds {
+			df = df.Filter(p)
+		}
+		if n.cols != nil {
+			df = df.Select(n.cols...)
+		}
+		return df, nil
+	case "scan_csv":
+		return readCSV(n.path, n.cols, compilePreds(n.preds), n.csv)
+	case "scan_ndjson":
+		s, err := newNDJSONScanner(n.path, n.cols, compilePreds(n.preds))
+		if err != nil {
+			return nil, fmt.Errorf("ScanNDJSON: %w", err)
+		}
+		defer s.Close()
+		df, _, err := s.read(0)
+		if err != nil {
+			return nil, fmt.Errorf("ScanNDJSON: %w", err)
+		}
+		return df, nil
+	case "scan_sqlite":
+		query := fmt.Sprintf(`SELECT * FROM %q`, n.table)
+		if len(n.cols) > 0 {
+			quoted := make([]string, len(n.cols))
+			for i, c := range n.cols {
+				quoted[i] = quoteIdent(c)
+			}
+			query = fmt.Sprintf(`SELECT %s FROM %q`, strings.Join(quoted, ", "), n.table)
+		}
+		df, err := ReadSqlite(n.path, "", query)
+		if err != nil {
+			return nil, fmt.Errorf("ScanSqlite: %w", err)
+		}
+		if len(n.cols) > 0 {
+			df = df.Select(n.cols...)
+		}
+		for _, p := range n.preds {
+			df = df.Filter(p)
+		}
+		return df, nil
+	case "groupby":
+		if in, ok := n.input.stream(streamBatchRows); ok {
+			defer in.close()
+			return streamGroupBy(in, n.keys, n.aggs)
+		}
+	}
+
+	in, err := n.input.run()
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "filter":
+		return in.Filter(n.cond), nil
+	case "select":
+		return in.Select(n.cols...), nil
+	case "drop":
+		return in.Drop(n.cols...), nil
+	case "column":
+		return in.columns(n.exprs), nil
+	case "groupby":
+		return in.GroupBy(n.keys, n.aggs...), nil
+	case "join":
+		right, err := n.right.run()
+		if err != nil {
+			return nil, err
+		}
+		if n.on != nil {
+			return in.JoinOn(right, n.on, n.how, n.suffixes...), nil
+		}
+		return in.Join(right, n.leftOn, n.rightOn, n.how, n.suffixes...), nil
+	case "orderby":
+		return in.OrderBy(n.column, n.asc), nil
+	}
+	return nil, fmt.Errorf("unknown plan operator %q", n.op)
+}
+
+// columns evaluates fused Column calls in one parallel pass; each expression
+// sees the outputs of the ones before it.
+func (df *DataFrame) columns(exprs []lazyExpr) *DataFrame {
+	for i, e := range exprs {
+		if isWindow(e.spec) {
+			// window functions need whole columns; evaluate around them
+			return df.columns(exprs[:i]).Column(e.name, e.spec).columns(exprs[i+1:])
+		}
+	}
+	if len(exprs) == 0 {
+		return df
+	}
+	if len(exprs) == 1 {
+		return df.Column(exprs[0].name, exprs[0].spec)
+	}
+	compiled := make([]Column, len(exprs))
+	for i, e := range exprs {
+		switch v := e.spec.(type) {
+		case ColumnExpr:
+			compiled[i] = Compile(v)
+		case Column:
+			compiled[i] = v
+		default:
+			fmt.Printf("Column error: unsupported spec type %T\n", v)
+			return df
+		}
+	}
+	inCols := append([]string(nil), df.Cols...)
+	outs := make([][]interface{}, len(exprs))
+	for i := range outs {
+		outs[i] = make([]interface{}, df.Rows)
+	}
+
+	w := runtime.GOMAXPROCS(0)
+	chunk := (df.Rows + w - 1) / w
+	var wg sync.WaitGroup
+	for g := 0; g < w; g++ {
+		start := g * chunk
+		end := start + chunk
+		if start >= df.Rows {
+			break
+		}
+		if end > df.Rows {
+			end = df.Rows
+		}
+		wg.Add(1)
+		go func(s, e int) {
+			defer wg.Done()
+			row := make(map[string]interface{}, len(inCols)+len(exprs))
+			for i := s; i < e; i++ {
+				for _, c := range inCols {
+					if i < len(df.Data[c]) {
+						row[c] = df.Data[c][i]
+					} else {
+						row[c] = nil
+					}
+				}
+				for j, c := range compiled {
+					v := c.Fn(row)
+					outs[j][i] = v
+					row[exprs[j].name] = v
+				}
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	for j, e := range exprs {
+		if _, ok := df.Data[e.name]; !ok {
+			df.Cols = append(df.Cols, e.name)
+		}
+		df.Data[e.name] = outs[j]
+		df.setDate(e.name, compiled[j].dateIn(df))
+	}
+	return df
+}
+
+func compilePreds(preds []interface{}) []Column {
+	out := make([]Column, 0, len(preds))
+	for _, p := range preds {
+		switch v := p.(type) {
+		case ColumnExpr:
+			out = append(out, Compile(v))
+		case Column:
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// csvHeader returns the column names of a CSV file or CSV text (nil on error).
+func csvHeader(input string, opt CSVOptions) []string {
+	s, err := newCSVScanner(input, nil, nil, opt)
+	if err != nil {
+		return nil
+	}
+	defer s.Close()
+	return s.keep
+}
+
+// sqliteColumns returns the column names of a sqlite table (nil on error).
+func sqliteColumns(path, table string) []string {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil
+	}
+	defer db.Close()
+	rows, err := db.Query(fmt.Sprintf(`SELECT * FROM %q LIMIT 0`, table))
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+	cols, _ := rows.Columns()
+	return cols
+}
+
+func (n *lazyNode) explain(b *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	describe := func(spec interface{}) string {
+		if e, ok := spec.(ColumnExpr); ok {
+			j, _ := json.Marshal(e)
+			return string(j)
+		}
+		if c, ok := spec.(Column); ok {
+			return c.Name
+		}
+		if w, ok := spec.(WindowColumn); ok {
+			return w.Name
+		}
+		return fmt.Sprint(spec)
+	}
+	switch n.op {
+	case "scan_df", "scan_csv", "scan_ndjson", "scan_sqlite":
+		src := map[string]string{"scan_df": "DataFrame", "scan_csv": "CSV " + n.path, "scan_ndjson": "NDJSON " + n.path, "scan_sqlite": "Sqlite " + n.path + " " + n.table}[n.op]
+		fmt.Fprintf(b, "%sScan %s", indent, src)
+		if len(n.cols) > 0 {
+			fmt.Fprintf(b, " columns=%v", n.cols)
+		}
+		for _, p := range n.preds {
+			fmt.Fprintf(b, " filter=%s", describe(p))
+		}
+		b.WriteString("\n")
+	case "filter":
+		fmt.Fprintf(b, "%sFilter %s\n", indent, describe(n.cond))
+	case "select":
+		fmt.Fprintf(b, "%sSelect %v\n", indent, n.cols)
+	case "drop":
+		fmt.Fprintf(b, "%sDrop %v\n", indent, n.cols)
+	case "column":
+		names := make([]string, len(n.exprs))
+		for i, e := range n.exprs {
+			names[i] = e.name + "=" + describe(e.spec)
+		}
+		fmt.Fprintf(b, "%sColumn %s\n", indent, strings.Join(names, ", "))
+	case "groupby":
+		fmt.Fprintf(b, "%sGroupBy %v aggs=%v\n", indent, n.keys, outputNames(n.keys, n.aggs)[len(n.keys):])
+	case "join":
+		if n.on != nil {
+			fmt.Fprintf(b, "%sJoin %s on %s\n", indent, n.how, describe(n.on))
+		} else {
+			fmt.Fprintf(b, "%sJoin %s %v=%v\n", indent, n.how, n.leftOn, n.rightOn)
+		}
+	case "orderby":
+		fmt.Fprintf(b, "%sOrderBy %s asc=%t\n", indent, n.column, n.asc)
+	}
+	if n.input != nil {
+		n.input.explain(b, depth+1)
+	}
+	if n.right != nil {
+		n.right.explain(b, depth+1)
+	}
+}
@@ -1,11 +1,12 @@
 package gophers
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"crypto/sha512"
 	"encoding/json"
 	"fmt"
-	"html"
+	"math"
 	"reflect"
 	"strings"
 )
@@ -434,17 +435,35 @@ func cmpNumbers(a, b interface{}, op string) bool {
 // per-row json.Unmarshal done by Evaluate(expr, row). This is the fast path
 // and the single source of truth for expression semantics.
 func Compile(e ColumnExpr) Column {
+	return compileExpr(e, false)
+}
+
+// compileExpr is Compile; with sql set, the comparisons, "and", "or", "not",
+// "isin" and "if" follow SQL's three-valued logic (see sqlLogic).
+func compileExpr(e ColumnExpr, sql bool) Column {
+	if sql {
+		if c, ok := sqlLogic(e); ok {
+			return c
+		}
+	}
 	switch e.Type {
 	case "col":
 		return Col(e.Name)
 	case "lit":
+		if n, ok := e.Value.(json.Number); ok {
+			if i, err := n.Int64(); err == nil {
+				return Lit(i)
+			}
+			f, _ := n.Float64()
+			return Lit(f)
+		}
 		return Lit(e.Value)
 	case "index":
 		// Compile sub-expression, then apply Column.Index(i)
 		var sub ColumnExpr
 		if len(e.Expr) > 0 {
 			_ = json.Unmarshal(e.Expr, &sub)
-			return Compile(sub).Index(e.Index)
+			return compileExpr(sub, sql).Index(e.Index)
 		}
 		// Fallback: index a plain column by name
 		base := e.Name
@@ -458,141 +477,69 @@ func Compile(e ColumnExpr) Column {
 		}
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).IsNull()
+		return compileExpr(sub, sql).IsNull()
 	case "isnotnull":
 		if len(e.Expr) == 0 {
 			return Lit(false)
 		}
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).IsNotNull()
-	case "eq":
-		var l, r ColumnExpr
-		_ = json.Unmarshal(e.Left, &l)
-		_ = json.Unmarshal(e.Right, &r)
-		lc, rc := Compile(l), Compile(r)
-		return Column{
-			Name: fmt.Sprintf("(%s)==(%s)", lc.Name, rc.Name),
-			Fn: func(row map[string]interface{}) interface{} {
-				lv, rv := lc.Fn(row), rc.Fn(row)
-				lf, le := toFloat64(lv)
-				rf, re := toFloat64(rv)
-				if le == nil && re == nil {
-					return lf == rf
-				}
-				ls, _ := toString(lv)
-				rs, _ := toString(rv)
-				return ls == rs
-			},
-		}
-	case "ne":
-		var l, r ColumnExpr
-		_ = json.Unmarshal(e.Left, &l)
-		_ = json.Unmarshal(e.Right, &r)
-		lc, rc := Compile(l), Compile(r)
-		return Column{
-			Name: fmt.Sprintf("(%s)!=(%s)", lc.Name, rc.Name),
-			Fn: func(row map[string]interface{}) interface{} {
-				lv, rv := lc.Fn(row), rc.Fn(row)
-				lf, le := toFloat64(lv)
-				rf, re := toFloat64(rv)
-				if le == nil && re == nil {
-					return lf != rf
-				}
-				ls, _ := toString(lv)
-				rs, _ := toString(rv)
-				return ls != rs
-			},
-		}
-	case "gt":
-		var l, r ColumnExpr
-		_ = json.Unmarshal(e.Left, &l)
-		_ = json.Unmarshal(e.Right, &r)
-		lc, rc := Compile(l), Compile(r)
-		return Column{
-			Name: fmt.Sprintf("(%s)>(%s)", lc.Name, rc.Name),
-			Fn: func(row map[string]interface{}) interface{} {
-				lv, rv := lc.Fn(row), rc.Fn(row)
-				lf, le := toFloat64(lv)
-				rf, re := toFloat64(rv)
-				if le != nil || re != nil {
-					return false
-				} // numeric-only
-				return lf > rf
-			},
-		}
-	case "ge":
-		var l, r ColumnExpr
-		_ = json.Unmarshal(e.Left, &l)
-		_ = json.Unmarshal(e.Right, &r)
-		lc, rc := Compile(l), Compile(r)
-		return Column{
-			Name: fmt.Sprintf("(%s)>=(%s)", lc.Name, rc.Name),
-			Fn: func(row map[string]interface{}) interface{} {
-				lv, rv := lc.Fn(row), rc.Fn(row)
-				lf, le := toFloat64(lv)
-				rf, re := toFloat64(rv)
-				if le != nil || re != nil {
-					return false
-				} // numeric-only
-				return lf >= rf
-			},
-		}
-	case "lt":
-		var l, r ColumnExpr
-		_ = json.Unmarshal(e.Left, &l)
-		_ = json.Unmarshal(e.Right, &r)
-		lc, rc := Compile(l), Compile(r)
-		return Column{
-			Name: fmt.Sprintf("(%s)<(%s)", lc.Name, rc.Name),
-			Fn: func(row map[string]interface{}) interface{} {
-				lv, rv := lc.Fn(row), rc.Fn(row)
-				lf, le := toFloat64(lv)
-				rf, re := toFloat64(rv)
-				if le != nil || re != nil {
-					return false
-				} // numeric-only
-				return lf < rf
-			},
-		}
-	case "le":
+		return compileExpr(sub, sql).IsNotNull()
+	case "eq", "ne", "gt", "ge", "lt", "le":
 		var l, r ColumnExpr
 		_ = json.Unmarshal(e.Left, &l)
 		_ = json.Unmarshal(e.Right, &r)
-		lc, rc := Compile(l), Compile(r)
+		lc, rc := compileExpr(l, sql), compileExpr(r, sql)
+		op := e.Type
 		return Column{
-			Name: fmt.Sprintf("(%s)<=(%s)", lc.Name, rc.Name),
+			Name: fmt.Sprintf("(%s)%s(%s)", lc.Name, compareSymbols[op], rc.Name),
 			Fn: func(row map[string]interface{}) interface{} {
 				lv, rv := lc.Fn(row), rc.Fn(row)
-				lf, le := toFloat64(lv)
-				rf, re := toFloat64(rv)
-				if le != nil || re != nil {
+				if (op == "eq" || op == "ne") && joinOnNull(row, lv, rv) {
 					return false
-				} // numeric-only
-				return lf <= rf
+				}
+				return compareValues(op, lv, rv)
 			},
 		}
 	case "and", "or":
 		var L, R ColumnExpr
 		_ = json.Unmarshal(e.Left, &L)
 		_ = json.Unmarshal(e.Right, &R)
-		lc, rc := Compile(L), Compile(R)
+		lc, rc := compileExpr(L, sql), compileExpr(R, sql)
 		if e.Type == "and" {
 			return And(lc, rc)
 		}
 		return Or(lc, rc)
+	case "not":
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		return Not(compileExpr(sub, sql))
+	case "isin":
+		var sub ColumnExpr
+		var vals []ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		_ = json.Unmarshal(e.Cols, &vals)
+		values := make([]interface{}, len(vals))
+		for i := range vals {
+			values[i] = compileExpr(vals[i], sql)
+		}
+		return compileExpr(sub, sql).IsIn(values...)
+	case "add", "sub", "mul", "div", "mod":
+		// integer literals must stay int64 so int arithmetic stays integral
+		l, r := unmarshalExprNumber(e.Left), unmarshalExprNumber(e.Right)
+		return arithmetic(e.Type, compileExpr(l, sql), compileExpr(r, sql))
 	case "if":
 		var C, T, F ColumnExpr
 		_ = json.Unmarshal(e.Cond, &C)
 		_ = json.Unmarshal(e.True, &T)
 		_ = json.Unmarshal(e.False, &F)
-		return If(Compile(C), Compile(T), Compile(F))
+		return If(compileExpr(C, sql), compileExpr(T, sql), compileExpr(F, sql))
 	case "sha256":
 		var cols []ColumnExpr
 		_ = json.Unmarshal(e.Cols, &cols)
 		cs := make([]Column, len(cols))
 		for i := range cols {
-			cs[i] = Compile(cols[i])
+			cs[i] = compileExpr(cols[i], sql)
 		}
 		return Column{Name: "sha256", Fn: func(row map[string]interface{}) interface{} {
 			parts := make([]string, len(cs))
@@ -606,7 +553,7 @@ func Compile(e ColumnExpr) Column {
 		_ = json.Unmarshal(e.Cols, &cols)
 		cs := make([]Column, len(cols))
 		for i := range cols {
-			cs[i] = Compile(cols[i])
+			cs[i] = compileExpr(cols[i], sql)
 		}
 		return Column{Name: "sha512", Fn: func(row map[string]interface{}) interface{} {
 			parts := make([]string, len(cs))
@@ -623,7 +570,7 @@ func Compile(e ColumnExpr) Column {
 		if len(e.Expr) > 0 {
 			var sub ColumnExpr
 			_ = json.Unmarshal(e.Expr, &sub)
-			return Compile(sub).Split(e.Delimiter)
+			return compileExpr(sub, sql).Split(e.Delimiter)
 		}
 		return Col(e.Col).Split(e.Delimiter)
 	case "concat":
@@ -631,20 +578,20 @@ func Compile(e ColumnExpr) Column {
 		_ = json.Unmarshal(e.Cols, &cols)
 		cs := make([]Column, len(cols))
 		for i := range cols {
-			cs[i] = Compile(cols[i])
+			cs[i] = compileExpr(cols[i], sql)
 		}
 		return Concat(e.Delimiter, cs...)
 	case "cast":
 		// Legacy payload stores sub-expression JSON as string in Col
 		var sub ColumnExpr
 		_ = json.Unmarshal([]byte(e.Col), &sub)
-		return Compile(sub).Cast(e.Datatype)
+		return compileExpr(sub, sql).Cast(e.Datatype)
 	case "arrays_zip":
 		var cols []ColumnExpr
 		_ = json.Unmarshal(e.Cols, &cols)
 		cs := make([]Column, len(cols))
 		for i := range cols {
-			cs[i] = Compile(cols[i])
+			cs[i] = compileExpr(cols[i], sql)
 		}
 		return Column{Name: "arrays_zip", Fn: func(row map[string]interface{}) interface{} {
 			out := make([]interface{}, len(cs))
@@ -658,7 +605,7 @@ func Compile(e ColumnExpr) Column {
 		if len(e.Expr) > 0 {
 			var sub ColumnExpr
 			_ = json.Unmarshal(e.Expr, &sub)
-			return Compile(sub).Keys()
+			return compileExpr(sub, sql).Keys()
 		}
 		return Column{Name: "keys(" + e.Col + ")", Fn: func(row map[string]interface{}) interface{} {
 			val := row[e.Col]
@@ -685,31 +632,31 @@ func Compile(e ColumnExpr) Column {
 		var left, right ColumnExpr
 		_ = json.Unmarshal(e.Left, &left)   // key expr
 		_ = json.Unmarshal(e.Right, &right) // nested map expr
-		return Compile(right).Lookup(Compile(left))
+		return compileExpr(right, sql).Lookup(compileExpr(left, sql))
 	case "lower":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).Lower()
+		return compileExpr(sub, sql).Lower()
 	case "upper":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).Upper()
+		return compileExpr(sub, sql).Upper()
 	case "trim":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		c := Compile(sub)
+		c := compileExpr(sub, sql)
 		return Column{Name: "trim", Fn: func(row map[string]interface{}) interface{} { return strings.TrimSpace(fmt.Sprint(c.Fn(row))) }}
 	case "ltrim":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		c := Compile(sub)
+		c := compileExpr(sub, sql)
 		return Column{Name: "ltrim", Fn: func(row map[string]interface{}) interface{} {
 			return strings.TrimLeft(fmt.Sprint(c.Fn(row)), " \t\r\n")
 		}}
 	case "rtrim":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		c := Compile(sub)
+		c := compileExpr(sub, sql)
 		return Column{Name: "rtrim", Fn: func(row map[string]interface{}) interface{} {
 			return strings.TrimRight(fmt.Sprint(c.Fn(row)), " \t\r\n")
 		}}
@@ -719,79 +666,190 @@ func Compile(e ColumnExpr) Column {
 		// If Index (count) is provided and >0, use Replace; else ReplaceAll
 		oldV, newV := fmt.Sprint(e.Old), fmt.Sprint(e.New)
 		if e.Index > 0 {
-			return Compile(sub).Replace(oldV, newV, e.Index)
+			return compileExpr(sub, sql).Replace(oldV, newV, e.Index)
 		}
-		return Compile(sub).ReplaceAll(oldV, newV)
+		return compileExpr(sub, sql).ReplaceAll(oldV, newV)
 	case "replace_all":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).ReplaceAll(fmt.Sprint(e.Old), fmt.Sprint(e.New))
+		return compileExpr(sub, sql).ReplaceAll(fmt.Sprint(e.Old), fmt.Sprint(e.New))
 	case "contains":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).Contains(fmt.Sprint(e.Substr))
+		return compileExpr(sub, sql).Contains(fmt.Sprint(e.Substr))
 	case "notcontains":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).NotContains(fmt.Sprint(e.Substr))
+		return compileExpr(sub, sql).NotContains(fmt.Sprint(e.Substr))
 	case "icontains":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).IContains(fmt.Sprint(e.Substr))
+		return compileExpr(sub, sql).IContains(fmt.Sprint(e.Substr))
 	case "inotcontains":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).INotContains(fmt.Sprint(e.Substr))
+		return compileExpr(sub, sql).INotContains(fmt.Sprint(e.Substr))
 	case "startswith":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).StartsWith(fmt.Sprint(e.Prefix))
+		return compileExpr(sub, sql).StartsWith(fmt.Sprint(e.Prefix))
 	case "endswith":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).EndsWith(fmt.Sprint(e.Suffix))
+		return compileExpr(sub, sql).EndsWith(fmt.Sprint(e.Suffix))
 	case "like":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).Like(fmt.Sprint(e.Pattern))
+		return compileExpr(sub, sql).Like(fmt.Sprint(e.Pattern))
 	case "notlike":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).NotLike(fmt.Sprint(e.Pattern))
+		return compileExpr(sub, sql).NotLike(fmt.Sprint(e.Pattern))
 	case "rlike":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).RLike(fmt.Sprint(e.Pattern))
+		return compileExpr(sub, sql).RLike(fmt.Sprint(e.Pattern))
 	case "notrlike":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).NotRLike(fmt.Sprint(e.Pattern))
+		return compileExpr(sub, sql).NotRLike(fmt.Sprint(e.Pattern))
 	case "regexp_replace":
 		// pattern in e.Pattern, replacement in e.New (reuse existing field)
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).RegexpReplace(fmt.Sprint(e.Pattern), fmt.Sprint(e.New))
+		return compileExpr(sub, sql).RegexpReplace(fmt.Sprint(e.Pattern), fmt.Sprint(e.New))
 	case "regexp_extract":
 		// pattern in e.Pattern, group index reuses e.Index to avoid types change
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).RegexpExtract(fmt.Sprint(e.Pattern), e.Index)
+		return compileExpr(sub, sql).RegexpExtract(fmt.Sprint(e.Pattern), e.Index)
 	case "length":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		return Compile(sub).Length()
+		return compileExpr(sub, sql).Length()
+	case "pow":
+		l, r := unmarshalExprNumber(e.Left), unmarshalExprNumber(e.Right)
+		return compileExpr(l, sql).Pow(compileExpr(r, sql))
+	case "abs", "floor", "ceil", "sqrt", "log", "exp":
+		sub := unmarshalExprNumber(e.Expr)
+		c := compileExpr(sub, sql)
+		switch e.Type {
+		case "abs":
+			return c.Abs()
+		case "floor":
+			return c.Floor()
+		case "ceil":
+			return c.Ceil()
+		case "sqrt":
+			return c.Sqrt()
+		case "log":
+			return c.Log()
+		}
+		return c.Exp()
+	case "round":
+		// decimal places in e.Index
+		return compileExpr(unmarshalExprNumber(e.Expr), sql).Round(e.Index)
+	case "greatest", "least", "coalesce":
+		var raw []json.RawMessage
+		_ = json.Unmarshal(e.Cols, &raw)
+		cs := make([]Column, len(raw))
+		for i := range raw {
+			cs[i] = compileExpr(unmarshalExprNumber(raw[i]), sql)
+		}
+		switch e.Type {
+		case "greatest":
+			return Greatest(cs...)
+		case "least":
+			return Least(cs...)
+		}
+		return Coalesce(cs...)
 	case "html_unescape":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
-		c := Compile(sub)
-		return Column{Name: "html_unescape", Fn: func(row map[string]interface{}) interface{} { return html.UnescapeString(fmt.Sprint(c.Fn(row))) }}
+		return compileExpr(sub, sql).HtmlUnescape()
+	case "isbetween":
+		// bounds are expressions in Left (lower) and Right (upper)
+		var sub, lo, hi ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		_ = json.Unmarshal(e.Left, &lo)
+		_ = json.Unmarshal(e.Right, &hi)
+		return compileExpr(sub, sql).IsBetween(compileExpr(lo, sql), compileExpr(hi, sql))
+	case "substr":
+		// start in e.Start (e.Index in older payloads), length in e.Length
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		start := e.Index
+		if len(e.Start) > 0 {
+			_ = json.Unmarshal(e.Start, &start)
+		}
+		return compileExpr(sub, sql).Substr(start, e.Length)
+	case "title":
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		return compileExpr(sub, sql).Title()
+	case "initcap":
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		return compileExpr(sub, sql).InitCap()
+	case "lpad", "rpad":
+		// pad string in e.Pad (e.Pattern in older payloads)
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		pad := " "
+		if e.Pad != "" {
+			pad = e.Pad
+		} else if e.Pattern != nil {
+			pad = fmt.Sprint(e.Pattern)
+		}
+		if e.Type == "lpad" {
+			return compileExpr(sub, sql).LPad(e.Length, pad)
+		}
+		return compileExpr(sub, sql).RPad(e.Length, pad)
+	case "reverse":
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		return compileExpr(sub, sql).Reverse()
+	case "repeat":
+		// count in e.Count (e.Index in older payloads)
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		return compileExpr(sub, sql).Repeat(exprCount(e.Count, e.Index))
+	case "translate":
+		// sets in e.From and e.To (e.Old and e.New in older payloads)
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		from, to := e.From, e.To
+		if from == "" && to == "" {
+			if e.Old != nil {
+				from = fmt.Sprint(e.Old)
+			}
+			if e.New != nil {
+				to = fmt.Sprint(e.New)
+			}
+		}
+		return compileExpr(sub, sql).Translate(from, to)
+	case "levenshtein":
+		// other string expression in e.Right
+		var sub, other ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		_ = json.Unmarshal(e.Right, &other)
+		return compileExpr(sub, sql).Levenshtein(compileExpr(other, sql))
+	case "soundex":
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		return compileExpr(sub, sql).Soundex()
+	case "split_part":
+		// part in e.Part (e.Index in older payloads)
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		return compileExpr(sub, sql).SplitPart(e.Delimiter, exprCount(e.Part, e.Index))
 	case "array_join":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
 		if e.New != nil {
-			return Compile(sub).ArrayJoin(e.Delimiter, fmt.Sprint(e.New))
+			return compileExpr(sub, sql).ArrayJoin(e.Delimiter, fmt.Sprint(e.New))
 		}
-		return Compile(sub).ArrayJoin(e.Delimiter)
+		return compileExpr(sub, sql).ArrayJoin(e.Delimiter)
 	case "extract_html":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
@@ -799,7 +857,7 @@ func Compile(e ColumnExpr) Column {
 		if e.Pattern != nil {
 			field = fmt.Sprint(e.Pattern)
 		} // optional
-		return Compile(sub).ExtractHTML(field)
+		return compileExpr(sub, sql).ExtractHTML(field)
 	case "extract_html_top":
 		var sub ColumnExpr
 		_ = json.Unmarshal(e.Expr, &sub)
@@ -807,7 +865,7 @@ func Compile(e ColumnExpr) Column {
 		if e.Pattern != nil {
 			field = fmt.Sprint(e.Pattern)
 		} // optional
-		return Compile(sub).ExtractHTMLTop(field)
+		return compileExpr(sub, sql).ExtractHTMLTop(field)
 	case "current_timestamp":
 		return CurrentTimestamp() // Call the function from functions.go (same package)
 
@@ -818,21 +876,71 @@ func Compile(e ColumnExpr) Column {
 		var endExpr, startExpr ColumnExpr
 		json.Unmarshal(e.End, &endExpr)     // Use e.End (new field)
 		json.Unmarshal(e.Start, &startExpr) // Use e.Start (new field)
-		endCol := Compile(endExpr)
-		startCol := Compile(startExpr)
+		endCol := compileExpr(endExpr, sql)
+		startCol := compileExpr(startExpr, sql)
 		return DateDiff(endCol, startCol, e.Format) // Call DateDiff from functions.go, use e.Format (new field)
 
 	case "to_epoch":
 		var subExpr ColumnExpr
 		json.Unmarshal(e.Expr, &subExpr)
-		col := Compile(subExpr)
+		col := compileExpr(subExpr, sql)
 		return col.ToEpoch(e.Format) // ToEpoch is a method on Column (from functions.go), use e.Format
 
 	case "from_epoch":
 		var subExpr ColumnExpr
 		json.Unmarshal(e.Expr, &subExpr)
-		col := Compile(subExpr)
+		col := compileExpr(subExpr, sql)
 		return col.FromEpoch(e.Format) // FromEpoch is a method on Column (from functions.go), use e.Format
+	case "to_timestamp", "to_date":
+		formats := e.Formats
+		if e.Format != "" {
+			formats = append([]string{e.Format}, formats...)
+		}
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		if e.Type == "to_date" {
+			return compileExpr(sub, sql).ToDate(formats...)
+		}
+		return compileExpr(sub, sql).ToTimestamp(formats...)
+	case "date_trunc":
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		return compileExpr(sub, sql).DateTrunc(e.Unit)
+	case "date_add":
+		// count in e.Count (e.Index in older payloads)
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		return compileExpr(sub, sql).DateAdd(e.Unit, exprCount(e.Count, e.Index))
+	case "year", "quarter", "month", "day", "dayofweek", "weekofyear", "hour":
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		c := compileExpr(sub, sql)
+		switch e.Type {
+		case "year":
+			return c.Year()
+		case "quarter":
+			return c.Quarter()
+		case "month":
+			return c.Month()
+		case "day":
+			return c.Day()
+		case "dayofweek":
+			return c.DayOfWeek()
+		case "weekofyear":
+			return c.WeekOfYear()
+		}
+		return c.Hour()
+	case "from_utc", "to_utc":
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		if e.Type == "from_utc" {
+			return compileExpr(sub, sql).FromUTC(e.Zone)
+		}
+		return compileExpr(sub, sql).ToUTC(e.Zone)
+	case "format_date":
+		var sub ColumnExpr
+		_ = json.Unmarshal(e.Expr, &sub)
+		return compileExpr(sub, sql).FormatDate(e.Format)
 	case "gen":
 		var genData struct {
 			LLM            LLM          `json:"llm"`
@@ -844,7 +952,7 @@ func Compile(e ColumnExpr) Column {
 		// Compile inputs
 		compiledInputs := make([]Column, len(genData.Inputs))
 		for i, inp := range genData.Inputs {
-			compiledInputs[i] = Compile(inp)
+			compiledInputs[i] = compileExpr(inp, sql)
 		}
 		return genData.LLM.Gen(genData.PromptTemplate, compiledInputs...)
 	default:
@@ -853,6 +961,101 @@ func Compile(e ColumnExpr) Column {
 	}
 }
 
+// unmarshalExprNumber decodes a sub-expression keeping numeric literals as
+// json.Number, so "lit" can tell 2 from 2.0.
+func unmarshalExprNumber(raw json.RawMessage) ColumnExpr {
+	var e ColumnExpr
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	_ = dec.Decode(&e)
+	return e
+}
+
+// arithmetic applies +, -, *, / or % ("add", "sub", "mul", "div", "mod") to two
+// numeric columns, parsing numeric strings as numericValue does. Nulls and
+// non-numeric values give nil, as does dividing by zero. Two integers give an
+// int64 except for division, which is always float64, and results that would
+// overflow an int64, which are computed as float64.
+func arithmetic(op string, l, r Column) Column {
+	symbol := map[string]string{"add": "+", "sub": "-", "mul": "*", "div": "/", "mod": "%"}[op]
+	return Column{
+		Name: fmt.Sprintf("(%s %s %s)", l.Name, symbol, r.Name),
+		Fn: func(row map[string]interface{}) interface{} {
+			lv, lok := numericValue(l.Fn(row))
+			rv, rok := numericValue(r.Fn(row))
+			if !lok || !rok {
+				return nil
+			}
+			li, lInt := lv.(int64)
+			ri, rInt := rv.(int64)
+			if lInt && rInt && op != "div" {
+				if op == "mod" && ri == 0 {
+					return nil
+				}
+				if n, ok := intArithmetic(op, li, ri); ok {
+					return n
+				}
+			}
+			lf, _ := asFloat(lv)
+			rf, _ := asFloat(rv)
+			switch op {
+			case "add":
+				return lf + rf
+			case "sub":
+				return lf - rf
+			case "mul":
+				return lf * rf
+			case "div":
+				if rf == 0 {
+					return nil
+				}
+				return lf / rf
+			case "mod":
+				if rf == 0 {
+					return nil
+				}
+				return math.Mod(lf, rf)
+			}
+			return nil
+		},
+	}
+}
+
+// intArithmetic applies op to two int64s, returning false when the result
+// overflows.
+func intArithmetic(op string, a, b int64) (int64, bool) {
+	switch op {
+	case "add":
+		n := a + b
+		return n, (b >= 0) == (n >= a)
+	case "sub":
+		n := a - b
+		return n, (b >= 0) == (n <= a)
+	case "mul":
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		n := a * b
+		return n, n/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)
+	case "mod":
+		return a % b, true
+	}
+	return 0, false
+}
+
+// asInt64 converts Go integer types to int64.
+func asInt64(v interface{}) (int64, bool) {
+	switch x := v.(type) {
+	case int:
+		return int64(x), true
+	case int32:
+		return int64(x), true
+	case int64:
+		return x, true
+	}
+	return 0, false
+}
+
 // tiny wrappers to avoid importing crypto here if you prefer; or reuse directly
 func sha256Sum(s string) [32]byte { return sha256.Sum256([]byte(s)) }
 func sha512Sum(s string) [64]byte { return sha512.Sum512([]byte(s)) }
@@ -872,6 +1075,7 @@ func If(condition Column, fn1 Column, fn2 Column) Column {
 			}
 			return fn2.Fn(row)
 		},
+		date: allDates(fn1, fn2),
 	}
 }
 
@@ -881,51 +1085,43 @@ func (c Column) IsNull() Column {
 	return Column{
 		Name: c.Name + "_isnull",
 		Fn: func(row map[string]interface{}) interface{} {
-			val := c.Fn(row)
-			if val == nil {
-				return true
-			}
-			switch v := val.(type) {
-			case string:
-				return v == "" || strings.ToLower(v) == "null"
-			case *string:
-				return v == nil || *v == "" || strings.ToLower(*v) == "null"
-			default:
-				return false
-			}
+			return isNullValue(c.Fn(row))
 		},
 	}
 }
 
+// isNullValue reports whether v is null: nil, or a string that is empty or "null".
+func isNullValue(v interface{}) bool {
+	switch x := v.(type) {
+	case nil:
+		return true
+	case string:
+		return x == "" || strings.ToLower(x) == "null"
+	case *string:
+		return x == nil || *x == "" || strings.ToLower(*x) == "null"
+	}
+	return false
+}
+
 // IsNotNull returns a new Column that, when applied to a row,
 // returns true if the original column value is not nil, not an empty string, and not "null".
 func (c Column) IsNotNull() Column {
 	return Column{
 		Name: c.Name + "_isnotnull",
 		Fn: func(row map[string]interface{}) interface{} {
-			val := c.Fn(row)
-			if val == nil {
-				return false
-			}
-			switch v := val.(type) {
-			case string:
-				return !(v == "" || strings.ToLower(v) == "null")
-			case *string:
-				return !(v == nil || *v == "" || strings.ToLower(*v) == "null")
-			default:
-				return true
-			}
+			return !isNullValue(c.Fn(row))
 		},
 	}
 }
 
 // Gt returns a Column that compares the numeric value at col with the given threshold.
-// The threshold can be of any numeric type (int, float32, float64, etc.).
+// The threshold can be of any numeric type (int, float32, float64, etc.) or a Column.
 func (c Column) Gt(threshold interface{}) Column {
 	return Column{
 		Name: c.Name + "_gt",
 		Fn: func(row map[string]interface{}) interface{} {
 			val := c.Fn(row)
+			threshold := operandValue(threshold, row)
 			fVal, err := toFloat64(val)
 			if err != nil {
 				return false
@@ -940,12 +1136,13 @@ func (c Column) Gt(threshold interface{}) Column {
 }
 
 // Ge returns a Column that compares the numeric value at col with the given threshold.
-// The threshold can be of any numeric type (int, float32, float64, etc.).
+// The threshold can be of any numeric type (int, float32, float64, etc.) or a Column.
 func (c Column) Ge(threshold interface{}) Column {
 	return Column{
 		Name: c.Name + "_ge",
 		Fn: func(row map[string]interface{}) interface{} {
 			val := c.Fn(row)
+			threshold := operandValue(threshold, row)
 			fVal, err := toFloat64(val)
 			if err != nil {
 				return false
@@ -960,12 +1157,13 @@ func (c Column) Ge(threshold interface{}) Column {
 }
 
 // Lt returns a Column that compares the numeric value at col with the given threshold.
-// The threshold can be of any numeric type (int, float32, float64, etc.).
+// The threshold can be of any numeric type (int, float32, float64, etc.) or a Column.
 func (c Column) Lt(threshold interface{}) Column {
 	return Column{
 		Name: c.Name + "_lt",
 		Fn: func(row map[string]interface{}) interface{} {
 			val := c.Fn(row)
+			threshold := operandValue(threshold, row)
 			fVal, err := toFloat64(val)
 			if err != nil {
 				return false
@@ -980,12 +1178,13 @@ func (c Column) Lt(threshold interface{}) Column {
 }
 
 // Le returns a Column that compares the numeric value at col with the given threshold.
-// The threshold can be of any numeric type (int, float32, float64, etc.).
+// The threshold can be of any numeric type (int, float32, float64, etc.) or a Column.
 func (c Column) Le(threshold interface{}) Column {
 	return Column{
 		Name: c.Name + "_le",
 		Fn: func(row map[string]interface{}) interface{} {
 			val := c.Fn(row)
+			threshold := operandValue(threshold, row)
 			fVal, err := toFloat64(val)
 			if err != nil {
 				return false
@@ -1000,12 +1199,17 @@ func (c Column) Le(threshold interface{}) Column {
 }
 
 // Eq returns a Column that, when evaluated on a row,
-// checks if the value from col is equal (same type and value) to threshold.
+// checks if the value from col is equal (same type and value) to threshold,
+// which may also be a Column.
 func (c Column) Eq(threshold interface{}) Column {
 	return Column{
 		Name: c.Name + "_eq",
 		Fn: func(row map[string]interface{}) interface{} {
 			val := c.Fn(row)
+			threshold := operandValue(threshold, row)
+			if joinOnNull(row, val, threshold) {
+				return false
+			}
 			// If either is nil, return equality directly.
 			if val == nil || threshold == nil {
 				return val == threshold
@@ -1021,12 +1225,17 @@ func (c Column) Eq(threshold interface{}) Column {
 }
 
 // Ne returns a Column that, when evaluated on a row,
-// checks if the value from col is NOT equal (diff type or value) to threshold.
+// checks if the value from col is NOT equal (diff type or value) to threshold,
+// which may also be a Column.
 func (c Column) Ne(threshold interface{}) Column {
 	return Column{
 		Name: c.Name + "_ne",
 		Fn: func(row map[string]interface{}) interface{} {
 			val := c.Fn(row)
+			threshold := operandValue(threshold, row)
+			if joinOnNull(row, val, threshold) {
+				return false
+			}
 			// If either is nil, return equality directly.
 			if val == nil || threshold == nil {
 				return val != threshold
@@ -1041,6 +1250,76 @@ func (c Column) Ne(threshold interface{}) Column {
 	}
 }
 
+// exprCount returns an integer ColumnExpr field, falling back to the legacy
+// Index field that older payloads used for it.
+func exprCount(v, legacy int) int {
+	if v != 0 {
+		return v
+	}
+	return legacy
+}
+
+// compareSymbols are the operators of the Compile comparisons, for column names.
+var compareSymbols = map[string]string{"eq": "==", "ne": "!=", "gt": ">", "ge": ">=", "lt": "<", "le": "<="}
+
+// compareValues applies a Compile comparison ("eq", "ne", "gt", "ge", "lt" or
+// "le") to two values. eq and ne compare numbers numerically, then booleans,
+// then values as strings; the ordering comparisons are numeric only and false
+// for anything else.
+func compareValues(op string, lv, rv interface{}) bool {
+	lf, le := toFloat64(lv)
+	rf, re := toFloat64(rv)
+	switch op {
+	case "eq", "ne":
+		var eq bool
+		if le == nil && re == nil {
+			eq = lf == rf
+		} else if lb, ok := lv.(bool); ok {
+			rb, ok := rv.(bool)
+			eq = ok && lb == rb
+		} else {
+			ls, _ := toString(lv)
+			rs, _ := toString(rv)
+			eq = ls == rs
+		}
+		return eq == (op == "eq")
+	}
+	if le != nil || re != nil {
+		return false
+	}
+	switch op {
+	case "gt":
+		return lf > rf
+	case "ge":
+		return lf >= rf
+	case "lt":
+		return lf < rf
+	}
+	return lf <= rf
+}
+
+// joinOnRow is set in the rows JoinOn evaluates its predicate on. There Eq and
+// Ne are false when either side is null, so null keys never match, as in Join.
+const joinOnRow = "\x00joinon"
+
+// joinOnNull reports whether row is a JoinOn row and a or b is null.
+func joinOnNull(row map[string]interface{}, a, b interface{}) bool {
+	if a != nil && b != nil {
+		return false
+	}
+	_, ok := row[joinOnRow]
+	return ok
+}
+
+// operandValue evaluates a comparison operand: a Column is evaluated against
+// the row (e.g. Col("start").Le(Col("ts"))), anything else is a literal.
+func operandValue(v interface{}, row map[string]interface{}) interface{} {
+	if c, ok := v.(Column); ok {
+		return c.Fn(row)
+	}
+	return v
+}
+
 // Or returns true if any of the provided conditions is true.
 func Or(conds ...Column) Column {
 	return Column{
@@ -1057,6 +1336,20 @@ func Or(conds ...Column) Column {
 	}
 }
 
+// Not negates a boolean condition; values that are not booleans give nil.
+func Not(cond Column) Column {
+	return Column{
+		Name: "not(" + cond.Name + ")",
+		Fn: func(row map[string]interface{}) interface{} {
+			v, ok := cond.Fn(row).(bool)
+			if !ok {
+				return nil
+			}
+			return !v
+		},
+	}
+}
+
 // And returns true if all provided conditions are true.
 func And(conds ...Column) Column {
 	return Column{
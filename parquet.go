@@ -0,0 +1,441 @@
+package gophers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress"
+	"github.com/parquet-go/parquet-go/deprecated"
+)
+
+// parquetColumnsKey is the key/value metadata entry used to remember the
+// DataFrame column order, since parquet groups are stored sorted by name.
+const parquetColumnsKey = "gophers.columns"
+
+const (
+	pqLeaf = iota
+	pqStruct
+	pqList
+	pqMap
+)
+
+// pqNode mirrors a parquet schema node along with the repetition and
+// definition levels needed to shred and assemble nested values.
+type pqNode struct {
+	name     string
+	kind     int
+	def      int // definition level at which the node is non-null
+	rep      int // list/map: repetition level of the repeated child
+	repDef   int // list/map: definition level at which an element exists
+	children []*pqNode
+	leaves   []int // leaf column indexes beneath this node
+	typ      parquet.Type
+}
+
+// parquetCodec maps a compression name to a parquet codec.
+func parquetCodec(name string) (compress.Codec, error) {
+	switch strings.ToLower(name) {
+	case "", "snappy":
+		return &parquet.Snappy, nil
+	case "zstd":
+		return &parquet.Zstd, nil
+	case "gzip":
+		return &parquet.Gzip, nil
+	case "none", "uncompressed":
+		return &parquet.Uncompressed, nil
+	default:
+		return nil, fmt.Errorf("unsupported parquet compression %q", name)
+	}
+}
+
+// parquetNodeOf builds a parquet schema node for a column from its values,
+// using the same type inference as Schema().
+func parquetNodeOf(values []interface{}) parquet.Node {
+	t, _ := inferColumnType(values)
+	switch {
+	case t == "int":
+		return parquet.Optional(parquet.Int(64))
+	case t == "float":
+		return parquet.Optional(parquet.Leaf(parquet.DoubleType))
+	case t == "boolean":
+		return parquet.Optional(parquet.Leaf(parquet.BooleanType))
+	case strings.HasPrefix(t, "array<"):
+		var elems []interface{}
+		for _, v := range values {
+			switch arr := v.(type) {
+			case []interface{}:
+				elems = append(elems, arr...)
+			case []string:
+				for _, s := range arr {
+					elems = append(elems, s)
+				}
+			}
+		}
+		return parquet.Optional(parquet.List(parquetNodeOf(elems)))
+	case strings.HasPrefix(t, "map<"):
+		fields := make(map[string][]interface{})
+		for _, v := range values {
+			for k, fv := range toStringMap(v) {
+				fields[k] = append(fields[k], fv)
+			}
+		}
+		if len(fields) == 0 {
+			// an empty group cannot be read back; store the maps as JSON text
+			return parquet.Optional(parquet.String())
+		}
+		group := parquet.Group{}
+		for k, fv := range fields {
+			group[k] = parquetNodeOf(fv)
+		}
+		return parquet.Optional(group)
+	case t == "timestamp" || (t == "any" && allTimes(values)):
+		return parquet.Optional(parquet.Timestamp(parquet.Microsecond))
+	default:
+		return parquet.Optional(parquet.String())
+	}
+}
+
+// allTimes reports whether every non-nil value is a time.Time.
+func allTimes(values []interface{}) bool {
+	seen := false
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if _, ok := v.(time.Time); !ok {
+			return false
+		}
+		seen = true
+	}
+	return seen
+}
+
+// toStringMap returns v as a map[string]interface{} (nil if it is not a map).
+func toStringMap(v interface{}) map[string]interface{} {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, vv := range m {
+			out[fmt.Sprint(k)] = vv
+		}
+		return out
+	}
+	return nil
+}
+
+// buildPqNode walks a parquet schema node and computes levels and leaf indexes.
+// next is the index of the next leaf column in depth-first order.
+func buildPqNode(name string, node parquet.Node, def, rep int, next *int) *pqNode {
+	if node.Optional() {
+		def++
+	}
+	if node.Repeated() {
+		// a bare repeated field is a list whose elements are the field itself
+		elem := buildPqNode(name, parquet.Required(node), def+1, rep+1, next)
+		return &pqNode{name: name, kind: pqList, def: def, rep: rep + 1, repDef: def + 1, children: []*pqNode{elem}, leaves: elem.leaves}
+	}
+	if node.Leaf() {
+		n := &pqNode{name: name, kind: pqLeaf, def: def, leaves: []int{*next}, typ: node.Type()}
+		*next++
+		return n
+	}
+	lt := node.Type().LogicalType()
+	fields := node.Fields()
+	if lt != nil && (lt.List != nil || lt.Map != nil) && len(fields) == 1 && fields[0].Repeated() {
+		repeated := fields[0]
+		n := &pqNode{name: name, kind: pqList, def: def, rep: rep + 1, repDef: def + 1}
+		inner := repeated.Fields()
+		switch {
+		case lt.Map != nil && len(inner) == 2:
+			n.kind = pqMap
+			for _, f := range inner {
+				n.children = append(n.children, buildPqNode(f.Name(), f, def+1, rep+1, next))
+			}
+		case !repeated.Leaf() && len(inner) == 1 && repeated.Name() != "array" && !strings.HasSuffix(repeated.Name(), "_tuple"):
+			n.children = []*pqNode{buildPqNode(inner[0].Name(), inner[0], def+1, rep+1, next)}
+		default:
+			// legacy two-level list: the repeated node is the element
+			n.children = []*pqNode{buildPqNode(repeated.Name(), parquet.Required(repeated), def+1, rep+1, next)}
+		}
+		for _, c := range n.children {
+			n.leaves = append(n.leaves, c.leaves...)
+		}
+		return n
+	}
+	n := &pqNode{name: name, kind: pqStruct, def: def}
+	for _, f := range fields {
+		c := buildPqNode(f.Name(), f, def, rep, next)
+		n.children = append(n.children, c)
+		n.leaves = append(n.leaves, c.leaves...)
+	}
+	return n
+}
+
+// parquetRoot returns the top-level fields of a schema as pqNodes.
+func parquetRoot(schema *parquet.Schema) []*pqNode {
+	next := 0
+	var nodes []*pqNode
+	for _, f := range schema.Fields() {
+		nodes = append(nodes, buildPqNode(f.Name(), f, 0, 0, &next))
+	}
+	return nodes
+}
+
+// shred appends the leaf values for v (at repetition level r and current
+// definition level d) to out, indexed by leaf column.
+func (n *pqNode) shred(v interface{}, r, d int, out [][]parquet.Value) {
+	if v == nil && n.def > d {
+		for _, leaf := range n.leaves {
+			out[leaf] = append(out[leaf], parquet.Value{}.Level(r, d, leaf))
+		}
+		return
+	}
+	switch n.kind {
+	case pqLeaf:
+		leaf := n.leaves[0]
+		pv := parquetValue(n.typ, v)
+		if pv.IsNull() {
+			// unconvertible values are written as nulls
+			out[leaf] = append(out[leaf], pv.Level(r, d, leaf))
+			return
+		}
+		out[leaf] = append(out[leaf], pv.Level(r, n.def, leaf))
+	case pqStruct:
+		m := toStringMap(v)
+		for _, c := range n.children {
+			c.shred(m[c.name], r, n.def, out)
+		}
+	case pqList, pqMap:
+		var elems []interface{}
+		var keys []string
+		switch arr := v.(type) {
+		case nil:
+		case []interface{}:
+			elems = arr
+		case []string:
+			for _, s := range arr {
+				elems = append(elems, s)
+			}
+		default:
+			if m := toStringMap(v); m != nil {
+				for k := range m {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					elems = append(elems, m[k])
+				}
+			} else {
+				elems = []interface{}{v}
+			}
+		}
+		if len(elems) == 0 {
+			for _, leaf := range n.leaves {
+				out[leaf] = append(out[leaf], parquet.Value{}.Level(r, n.def, leaf))
+			}
+			return
+		}
+		for i, e := range elems {
+			rr := r
+			if i > 0 {
+				rr = n.rep
+			}
+			if n.kind == pqMap {
+				n.children[0].shred(keys[i], rr, n.repDef, out)
+				n.children[1].shred(e, rr, n.repDef, out)
+			} else {
+				n.children[0].shred(e, rr, n.repDef, out)
+			}
+		}
+	}
+}
+
+// assemble rebuilds a value from the leaf values of a single instance.
+func (n *pqNode) assemble(vals map[int][]parquet.Value) interface{} {
+	first := vals[n.leaves[0]]
+	if len(first) == 0 || first[0].DefinitionLevel() < n.def {
+		return nil
+	}
+	switch n.kind {
+	case pqLeaf:
+		return goValue(n.typ, first[0])
+	case pqStruct:
+		m := make(map[string]interface{}, len(n.children))
+		for _, c := range n.children {
+			m[c.name] = c.assemble(vals)
+		}
+		return m
+	}
+	if first[0].DefinitionLevel() < n.repDef {
+		if n.kind == pqMap {
+			return map[string]interface{}{}
+		}
+		return []interface{}{}
+	}
+	// split every leaf slice into one segment per element
+	segments := make(map[int][][]parquet.Value, len(n.leaves))
+	for _, leaf := range n.leaves {
+		vs := vals[leaf]
+		start := 0
+		for i := 1; i < len(vs); i++ {
+			if vs[i].RepetitionLevel() <= n.rep {
+				segments[leaf] = append(segments[leaf], vs[start:i])
+				start = i
+			}
+		}
+		segments[leaf] = append(segments[leaf], vs[start:])
+	}
+	count := len(segments[n.leaves[0]])
+	elem := func(k int) map[int][]parquet.Value {
+		sub := make(map[int][]parquet.Value, len(n.leaves))
+		for _, leaf := range n.leaves {
+			if k < len(segments[leaf]) {
+				sub[leaf] = segments[leaf][k]
+			}
+		}
+		return sub
+	}
+	if n.kind == pqMap {
+		m := make(map[string]interface{}, count)
+		for k := 0; k < count; k++ {
+			sub := elem(k)
+			key := n.children[0].assemble(sub)
+			m[fmt.Sprint(key)] = n.children[1].assemble(sub)
+		}
+		return m
+	}
+	list := make([]interface{}, count)
+	for k := 0; k < count; k++ {
+		list[k] = n.children[0].assemble(elem(k))
+	}
+	return list
+}
+
+// parquetValue converts a Go value into a parquet value of the leaf type.
+func parquetValue(t parquet.Type, v interface{}) parquet.Value {
+	switch t.Kind() {
+	case parquet.Boolean:
+		b, ok := v.(bool)
+		if !ok {
+			b = strings.EqualFold(fmt.Sprint(v), "true")
+		}
+		return parquet.BooleanValue(b)
+	case parquet.Int32, parquet.Int64:
+		if tm, ok := v.(time.Time); ok {
+			if t.Kind() == parquet.Int32 {
+				// DATE: days since the Unix epoch
+				y, m, d := tm.Date()
+				return parquet.Int32Value(int32(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400))
+			}
+			return parquet.Int64Value(tm.UnixMicro())
+		}
+		i, err := toInt(v)
+		if err != nil {
+			return parquet.Value{}
+		}
+		if t.Kind() == parquet.Int32 {
+			return parquet.Int32Value(int32(i))
+		}
+		return parquet.Int64Value(int64(i))
+	case parquet.Float, parquet.Double:
+		f, err := toFloat64(v)
+		if err != nil {
+			return parquet.Value{}
+		}
+		if t.Kind() == parquet.Float {
+			return parquet.FloatValue(float32(f))
+		}
+		return parquet.DoubleValue(f)
+	default:
+		switch x := v.(type) {
+		case string:
+			return parquet.ByteArrayValue([]byte(x))
+		case []interface{}, map[string]interface{}:
+			b, _ := json.Marshal(x)
+			return parquet.ByteArrayValue(b)
+		case map[interface{}]interface{}:
+			b, _ := json.Marshal(toStringMap(x))
+			return parquet.ByteArrayValue(b)
+		default:
+			return parquet.ByteArrayValue([]byte(fastToString(v)))
+		}
+	}
+}
+
+// goValue converts a parquet value into the Go type used by DataFrame columns.
+func goValue(t parquet.Type, v parquet.Value) interface{} {
+	if v.IsNull() {
+		return nil
+	}
+	lt := t.LogicalType()
+	switch v.Kind() {
+	case parquet.Boolean:
+		return v.Boolean()
+	case parquet.Int32:
+		if lt != nil && lt.Date != nil {
+			return time.Unix(int64(v.Int32())*86400, 0).UTC()
+		}
+		return int(v.Int32())
+	case parquet.Int64:
+		if lt != nil && lt.Timestamp != nil {
+			switch {
+			case lt.Timestamp.Unit.Millis != nil:
+				return time.UnixMilli(v.Int64()).UTC()
+			case lt.Timestamp.Unit.Nanos != nil:
+				return time.Unix(0, v.Int64()).UTC()
+			default:
+				return time.UnixMicro(v.Int64()).UTC()
+			}
+		}
+		return int(v.Int64())
+	case parquet.Int96:
+		return int96Time(v.Int96())
+	case parquet.Float:
+		return float64(v.Float())
+	case parquet.Double:
+		return v.Double()
+	default:
+		return string(v.ByteArray())
+	}
+}
+
+// int96Time decodes the legacy INT96 timestamp (nanoseconds of day + julian day).
+func int96Time(i deprecated.Int96) time.Time {
+	nanos := int64(i[1])<<32 | int64(i[0])
+	days := int64(i[2]) - 2440588 // julian day of the unix epoch
+	return time.Unix(days*86400, nanos).UTC()
+}
+
+// readParquetRowGroup assembles the rows of a single row group into columns.
+func readParquetRowGroup(rg parquet.RowGroup, nodes []*pqNode) (map[string][]interface{}, error) {
+	cols := make(map[string][]interface{}, len(nodes))
+	rows := rg.Rows()
+	defer rows.Close()
+	buf := make([]parquet.Row, 256)
+	for {
+		n, err := rows.ReadRows(buf)
+		for _, row := range buf[:n] {
+			vals := make(map[int][]parquet.Value)
+			for _, v := range row {
+				vals[v.Column()] = append(vals[v.Column()], v)
+			}
+			for _, node := range nodes {
+				cols[node.name] = append(cols[node.name], node.assemble(vals))
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cols, nil
+}
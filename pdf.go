@@ -0,0 +1,904 @@
+package gophers
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A4 portrait page size and margin, in points.
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+	pdfContent    = pdfPageWidth - 2*pdfMargin
+)
+
+// SavePDF writes the report to filename as an A4 PDF.
+func (report *Report) SavePDF(filename string) error {
+	b, err := report.PDF()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, b, 0644)
+}
+
+// PDF lays the report out as an A4 PDF document from the blocks recorded by
+// the Add methods (see Report.Layout). Each page starts on a new sheet with
+// its title, followed by its headings, text, bullet lists, tables
+// (AddDataframe, DataTable charts and <table> markup in AddHTML) and charts,
+// drawn as vectors from their SVG rendering. Filters are left out. Pages are
+// written in the order they were added.
+func (report *Report) PDF() ([]byte, error) {
+	if len(report.Layout) == 0 && len(report.Pageshtml) > 0 {
+		return nil, fmt.Errorf("SavePDF: report has pages but no recorded blocks; build it with the Add methods")
+	}
+	w := newPDFWriter()
+	for i, page := range report.Layout {
+		if i > 0 {
+			w.newPage()
+		}
+		w.paragraph(page.Name, 28, true, "#111827", "left", 0)
+		w.y += 12
+		for j, block := range page.Blocks {
+			if err := w.block(block); err != nil {
+				return nil, fmt.Errorf("SavePDF: page %q block %d: %w", page.Name, j+1, err)
+			}
+		}
+	}
+	return w.bytes(report.Title)
+}
+
+// pdfHeadingSizes maps AddHeading sizes 1 (largest) to 10 to font sizes.
+var pdfHeadingSizes = map[int]float64{1: 26, 2: 22, 3: 20, 4: 18, 5: 16, 6: 14, 7: 13, 8: 12, 9: 11, 10: 10}
+
+// block lays out one recorded page block.
+func (w *pdfWriter) block(b ReportBlock) error {
+	switch b.Type {
+	case "heading":
+		size, ok := pdfHeadingSizes[b.Size]
+		if !ok {
+			size = 12
+		}
+		w.y += 6
+		w.paragraph(strings.Join(pdfPlainText(b.Text), " "), size, true, "#111827", "left", 0)
+		w.y += 6
+	case "text":
+		w.paragraph(strings.Join(pdfPlainText(b.Text), " "), 11, false, "#1f2937", "left", 0)
+		w.y += 8
+	case "subtext":
+		w.paragraph(strings.Join(pdfPlainText(b.Text), " "), 9, false, "#6b7280", "center", 0)
+		w.y += 10
+	case "bullets":
+		for _, item := range b.Items {
+			lines := pdfWrap(strings.Join(pdfPlainText(item), " "), 11, false, pdfContent-28)
+			for i, line := range lines {
+				w.ensure(11 * 1.4)
+				if i == 0 {
+					w.text(pdfMargin+14, w.baseline(11), 11, false, "#1f2937", "•", 0)
+				}
+				w.text(pdfMargin+28, w.baseline(11), 11, false, "#1f2937", line, 0)
+				w.y += 11 * 1.4
+			}
+		}
+		w.y += 8
+	case "html":
+		w.html(b.Text)
+	case "dataframe":
+		if b.Data != nil {
+			w.dataFrame(b.Data, b.Data.Cols)
+		}
+	case "chart":
+		if b.Chart == nil {
+			return nil
+		}
+		if b.Chart.Jspreid == "" { // DataTable
+			if spec := b.Chart.Spec; spec != nil && spec.Kind == "datatable" && spec.Source != nil {
+				cols := spec.Cols
+				if len(cols) == 0 {
+					cols = spec.Source.Cols
+				}
+				w.dataFrame(spec.Source, cols)
+				return nil
+			}
+			w.html(b.Chart.Htmlpostid)
+			return nil
+		}
+		config, err := b.Chart.config()
+		if err != nil {
+			return err
+		}
+		return w.svg(renderSVG(config))
+	}
+	return nil
+}
+
+// dataFrame lays out the given columns of df as a table.
+func (w *pdfWriter) dataFrame(df *DataFrame, cols []string) {
+	rows := make([][]string, df.Rows)
+	for i := range rows {
+		rows[i] = make([]string, len(cols))
+		for j, col := range cols {
+			if vals := df.Data[col]; i < len(vals) {
+				rows[i][j] = pdfCellText(vals[i], df.Dates[col])
+			}
+		}
+	}
+	w.table(cols, rows)
+}
+
+var (
+	pdfTableTag  = regexp.MustCompile(`(?is)<table[^>]*>.*?</table>`)
+	pdfRowTag    = regexp.MustCompile(`(?is)<tr[^>]*>(.*?)</tr>`)
+	pdfCellTag   = regexp.MustCompile(`(?is)<t([hd])[^>]*>(.*?)</t[hd]>`)
+	pdfDropTags  = regexp.MustCompile(`(?is)<(script|style|head|title)[^>]*>.*?</(script|style|head|title)>`)
+	pdfBreakTags = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|li|tr|ul|ol|table|section|article|header|footer|blockquote|pre)>`)
+	pdfAnyTag    = regexp.MustCompile(`(?s)<[^>]*>`)
+	pdfSpaces    = regexp.MustCompile(`\s+`)
+)
+
+// html lays out the text of HTML markup with its <table> elements drawn as tables.
+func (w *pdfWriter) html(content string) {
+	content = pdfDropTags.ReplaceAllString(content, "")
+	last := 0
+	for _, loc := range pdfTableTag.FindAllStringIndex(content, -1) {
+		w.htmlText(content[last:loc[0]])
+		var header []string
+		var rows [][]string
+		for _, tr := range pdfRowTag.FindAllStringSubmatch(content[loc[0]:loc[1]], -1) {
+			var row []string
+			isHeader := true
+			for _, td := range pdfCellTag.FindAllStringSubmatch(tr[1], -1) {
+				row = append(row, strings.Join(pdfPlainText(td[2]), " "))
+				isHeader = isHeader && strings.EqualFold(td[1], "h")
+			}
+			if header == nil && isHeader && len(rows) == 0 {
+				header = row
+				continue
+			}
+			rows = append(rows, row)
+		}
+		w.table(header, rows)
+		last = loc[1]
+	}
+	w.htmlText(content[last:])
+}
+
+// htmlText lays out the text of HTML markup, one paragraph per block element.
+func (w *pdfWriter) htmlText(content string) {
+	lines := pdfPlainText(content)
+	for _, line := range lines {
+		w.paragraph(line, 11, false, "#1f2937", "left", 0)
+		w.y += 4
+	}
+	if len(lines) > 0 {
+		w.y += 4
+	}
+}
+
+// pdfPlainText strips the tags from HTML and returns its non-empty lines of text.
+func pdfPlainText(s string) []string {
+	s = pdfDropTags.ReplaceAllString(s, "")
+	s = pdfBreakTags.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(pdfAnyTag.ReplaceAllString(s, " "))
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(pdfSpaces.ReplaceAllString(line, " ")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// pdfCellText formats a DataFrame cell for a table; date is set for a date
+// column (see DataFrame.Dates).
+func pdfCellText(v interface{}, date bool) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return displayTime(t, date)
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(t)
+		return string(b)
+	}
+	return fastToString(v)
+}
+
+// pdfWriter lays out A4 pages with the standard Helvetica fonts. Layout
+// positions (y) are points from the top of the page; drawing calls take PDF
+// coordinates, with the origin at the bottom-left corner.
+type pdfWriter struct {
+	pages  []*bytes.Buffer
+	page   *bytes.Buffer
+	y      float64           // layout cursor
+	alphas map[string]string // fill opacity -> ExtGState name
+}
+
+func newPDFWriter() *pdfWriter {
+	w := &pdfWriter{alphas: map[string]string{}}
+	w.newPage()
+	return w
+}
+
+func (w *pdfWriter) newPage() {
+	w.page = &bytes.Buffer{}
+	w.pages = append(w.pages, w.page)
+	w.y = pdfMargin
+}
+
+// ensure starts a new page unless h more points fit on the current one.
+func (w *pdfWriter) ensure(h float64) {
+	if w.y+h > pdfPageHeight-pdfMargin && w.y > pdfMargin {
+		w.newPage()
+	}
+}
+
+// baseline returns the PDF y of a text line of the given size at the cursor.
+func (w *pdfWriter) baseline(size float64) float64 {
+	return pdfPageHeight - w.y - size
+}
+
+func (w *pdfWriter) op(format string, args ...interface{}) {
+	fmt.Fprintf(w.page, format, args...)
+	w.page.WriteByte('\n')
+}
+
+// text draws s with its baseline starting at x, y, rotated by angle degrees
+// counterclockwise.
+func (w *pdfWriter) text(x, y, size float64, bold bool, color string, s string, angle float64) {
+	rgb, ok := pdfColor(color)
+	if !ok || s == "" {
+		return
+	}
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	w.op("BT /%s %.2f Tf %.3f %.3f %.3f rg %.4f %.4f %.4f %.4f %.2f %.2f Tm (%s) Tj ET",
+		font, size, rgb[0], rgb[1], rgb[2], cos, sin, -sin, cos, x, y, pdfString(s))
+}
+
+// fillRect fills a rectangle given in layout coordinates.
+func (w *pdfWriter) fillRect(x, top, width, height float64, color string) {
+	rgb, _ := pdfColor(color)
+	w.op("%.3f %.3f %.3f rg %.2f %.2f %.2f %.2f re f", rgb[0], rgb[1], rgb[2], x, pdfPageHeight-top-height, width, height)
+}
+
+// hline strokes a horizontal line at layout position top.
+func (w *pdfWriter) hline(x1, x2, top float64, color string, width float64) {
+	rgb, _ := pdfColor(color)
+	w.op("%.3f %.3f %.3f RG %.2f w %.2f %.2f m %.2f %.2f l S", rgb[0], rgb[1], rgb[2], width, x1, pdfPageHeight-top, x2, pdfPageHeight-top)
+}
+
+// paragraph wraps s to the content width and lays it out line by line.
+func (w *pdfWriter) paragraph(s string, size float64, bold bool, color string, align string, indent float64) {
+	lh := size * 1.35
+	for _, line := range pdfWrap(s, size, bold, pdfContent-indent) {
+		w.ensure(lh)
+		x := pdfMargin + indent
+		switch align {
+		case "center":
+			x += (pdfContent - indent - pdfTextWidth(line, size, bold)) / 2
+		case "right":
+			x += pdfContent - indent - pdfTextWidth(line, size, bold)
+		}
+		w.text(x, w.baseline(size), size, bold, color, line, 0)
+		w.y += lh
+	}
+}
+
+// table lays out a table with a shaded header row, repeated on each page it
+// spans. Columns get their natural width; wide tables use a smaller font and
+// then truncate cells to fit the page.
+func (w *pdfWriter) table(header []string, rows [][]string) {
+	cols := len(header)
+	for _, row := range rows {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+	if cols == 0 {
+		return
+	}
+	cell := func(row []string, j int) string {
+		if j < len(row) {
+			return row[j]
+		}
+		return ""
+	}
+
+	const pad = 4.0
+	size := 8.0
+	widths := make([]float64, cols)
+	total := 0.0
+	for j := range widths {
+		widths[j] = pdfTextWidth(cell(header, j), size, true)
+		for _, row := range rows {
+			widths[j] = math.Max(widths[j], pdfTextWidth(cell(row, j), size, false))
+		}
+		widths[j] = math.Min(widths[j]+2*pad+1, 200)
+		total += widths[j]
+	}
+	if total > pdfContent {
+		shrink := math.Max(6/size, pdfContent/total)
+		size *= shrink
+		total = 0
+		for j := range widths {
+			widths[j] *= shrink
+			total += widths[j]
+		}
+	}
+	if total > pdfContent {
+		for j := range widths {
+			widths[j] *= pdfContent / total
+		}
+		total = pdfContent
+	}
+
+	rowH := size * 1.8
+	drawRow := func(row []string, bold bool, fill string) {
+		if fill != "" {
+			w.fillRect(pdfMargin, w.y, total, rowH, fill)
+		}
+		x := pdfMargin
+		for j, width := range widths {
+			s := pdfFit(cell(row, j), size, bold, width-2*pad)
+			tx := x + pad
+			if _, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil && !bold {
+				tx = x + width - pad - pdfTextWidth(s, size, bold)
+			}
+			w.text(tx, pdfPageHeight-w.y-rowH/2-size*0.35, size, bold, "#1f2937", s, 0)
+			x += width
+		}
+		w.y += rowH
+	}
+	drawHeader := func() {
+		if header != nil {
+			drawRow(header, true, "#e5e7eb")
+			w.hline(pdfMargin, pdfMargin+total, w.y, "#9ca3af", 0.75)
+		}
+	}
+
+	w.ensure(rowH * 2)
+	drawHeader()
+	for i, row := range rows {
+		if w.y+rowH > pdfPageHeight-pdfMargin {
+			w.newPage()
+			drawHeader()
+		}
+		fill := ""
+		if i%2 == 1 {
+			fill = "#f3f4f6"
+		}
+		drawRow(row, false, fill)
+	}
+	w.hline(pdfMargin, pdfMargin+total, w.y, "#d1d5db", 0.5)
+	w.y += 12
+}
+
+// svg draws an SVG document written by renderSVG at the cursor, scaled down to
+// fit the page. It handles the elements renderSVG emits: rect, line, circle,
+// path (M, L, A and Z commands), text and vertical linear gradients.
+func (w *pdfWriter) svg(doc string) error {
+	var width, height float64
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	for width == 0 {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("chart SVG: %v", err)
+		}
+		if t, ok := tok.(xml.StartElement); ok && t.Name.Local == "svg" {
+			a := pdfAttrs(t)
+			width, height = pdfFloat(a["width"], 800), pdfFloat(a["height"], 400)
+		}
+	}
+	s := math.Min(1, pdfContent/width)
+	s = math.Min(s, (pdfPageHeight-2*pdfMargin)/height)
+	w.ensure(height * s)
+	x0 := pdfMargin + (pdfContent-width*s)/2
+	y0 := w.y
+	X := func(x float64) float64 { return x0 + x*s }
+	Y := func(y float64) float64 { return pdfPageHeight - y0 - y*s }
+
+	gradients := map[string][]string{}
+	var gradient string
+	var text map[string]string
+	var content strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("chart SVG: %v", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			a := pdfAttrs(t)
+			switch t.Name.Local {
+			case "rect":
+				x, y := pdfFloat(a["x"], 0), pdfFloat(a["y"], 0)
+				rw, rh := pdfFloat(a["width"], 0), pdfFloat(a["height"], 0)
+				if id := strings.TrimSuffix(strings.TrimPrefix(a["fill"], "url(#"), ")"); id != a["fill"] {
+					// vertical gradient from the first stop (bottom) to the last (top)
+					if stops := gradients[id]; len(stops) > 0 {
+						const steps = 32
+						for i := 0; i < steps; i++ {
+							color := svgMix(stops[0], stops[len(stops)-1], (float64(i)+0.5)/steps)
+							top := y + rh*float64(steps-i-1)/steps
+							w.svgPaint(map[string]string{"fill": color},
+								fmt.Sprintf("%.2f %.2f %.2f %.2f re", X(x), Y(top+rh/steps), rw*s, rh/steps*s), s)
+						}
+					}
+					a["fill"] = "none"
+				}
+				w.svgPaint(a, fmt.Sprintf("%.2f %.2f %.2f %.2f re", X(x), Y(y+rh), rw*s, rh*s), s)
+			case "line":
+				if a["stroke"] == "" {
+					a["stroke"] = "#000000"
+				}
+				a["fill"] = "none"
+				w.svgPaint(a, fmt.Sprintf("%.2f %.2f m %.2f %.2f l", X(pdfFloat(a["x1"], 0)), Y(pdfFloat(a["y1"], 0)), X(pdfFloat(a["x2"], 0)), Y(pdfFloat(a["y2"], 0))), s)
+			case "circle":
+				cx, cy, r := pdfFloat(a["cx"], 0), pdfFloat(a["cy"], 0), pdfFloat(a["r"], 0)
+				path := fmt.Sprintf("%.2f %.2f m", X(cx+r), Y(cy))
+				for _, c := range pdfArc(cx+r, cy, r, r, false, true, cx-r, cy) {
+					path += fmt.Sprintf(" %.2f %.2f %.2f %.2f %.2f %.2f c", X(c[0]), Y(c[1]), X(c[2]), Y(c[3]), X(c[4]), Y(c[5]))
+				}
+				for _, c := range pdfArc(cx-r, cy, r, r, false, true, cx+r, cy) {
+					path += fmt.Sprintf(" %.2f %.2f %.2f %.2f %.2f %.2f c", X(c[0]), Y(c[1]), X(c[2]), Y(c[3]), X(c[4]), Y(c[5]))
+				}
+				w.svgPaint(a, path+" h", s)
+			case "path":
+				w.svgPaint(a, pdfPath(a["d"], X, Y), s)
+			case "text":
+				text = a
+				content.Reset()
+			case "linearGradient":
+				gradient = a["id"]
+				gradients[gradient] = nil
+			case "stop":
+				if gradient != "" {
+					gradients[gradient] = append(gradients[gradient], a["stop-color"])
+				}
+			}
+		case xml.CharData:
+			if text != nil {
+				content.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "text":
+				w.svgText(text, content.String(), X, Y, s)
+				text = nil
+			case "linearGradient":
+				gradient = ""
+			}
+		}
+	}
+	w.y += height*s + 12
+	return nil
+}
+
+// svgText draws an SVG text element, honoring text-anchor and a rotate() transform.
+func (w *pdfWriter) svgText(a map[string]string, s string, X, Y func(float64) float64, scale float64) {
+	size := pdfFloat(a["font-size"], 12) * scale
+	bold := a["font-weight"] == "bold"
+	angle := 0.0
+	if m := pdfRotate.FindStringSubmatch(a["transform"]); m != nil {
+		angle = -pdfFloat(m[1], 0)
+	}
+	fill := a["fill"]
+	if fill == "" {
+		fill = "#000000"
+	}
+	offset := 0.0
+	switch a["text-anchor"] {
+	case "middle":
+		offset = pdfTextWidth(s, size, bold) / 2
+	case "end":
+		offset = pdfTextWidth(s, size, bold)
+	}
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	x, y := X(pdfFloat(a["x"], 0)), Y(pdfFloat(a["y"], 0))
+	w.text(x-offset*cos, y-offset*sin, size, bold, fill, s, angle)
+}
+
+var pdfRotate = regexp.MustCompile(`rotate\(\s*(-?[0-9.]+)`)
+
+// svgPaint fills and strokes a PDF path per the SVG fill, stroke and opacity attributes.
+func (w *pdfWriter) svgPaint(a map[string]string, path string, scale float64) {
+	fillAttr, ok := a["fill"]
+	if !ok {
+		fillAttr = "#000000"
+	}
+	fill, hasFill := pdfColor(fillAttr)
+	stroke, hasStroke := pdfColor(a["stroke"])
+	if !hasFill && !hasStroke {
+		return
+	}
+	w.op("q")
+	if opacity := pdfFloat(a["fill-opacity"], pdfFloat(a["opacity"], 1)); opacity < 1 {
+		key := strconv.FormatFloat(opacity, 'f', 2, 64)
+		name, ok := w.alphas[key]
+		if !ok {
+			name = fmt.Sprintf("GS%d", len(w.alphas)+1)
+			w.alphas[key] = name
+		}
+		w.op("/%s gs", name)
+	}
+	paint := "f"
+	if hasFill {
+		w.op("%.3f %.3f %.3f rg", fill[0], fill[1], fill[2])
+	}
+	if hasStroke {
+		w.op("%.3f %.3f %.3f RG %.2f w", stroke[0], stroke[1], stroke[2], pdfFloat(a["stroke-width"], 1)*scale)
+		paint = "S"
+		if hasFill {
+			paint = "B"
+		}
+	}
+	w.op("%s %s", path, paint)
+	w.op("Q")
+}
+
+// pdfPath converts SVG path data with absolute M, L, A and Z commands to PDF path operators.
+func pdfPath(d string, X, Y func(float64) float64) string {
+	tokens := pdfPathTokens.FindAllString(d, -1)
+	var b strings.Builder
+	cmd := ""
+	var cx, cy, sx, sy float64
+	num := func(i *int) float64 {
+		if *i >= len(tokens) {
+			return 0
+		}
+		f, _ := strconv.ParseFloat(tokens[*i], 64)
+		*i++
+		return f
+	}
+	for i := 0; i < len(tokens); {
+		if c := tokens[i]; strings.ContainsAny(c[:1], "MLAZmlaz") {
+			cmd = strings.ToUpper(c)
+			i++
+			if cmd == "Z" {
+				b.WriteString(" h")
+				cx, cy = sx, sy
+				continue
+			}
+		}
+		switch cmd {
+		case "M":
+			cx, cy = num(&i), num(&i)
+			sx, sy = cx, cy
+			fmt.Fprintf(&b, " %.2f %.2f m", X(cx), Y(cy))
+			cmd = "L"
+		case "L":
+			cx, cy = num(&i), num(&i)
+			fmt.Fprintf(&b, " %.2f %.2f l", X(cx), Y(cy))
+		case "A":
+			rx, ry := num(&i), num(&i)
+			num(&i) // x axis rotation; renderSVG arcs are circular
+			large, sweep := num(&i) != 0, num(&i) != 0
+			x, y := num(&i), num(&i)
+			for _, c := range pdfArc(cx, cy, rx, ry, large, sweep, x, y) {
+				fmt.Fprintf(&b, " %.2f %.2f %.2f %.2f %.2f %.2f c", X(c[0]), Y(c[1]), X(c[2]), Y(c[3]), X(c[4]), Y(c[5]))
+			}
+			cx, cy = x, y
+		default:
+			i++
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+var pdfPathTokens = regexp.MustCompile(`[MLAZmlaz]|[-+]?(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE][-+]?[0-9]+)?`)
+
+// pdfArc approximates an SVG elliptical arc (no axis rotation) from x1, y1 to
+// x2, y2 with cubic Bézier curves of at most 90 degrees each. Each curve is
+// returned as its two control points and end point.
+func pdfArc(x1, y1, rx, ry float64, large, sweep bool, x2, y2 float64) [][6]float64 {
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 || (x1 == x2 && y1 == y2) {
+		return [][6]float64{{x1, y1, x2, y2, x2, y2}}
+	}
+	// center parameterization (SVG 1.1 implementation notes, F.6.5)
+	px, py := (x1-x2)/2, (y1-y2)/2
+	if l := px*px/(rx*rx) + py*py/(ry*ry); l > 1 {
+		rx, ry = rx*math.Sqrt(l), ry*math.Sqrt(l)
+	}
+	num := rx*rx*ry*ry - rx*rx*py*py - ry*ry*px*px
+	den := rx*rx*py*py + ry*ry*px*px
+	coef := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		coef = -coef
+	}
+	cxp, cyp := coef*rx*py/ry, -coef*ry*px/rx
+	cx, cy := cxp+(x1+x2)/2, cyp+(y1+y2)/2
+	theta := math.Atan2((py-cyp)/ry, (px-cxp)/rx)
+	delta := math.Atan2((-py-cyp)/ry, (-px-cxp)/rx) - theta
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+
+	n := int(math.Ceil(math.Abs(delta)/(math.Pi/2) - 1e-9))
+	if n < 1 {
+		n = 1
+	}
+	step := delta / float64(n)
+	k := 4.0 / 3 * math.Tan(step/4)
+	out := make([][6]float64, 0, n)
+	for i := 0; i < n; i++ {
+		a1 := theta + float64(i)*step
+		a2 := a1 + step
+		sin1, cos1 := math.Sincos(a1)
+		sin2, cos2 := math.Sincos(a2)
+		out = append(out, [6]float64{
+			cx + rx*cos1 - k*rx*sin1, cy + ry*sin1 + k*ry*cos1,
+			cx + rx*cos2 + k*rx*sin2, cy + ry*sin2 - k*ry*cos2,
+			cx + rx*cos2, cy + ry*sin2,
+		})
+	}
+	return out
+}
+
+func pdfAttrs(t xml.StartElement) map[string]string {
+	a := make(map[string]string, len(t.Attr))
+	for _, attr := range t.Attr {
+		a[attr.Name.Local] = attr.Value
+	}
+	return a
+}
+
+func pdfFloat(s string, def float64) float64 {
+	f, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "px"), 64)
+	if err != nil {
+		return def
+	}
+	return f
+}
+
+// pdfColorNames are the named colors accepted besides #rgb, #rrggbb and rgb().
+var pdfColorNames = map[string]string{
+	"black": "#000000", "white": "#ffffff", "gray": "#808080", "grey": "#808080", "red": "#ff0000",
+	"green": "#008000", "blue": "#0000ff", "yellow": "#ffff00", "orange": "#ffa500", "purple": "#800080",
+}
+
+// pdfColor parses a color to RGB components in 0..1; none and transparent report false.
+func pdfColor(c string) ([3]float64, bool) {
+	c = strings.ToLower(strings.TrimSpace(c))
+	if named, ok := pdfColorNames[c]; ok {
+		c = named
+	}
+	var rgb [3]float64
+	switch {
+	case c == "" || c == "none" || c == "transparent":
+		return rgb, false
+	case strings.HasPrefix(c, "rgb"):
+		start, end := strings.Index(c, "("), strings.Index(c, ")")
+		if start < 0 || end < start {
+			return rgb, true
+		}
+		parts := strings.Split(c[start+1:end], ",")
+		for i := 0; i < 3 && i < len(parts); i++ {
+			rgb[i] = pdfFloat(parts[i], 0) / 255
+		}
+		return rgb, true
+	case strings.HasPrefix(c, "#"):
+		hex := c[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		for i := 0; i < 3 && len(hex) >= 2*i+2; i++ {
+			n, _ := strconv.ParseUint(hex[2*i:2*i+2], 16, 8)
+			rgb[i] = float64(n) / 255
+		}
+	}
+	return rgb, true
+}
+
+// Helvetica and Helvetica-Bold advance widths (1/1000 em) for ASCII 32-126.
+var (
+	pdfHelvetica = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+	pdfHelveticaBold = [95]int{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}
+)
+
+// pdfWinAnsi maps the non-Latin-1 characters of WinAnsiEncoding to their codes.
+var pdfWinAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+	'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// pdfEncode converts s to WinAnsiEncoding bytes; other characters become "?".
+func pdfEncode(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			out = append(out, ' ')
+		case r >= 32 && r < 127, r >= 0xa0 && r <= 0xff:
+			out = append(out, byte(r))
+		default:
+			if b, ok := pdfWinAnsi[r]; ok {
+				out = append(out, b)
+			} else {
+				out = append(out, '?')
+			}
+		}
+	}
+	return out
+}
+
+// pdfString encodes s as the body of a PDF literal string.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, c := range pdfEncode(s) {
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 32 || c > 126:
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// pdfTextWidth measures s in points.
+func pdfTextWidth(s string, size float64, bold bool) float64 {
+	widths := &pdfHelvetica
+	if bold {
+		widths = &pdfHelveticaBold
+	}
+	total := 0
+	for _, c := range pdfEncode(s) {
+		switch {
+		case c >= 32 && c < 127:
+			total += widths[c-32]
+		case c == 0x95:
+			total += 350
+		case c == 0x85 || c == 0x89 || c == 0x97:
+			total += 1000
+		case c == 0xa0:
+			total += 278
+		default:
+			total += 556
+		}
+	}
+	return float64(total) * size / 1000
+}
+
+// pdfWrap breaks s into lines no wider than width, splitting overlong words.
+func pdfWrap(s string, size float64, bold bool, width float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if pdfTextWidth(candidate, size, bold) <= width {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = ""
+		for _, r := range word {
+			if line != "" && pdfTextWidth(line+string(r), size, bold) > width {
+				lines = append(lines, line)
+				line = ""
+			}
+			line += string(r)
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// pdfFit truncates s with an ellipsis to fit width.
+func pdfFit(s string, size float64, bold bool, width float64) string {
+	if pdfTextWidth(s, size, bold) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdfTextWidth(string(runes)+"…", size, bold) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "…"
+}
+
+// bytes numbers the pages and assembles the PDF file.
+func (w *pdfWriter) bytes(title string) ([]byte, error) {
+	for i, page := range w.pages {
+		w.page = page
+		label := fmt.Sprintf("%d / %d", i+1, len(w.pages))
+		w.text((pdfPageWidth-pdfTextWidth(label, 8, false))/2, pdfMargin/2, 8, false, "#9ca3af", label, 0)
+	}
+
+	var out bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catalog, 2 page tree, 3 info, 4-5 fonts, then a page and its content per page
+	kids := make([]string, len(w.pages))
+	for i := range w.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 6+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages)))
+	obj(fmt.Sprintf("<< /Title (%s) /Producer (gophers) >>", pdfString(title)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	keys := make([]string, 0, len(w.alphas))
+	for key := range w.alphas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	states := ""
+	for _, key := range keys {
+		states += fmt.Sprintf(" /%s << /Type /ExtGState /ca %s >>", w.alphas[key], key)
+	}
+	resources := "<< /Font << /F1 4 0 R /F2 5 0 R >> /ExtGState <<" + states + " >> >>"
+
+	for i, page := range w.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %g %g] /Resources %s /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, resources, 7+2*i))
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+		obj(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.String()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes(), nil
+}
@@ -0,0 +1,299 @@
+package gophers
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+const (
+	profileTopK    = 10 // most frequent values charted for non-numeric columns
+	profileSamples = 10 // distinct sample values listed per column
+)
+
+// profileOverviewPage names the overview page of a Profile report.
+const profileOverviewPage = "Overview"
+
+var profileEmail = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+
+// columnProfile holds what a Profile column page shows.
+type columnProfile struct {
+	name     string
+	page     string
+	typ      string
+	c        *typedColumn
+	st       *describeStats
+	patterns []string // "Emails: 3 of 10 (30.0%)", ...
+}
+
+// Profile returns a Report describing the DataFrame, in place of a
+// pandas-profiling step. The "Overview" page lists the row and column counts,
+// duplicate rows, a per-column summary table and a correlation heatmap of the
+// numeric columns. Each column gets a page with its type, null percentage and
+// distinct count, a histogram (numeric columns) or a bar chart of the most
+// frequent values, sample values, and the emails, dates and numeric-looking
+// strings detected in string columns. Columns are scanned in parallel shards,
+// as in Describe.
+func (df *DataFrame) Profile() *Report {
+	report := CreateReport("Data Profile")
+	report.AddPage(profileOverviewPage)
+	if df == nil {
+		return report
+	}
+
+	profiles := make([]*columnProfile, len(df.Cols))
+	pages := map[string]bool{profileOverviewPage: true}
+	for i, name := range df.Cols {
+		c := df.typedColumn(name)
+		t, _ := df.columnType(name)
+		p := &columnProfile{name: name, page: profilePageName(pages, name), typ: t, c: c, st: describeColumn(c)}
+		if c.kind == kindString {
+			p.patterns = profilePatterns(c, p.st)
+		}
+		profiles[i] = p
+	}
+
+	report.profileOverview(df, profiles)
+	for _, p := range profiles {
+		report.profileColumn(p)
+	}
+	return report
+}
+
+// profileOverview fills the overview page.
+func (report *Report) profileOverview(df *DataFrame, profiles []*columnProfile) {
+	page := profileOverviewPage
+	cells, nulls := df.Rows*len(df.Cols), 0
+	for _, p := range profiles {
+		nulls += p.c.n - p.st.count
+	}
+	dups := df.CountDuplicates()
+	report.AddBullets(page,
+		fmt.Sprintf("Rows: %d", df.Rows),
+		fmt.Sprintf("Columns: %d", len(df.Cols)),
+		fmt.Sprintf("Duplicate rows: %d (%s)", dups, profilePercent(dups, df.Rows)),
+		fmt.Sprintf("Null cells: %d (%s)", nulls, profilePercent(nulls, cells)),
+	)
+
+	summary := &DataFrame{
+		Cols: []string{"column", "type", "nulls", "null %", "distinct"},
+		Data: map[string][]interface{}{},
+		Rows: len(profiles),
+	}
+	for _, p := range profiles {
+		n := p.c.n - p.st.count
+		summary.Data["column"] = append(summary.Data["column"], p.name)
+		summary.Data["type"] = append(summary.Data["type"], p.typ)
+		summary.Data["nulls"] = append(summary.Data["nulls"], n)
+		summary.Data["null %"] = append(summary.Data["null %"], profilePercent(n, p.c.n))
+		summary.Data["distinct"] = append(summary.Data["distinct"], len(p.st.freq))
+	}
+	report.AddDataframe(page, summary)
+
+	var numeric []*columnProfile
+	for _, p := range profiles {
+		if p.c.kind == kindInt64 || p.c.kind == kindFloat64 {
+			numeric = append(numeric, p)
+		}
+	}
+	if len(numeric) < 2 {
+		return
+	}
+	corr := profileCorrelations(numeric)
+	long := &DataFrame{Cols: []string{"x", "y", "correlation"}, Data: map[string][]interface{}{}}
+	for i, a := range numeric {
+		for j, b := range numeric {
+			var v interface{}
+			if !math.IsNaN(corr[i][j]) {
+				v = math.Round(corr[i][j]*1000) / 1000
+			}
+			long.Data["x"] = append(long.Data["x"], a.name)
+			long.Data["y"] = append(long.Data["y"], b.name)
+			long.Data["correlation"] = append(long.Data["correlation"], v)
+			long.Rows++
+		}
+	}
+	chart := long.Heatmap("x", "y", "correlation", Max("correlation"))
+	chart.Spec = nil // profile charts stay static rather than embedding their rows for filters
+	report.AddHeading(page, "Correlations", 5)
+	report.AddChart(page, chart)
+}
+
+// profileColumn adds the page of one column.
+func (report *Report) profileColumn(p *columnProfile) {
+	report.AddPage(p.page)
+	c, st := p.c, p.st
+	nulls := c.n - st.count
+	stats := []string{
+		"Type: " + html.EscapeString(p.typ),
+		fmt.Sprintf("Nulls: %d (%s)", nulls, profilePercent(nulls, c.n)),
+		fmt.Sprintf("Distinct: %d (%s)", len(st.freq), profilePercent(len(st.freq), st.count)),
+	}
+	if st.count > 0 && c.kind != kindNested {
+		stats = append(stats,
+			"Min: "+html.EscapeString(fmt.Sprintf("%v", c.value(st.min))),
+			"Max: "+html.EscapeString(fmt.Sprintf("%v", c.value(st.max))),
+		)
+	}
+	if (c.kind == kindInt64 || c.kind == kindFloat64) && st.count > 0 {
+		stats = append(stats, "Mean: "+strconv.FormatFloat(st.mean, 'g', 6, 64))
+		if st.count > 1 {
+			stats = append(stats, "Std: "+strconv.FormatFloat(math.Sqrt(st.m2/float64(st.count-1)), 'g', 6, 64))
+		}
+	}
+	report.AddBullets(p.page, stats...)
+
+	if st.count > 0 {
+		var chart Chart
+		if c.kind == kindInt64 || c.kind == kindFloat64 {
+			chart = (&DataFrame{Cols: []string{p.name}, Data: map[string][]interface{}{p.name: c.boxed}, Rows: c.n}).Histogram(p.name, 0)
+		} else {
+			chart = profileTopValues(p)
+		}
+		chart.Spec = nil
+		report.AddChart(p.page, chart)
+	}
+
+	if len(st.order) > 0 {
+		samples := make([]string, 0, profileSamples)
+		for _, k := range st.order {
+			if len(samples) == profileSamples {
+				break
+			}
+			samples = append(samples, html.EscapeString(fmt.Sprintf("%v", c.value(st.freq[k].first))))
+		}
+		report.AddHeading(p.page, "Sample values", 6)
+		report.AddBullets(p.page, samples...)
+	}
+
+	if c.kind == kindString {
+		report.AddHeading(p.page, "Patterns", 6)
+		if len(p.patterns) == 0 {
+			report.AddText(p.page, "No emails, dates or numeric strings detected.")
+		} else {
+			report.AddBullets(p.page, p.patterns...)
+		}
+	}
+}
+
+// profileTopValues charts the most frequent values of a column.
+func profileTopValues(p *columnProfile) Chart {
+	order := append([]typedKey(nil), p.st.order...)
+	sort.SliceStable(order, func(a, b int) bool { return p.st.freq[order[a]].n > p.st.freq[order[b]].n })
+	if len(order) > profileTopK {
+		order = order[:profileTopK]
+	}
+	top := &DataFrame{Cols: []string{p.name, "count"}, Data: map[string][]interface{}{}, Rows: len(order)}
+	for _, k := range order {
+		f := p.st.freq[k]
+		top.Data[p.name] = append(top.Data[p.name], fmt.Sprintf("%v", p.c.value(f.first)))
+		top.Data["count"] = append(top.Data["count"], f.n)
+	}
+	return top.BarChart(p.name, "Most frequent values", p.name, []Aggregation{Sum("count")})
+}
+
+// profilePatterns counts the string values that look like emails, dates or
+// numbers, checking each distinct value once.
+func profilePatterns(c *typedColumn, st *describeStats) []string {
+	var emails, dates, numbers int
+	for _, k := range st.order {
+		f := st.freq[k]
+		s := strings.TrimSpace(c.strs[f.first])
+		if profileEmail.MatchString(s) {
+			emails += f.n
+		}
+		if _, ok := parseTimeAny(s); ok {
+			dates += f.n
+		}
+		if _, err := strconv.ParseFloat(s, 64); err == nil {
+			numbers += f.n
+		}
+	}
+	var out []string
+	for _, m := range []struct {
+		name string
+		n    int
+	}{{"Emails", emails}, {"Dates", dates}, {"Numeric strings", numbers}} {
+		if m.n > 0 {
+			out = append(out, fmt.Sprintf("%s: %d of %d (%s)", m.name, m.n, st.count, profilePercent(m.n, st.count)))
+		}
+	}
+	return out
+}
+
+// profileCorrelations returns the Pearson correlation matrix of numeric
+// columns over the rows where both values are present, one goroutine per
+// column. Pairs without variance are NaN.
+func profileCorrelations(cols []*columnProfile) [][]float64 {
+	out := make([][]float64, len(cols))
+	for i := range out {
+		out[i] = make([]float64, len(cols))
+	}
+	var wg sync.WaitGroup
+	for i := range cols {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			a := cols[i].c
+			for j := i; j < len(cols); j++ {
+				b := cols[j].c
+				// Welford's update of the means and co-moments; the one-pass
+				// sum-of-products formula loses precision to cancellation
+				var n, mx, my, sxx, syy, sxy float64
+				for r := 0; r < a.n && r < b.n; r++ {
+					x, okx := a.float(r)
+					y, oky := b.float(r)
+					if !okx || !oky || !a.isValid(r) || !b.isValid(r) || math.IsNaN(x+y) || math.IsInf(x+y, 0) {
+						continue
+					}
+					n++
+					dx := x - mx
+					mx += dx / n
+					dy := y - my
+					my += dy / n
+					sxx += dx * (x - mx)
+					syy += dy * (y - my)
+					sxy += dx * (y - my)
+				}
+				r := math.NaN()
+				if d := math.Sqrt(sxx * syy); n > 1 && d > 0 {
+					r = math.Max(-1, math.Min(1, sxy/d))
+				}
+				out[i][j] = r
+				out[j][i] = r
+			}
+		}(i)
+	}
+	wg.Wait()
+	return out
+}
+
+// profilePageName returns a unique page name for a column. Report pages are
+// matched by name in quoted page == '...' expressions of the page markup, so
+// quotes, backslashes and markup characters are replaced with "_".
+func profilePageName(seen map[string]bool, column string) string {
+	page := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`'"\<>&`, r) || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, column)
+	if page == profileOverviewPage {
+		page = column + " (column)"
+	}
+	return uniqueName(seen, page)
+}
+
+// profilePercent formats n/total as a percentage.
+func profilePercent(n, total int) string {
+	if total == 0 {
+		return "0%"
+	}
+	return strconv.FormatFloat(float64(n)*100/float64(total), 'f', 1, 64) + "%"
+}
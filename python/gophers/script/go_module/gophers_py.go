@@ -11,7 +11,6 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/url"
 	"os"
 	"strings"
@@ -80,40 +79,60 @@ func fileExists(filename string) bool {
 	return !info.IsDir()
 }
 
+// errorResult reports a wrapper error as {"error": ...}, which the Python side
+// raises as RuntimeError instead of the host process exiting.
+func errorResult(msg string) *C.char {
+	b, _ := json.Marshal(map[string]string{"error": msg})
+	return C.CString(string(b))
+}
+
+// dataFrameResult marshals a reader's DataFrame, or returns {"error": ...} so
+// the Python side raises instead of the host process exiting.
+func dataFrameResult(op string, df *g.DataFrame, err error) *C.char {
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	b, err := json.Marshal(df)
+	if err != nil {
+		return errorResult(fmt.Sprintf("%s: marshal error: %v", op, err))
+	}
+	return C.CString(string(b))
+}
+
+// csvOptionsArg decodes a JSON CSVOptions object ("" or "{}" for defaults).
+func csvOptionsArg(optsJson *C.char) (g.CSVOptions, error) {
+	var opts g.CSVOptions
+	if s := C.GoString(optsJson); s != "" && s != "null" {
+		if err := json.Unmarshal([]byte(s), &opts); err != nil {
+			return opts, fmt.Errorf("CSVOptions: unmarshal error: %v", err)
+		}
+	}
+	return opts, nil
+}
+
 //export ReadCSV
-func ReadCSV(csvData *C.char) *C.char {
-	df := g.ReadCSV(C.GoString(csvData))
-	js, err := json.Marshal(df)
+func ReadCSV(csvData *C.char, optsJson *C.char) *C.char {
+	opts, err := csvOptionsArg(optsJson)
 	if err != nil {
-		errStr := fmt.Sprintf("ReadCSV wrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return dataFrameResult("ReadCSV", nil, err)
 	}
-	return C.CString(string(js))
+	df, err := g.ReadCSV(C.GoString(csvData), opts)
+	return dataFrameResult("ReadCSV", df, err)
 }
 
 //export ReadJSON
 func ReadJSON(jsonStr *C.char) *C.char {
 	if jsonStr == nil {
-		log.Fatalf("Error: jsonStr is nil")
-		return C.CString("")
+		return C.CString(`{"error":"ReadJSON: input is nil"}`)
 	}
-	df := g.ReadJSON(C.GoString(jsonStr))
-	jsonBytes, err := json.Marshal(df)
-	if err != nil {
-		log.Fatalf("ReadJSON: marshal: %v", err)
-	}
-	return C.CString(string(jsonBytes))
+	df, err := g.ReadJSON(C.GoString(jsonStr))
+	return dataFrameResult("ReadJSON", df, err)
 }
 
 //export ReadNDJSON
 func ReadNDJSON(ndjson *C.char) *C.char {
-	df := g.ReadNDJSON(C.GoString(ndjson))
-	b, err := json.Marshal(df)
-	if err != nil {
-		log.Fatalf("ReadNDJSON wrapper: marshal error: %v", err)
-	}
-	return C.CString(string(b))
+	df, err := g.ReadNDJSON(C.GoString(ndjson))
+	return dataFrameResult("ReadNDJSON", df, err)
 }
 
 // ReadYAML reads a YAML string or file and converts it to a DataFrame.
@@ -121,18 +140,10 @@ func ReadNDJSON(ndjson *C.char) *C.char {
 //export ReadYAML
 func ReadYAML(yamlStr *C.char) *C.char {
 	if yamlStr == nil {
-		errStr := "ReadYAML: input is nil"
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return C.CString(`{"error":"ReadYAML: input is nil"}`)
 	}
-	df := g.ReadYAML(C.GoString(yamlStr))
-	jsonBytes, err := json.Marshal(df)
-	if err != nil {
-		errStr := fmt.Sprintf("ReadYAML: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
-	}
-	return C.CString(string(jsonBytes))
+	df, err := g.ReadYAML(C.GoString(yamlStr))
+	return dataFrameResult("ReadYAML", df, err)
 }
 
 // ReadSqlite is a helper that returns the DataFrame JSON string.
@@ -154,7 +165,7 @@ func readSqliteGo(path, table, query string) (string, error) {
 func ReadSqlite(dbPath *C.char, table *C.char, query *C.char) *C.char {
 	js, err := readSqliteGo(C.GoString(dbPath), C.GoString(table), C.GoString(query))
 	if err != nil {
-		return C.CString(fmt.Sprintf(`{"error":%q}`, err.Error()))
+		return errorResult(err.Error())
 	}
 	return C.CString(js)
 }
@@ -165,13 +176,13 @@ func GetSqliteTables(dbPath *C.char) *C.char {
 
 	db, err := sql.Open("sqlite3", path)
 	if err != nil {
-		return C.CString(fmt.Sprintf(`{"error":%q}`, fmt.Sprintf("open error: %v", err)))
+		return errorResult(fmt.Sprintf("open error: %v", err))
 	}
 	defer db.Close()
 
 	rows, err := db.Query(`SELECT name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
 	if err != nil {
-		return C.CString(fmt.Sprintf(`{"error":%q}`, fmt.Sprintf("query error: %v", err)))
+		return errorResult(fmt.Sprintf("query error: %v", err))
 	}
 	defer rows.Close()
 
@@ -179,12 +190,12 @@ func GetSqliteTables(dbPath *C.char) *C.char {
 	for rows.Next() {
 		var name string
 		if err := rows.Scan(&name); err != nil {
-			return C.CString(fmt.Sprintf(`{"error":%q}`, fmt.Sprintf("scan error: %v", err)))
+			return errorResult(fmt.Sprintf("scan error: %v", err))
 		}
 		names = append(names, name)
 	}
 	if err := rows.Err(); err != nil {
-		return C.CString(fmt.Sprintf(`{"error":%q}`, fmt.Sprintf("rows error: %v", err)))
+		return errorResult(fmt.Sprintf("rows error: %v", err))
 	}
 
 	payload, _ := json.Marshal(map[string]interface{}{"tables": names})
@@ -200,38 +211,19 @@ func GetSqliteSchema(dbPath *C.char, table *C.char) *C.char {
 //export SqliteSQLWrapper
 func SqliteSQLWrapper(path *C.char, sql *C.char) *C.char {
 	df, err := g.SqliteSQL(C.GoString(path), C.GoString(sql))
-	if err != nil {
-		// Return a one-row DF with error for consistency
-		rows := []map[string]interface{}{{"error": err.Error()}}
-		b, _ := json.Marshal(g.Dataframe(rows))
-		return C.CString(string(b))
-	}
-	b, _ := json.Marshal(df)
-	return C.CString(string(b))
+	return dataFrameResult("SqliteSQL", df, err)
 }
 
 //export ReadHTML
 func ReadHTML(htmlInput *C.char) *C.char {
-	df := g.ReadHTML(C.GoString(htmlInput))
-	js, err := json.Marshal(df)
-	if err != nil {
-		errStr := fmt.Sprintf("ReadHTML: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
-	}
-	return C.CString(string(js))
+	df, err := g.ReadHTML(C.GoString(htmlInput))
+	return dataFrameResult("ReadHTML", df, err)
 }
 
 //export ReadHTMLTop
 func ReadHTMLTop(htmlInput *C.char) *C.char {
-	df := g.ReadHTMLTop(C.GoString(htmlInput))
-	js, err := json.Marshal(df)
-	if err != nil {
-		errStr := fmt.Sprintf("ReadHTMLTop: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
-	}
-	return C.CString(string(js))
+	df, err := g.ReadHTMLTop(C.GoString(htmlInput))
+	return dataFrameResult("ReadHTMLTop", df, err)
 }
 
 //export Clone
@@ -248,16 +240,14 @@ func Flatten(dfJson *C.char, flattenColsJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("Flatten: DataFrame unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	// Unmarshal the flatten columns (JSON array of strings).
 	var flattenCols []string
 	if err := json.Unmarshal([]byte(C.GoString(flattenColsJson)), &flattenCols); err != nil {
 		errStr := fmt.Sprintf("Flatten: flattenCols unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	// Call the Flatten method.
@@ -267,8 +257,7 @@ func Flatten(dfJson *C.char, flattenColsJson *C.char) *C.char {
 	jsonBytes, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("Flatten: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(jsonBytes))
@@ -282,15 +271,13 @@ func KeysToCols(dfJson *C.char, nestedCol *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("KeysToCols: DataFrame unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	newDF := df.KeysToCols(C.GoString(nestedCol))
 	jsonBytes, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("KeysToCols: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(jsonBytes))
 }
@@ -313,8 +300,7 @@ func StringArrayConvert(dfJson *C.char, column *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("StringArrayConvert: DataFrame unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	// Call the StringArrayConvert method.
@@ -324,8 +310,7 @@ func StringArrayConvert(dfJson *C.char, column *C.char) *C.char {
 	jsonBytes, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("StringArrayConvert: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(jsonBytes))
@@ -340,13 +325,16 @@ func StringArrayConvert(dfJson *C.char, column *C.char) *C.char {
 //
 //export ReadParquet
 func ReadParquet(parquetPath *C.char) *C.char {
-	goPath := C.GoString(parquetPath)
-	df := g.ReadParquet(goPath)
-	jsonBytes, err := json.Marshal(df)
-	if err != nil {
-		log.Fatalf("ReadParquet: error marshalling DataFrame: %v", err)
-	}
-	return C.CString(string(jsonBytes))
+	df, err := g.ReadParquet(C.GoString(parquetPath))
+	return dataFrameResult("ReadParquet", df, err)
+}
+
+// ReadXLSX reads a sheet of an Excel workbook ("" for the first sheet) and returns the DataFrame JSON.
+//
+//export ReadXLSX
+func ReadXLSX(xlsxPath *C.char, sheet *C.char) *C.char {
+	df, err := g.ReadXLSX(C.GoString(xlsxPath), C.GoString(sheet))
+	return dataFrameResult("ReadXLSX", df, err)
 }
 
 //export GetAPI
@@ -388,14 +376,12 @@ func GetAPI(endpoint *C.char, headers *C.char, queryParams *C.char) *C.char {
 	df, err := g.GetAPI(ep, h, qm)
 	if err != nil {
 		errStr := fmt.Sprintf("GetAPI: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	jsonBytes, err := json.Marshal(df)
 	if err != nil {
 		errStr := fmt.Sprintf("GetAPI: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(jsonBytes))
 }
@@ -406,7 +392,7 @@ func GetAPI(endpoint *C.char, headers *C.char, queryParams *C.char) *C.char {
 func Show(dfJson *C.char, chars C.int, record_count C.int) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		log.Fatalf("Error unmarshalling DataFrame JSON: %v", err)
+		return errorResult(fmt.Sprintf("Error unmarshalling DataFrame JSON: %v", err))
 	}
 	text := df.Show(int(chars), int(record_count))
 	return C.CString(text)
@@ -416,7 +402,7 @@ func Show(dfJson *C.char, chars C.int, record_count C.int) *C.char {
 func Head(dfJson *C.char, chars C.int) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		log.Fatalf("Error unmarshalling DataFrame JSON in Head: %v", err)
+		return errorResult(fmt.Sprintf("Error unmarshalling DataFrame JSON in Head: %v", err))
 	}
 	text := df.Head(int(chars))
 	return C.CString(text)
@@ -426,7 +412,7 @@ func Head(dfJson *C.char, chars C.int) *C.char {
 func Tail(dfJson *C.char, chars C.int) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		log.Fatalf("Error unmarshalling DataFrame JSON in Tail: %v", err)
+		return errorResult(fmt.Sprintf("Error unmarshalling DataFrame JSON in Tail: %v", err))
 	}
 	out := df.Tail(int(chars))
 	return C.CString(out)
@@ -436,29 +422,32 @@ func Tail(dfJson *C.char, chars C.int) *C.char {
 func Vertical(dfJson *C.char, chars C.int, record_count C.int) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		log.Fatalf("Error unmarshalling DataFrame JSON in Vertical: %v", err)
+		return errorResult(fmt.Sprintf("Error unmarshalling DataFrame JSON in Vertical: %v", err))
 	}
 	out := df.Vertical(int(chars), int(record_count))
 	return C.CString(out)
 }
 
 // DisplayBrowserWrapper is an exported function that wraps the DisplayBrowser method.
-// It takes a JSON-string representing the DataFrame, calls DisplayBrowser, and
-// returns an empty string on success or an error message on failure.
+// It takes a JSON-string representing the DataFrame and optional SaveOptions JSON, calls DisplayBrowser, and
+// returns an empty string on success or {"error": ...} on failure.
 //
 //export DisplayBrowserWrapper
-func DisplayBrowserWrapper(dfJson *C.char) *C.char {
+func DisplayBrowserWrapper(dfJson *C.char, optsJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("DisplayBrowserWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
-	if err := df.DisplayBrowser(); err != nil {
+	opts, err := saveOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("DisplayBrowserWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	if err := df.DisplayBrowser(opts); err != nil {
 		errStr := fmt.Sprintf("DisplayBrowserWrapper: error displaying in browser: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	// Return an empty string to denote success.
@@ -509,23 +498,21 @@ func mapToString(data map[string][]interface{}) string {
 
 // DisplayWrapper is an exported function that wraps the Display method.
 // It takes a JSON-string representing the DataFrame, calls Display, and
-// returns the HTML string on success or an error message on failure.
+// returns the HTML string on success or {"error": ...} on failure.
 //
 //export DisplayWrapper
 func DisplayWrapper(dfJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("DisplayWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	displayResult := df.Display()
 	html, ok := displayResult["text/html"].(string)
 	if !ok {
 		errStr := "DisplayWrapper: error displaying dataframe: invalid HTML content"
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(html)
@@ -534,22 +521,25 @@ func DisplayWrapper(dfJson *C.char) *C.char {
 // DisplayToFile
 // DisplayToFileWrapper is an exported function that wraps the DisplayToFile method.
 // It takes a JSON-string representing the DataFrame and a file path, calls DisplayToFile,
-// and returns an empty string on success or an error message on failure.
+// and returns an empty string on success or {"error": ...} on failure.
 //
 //export DisplayToFileWrapper
-func DisplayToFileWrapper(dfJson *C.char, filePath *C.char) *C.char {
+func DisplayToFileWrapper(dfJson *C.char, filePath *C.char, optsJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("DisplayToFileWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	path := C.GoString(filePath)
-	if err := df.DisplayToFile(path); err != nil {
+	opts, err := saveOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("DisplayToFileWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	if err := df.DisplayToFile(path, opts); err != nil {
 		errStr := fmt.Sprintf("DisplayToFileWrapper: error writing to file: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	// Return an empty string to denote success.
@@ -558,23 +548,21 @@ func DisplayToFileWrapper(dfJson *C.char, filePath *C.char) *C.char {
 
 // DisplayChartWrapper is an exported function that wraps the DisplayChart function.
 // It takes a JSON-string representing the Chart, calls DisplayChart, and
-// returns the HTML string on success or an error message on failure.
+// returns the HTML string on success or {"error": ...} on failure.
 //
 //export DisplayChartWrapper
 func DisplayChartWrapper(chartJson *C.char) *C.char {
 	var chart Chart
 	if err := json.Unmarshal([]byte(C.GoString(chartJson)), &chart); err != nil {
 		errStr := fmt.Sprintf("DisplayChartWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	displayChart := DisplayChart(chart)
 	html, ok := displayChart["text/html"].(string)
 	if !ok {
 		errStr := "DisplayChartWrapper: error displaying chart"
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(html)
@@ -600,19 +588,17 @@ func DisplayHTML(html string) map[string]interface{} {
 // BarChartWrapper is an exported function that wraps the BarChart function.
 //
 //export BarChartWrapper
-func BarChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupcol *C.char, aggsJson *C.char) *C.char {
+func BarChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupcol *C.char, aggsJson *C.char, optsJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("BarChartWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	var simpleAggs []SimpleAggregation
 	if err := json.Unmarshal([]byte(C.GoString(aggsJson)), &simpleAggs); err != nil {
 		errStr := fmt.Sprintf("BarChartWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	// Reconstruct the Aggregation structs
@@ -641,7 +627,12 @@ func BarChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupcol *
 		}
 	}
 
-	chart := df.BarChart(C.GoString(title), C.GoString(subtitle), C.GoString(groupcol), aggs)
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("BarChartWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	chart := df.BarChart(C.GoString(title), C.GoString(subtitle), C.GoString(groupcol), aggs, opts)
 	// displayChart := DisplayChart(chart)
 	// html, ok := displayChart["text/html"].(string)
 	// if !ok {
@@ -654,30 +645,27 @@ func BarChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupcol *
 	// fmt.Println(string(chartJson))
 	if err != nil {
 		errStr := fmt.Sprintf("BarChartWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(chartJson))
 }
 
 // ColumnChartWrapper is an exported function that wraps the ColumnChart function.
 // It takes a JSON-string representing the DataFrame and chart parameters, calls ColumnChart, and
-// returns the HTML string on success or an error message on failure.
+// returns the HTML string on success or {"error": ...} on failure.
 //
 //export ColumnChartWrapper
-func ColumnChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupcol *C.char, aggsJson *C.char) *C.char {
+func ColumnChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupcol *C.char, aggsJson *C.char, optsJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("ColumnChartWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	var simpleAggs []SimpleAggregation
 	if err := json.Unmarshal([]byte(C.GoString(aggsJson)), &simpleAggs); err != nil {
 		errStr := fmt.Sprintf("ColumnChartWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	// Reconstruct the Aggregation structs
@@ -706,7 +694,12 @@ func ColumnChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupco
 		}
 	}
 
-	chart := df.ColumnChart(C.GoString(title), C.GoString(subtitle), C.GoString(groupcol), aggs)
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("ColumnChartWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	chart := df.ColumnChart(C.GoString(title), C.GoString(subtitle), C.GoString(groupcol), aggs, opts)
 	// displayChart := DisplayChart(chart)
 	// html, ok := displayChart["text/html"].(string)
 	// if !ok {
@@ -719,30 +712,27 @@ func ColumnChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupco
 	// fmt.Println(string(chartJson))
 	if err != nil {
 		errStr := fmt.Sprintf("ColumnChartWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(chartJson))
 }
 
 // StackedBarChartWrapper is an exported function that wraps the StackedBarChart function.
 // It takes a JSON-string representing the DataFrame and chart parameters, calls StackedBarChart, and
-// returns the HTML string on success or an error message on failure.
+// returns the HTML string on success or {"error": ...} on failure.
 //
 //export StackedBarChartWrapper
-func StackedBarChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupcol *C.char, aggsJson *C.char) *C.char {
+func StackedBarChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupcol *C.char, aggsJson *C.char, optsJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("StackedBarChartWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	var simpleAggs []SimpleAggregation
 	if err := json.Unmarshal([]byte(C.GoString(aggsJson)), &simpleAggs); err != nil {
 		errStr := fmt.Sprintf("ColumnChartWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	// Reconstruct the Aggregation structs
@@ -771,13 +761,17 @@ func StackedBarChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, gro
 		}
 	}
 
-	chart := df.StackedBarChart(C.GoString(title), C.GoString(subtitle), C.GoString(groupcol), aggs)
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("StackedBarChartWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	chart := df.StackedBarChart(C.GoString(title), C.GoString(subtitle), C.GoString(groupcol), aggs, opts)
 	displayChart := DisplayChart(chart)
 	html, ok := displayChart["text/html"].(string)
 	if !ok {
 		errStr := "StackedBarChartWrapper: error displaying chart"
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(html)
@@ -785,49 +779,357 @@ func StackedBarChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, gro
 
 // StackedPercentChartWrapper is an exported function that wraps the StackedPercentChart function.
 // It takes a JSON-string representing the DataFrame and chart parameters, calls StackedPercentChart, and
-// returns the HTML string on success or an error message on failure.
+// returns the HTML string on success or {"error": ...} on failure.
 //
 //export StackedPercentChartWrapper
-func StackedPercentChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupcol *C.char, aggsJson *C.char) *C.char {
+func StackedPercentChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupcol *C.char, aggsJson *C.char, optsJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("StackedPercentChartWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	var aggs []Aggregation
 	if err := json.Unmarshal([]byte(C.GoString(aggsJson)), &aggs); err != nil {
 		errStr := fmt.Sprintf("StackedPercentChartWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
-	chart := df.StackedPercentChart(C.GoString(title), C.GoString(subtitle), C.GoString(groupcol), aggs)
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("StackedPercentChartWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	chart := df.StackedPercentChart(C.GoString(title), C.GoString(subtitle), C.GoString(groupcol), aggs, opts)
 	displayChart := DisplayChart(chart)
 	html, ok := displayChart["text/html"].(string)
 	if !ok {
 		errStr := "StackedPercentChartWrapper: error displaying chart"
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(html)
 }
 
-// PieChart
+// PieChartWrapper is an exported function that wraps the PieChart function.
+// The aggregation is a JSON object such as {"ColumnName": "sales", "Fn": "Sum"}.
+//
+//export PieChartWrapper
+func PieChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, namecol *C.char, aggJson *C.char, optsJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("PieChartWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+	agg, err := chartAggregation(C.GoString(aggJson))
+	if err != nil {
+		errStr := fmt.Sprintf("PieChartWrapper: %v", err)
+		return errorResult(errStr)
+	}
 
-// AreaChart
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("PieChartWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	chart := df.PieChart(C.GoString(title), C.GoString(subtitle), C.GoString(namecol), agg, opts)
+	return chartResult("PieChartWrapper", chart)
+}
 
-// DataTable
+// AreaChartWrapper is an exported function that wraps the AreaChart function.
+// ycolsJson is a JSON array of the y column names.
+//
+//export AreaChartWrapper
+func AreaChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, xcol *C.char, ycolsJson *C.char, optsJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("AreaChartWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+	var ycols []string
+	if err := json.Unmarshal([]byte(C.GoString(ycolsJson)), &ycols); err != nil {
+		errStr := fmt.Sprintf("AreaChartWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
 
-// ScatterPlot
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("AreaChartWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	if err := columnsError(&df, append([]string{C.GoString(xcol)}, ycols...)...); err != nil {
+		return errorResult(fmt.Sprintf("AreaChartWrapper: %v", err))
+	}
+	chart := df.AreaChart(C.GoString(title), C.GoString(subtitle), C.GoString(xcol), ycols, opts)
+	return chartResult("AreaChartWrapper", chart)
+}
 
-// BubbleChart
+// DataTableWrapper is an exported function that wraps the DataTable function.
+// colsJson is a JSON array of column names (empty for all columns).
+//
+//export DataTableWrapper
+func DataTableWrapper(dfJson *C.char, colsJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("DataTableWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+	var cols []string
+	if err := json.Unmarshal([]byte(C.GoString(colsJson)), &cols); err != nil {
+		errStr := fmt.Sprintf("DataTableWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+
+	chart := df.DataTable(cols...)
+	return chartResult("DataTableWrapper", chart)
+}
+
+// ScatterPlotWrapper is an exported function that wraps the ScatterPlot function.
+//
+//export ScatterPlotWrapper
+func ScatterPlotWrapper(dfJson *C.char, title *C.char, subtitle *C.char, xcol *C.char, ycol *C.char, groupcol *C.char, optsJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("ScatterPlotWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("ScatterPlotWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	cols := []string{C.GoString(xcol), C.GoString(ycol)}
+	if g := C.GoString(groupcol); g != "" {
+		cols = append(cols, g)
+	}
+	if err := columnsError(&df, cols...); err != nil {
+		return errorResult(fmt.Sprintf("ScatterPlotWrapper: %v", err))
+	}
+	chart := df.ScatterPlot(C.GoString(title), C.GoString(subtitle), C.GoString(xcol), C.GoString(ycol), C.GoString(groupcol), opts)
+	return chartResult("ScatterPlotWrapper", chart)
+}
 
-// TreeMap
+// BubbleChartWrapper is an exported function that wraps the BubbleChart function.
+//
+//export BubbleChartWrapper
+func BubbleChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, xcol *C.char, ycol *C.char, sizecol *C.char, groupcol *C.char, optsJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("BubbleChartWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
 
-// LineChart
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("BubbleChartWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	cols := []string{C.GoString(xcol), C.GoString(ycol), C.GoString(sizecol)}
+	if g := C.GoString(groupcol); g != "" {
+		cols = append(cols, g)
+	}
+	if err := columnsError(&df, cols...); err != nil {
+		return errorResult(fmt.Sprintf("BubbleChartWrapper: %v", err))
+	}
+	chart := df.BubbleChart(C.GoString(title), C.GoString(subtitle), C.GoString(xcol), C.GoString(ycol), C.GoString(sizecol), C.GoString(groupcol), opts)
+	return chartResult("BubbleChartWrapper", chart)
+}
+
+// TreeMapWrapper is an exported function that wraps the TreeMap function.
+// groupcolsJson is a JSON array of the hierarchy columns, outermost first.
+//
+//export TreeMapWrapper
+func TreeMapWrapper(dfJson *C.char, title *C.char, subtitle *C.char, groupcolsJson *C.char, aggJson *C.char, optsJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("TreeMapWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+	var groupcols []string
+	if err := json.Unmarshal([]byte(C.GoString(groupcolsJson)), &groupcols); err != nil {
+		errStr := fmt.Sprintf("TreeMapWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+	agg, err := chartAggregation(C.GoString(aggJson))
+	if err != nil {
+		errStr := fmt.Sprintf("TreeMapWrapper: %v", err)
+		return errorResult(errStr)
+	}
+
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("TreeMapWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	chart := df.TreeMap(C.GoString(title), C.GoString(subtitle), groupcols, agg, opts)
+	return chartResult("TreeMapWrapper", chart)
+}
+
+// LineChartWrapper is an exported function that wraps the LineChart function.
+// ycolsJson is a JSON array of the y column names.
+//
+//export LineChartWrapper
+func LineChartWrapper(dfJson *C.char, title *C.char, subtitle *C.char, xcol *C.char, ycolsJson *C.char, optsJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("LineChartWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+	var ycols []string
+	if err := json.Unmarshal([]byte(C.GoString(ycolsJson)), &ycols); err != nil {
+		errStr := fmt.Sprintf("LineChartWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("LineChartWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	if err := columnsError(&df, append([]string{C.GoString(xcol)}, ycols...)...); err != nil {
+		return errorResult(fmt.Sprintf("LineChartWrapper: %v", err))
+	}
+	chart := df.LineChart(C.GoString(title), C.GoString(subtitle), C.GoString(xcol), ycols, opts)
+	return chartResult("LineChartWrapper", chart)
+}
+
+// HistogramWrapper is an exported function that wraps the Histogram function.
+//
+//export HistogramWrapper
+func HistogramWrapper(dfJson *C.char, col *C.char, bins C.int, optsJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("HistogramWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("HistogramWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	chart := df.Histogram(C.GoString(col), int(bins), opts)
+	return chartResult("HistogramWrapper", chart)
+}
+
+// BoxPlotWrapper is an exported function that wraps the BoxPlot function.
+//
+//export BoxPlotWrapper
+func BoxPlotWrapper(dfJson *C.char, valueCol *C.char, groupCol *C.char, optsJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("BoxPlotWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("BoxPlotWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	chart := df.BoxPlot(C.GoString(valueCol), C.GoString(groupCol), opts)
+	return chartResult("BoxPlotWrapper", chart)
+}
+
+// HeatmapWrapper is an exported function that wraps the Heatmap function.
+// The aggregation is a JSON object such as {"ColumnName": "sales", "Fn": "Sum"}.
+//
+//export HeatmapWrapper
+func HeatmapWrapper(dfJson *C.char, xCol *C.char, yCol *C.char, valueCol *C.char, aggJson *C.char, optsJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("HeatmapWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+	agg, err := chartAggregation(C.GoString(aggJson))
+	if err != nil {
+		errStr := fmt.Sprintf("HeatmapWrapper: %v", err)
+		return errorResult(errStr)
+	}
+
+	opts, err := chartOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("HeatmapWrapper: %v", err)
+		return errorResult(errStr)
+	}
+	chart := df.Heatmap(C.GoString(xCol), C.GoString(yCol), C.GoString(valueCol), agg, opts)
+	return chartResult("HeatmapWrapper", chart)
+}
+
+// ChartSVGWrapper renders a chart (as returned by the chart wrappers) to SVG.
+// It returns the SVG document or {"error": ...} when the chart has no chart config.
+//
+//export ChartSVGWrapper
+func ChartSVGWrapper(chartJson *C.char) *C.char {
+	var chart Chart
+	if err := json.Unmarshal([]byte(C.GoString(chartJson)), &chart); err != nil {
+		return errorResult(fmt.Sprintf("ChartSVGWrapper: unmarshal error: %v", err))
+	}
+	svg, err := chart.SVG()
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return C.CString(svg)
+}
+
+// chartOptionsArg decodes the optional ChartOptions JSON passed to the chart wrappers.
+func chartOptionsArg(optsJson *C.char) (g.ChartOptions, error) {
+	var opts g.ChartOptions
+	if s := C.GoString(optsJson); s != "" && s != "null" {
+		if err := json.Unmarshal([]byte(s), &opts); err != nil {
+			return opts, fmt.Errorf("ChartOptions: unmarshal error: %v", err)
+		}
+	}
+	return opts, nil
+}
+
+// saveOptionsArg decodes an optional SaveOptions JSON object; "" or "null" means defaults.
+func saveOptionsArg(optsJson *C.char) (g.SaveOptions, error) {
+	var opts g.SaveOptions
+	if s := C.GoString(optsJson); s != "" && s != "null" {
+		if err := json.Unmarshal([]byte(s), &opts); err != nil {
+			return opts, fmt.Errorf("SaveOptions: unmarshal error: %v", err)
+		}
+	}
+	return opts, nil
+}
+
+// chartAggregation decodes a single {"ColumnName", "Fn"} aggregation for the chart wrappers.
+func chartAggregation(aggJson string) (Aggregation, error) {
+	var spec struct {
+		ColumnName string
+		Fn         string
+	}
+	if err := json.Unmarshal([]byte(aggJson), &spec); err != nil {
+		return Aggregation{}, fmt.Errorf("unmarshal error: %v", err)
+	}
+	agg, ok := aggregationFromName(spec.Fn, spec.ColumnName)
+	if !ok {
+		return Aggregation{}, fmt.Errorf("unsupported aggregation %q", spec.Fn)
+	}
+	return agg, nil
+}
+
+// columnsError reports the first of cols that is not a column of df.
+func columnsError(df *DataFrame, cols ...string) error {
+	for _, c := range cols {
+		if _, ok := df.Data[c]; !ok {
+			return fmt.Errorf("column %q does not exist", c)
+		}
+	}
+	return nil
+}
+
+// chartResult marshals a chart for the Python Chart object.
+func chartResult(op string, chart Chart) *C.char {
+	chartJson, err := json.Marshal(chart)
+	if err != nil {
+		errStr := fmt.Sprintf("%s: marshal error: %v", op, err)
+		return errorResult(errStr)
+	}
+	return C.CString(string(chartJson))
+}
 
 // MixedChart (Column + Line)
 
@@ -850,8 +1152,7 @@ func CreateReportWrapper(title *C.char) *C.char {
 	// fmt.Printf("printing stringed reportJson:%s", reportJson)
 	if err != nil {
 		errStr := fmt.Sprintf("CreateReportWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	reportJsonStr := string(reportJson)
 	// fmt.Println("CreateReportWrapper: Created report JSON:", reportJsonStr)
@@ -859,6 +1160,92 @@ func CreateReportWrapper(title *C.char) *C.char {
 	return C.CString(reportJsonStr)
 }
 
+// ReadReportSpecWrapper parses a JSON or YAML report spec (path or string) and
+// returns it as JSON, or {"error": ...} when it is invalid.
+//
+//export ReadReportSpecWrapper
+func ReadReportSpecWrapper(input *C.char) *C.char {
+	spec, err := g.ReadReportSpec(C.GoString(input))
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return C.CString(spec.ToJSON())
+}
+
+// ReportSpecToYAMLWrapper converts a JSON report spec to YAML.
+//
+//export ReportSpecToYAMLWrapper
+func ReportSpecToYAMLWrapper(specJson *C.char) *C.char {
+	spec, err := g.ReadReportSpec(C.GoString(specJson))
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return C.CString(spec.ToYAML())
+}
+
+// ReportSpecWrapper returns {"spec": ..., "datasets": {name: DataFrame}} for a
+// report JSON, or {"error": ...} when it does not parse.
+//
+//export ReportSpecWrapper
+func ReportSpecWrapper(reportJson *C.char) *C.char {
+	var report Report
+	if err := json.Unmarshal([]byte(C.GoString(reportJson)), &report); err != nil {
+		return errorResult(fmt.Sprintf("ReportSpecWrapper: unmarshal error: %v", err))
+	}
+	spec, datasets := report.Spec()
+	out, err := json.Marshal(map[string]interface{}{"spec": spec, "datasets": datasets})
+	if err != nil {
+		return errorResult(fmt.Sprintf("ReportSpecWrapper: marshal error: %v", err))
+	}
+	return C.CString(string(out))
+}
+
+// RenderReportWrapper builds a report from a spec (JSON/YAML path or string) and
+// a JSON object mapping dataset names to DataFrames. It returns the report JSON,
+// or {"error": ...} when the spec does not match the datasets.
+//
+//export RenderReportWrapper
+func RenderReportWrapper(specInput *C.char, datasetsJson *C.char) *C.char {
+	spec, err := g.ReadReportSpec(C.GoString(specInput))
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	var datasets map[string]*DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(datasetsJson)), &datasets); err != nil {
+		return errorResult(fmt.Sprintf("RenderReportWrapper: unmarshal error: %v", err))
+	}
+	report, err := g.RenderReport(spec, datasets)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	reportJson, err := json.Marshal(report)
+	if err != nil {
+		return errorResult(fmt.Sprintf("RenderReportWrapper: marshal error: %v", err))
+	}
+	return C.CString(string(reportJson))
+}
+
+// SQLWrapper runs a SELECT query over a JSON object mapping table names to
+// DataFrames. It returns the result DataFrame JSON, or {"error": ...} when the
+// query does not parse or does not match the tables.
+//
+//export SQLWrapper
+func SQLWrapper(query *C.char, tablesJson *C.char) *C.char {
+	var tables map[string]*DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(tablesJson)), &tables); err != nil {
+		return errorResult(fmt.Sprintf("SQLWrapper: unmarshal error: %v", err))
+	}
+	df, err := g.SQL(C.GoString(query), tables)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	dfJson, err := json.Marshal(df)
+	if err != nil {
+		return errorResult(fmt.Sprintf("SQLWrapper: marshal error: %v", err))
+	}
+	return C.CString(string(dfJson))
+}
+
 // OpenReportWrapper is an exported function that wraps the Open method.
 //
 //export OpenReportWrapper
@@ -866,15 +1253,13 @@ func OpenReportWrapper(reportJson *C.char) *C.char {
 	var report Report
 	if err := json.Unmarshal([]byte(C.GoString(reportJson)), &report); err != nil {
 		errStr := fmt.Sprintf("OpenReportWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	// fmt.Println("printing report:")
 	// fmt.Println(report)
 	if err := report.Open(); err != nil {
 		errStr := fmt.Sprintf("OpenReportWrapper: open error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString("success")
@@ -883,22 +1268,40 @@ func OpenReportWrapper(reportJson *C.char) *C.char {
 // SaveReportWrapper is an exported function that wraps the Save method.
 //
 //export SaveReportWrapper
-func SaveReportWrapper(reportJson *C.char, filename *C.char) *C.char {
+func SaveReportWrapper(reportJson *C.char, filename *C.char, optsJson *C.char) *C.char {
 	var report Report
 	if err := json.Unmarshal([]byte(C.GoString(reportJson)), &report); err != nil {
 		errStr := fmt.Sprintf("SaveReportWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
+	}
+	opts, err := saveOptionsArg(optsJson)
+	if err != nil {
+		errStr := fmt.Sprintf("SaveReportWrapper: %v", err)
+		return errorResult(errStr)
 	}
-	if err := report.Save(C.GoString(filename)); err != nil {
+	if err := report.Save(C.GoString(filename), opts); err != nil {
 		errStr := fmt.Sprintf("SaveReportWrapper: save error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString("success")
 }
 
+// SavePDFReportWrapper is an exported function that wraps the SavePDF method.
+// It returns "success" or {"error": ...}.
+//
+//export SavePDFReportWrapper
+func SavePDFReportWrapper(reportJson *C.char, filename *C.char) *C.char {
+	var report Report
+	if err := json.Unmarshal([]byte(C.GoString(reportJson)), &report); err != nil {
+		return errorResult(fmt.Sprintf("SavePDFReportWrapper: unmarshal error: %v", err))
+	}
+	if err := report.SavePDF(C.GoString(filename)); err != nil {
+		return errorResult(err.Error())
+	}
+	return C.CString("success")
+}
+
 // AddPageWrapper is an exported function that wraps the AddPage method.
 //
 //export AddPageWrapper
@@ -906,8 +1309,7 @@ func AddPageWrapper(reportJson *C.char, name *C.char) *C.char {
 	var report Report
 	if err := json.Unmarshal([]byte(C.GoString(reportJson)), &report); err != nil {
 		errStr := fmt.Sprintf("AddPageWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	// report.init() // Initialize the maps
 	report.AddPage(C.GoString(name))
@@ -915,8 +1317,7 @@ func AddPageWrapper(reportJson *C.char, name *C.char) *C.char {
 	reportJsonBytes, err := json.Marshal(report)
 	if err != nil {
 		errStr := fmt.Sprintf("AddPageWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	// fmt.Println("AddPageWrapper: Updated report JSON:", string(reportJsonBytes))
@@ -930,16 +1331,14 @@ func AddHTMLWrapper(reportJson *C.char, page *C.char, text *C.char) *C.char {
 	var report Report
 	if err := json.Unmarshal([]byte(C.GoString(reportJson)), &report); err != nil {
 		errStr := fmt.Sprintf("AddHTMLWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	// report.init() // Initialize the maps
 	report.AddHTML(C.GoString(page), C.GoString(text))
 	reportJsonBytes, err := json.Marshal(report)
 	if err != nil {
 		errStr := fmt.Sprintf("AddHTMLWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(reportJsonBytes))
@@ -952,23 +1351,20 @@ func AddDataframeWrapper(reportJson *C.char, page *C.char, dfJson *C.char) *C.ch
 	var report Report
 	if err := json.Unmarshal([]byte(C.GoString(reportJson)), &report); err != nil {
 		errStr := fmt.Sprintf("AddDataframeWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("AddDataframeWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	// report.init() // Initialize the maps
 	report.AddDataframe(C.GoString(page), &df)
 	reportJsonBytes, err := json.Marshal(report)
 	if err != nil {
 		errStr := fmt.Sprintf("AddDataframeWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(reportJsonBytes))
@@ -987,8 +1383,7 @@ func AddChartWrapper(reportJson *C.char, page *C.char, chartJson *C.char) *C.cha
 	var chart Chart
 	if err := json.Unmarshal([]byte(C.GoString(chartJson)), &chart); err != nil {
 		errStr := fmt.Sprintf("AddChartWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	// report.init() // Initialize the maps
 	// fmt.Println("adding chart to page...")
@@ -999,8 +1394,7 @@ func AddChartWrapper(reportJson *C.char, page *C.char, chartJson *C.char) *C.cha
 	reportJsonBytes, err := json.Marshal(report)
 	if err != nil {
 		errStr := fmt.Sprintf("AddChartWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(reportJsonBytes))
@@ -1011,16 +1405,37 @@ func AddHeadingWrapper(reportJson *C.char, page *C.char, heading *C.char, size C
 	var report Report
 	if err := json.Unmarshal([]byte(C.GoString(reportJson)), &report); err != nil {
 		errStr := fmt.Sprintf("AddHeadingWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	report.AddHeading(C.GoString(page), C.GoString(heading), int(size))
 	reportJsonBytes, err := json.Marshal(report)
 	if err != nil {
 		errStr := fmt.Sprintf("AddHeadingWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
+	}
+
+	return C.CString(string(reportJsonBytes))
+}
+
+// AddFilterWrapper is an exported function that wraps the AddFilter method.
+// It returns the updated report JSON, or {"error": ...} when the page or kind is invalid.
+//
+//export AddFilterWrapper
+func AddFilterWrapper(reportJson *C.char, page *C.char, column *C.char, kind *C.char) *C.char {
+	var report Report
+	if err := json.Unmarshal([]byte(C.GoString(reportJson)), &report); err != nil {
+		errStr := fmt.Sprintf("AddFilterWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+
+	if err := report.AddFilter(C.GoString(page), C.GoString(column), C.GoString(kind)); err != nil {
+		return errorResult(err.Error())
+	}
+	reportJsonBytes, err := json.Marshal(report)
+	if err != nil {
+		errStr := fmt.Sprintf("AddFilterWrapper: marshal error: %v", err)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(reportJsonBytes))
@@ -1033,16 +1448,14 @@ func AddTextWrapper(reportJson *C.char, page *C.char, text *C.char) *C.char {
 	var report Report
 	if err := json.Unmarshal([]byte(C.GoString(reportJson)), &report); err != nil {
 		errStr := fmt.Sprintf("AddTextWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	// report.init() // Initialize the maps
 	report.AddText(C.GoString(page), C.GoString(text))
 	reportJsonBytes, err := json.Marshal(report)
 	if err != nil {
 		errStr := fmt.Sprintf("AddTextWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(reportJsonBytes))
@@ -1055,16 +1468,14 @@ func AddSubTextWrapper(reportJson *C.char, page *C.char, text *C.char) *C.char {
 	var report Report
 	if err := json.Unmarshal([]byte(C.GoString(reportJson)), &report); err != nil {
 		errStr := fmt.Sprintf("AddSubTextWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	// report.init() // Initialize the maps
 	report.AddSubText(C.GoString(page), C.GoString(text))
 	reportJsonBytes, err := json.Marshal(report)
 	if err != nil {
 		errStr := fmt.Sprintf("AddSubTextWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(reportJsonBytes))
@@ -1077,23 +1488,20 @@ func AddBulletsWrapper(reportJson *C.char, page *C.char, bulletsJson *C.char) *C
 	var report Report
 	if err := json.Unmarshal([]byte(C.GoString(reportJson)), &report); err != nil {
 		errStr := fmt.Sprintf("AddBulletsWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	var bullets []string
 	if err := json.Unmarshal([]byte(C.GoString(bulletsJson)), &bullets); err != nil {
 		errStr := fmt.Sprintf("AddBulletsWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	// report.init() // Initialize the maps
 	report.AddBullets(C.GoString(page), bullets...)
 	reportJsonBytes, err := json.Marshal(report)
 	if err != nil {
 		errStr := fmt.Sprintf("AddBulletsWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(reportJsonBytes))
@@ -1110,8 +1518,7 @@ func SumWrapper(name *C.char) *C.char {
 	aggJson, err := json.Marshal(map[string]string{"ColumnName": colName, "Fn": "Sum"})
 	if err != nil {
 		errStr := fmt.Sprintf("SumWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(aggJson))
 }
@@ -1123,8 +1530,7 @@ func AggWrapper(colsJson *C.char) *C.char {
 	var cols []Column
 	if err := json.Unmarshal([]byte(C.GoString(colsJson)), &cols); err != nil {
 		errStr := fmt.Sprintf("AggWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	// Convert []Column -> []interface{} for g.Agg(...interface{})
@@ -1144,8 +1550,7 @@ func AggWrapper(colsJson *C.char) *C.char {
 	aggsJson, err := json.Marshal(simpleAggs)
 	if err != nil {
 		errStr := fmt.Sprintf("AggWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(aggsJson))
@@ -1159,8 +1564,7 @@ func MaxWrapper(name *C.char) *C.char {
 	aggJson, err := json.Marshal(map[string]string{"ColumnName": agg.ColumnName, "Fn": "Max"})
 	if err != nil {
 		errStr := fmt.Sprintf("MaxWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(aggJson))
 }
@@ -1173,8 +1577,7 @@ func MinWrapper(name *C.char) *C.char {
 	aggJson, err := json.Marshal(map[string]string{"ColumnName": agg.ColumnName, "Fn": "Min"})
 	if err != nil {
 		errStr := fmt.Sprintf("MinWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(aggJson))
 }
@@ -1187,8 +1590,7 @@ func MedianWrapper(name *C.char) *C.char {
 	aggJson, err := json.Marshal(map[string]string{"ColumnName": agg.ColumnName, "Fn": "Median"})
 	if err != nil {
 		errStr := fmt.Sprintf("MedianWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(aggJson))
 }
@@ -1201,8 +1603,7 @@ func MeanWrapper(name *C.char) *C.char {
 	aggJson, err := json.Marshal(map[string]string{"ColumnName": agg.ColumnName, "Fn": "Mean"})
 	if err != nil {
 		errStr := fmt.Sprintf("MeanWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(aggJson))
 }
@@ -1215,8 +1616,7 @@ func ModeWrapper(name *C.char) *C.char {
 	aggJson, err := json.Marshal(map[string]string{"ColumnName": agg.ColumnName, "Fn": "Mode"})
 	if err != nil {
 		errStr := fmt.Sprintf("ModeWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(aggJson))
 }
@@ -1229,8 +1629,7 @@ func UniqueWrapper(name *C.char) *C.char {
 	aggJson, err := json.Marshal(map[string]string{"ColumnName": agg.ColumnName, "Fn": "Unique"})
 	if err != nil {
 		errStr := fmt.Sprintf("UniqueWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(aggJson))
 }
@@ -1243,8 +1642,7 @@ func FirstWrapper(name *C.char) *C.char {
 	aggJson, err := json.Marshal(map[string]string{"ColumnName": agg.ColumnName, "Fn": "First"})
 	if err != nil {
 		errStr := fmt.Sprintf("FirstWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(aggJson))
 }
@@ -1273,18 +1671,15 @@ func IfWrapper(conditionJson *C.char, fn1Json *C.char, fn2Json *C.char) *C.char
 	var condition, fn1, fn2 Column
 	if err := json.Unmarshal([]byte(C.GoString(conditionJson)), &condition); err != nil {
 		errStr := fmt.Sprintf("IfWrapper: unmarshal error for condition: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	if err := json.Unmarshal([]byte(C.GoString(fn1Json)), &fn1); err != nil {
 		errStr := fmt.Sprintf("IfWrapper: unmarshal error for fn1: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	if err := json.Unmarshal([]byte(C.GoString(fn2Json)), &fn2); err != nil {
 		errStr := fmt.Sprintf("IfWrapper: unmarshal error for fn2: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	result := g.If(condition, fn1, fn2)
@@ -1297,8 +1692,7 @@ func IfWrapper(conditionJson *C.char, fn1Json *C.char, fn2Json *C.char) *C.char
 	})
 	if err != nil {
 		errStr := fmt.Sprintf("IfWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(resultJson))
@@ -1314,11 +1708,11 @@ func IfWrapper(conditionJson *C.char, fn1Json *C.char, fn2Json *C.char) *C.char
 func LLMQueryWrapper(llmJson *C.char, dfJson *C.char, question *C.char) *C.char {
 	var llm LLM
 	if err := json.Unmarshal([]byte(C.GoString(llmJson)), &llm); err != nil {
-		return C.CString(fmt.Sprintf("error unmarshaling LLM: %v", err))
+		return errorResult(fmt.Sprintf("error unmarshaling LLM: %v", err))
 	}
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		return C.CString(fmt.Sprintf("error unmarshaling DataFrame: %v", err))
+		return errorResult(fmt.Sprintf("error unmarshaling DataFrame: %v", err))
 	}
 	result := llm.Query(&df, C.GoString(question))
 	return C.CString(result)
@@ -1328,18 +1722,18 @@ func LLMQueryWrapper(llmJson *C.char, dfJson *C.char, question *C.char) *C.char
 func ColumnWrapper(dfJson *C.char, newCol *C.char, colSpecJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		log.Fatalf("Error unmarshalling DataFrame JSON in ColumnOp: %v", err)
+		return errorResult(fmt.Sprintf("Error unmarshalling DataFrame JSON in ColumnOp: %v", err))
 	}
 
 	var colSpec ColumnExpr
 	if err := json.Unmarshal([]byte(C.GoString(colSpecJson)), &colSpec); err != nil {
-		log.Fatalf("Error unmarshalling ColumnExpr JSON in ColumnOp: %v", err)
+		return errorResult(fmt.Sprintf("Error unmarshalling ColumnExpr JSON in ColumnOp: %v", err))
 	}
 
 	newDF := df.Column(C.GoString(newCol), colSpec)
 	newJSON, err := json.Marshal(newDF)
 	if err != nil {
-		log.Fatalf("Error marshalling new DataFrame in ColumnOp: %v", err)
+		return errorResult(fmt.Sprintf("Error marshalling new DataFrame in ColumnOp: %v", err))
 	}
 	return C.CString(string(newJSON))
 }
@@ -1353,15 +1747,13 @@ func FilterWrapper(dfJson *C.char, conditionJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("FilterWrapper: unmarshal error (DataFrame): %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	var expr ColumnExpr
 	if err := json.Unmarshal([]byte(C.GoString(conditionJson)), &expr); err != nil {
 		errStr := fmt.Sprintf("FilterWrapper: unmarshal error (Condition ColumnExpr): %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	// Create a Column with the parsed ColumnExpr.
 
@@ -1369,8 +1761,7 @@ func FilterWrapper(dfJson *C.char, conditionJson *C.char) *C.char {
 	resultJson, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("FilterWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(resultJson))
@@ -1385,23 +1776,20 @@ func Explode(dfJson *C.char, colsJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("Explode: unmarshal error (DataFrame): %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	var cols []string
 	if err := json.Unmarshal([]byte(C.GoString(colsJson)), &cols); err != nil {
 		errStr := fmt.Sprintf("Explode: unmarshal error (columns): %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	newDF := df.Explode(cols...)
 	resultJson, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("Explode: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(resultJson))
@@ -1412,15 +1800,13 @@ func RenameWrapper(dfJson *C.char, oldCol *C.char, newCol *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("RenameWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	newDF := df.Rename(C.GoString(oldCol), C.GoString(newCol))
 	resultJson, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("RenameWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(resultJson))
 }
@@ -1430,15 +1816,13 @@ func FillNAWrapper(dfJson *C.char, replacement *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("FillNAWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	newDF := df.FillNA(C.GoString(replacement))
 	resultJson, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("FillNAWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(resultJson))
 }
@@ -1448,15 +1832,13 @@ func DropNAWrapper(dfJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("DropNAWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	newDF := df.DropNA()
 	resultJson, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("DropNAWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(resultJson))
 }
@@ -1469,8 +1851,7 @@ func DropDuplicatesWrapper(dfJson *C.char, colsJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("DropDuplicatesWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	var cols []string
 	if err := json.Unmarshal([]byte(C.GoString(colsJson)), &cols); err != nil {
@@ -1481,12 +1862,51 @@ func DropDuplicatesWrapper(dfJson *C.char, colsJson *C.char) *C.char {
 	resultJson, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("DropDuplicatesWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(resultJson))
 }
 
+// DescribeWrapper accepts a JSON array of percentiles. If empty, the quartiles
+// are used.
+//
+//export DescribeWrapper
+func DescribeWrapper(dfJson *C.char, percentilesJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("DescribeWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+	var percentiles []float64
+	if err := json.Unmarshal([]byte(C.GoString(percentilesJson)), &percentiles); err != nil {
+		percentiles = nil
+	}
+	newDF := df.Describe(percentiles...)
+	resultJson, err := json.Marshal(newDF)
+	if err != nil {
+		errStr := fmt.Sprintf("DescribeWrapper: marshal error: %v", err)
+		return errorResult(errStr)
+	}
+	return C.CString(string(resultJson))
+}
+
+// ProfileWrapper returns the profile report of a DataFrame as report JSON.
+//
+//export ProfileWrapper
+func ProfileWrapper(dfJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("ProfileWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+	reportJson, err := json.Marshal(df.Profile())
+	if err != nil {
+		errStr := fmt.Sprintf("ProfileWrapper: marshal error: %v", err)
+		return errorResult(errStr)
+	}
+	return C.CString(string(reportJson))
+}
+
 // SelectWrapper is an exported function that wraps the Select method.
 // It takes a JSON-string representing the DataFrame and a JSON-string representing the column names.
 // It returns the resulting DataFrame as a JSON string.
@@ -1496,28 +1916,52 @@ func SelectWrapper(dfJson *C.char, colsJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("SelectWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	var selectedCols []string
 	if err := json.Unmarshal([]byte(C.GoString(colsJson)), &selectedCols); err != nil {
 		errStr := fmt.Sprintf("SelectWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	selectedDF := df.Select(selectedCols...)
 	resultJson, err := json.Marshal(selectedDF)
 	if err != nil {
 		errStr := fmt.Sprintf("SelectWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(resultJson))
 }
 
+// aggregationFromName maps an aggregation's JSON function name ("Sum", "Max", ...) to the Go aggregation.
+func aggregationFromName(fnName, colName string) (Aggregation, bool) {
+	switch fnName {
+	case "Sum":
+		return g.Sum(colName), true
+	case "Max":
+		return g.Max(colName), true
+	case "Min":
+		return g.Min(colName), true
+	case "Mean":
+		return g.Mean(colName), true
+	case "Median":
+		return g.Median(colName), true
+	case "Mode":
+		return g.Mode(colName), true
+	case "Unique":
+		return g.Unique(colName), true
+	case "First":
+		return g.First(colName), true
+	case "CollectList":
+		return g.CollectList(colName), true
+	case "CollectSet":
+		return g.CollectSet(colName), true
+	}
+	return Aggregation{}, false
+}
+
 // GroupByWrapper is an exported function that wraps the GroupBy method.
 // It takes a JSON-string representing the DataFrame, the group column, and a JSON-string representing the aggregations.
 // It returns the resulting DataFrame as a JSON string.
@@ -1527,43 +1971,20 @@ func GroupByWrapper(dfJson *C.char, groupCol *C.char, aggsJson *C.char) *C.char
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("GroupByWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	var aggCols []map[string]string
 	if err := json.Unmarshal([]byte(C.GoString(aggsJson)), &aggCols); err != nil {
 		errStr := fmt.Sprintf("GroupByWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	// Extract column names and function names from the aggregation JSON
 	var aggregations []Aggregation
 	for _, agg := range aggCols {
-		colName := agg["ColumnName"]
-		fnName := agg["Fn"]
-		switch fnName {
-		case "Sum":
-			aggregations = append(aggregations, g.Sum(colName))
-		case "Max":
-			aggregations = append(aggregations, g.Max(colName))
-		case "Min":
-			aggregations = append(aggregations, g.Min(colName))
-		case "Mean":
-			aggregations = append(aggregations, g.Mean(colName))
-		case "Median":
-			aggregations = append(aggregations, g.Median(colName))
-		case "Mode":
-			aggregations = append(aggregations, g.Mode(colName))
-		case "Unique":
-			aggregations = append(aggregations, g.Unique(colName))
-		case "First":
-			aggregations = append(aggregations, g.First(colName))
-		case "CollectList":
-			aggregations = append(aggregations, g.CollectList(colName))
-		case "CollectSet":
-			aggregations = append(aggregations, g.CollectSet(colName))
+		if a, ok := aggregationFromName(agg["Fn"], agg["ColumnName"]); ok {
+			aggregations = append(aggregations, a)
 		}
 	}
 
@@ -1571,34 +1992,135 @@ func GroupByWrapper(dfJson *C.char, groupCol *C.char, aggsJson *C.char) *C.char
 	resultJson, err := json.Marshal(groupedDF)
 	if err != nil {
 		errStr := fmt.Sprintf("GroupByWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(resultJson))
 }
 
+// joinKeysArg decodes a join key argument: a column name or a JSON array of names.
+func joinKeysArg(s string) interface{} {
+	var keys []string
+	if strings.HasPrefix(strings.TrimSpace(s), "[") && json.Unmarshal([]byte(s), &keys) == nil {
+		return keys
+	}
+	return s
+}
+
+// joinSuffixesArg decodes the optional JSON array of collision suffixes.
+func joinSuffixesArg(s string) []string {
+	var suffixes []string
+	if s != "" {
+		_ = json.Unmarshal([]byte(s), &suffixes)
+	}
+	return suffixes
+}
+
+// PivotWrapper reshapes a DataFrame to wide form.
+// indexJson is a JSON array of index columns; aggJson is an aggregation like {"ColumnName":"x","Fn":"Sum"}.
+//
+//export PivotWrapper
+func PivotWrapper(dfJson *C.char, indexJson *C.char, pivotCol *C.char, valueCol *C.char, aggJson *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("PivotWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+	var index []string
+	if err := json.Unmarshal([]byte(C.GoString(indexJson)), &index); err != nil {
+		errStr := fmt.Sprintf("PivotWrapper: unmarshal index error: %v", err)
+		return errorResult(errStr)
+	}
+	var aggSpec map[string]string
+	if err := json.Unmarshal([]byte(C.GoString(aggJson)), &aggSpec); err != nil {
+		errStr := fmt.Sprintf("PivotWrapper: unmarshal agg error: %v", err)
+		return errorResult(errStr)
+	}
+	agg, ok := aggregationFromName(aggSpec["Fn"], aggSpec["ColumnName"])
+	if !ok {
+		errStr := fmt.Sprintf("PivotWrapper: unknown aggregation %q", aggSpec["Fn"])
+		return errorResult(errStr)
+	}
+	resultJson, err := json.Marshal(df.Pivot(index, C.GoString(pivotCol), C.GoString(valueCol), agg))
+	if err != nil {
+		errStr := fmt.Sprintf("PivotWrapper: marshal error: %v", err)
+		return errorResult(errStr)
+	}
+	return C.CString(string(resultJson))
+}
+
+// UnpivotWrapper reshapes a DataFrame to long form; idColsJson and valueColsJson are JSON arrays.
+//
+//export UnpivotWrapper
+func UnpivotWrapper(dfJson *C.char, idColsJson *C.char, valueColsJson *C.char, varName *C.char, valueName *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		errStr := fmt.Sprintf("UnpivotWrapper: unmarshal error: %v", err)
+		return errorResult(errStr)
+	}
+	var idCols, valueCols []string
+	if err := json.Unmarshal([]byte(C.GoString(idColsJson)), &idCols); err != nil {
+		errStr := fmt.Sprintf("UnpivotWrapper: unmarshal id columns error: %v", err)
+		return errorResult(errStr)
+	}
+	if err := json.Unmarshal([]byte(C.GoString(valueColsJson)), &valueCols); err != nil {
+		errStr := fmt.Sprintf("UnpivotWrapper: unmarshal value columns error: %v", err)
+		return errorResult(errStr)
+	}
+	resultJson, err := json.Marshal(df.Unpivot(idCols, valueCols, C.GoString(varName), C.GoString(valueName)))
+	if err != nil {
+		errStr := fmt.Sprintf("UnpivotWrapper: marshal error: %v", err)
+		return errorResult(errStr)
+	}
+	return C.CString(string(resultJson))
+}
+
 // This wrapper accepts two DataFrame JSON strings and join parameters.
+// leftOn/rightOn are column names or JSON arrays of names; suffixes is a JSON array.
 //
 //export JoinWrapper
-func JoinWrapper(leftDfJson *C.char, rightDfJson *C.char, leftOn *C.char, rightOn *C.char, joinType *C.char) *C.char {
+func JoinWrapper(leftDfJson *C.char, rightDfJson *C.char, leftOn *C.char, rightOn *C.char, joinType *C.char, suffixes *C.char) *C.char {
 	var leftDf, rightDf DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(leftDfJson)), &leftDf); err != nil {
 		errStr := fmt.Sprintf("JoinWrapper: unmarshal leftDf error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	if err := json.Unmarshal([]byte(C.GoString(rightDfJson)), &rightDf); err != nil {
 		errStr := fmt.Sprintf("JoinWrapper: unmarshal rightDf error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
-	newDF := leftDf.Join(&rightDf, C.GoString(leftOn), C.GoString(rightOn), C.GoString(joinType))
+	newDF := leftDf.Join(&rightDf, joinKeysArg(C.GoString(leftOn)), joinKeysArg(C.GoString(rightOn)), C.GoString(joinType), joinSuffixesArg(C.GoString(suffixes))...)
 	resultJson, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("JoinWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
+	}
+	return C.CString(string(resultJson))
+}
+
+// JoinOnWrapper joins two DataFrames on a ColumnExpr predicate (non-equi joins).
+//
+//export JoinOnWrapper
+func JoinOnWrapper(leftDfJson *C.char, rightDfJson *C.char, exprJson *C.char, joinType *C.char, suffixes *C.char) *C.char {
+	var leftDf, rightDf DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(leftDfJson)), &leftDf); err != nil {
+		errStr := fmt.Sprintf("JoinOnWrapper: unmarshal leftDf error: %v", err)
+		return errorResult(errStr)
+	}
+	if err := json.Unmarshal([]byte(C.GoString(rightDfJson)), &rightDf); err != nil {
+		errStr := fmt.Sprintf("JoinOnWrapper: unmarshal rightDf error: %v", err)
+		return errorResult(errStr)
+	}
+	var on ColumnExpr
+	if err := json.Unmarshal([]byte(C.GoString(exprJson)), &on); err != nil {
+		errStr := fmt.Sprintf("JoinOnWrapper: unmarshal expr error: %v", err)
+		return errorResult(errStr)
+	}
+	newDF := leftDf.JoinOn(&rightDf, on, C.GoString(joinType), joinSuffixesArg(C.GoString(suffixes))...)
+	resultJson, err := json.Marshal(newDF)
+	if err != nil {
+		errStr := fmt.Sprintf("JoinOnWrapper: marshal error: %v", err)
+		return errorResult(errStr)
 	}
 	return C.CString(string(resultJson))
 }
@@ -1608,20 +2130,17 @@ func UnionWrapper(leftDfJson *C.char, rightDfJson *C.char) *C.char {
 	var leftDf, rightDf DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(leftDfJson)), &leftDf); err != nil {
 		errStr := fmt.Sprintf("UnionWrapper: unmarshal leftDf error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	if err := json.Unmarshal([]byte(C.GoString(rightDfJson)), &rightDf); err != nil {
 		errStr := fmt.Sprintf("UnionWrapper: unmarshal rightDf error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	newDF := leftDf.Union(&rightDf)
 	resultJson, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("UnionWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(resultJson))
 }
@@ -1631,21 +2150,18 @@ func DropWrapper(dfJson *C.char, colsJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("DropWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	var cols []string
 	if err := json.Unmarshal([]byte(C.GoString(colsJson)), &cols); err != nil {
 		errStr := fmt.Sprintf("DropWrapper: unmarshal columns error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	newDF := df.Drop(cols...)
 	resultJson, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("DropWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(resultJson))
 }
@@ -1655,8 +2171,7 @@ func OrderByWrapper(dfJson *C.char, column *C.char, asc *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("OrderByWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	// Interpret asc as a boolean. For example, pass "true" for ascending.
 	ascStr := strings.ToLower(C.GoString(asc))
@@ -1670,8 +2185,7 @@ func OrderByWrapper(dfJson *C.char, column *C.char, asc *C.char) *C.char {
 	resultJson, err := json.Marshal(newDF)
 	if err != nil {
 		errStr := fmt.Sprintf("OrderByWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 	return C.CString(string(resultJson))
 }
@@ -1684,8 +2198,7 @@ func SortWrapper(dfJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
 		errStr := fmt.Sprintf("SortWrapper: unmarshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	df.Sort() // sort columns alphabetically
@@ -1693,8 +2206,7 @@ func SortWrapper(dfJson *C.char) *C.char {
 	resultJson, err := json.Marshal(df)
 	if err != nil {
 		errStr := fmt.Sprintf("SortWrapper: marshal error: %v", err)
-		log.Fatal(errStr)
-		return C.CString(errStr)
+		return errorResult(errStr)
 	}
 
 	return C.CString(string(resultJson))
@@ -1710,12 +2222,12 @@ func SortWrapper(dfJson *C.char) *C.char {
 func ColumnsWrapper(dfJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		log.Fatalf("ColumnsWrapper: error unmarshalling DataFrame: %v", err)
+		return errorResult(fmt.Sprintf("ColumnsWrapper: error unmarshalling DataFrame: %v", err))
 	}
 	cols := df.Columns()
 	colsJSON, err := json.Marshal(cols)
 	if err != nil {
-		log.Fatalf("ColumnsWrapper: error marshalling columns: %v", err)
+		return errorResult(fmt.Sprintf("ColumnsWrapper: error marshalling columns: %v", err))
 	}
 	return C.CString(string(colsJSON))
 }
@@ -1726,7 +2238,7 @@ func ColumnsWrapper(dfJson *C.char) *C.char {
 func CountWrapper(dfJson *C.char) C.int {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		log.Fatalf("CountWrapper: error unmarshalling DataFrame: %v", err)
+		return -1
 	}
 	return C.int(df.Count())
 }
@@ -1738,7 +2250,7 @@ func CountWrapper(dfJson *C.char) C.int {
 func CountDuplicatesWrapper(dfJson *C.char, colsJson *C.char) C.int {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		log.Fatalf("CountDuplicatesWrapper: error unmarshalling DataFrame: %v", err)
+		return -1
 	}
 
 	var cols []string
@@ -1757,7 +2269,7 @@ func CountDuplicatesWrapper(dfJson *C.char, colsJson *C.char) C.int {
 func CountDistinctWrapper(dfJson *C.char, colsJson *C.char) C.int {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		log.Fatalf("CountDistinctWrapper: error unmarshalling DataFrame: %v", err)
+		return -1
 	}
 
 	var cols []string
@@ -1774,13 +2286,13 @@ func CountDistinctWrapper(dfJson *C.char, colsJson *C.char) C.int {
 func CollectWrapper(dfJson *C.char, colName *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		log.Fatalf("CollectWrapper: error unmarshalling DataFrame: %v", err)
+		return errorResult(fmt.Sprintf("CollectWrapper: error unmarshalling DataFrame: %v", err))
 	}
 	col := C.GoString(colName)
 	collected := df.Collect(col)
 	result, err := json.Marshal(collected)
 	if err != nil {
-		log.Fatalf("CollectWrapper: error marshalling collected values: %v", err)
+		return errorResult(fmt.Sprintf("CollectWrapper: error marshalling collected values: %v", err))
 	}
 	return C.CString(string(result))
 }
@@ -1790,14 +2302,48 @@ func CollectWrapper(dfJson *C.char, colName *C.char) *C.char {
 // SINKS --------------------------------------------------
 
 //export ToCSVFile
-func ToCSVFile(dfJson *C.char, filename *C.char) *C.char {
+func ToCSVFile(dfJson *C.char, filename *C.char, optsJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		log.Fatalf("ToCSVFile: unmarshal error: %v", err)
+		return errorResult(fmt.Sprintf("ToCSVFile: unmarshal error: %v", err))
 	}
-	err := df.ToCSVFile(C.GoString(filename))
+	opts, err := csvOptionsArg(optsJson)
 	if err != nil {
-		return C.CString(err.Error())
+		return errorResult(err.Error())
+	}
+	err = df.ToCSVFile(C.GoString(filename), opts)
+	if err != nil {
+		return errorResult(err.Error())
+	}
+	return C.CString("success")
+}
+
+// ToXLSXFile writes the DataFrame to a sheet of an Excel workbook, keeping its other sheets.
+// It returns "success" or {"error": ...}.
+//
+//export ToXLSXFile
+func ToXLSXFile(dfJson *C.char, filename *C.char, sheet *C.char) *C.char {
+	var df DataFrame
+	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
+		return errorResult(fmt.Sprintf("ToXLSXFile: unmarshal error: %v", err))
+	}
+	if err := df.ToXLSXFile(C.GoString(filename), C.GoString(sheet)); err != nil {
+		return errorResult(err.Error())
+	}
+	return C.CString("success")
+}
+
+// WriteXLSXWrapper writes a JSON array of {"name", "dataframe"} sheets to one
+// workbook. It returns "success" or {"error": ...}.
+//
+//export WriteXLSXWrapper
+func WriteXLSXWrapper(filename *C.char, sheetsJson *C.char) *C.char {
+	var sheets []g.XLSXSheet
+	if err := json.Unmarshal([]byte(C.GoString(sheetsJson)), &sheets); err != nil {
+		return errorResult(fmt.Sprintf("WriteXLSXWrapper: unmarshal error: %v", err))
+	}
+	if err := g.WriteXLSX(C.GoString(filename), sheets...); err != nil {
+		return errorResult(err.Error())
 	}
 	return C.CString("success")
 }
@@ -1806,7 +2352,7 @@ func ToCSVFile(dfJson *C.char, filename *C.char) *C.char {
 func ToJSON(dfJson *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		return C.CString(fmt.Sprintf("ToJSON: unmarshal error: %v", err))
+		return errorResult(fmt.Sprintf("ToJSON: unmarshal error: %v", err))
 	}
 	// rows-array JSON
 	return C.CString(df.ToJSON())
@@ -1816,11 +2362,11 @@ func ToJSON(dfJson *C.char) *C.char {
 func WriteSqlite(dbPath *C.char, table *C.char, dfJson *C.char, mode *C.char, keyColsJson *C.char, createIdx C.int) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		return C.CString(fmt.Sprintf("WriteSqlite: dataframe unmarshal error: %v", err))
+		return errorResult(fmt.Sprintf("WriteSqlite: dataframe unmarshal error: %v", err))
 	}
 	var keys []string
 	if err := json.Unmarshal([]byte(C.GoString(keyColsJson)), &keys); err != nil && len(C.GoString(keyColsJson)) > 0 {
-		return C.CString(fmt.Sprintf("WriteSqlite: key columns unmarshal error: %v", err))
+		return errorResult(fmt.Sprintf("WriteSqlite: key columns unmarshal error: %v", err))
 	}
 	err := df.WriteSqlite(
 		C.GoString(dbPath),
@@ -1830,7 +2376,7 @@ func WriteSqlite(dbPath *C.char, table *C.char, dfJson *C.char, mode *C.char, ke
 		createIdx != 0,
 	)
 	if err != nil {
-		return C.CString(err.Error())
+		return errorResult(err.Error())
 	}
 	return C.CString("success")
 }
@@ -1839,8 +2385,7 @@ func WriteSqlite(dbPath *C.char, table *C.char, dfJson *C.char, mode *C.char, ke
 func PostAPI(dfJson *C.char, endpoint *C.char, headers *C.char, queryParams *C.char) *C.char {
 	var df DataFrame
 	if err := json.Unmarshal([]byte(C.GoString(dfJson)), &df); err != nil {
-		errStr := fmt.Sprintf("PostAPI: dataframe unmarshal error: %v", err)
-		return C.CString(errStr)
+		return errorResult(fmt.Sprintf("PostAPI: dataframe unmarshal error: %v", err))
 	}
 	ep := C.GoString(endpoint)
 	hStr := C.GoString(headers)
@@ -1878,8 +2423,7 @@ func PostAPI(dfJson *C.char, endpoint *C.char, headers *C.char, queryParams *C.c
 	respBody, err := df.PostAPI(ep, h, qm)
 	if err != nil {
 		// Return raw body (may contain server error info) plus error note.
-		errWrap := fmt.Sprintf("error: %v\n%s", err, respBody)
-		return C.CString(errWrap)
+		return errorResult(fmt.Sprintf("%v\n%s", err, respBody))
 	}
 	return C.CString(respBody)
 }
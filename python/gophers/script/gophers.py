@@ -20,6 +20,7 @@ gophers.ReadHTML.restype = c_void_p
 gophers.ReadHTMLTop.restype = c_void_p
 gophers.ReadYAML.restype = c_void_p
 gophers.ReadParquet.restype = c_void_p
+gophers.ReadXLSX.restype = c_void_p
 gophers.GetAPI.restype = c_void_p
 gophers.Show.restype = c_void_p
 gophers.Head.restype = c_void_p
@@ -39,16 +40,31 @@ gophers.BarChartWrapper.restype = c_void_p
 gophers.ColumnChartWrapper.restype = c_void_p
 gophers.StackedBarChartWrapper.restype = c_void_p
 gophers.StackedPercentChartWrapper.restype = c_void_p
+gophers.PieChartWrapper.restype = c_void_p
+gophers.LineChartWrapper.restype = c_void_p
+gophers.AreaChartWrapper.restype = c_void_p
+gophers.ScatterPlotWrapper.restype = c_void_p
+gophers.BubbleChartWrapper.restype = c_void_p
+gophers.TreeMapWrapper.restype = c_void_p
+gophers.DataTableWrapper.restype = c_void_p
+gophers.HistogramWrapper.restype = c_void_p
+gophers.BoxPlotWrapper.restype = c_void_p
+gophers.HeatmapWrapper.restype = c_void_p
 gophers.Explode.restype = c_void_p
 gophers.FilterWrapper.restype = c_void_p
 gophers.SelectWrapper.restype = c_void_p
 gophers.UnionWrapper.restype = c_void_p
 gophers.JoinWrapper.restype = c_void_p
+gophers.JoinOnWrapper.restype = c_void_p
+gophers.PivotWrapper.restype = c_void_p
+gophers.UnpivotWrapper.restype = c_void_p
 gophers.SortWrapper.restype = c_void_p
 gophers.FilterWrapper.restype = c_void_p
 gophers.OrderByWrapper.restype = c_void_p
 gophers.DropWrapper.restype = c_void_p
 gophers.DropDuplicatesWrapper.restype = c_void_p
+gophers.DescribeWrapper.restype = c_void_p
+gophers.ProfileWrapper.restype = c_void_p
 gophers.DropNAWrapper.restype = c_void_p
 gophers.FillNAWrapper.restype = c_void_p
 gophers.RenameWrapper.restype = c_void_p
@@ -65,17 +81,27 @@ gophers.FirstWrapper.restype = c_void_p
 gophers.CollectListWrapper.restype = c_void_p
 gophers.CollectSetWrapper.restype = c_void_p
 gophers.CreateReportWrapper.restype = c_void_p
+gophers.ReadReportSpecWrapper.restype = c_void_p
+gophers.ReportSpecToYAMLWrapper.restype = c_void_p
+gophers.RenderReportWrapper.restype = c_void_p
+gophers.ReportSpecWrapper.restype = c_void_p
+gophers.SQLWrapper.restype = c_void_p
 gophers.OpenReportWrapper.restype = c_void_p
 gophers.SaveReportWrapper.restype = c_void_p
+gophers.SavePDFReportWrapper.restype = c_void_p
+gophers.ChartSVGWrapper.restype = c_void_p
 gophers.AddPageWrapper.restype = c_void_p
 gophers.AddHTMLWrapper.restype = c_void_p
 gophers.AddDataframeWrapper.restype = c_void_p
 gophers.AddChartWrapper.restype = c_void_p
 gophers.AddHeadingWrapper.restype = c_void_p
+gophers.AddFilterWrapper.restype = c_void_p
 gophers.AddTextWrapper.restype = c_void_p
 gophers.AddSubTextWrapper.restype = c_void_p
 gophers.AddBulletsWrapper.restype = c_void_p
 gophers.ToCSVFile.restype = c_void_p
+gophers.ToXLSXFile.restype = c_void_p
+gophers.WriteXLSXWrapper.restype = c_void_p
 gophers.ToJSON.restype = c_void_p
 gophers.Flatten.restype = c_void_p
 gophers.StringArrayConvert.restype = c_void_p
@@ -159,9 +185,19 @@ def _cstr(ptr_or_func, *args):
     if not ptr:
         return ""
     try:
-        return string_at(ptr).decode("utf-8", "replace")
+        result = string_at(ptr).decode("utf-8", "replace")
     finally:
         gophers.Free(ptr)
+    if result.startswith('{"error":'):
+        # Go wrappers report failures as {"error": ...} instead of exiting.
+        raise RuntimeError(json.loads(result)["error"])
+    return result
+
+def _count(n):
+    """Raise RuntimeError for the -1 a Go count wrapper returns on bad input."""
+    if n < 0:
+        raise RuntimeError("invalid DataFrame JSON")
+    return n
 
 class ColumnExpr:
     def __init__(self, expr):
@@ -172,34 +208,73 @@ class ColumnExpr:
 
     def Help(self):
         print("""Column Help:
+    Abs()
+    Add(other)
+    Ceil()
     Contains(substr)
     CurrentDate()
     CurrentTimestamp()
+    DateAdd(unit, n)
     DateDiff(endDate, startDate, format)
+    DateTrunc(unit)
+    Day()
+    DayOfWeek()
+    Div(other)
     EndsWith(suffix)
     Eq(other)
+    Exp()
+    Floor()
+    FormatDate(format)
     FromEpoch(format)
+    FromUTC(zone)
     Ge(other)
     Gt(other)
+    Hour()
     HtmlUnescape()
+    InitCap()
+    IsBetween(lower, upper)
+    IsIn(*values)
     IsNotNull()
     IsNull()
     Le(other)
+    Length()
+    Levenshtein(other)
     Like(pattern)
+    Log()
     Lower()
+    LPad(length, pad)
     Lt(other)
     LTrim()
+    Mod(other)
+    Month()
+    Mul(other)
     Ne(other)
     NotContains(substr)
     NotLike(pattern)
+    Pow(other)
+    Quarter()
+    Repeat(n)
     Replace(old, new)
+    Reverse()
+    Round(n)
+    RPad(length, pad)
     RTrim()
+    Soundex()
+    SplitPart(delimiter, part)
+    Sqrt()
     StartsWith(prefix)
+    Sub(other)
     Substr(start, length)
     Title()
+    ToDate(*formats)
     ToEpoch(format)
+    ToTimestamp(*formats)
+    ToUTC(zone)
+    Translate(from_chars, to_chars)
     Trim()
     Upper()
+    WeekOfYear()
+    Year()
 """)
         
     def __repr__(self):
@@ -311,12 +386,130 @@ class ColumnExpr:
             payload["pattern"] = field  # optional field name
         return ColumnExpr(payload)
     
+    def Title(self):
+        return ColumnExpr({ "type": "title", "expr": self.expr })
 
-    # def Title(self):
-    #     return ColumnExpr({ "type": "title", "expr": self.expr })
-    
-    # def Substr(self, start, length):
-    #     return ColumnExpr({ "type": "substr", "expr": self.expr, "start": start, "length": length })
+    def InitCap(self):
+        return ColumnExpr({ "type": "initcap", "expr": self.expr })
+
+    def Substr(self, start: int, length: int):
+        # start is 1-based; a negative start counts from the end
+        return ColumnExpr({ "type": "substr", "expr": self.expr, "start": int(start), "length": int(length) })
+
+    def LPad(self, length: int, pad: str = " "):
+        return ColumnExpr({ "type": "lpad", "expr": self.expr, "length": int(length), "pad": pad })
+
+    def RPad(self, length: int, pad: str = " "):
+        return ColumnExpr({ "type": "rpad", "expr": self.expr, "length": int(length), "pad": pad })
+
+    def Reverse(self):
+        return ColumnExpr({ "type": "reverse", "expr": self.expr })
+
+    def Repeat(self, n: int):
+        return ColumnExpr({ "type": "repeat", "expr": self.expr, "count": int(n) })
+
+    def Translate(self, from_chars: str, to_chars: str):
+        return ColumnExpr({ "type": "translate", "expr": self.expr, "from": from_chars, "to": to_chars })
+
+    def Levenshtein(self, other):
+        return ColumnExpr({ "type": "levenshtein", "expr": self.expr, "right": self._unwrap(other) })
+
+    def Soundex(self):
+        return ColumnExpr({ "type": "soundex", "expr": self.expr })
+
+    def SplitPart(self, delimiter: str, part: int):
+        return ColumnExpr({ "type": "split_part", "expr": self.expr, "delimiter": delimiter, "part": int(part) })
+
+    def IsIn(self, *values):
+        if len(values) == 1 and isinstance(values[0], (list, tuple, set)):
+            values = tuple(values[0])
+        return ColumnExpr({ "type": "isin", "expr": self.expr, "cols": [self._unwrap(v) for v in values] })
+
+    def IsBetween(self, lower, upper):
+        return ColumnExpr({ "type": "isbetween", "expr": self.expr, "left": self._unwrap(lower), "right": self._unwrap(upper) })
+
+    # Math: null or non-numeric operands give None; two ints stay int, Div is always float
+    def Add(self, other):
+        return ColumnExpr({ "type": "add", "left": self.expr, "right": self._unwrap(other) })
+
+    def Sub(self, other):
+        return ColumnExpr({ "type": "sub", "left": self.expr, "right": self._unwrap(other) })
+
+    def Mul(self, other):
+        return ColumnExpr({ "type": "mul", "left": self.expr, "right": self._unwrap(other) })
+
+    def Div(self, other):
+        return ColumnExpr({ "type": "div", "left": self.expr, "right": self._unwrap(other) })
+
+    def Mod(self, other):
+        return ColumnExpr({ "type": "mod", "left": self.expr, "right": self._unwrap(other) })
+
+    def Pow(self, other):
+        return ColumnExpr({ "type": "pow", "left": self.expr, "right": self._unwrap(other) })
+
+    def Abs(self):
+        return ColumnExpr({ "type": "abs", "expr": self.expr })
+
+    def Round(self, n: int = 0):
+        return ColumnExpr({ "type": "round", "expr": self.expr, "index": int(n) })
+
+    def Floor(self):
+        return ColumnExpr({ "type": "floor", "expr": self.expr })
+
+    def Ceil(self):
+        return ColumnExpr({ "type": "ceil", "expr": self.expr })
+
+    def Sqrt(self):
+        return ColumnExpr({ "type": "sqrt", "expr": self.expr })
+
+    def Log(self):
+        return ColumnExpr({ "type": "log", "expr": self.expr })
+
+    def Exp(self):
+        return ColumnExpr({ "type": "exp", "expr": self.expr })
+
+    # Dates: results are timestamps (or ints for the parts); values that don't parse give None
+    def ToTimestamp(self, *formats):
+        return ColumnExpr({ "type": "to_timestamp", "expr": self.expr, "formats": list(formats) })
+
+    def ToDate(self, *formats):
+        return ColumnExpr({ "type": "to_date", "expr": self.expr, "formats": list(formats) })
+
+    def DateTrunc(self, unit: str):
+        return ColumnExpr({ "type": "date_trunc", "expr": self.expr, "unit": unit })
+
+    def DateAdd(self, unit: str, n: int):
+        return ColumnExpr({ "type": "date_add", "expr": self.expr, "unit": unit, "count": int(n) })
+
+    def Year(self):
+        return ColumnExpr({ "type": "year", "expr": self.expr })
+
+    def Quarter(self):
+        return ColumnExpr({ "type": "quarter", "expr": self.expr })
+
+    def Month(self):
+        return ColumnExpr({ "type": "month", "expr": self.expr })
+
+    def Day(self):
+        return ColumnExpr({ "type": "day", "expr": self.expr })
+
+    def DayOfWeek(self):
+        return ColumnExpr({ "type": "dayofweek", "expr": self.expr })
+
+    def WeekOfYear(self):
+        return ColumnExpr({ "type": "weekofyear", "expr": self.expr })
+
+    def Hour(self):
+        return ColumnExpr({ "type": "hour", "expr": self.expr })
+
+    def FromUTC(self, zone: str):
+        return ColumnExpr({ "type": "from_utc", "expr": self.expr, "zone": zone })
+
+    def ToUTC(self, zone: str):
+        return ColumnExpr({ "type": "to_utc", "expr": self.expr, "zone": zone })
+
+    def FormatDate(self, format: str):
+        return ColumnExpr({ "type": "format_date", "expr": self.expr, "format": format })
     
     def _unwrap(self, v):
         # Always return a ColumnExpr JSON object
@@ -412,6 +605,16 @@ class Chart:
     def __init__(self, html):
         self.html = html
 
+    def SVG(self):
+        """Renders the chart as standalone SVG (no JavaScript needed)."""
+        svg = _cstr(gophers.ChartSVGWrapper, self.html.encode('utf-8'))
+        return svg
+
+    def SaveSVG(self, filename):
+        with open(filename, "w", encoding="utf-8") as f:
+            f.write(self.SVG())
+        return self
+
 # Report + Methods
 class Report:
     def __init__(self, report_json):
@@ -423,6 +626,7 @@ class Report:
     AddBullets(page, bullets)
     AddChart(page, chart)
     AddDataframe(page, df)
+    AddFilter(page, column, kind)
     AddHeading(page, text, size)
     AddHTML(page, text)
     AddPage(name)
@@ -437,7 +641,9 @@ class Report:
     Success(color)
     Warning(color)
     Open()
-    Save(filename)""")
+    Save(filename, options)
+    SavePDF(filename)
+    Spec()""")
         
     def Accent(self, color):
         result = _cstr(gophers.Accent(self.report_json.encode('utf-8'), color.encode('utf-8')))
@@ -529,12 +735,25 @@ class Report:
             print("Error opening report:", err)
         return self
 
-    def Save(self, filename):
-        err = _cstr(gophers.SaveReportWrapper(self.report_json.encode('utf-8'), filename.encode('utf-8')))
+    def Save(self, filename, options=None):
+        """options: optional dict, e.g. {"svg": True, "offline": True}"""
+        err = _cstr(gophers.SaveReportWrapper(self.report_json.encode('utf-8'), filename.encode('utf-8'), json.dumps(options or {}).encode('utf-8')))
         if err:
             print("Error saving report:", err)
         return self
 
+    def SavePDF(self, filename):
+        """Writes the report as an A4 PDF with its headings, text, tables and charts."""
+        result = _cstr(gophers.SavePDFReportWrapper(self.report_json.encode('utf-8'), filename.encode('utf-8')))
+        return self
+
+    def Spec(self):
+        """Returns (spec dict, {name: DataFrame}); RenderReport(spec, datasets) rebuilds the report."""
+        result = _cstr(gophers.ReportSpecWrapper(self.report_json.encode('utf-8')))
+        out = json.loads(result)
+        datasets = {name: DataFrame(json.dumps(df)) for name, df in out["datasets"].items()}
+        return out["spec"], datasets
+
     def AddPage(self, name):
         result = _cstr(gophers.AddPageWrapper(self.report_json.encode('utf-8'), name.encode('utf-8')))
         if result:
@@ -576,6 +795,12 @@ class Report:
         else:
             print(f"Error adding chart, empty result")
         return self
+    def AddFilter(self, page, column, kind):
+        """kind: "select", "multiselect", "range" or "daterange"; re-filters the page's charts in the browser"""
+        result = _cstr(gophers.AddFilterWrapper(self.report_json.encode('utf-8'), page.encode('utf-8'), column.encode('utf-8'), kind.encode('utf-8')))
+        self.report_json = result
+        return self
+
     def AddHeading(self, page, text, size):
         result = _cstr(gophers.AddHeadingWrapper(self.report_json.encode('utf-8'), page.encode('utf-8'), text.encode('utf-8'), size))
         if result:
@@ -618,77 +843,90 @@ def Help():
     CollectList(col_name)
     CollectSet(col_name)
     Concat(delimiter, *cols)
+    DenseRank()
     DisplayChart(chart)
     DisplayHTML(html)
     GetAPI(endpoint, headers, query_params)
     GetSqliteSchema(db_path, table),
     GetSqliteTables(db_path),
     If(condition, trueExpr, falseExpr)
+    Lag(col_name, offset, default)
+    Lead(col_name, offset, default)
     Lit(value)
     Or(left, right)
-    ReadCSV(csv_data)
+    Over(agg, window)
+    Rank()
+    ReadCSV(csv_data, options)
     ReadHTML(html_input)
     ReadJSON(json_data)
     ReadNDJSON(json_data)
     ReadSqlite(db_path, table, query)
     ReadYAML(yaml_data)
     ReadParquet(parquet_input)
+    ReadReportSpec(input)
+    ReadXLSX(xlsx_input, sheet)
+    RenderReport(spec, datasets)
+    ReportSpecToYAML(spec)
+    RowNumber()
     SHA256(*cols)
     SHA512(*cols)
+    SQL(query, tables)
     Split(col_name, delimiter)
     Sum(column_name)
     UDF(new_col, input_col, fn)
+    Window()
+    WriteXLSX(filename, sheets)
 """)
 
     
 # Aggregate functions
 def Sum(column_name):
     # Call the Go SumWrapper function with only the column name
-    sum_agg_json = gophers.SumWrapper(column_name.encode('utf-8')).decode('utf-8')
+    sum_agg_json = _cstr(gophers.SumWrapper(column_name.encode('utf-8')))
     # Parse the JSON string into a Python dict before returning it
     return json.loads(sum_agg_json)
 def Max(column_name):
     # Call the Go SumWrapper function with only the column name
-    sum_agg_json = gophers.MaxWrapper(column_name.encode('utf-8')).decode('utf-8')
+    sum_agg_json = _cstr(gophers.MaxWrapper(column_name.encode('utf-8')))
     # Parse the JSON string into a Python dict before returning it
     return json.loads(sum_agg_json)
 def Min(column_name):
     # Call the Go SumWrapper function with only the column name
-    sum_agg_json = gophers.MinWrapper(column_name.encode('utf-8')).decode('utf-8')
+    sum_agg_json = _cstr(gophers.MinWrapper(column_name.encode('utf-8')))
     # Parse the JSON string into a Python dict before returning it
     return json.loads(sum_agg_json)
 def Median(column_name):
     # Call the Go SumWrapper function with only the column name
-    sum_agg_json = gophers.MedianWrapper(column_name.encode('utf-8')).decode('utf-8')
+    sum_agg_json = _cstr(gophers.MedianWrapper(column_name.encode('utf-8')))
     # Parse the JSON string into a Python dict before returning it
     return json.loads(sum_agg_json)
 def Mean(column_name):
     # Call the Go SumWrapper function with only the column name
-    sum_agg_json = gophers.MeanWrapper(column_name.encode('utf-8')).decode('utf-8')
+    sum_agg_json = _cstr(gophers.MeanWrapper(column_name.encode('utf-8')))
     # Parse the JSON string into a Python dict before returning it
     return json.loads(sum_agg_json)
 def Mode(column_name):
     # Call the Go SumWrapper function with only the column name
-    sum_agg_json = gophers.ModeWrapper(column_name.encode('utf-8')).decode('utf-8')
+    sum_agg_json = _cstr(gophers.ModeWrapper(column_name.encode('utf-8')))
     # Parse the JSON string into a Python dict before returning it
     return json.loads(sum_agg_json)
 def First(column_name):
     # Call the Go SumWrapper function with only the column name
-    sum_agg_json = gophers.FirstWrapper(column_name.encode('utf-8')).decode('utf-8')
+    sum_agg_json = _cstr(gophers.FirstWrapper(column_name.encode('utf-8')))
     # Parse the JSON string into a Python dict before returning it
     return json.loads(sum_agg_json)
 def Unique(column_name):
     # Call the Go SumWrapper function with only the column name
-    sum_agg_json = gophers.UniqueWrapper(column_name.encode('utf-8')).decode('utf-8')
+    sum_agg_json = _cstr(gophers.UniqueWrapper(column_name.encode('utf-8')))
     # Parse the JSON string into a Python dict before returning it
     return json.loads(sum_agg_json)
 
 def CollectListAgg(column_name):
-    js = gophers.CollectListWrapper(column_name.encode('utf-8')).decode('utf-8')
+    js = _cstr(gophers.CollectListWrapper(column_name.encode('utf-8')))
     return json.loads(js)
 
 def CollectSetAgg(column_name):
-    js = gophers.CollectSetWrapper(column_name.encode('utf-8')).decode('utf-8')
+    js = _cstr(gophers.CollectSetWrapper(column_name.encode('utf-8')))
     return json.loads(js)
 
 # def Agg(*aggregations):
@@ -704,18 +942,18 @@ def Agg(*items):
             if t == "col":
                 name = it.expr.get("name", "")
                 if name:
-                    out.append(json.loads(gophers.FirstWrapper(name.encode('utf-8')).decode('utf-8')))
+                    out.append(json.loads(_cstr(gophers.FirstWrapper(name.encode('utf-8')))))
             elif t == "collectlist":
                 col = it.expr.get("col", "")
                 if col:
-                    out.append(json.loads(gophers.CollectListWrapper(col.encode('utf-8')).decode('utf-8')))
+                    out.append(json.loads(_cstr(gophers.CollectListWrapper(col.encode('utf-8')))))
             elif t == "collectset":
                 col = it.expr.get("col", "")
                 if col:
-                    out.append(json.loads(gophers.CollectSetWrapper(col.encode('utf-8')).decode('utf-8')))
+                    out.append(json.loads(_cstr(gophers.CollectSetWrapper(col.encode('utf-8')))))
         elif isinstance(it, str):
             # plain column name -> First
-            out.append(json.loads(gophers.FirstWrapper(it.encode('utf-8')).decode('utf-8')))
+            out.append(json.loads(_cstr(gophers.FirstWrapper(it.encode('utf-8')))))
         elif isinstance(it, (list, tuple)):
             for x in it:
                 out.extend(Agg(x))
@@ -765,6 +1003,18 @@ def And(*conds):
 def If(condition, trueExpr, falseExpr):
     return ColumnExpr({ "type": "if", "cond": json.loads(condition.to_json()), "true": json.loads(trueExpr.to_json()), "false": json.loads(falseExpr.to_json()) })
 
+def Greatest(*cols):
+    """Returns the largest non-null value of the given columns or literals."""
+    return ColumnExpr({ "type": "greatest", "cols": [(c if isinstance(c, ColumnExpr) else Lit(c)).expr for c in cols] })
+
+def Least(*cols):
+    """Returns the smallest non-null value of the given columns or literals."""
+    return ColumnExpr({ "type": "least", "cols": [(c if isinstance(c, ColumnExpr) else Lit(c)).expr for c in cols] })
+
+def Coalesce(*cols):
+    """Returns the first value of the given columns or literals that is not null."""
+    return ColumnExpr({ "type": "coalesce", "cols": [(c if isinstance(c, ColumnExpr) else Lit(c)).expr for c in cols] })
+
 # List functions
 def SHA256(*cols):
     return ColumnExpr({ "type": "sha256", "cols": [json.loads(col.to_json()) for col in cols] })
@@ -802,6 +1052,81 @@ def ArraysZip(*cols):
         "cols": [json.loads(col.to_json()) for col in cols]
     })
 
+# Window functions
+class WindowSpec:
+    """
+    Partitioning, ordering and frame of a window function.
+    Usage: Window().PartitionBy("store").OrderBy("day").RowsBetween(-6, 0)
+    """
+    def __init__(self, partition_by=None, order_by=None, frame=None):
+        self.partition_by = list(partition_by or [])
+        self.order_by = list(order_by or [])
+        self.frame = frame
+
+    def PartitionBy(self, *cols):
+        return WindowSpec(self.partition_by + list(cols), self.order_by, self.frame)
+
+    def OrderBy(self, col, asc=True):
+        return WindowSpec(self.partition_by, self.order_by + [{ "col": col, "asc": asc }], self.frame)
+
+    def RowsBetween(self, start=None, end=0):
+        # None means unbounded
+        return WindowSpec(self.partition_by, self.order_by, { "unit": "rows", "start": start, "end": end })
+
+    def RangeBetween(self, start=None, end=0):
+        # numbers, or durations such as "-7d" / "-12h" for date columns; None means unbounded
+        return WindowSpec(self.partition_by, self.order_by, { "unit": "range", "start": start, "end": end })
+
+class WindowFunc:
+    def __init__(self, func, col="", offset=None, default=None):
+        self.func = func
+        self.col = col
+        self.offset = offset
+        self.default = default
+
+    def Over(self, spec):
+        data = { "func": self.func }
+        if self.col:
+            data["col"] = self.col
+        if self.offset is not None:
+            data["offset"] = self.offset
+        if self.default is not None:
+            data["default"] = self.default
+        if spec.partition_by:
+            data["partition_by"] = spec.partition_by
+        if spec.order_by:
+            data["order_by"] = spec.order_by
+        if spec.frame is not None:
+            data["frame"] = spec.frame
+        return ColumnExpr({ "type": "window", "data": data })
+
+def Window():
+    return WindowSpec()
+
+def RowNumber():
+    return WindowFunc("row_number")
+
+def Rank():
+    return WindowFunc("rank")
+
+def DenseRank():
+    return WindowFunc("dense_rank")
+
+def Lag(col_name, offset=1, default=None):
+    return WindowFunc("lag", col_name, offset, default)
+
+def Lead(col_name, offset=1, default=None):
+    return WindowFunc("lead", col_name, offset, default)
+
+def Over(agg, spec):
+    """
+    Evaluates an aggregation such as Sum("amount") over a window.
+    Usage: df.Column("running", Over(Sum("amount"), Window().PartitionBy("id").OrderBy("day")))
+    """
+    if isinstance(agg, WindowFunc):
+        return agg.Over(spec)
+    return WindowFunc(agg["Fn"].lower(), agg["ColumnName"]).Over(spec)
+
 # def Keys(col_name):
 #     return ColumnExpr({ "type": "keys", "col": col_name })
 
@@ -826,20 +1151,31 @@ def ArraysZip(*cols):
 #     })
 
 # Source functions
+def _reader_result(df_json):
+    """Raise RuntimeError for an {"error": ...} reply from a Go reader."""
+    if df_json.startswith('{"error":'):
+        raise RuntimeError(json.loads(df_json)["error"])
+    return DataFrame(df_json)
+
 def ReadJSON(json_data):
     # Store the JSON representation of DataFrame from Go.
     df_json = _cstr(gophers.ReadJSON(json_data.encode('utf-8')))
-    return DataFrame(df_json)
+    return _reader_result(df_json)
 
 def ReadNDJSON(json_data):
     # Store the JSON representation of DataFrame from Go.
     df_json = _cstr(gophers.ReadNDJSON(json_data.encode('utf-8')))
-    return DataFrame(df_json)
+    return _reader_result(df_json)
 
-def ReadCSV(json_data):
+def ReadCSV(json_data, options=None):
+    """
+    options: optional dict of CSV options, e.g.
+    {"delimiter": ";", "no_header": True, "comment": "#", "null_values": ["NA", "\\N"],
+     "infer_types": True, "lazy_quotes": True, "encoding": "latin1"}
+    """
     # Store the JSON representation of DataFrame from Go.
-    df_json = _cstr(gophers.ReadCSV(json_data.encode('utf-8')))
-    return DataFrame(df_json)
+    df_json = _cstr(gophers.ReadCSV(json_data.encode('utf-8'), json.dumps(options or {}).encode('utf-8')))
+    return _reader_result(df_json)
 
 def ReadHTML(html_input):
     """
@@ -847,23 +1183,30 @@ def ReadHTML(html_input):
     Returns a DataFrame of HTML element nodes.
     """
     df_json = _cstr(gophers.ReadHTML, html_input.encode('utf-8'))
-    return DataFrame(df_json)
+    return _reader_result(df_json)
 
 def ReadHTMLTop(html_input):
     df_json = _cstr(gophers.ReadHTMLTop, html_input.encode('utf-8'))
-    return DataFrame(df_json)
+    return _reader_result(df_json)
 
 def ReadYAML(yaml_data):
     # Store the JSON representation of DataFrame from Go.
     df_json = _cstr(gophers.ReadYAML(yaml_data.encode('utf-8')))
-    return DataFrame(df_json)
+    return _reader_result(df_json)
 
 def ReadParquet(parquet_input):
     """
     parquet_input: path to a file or NDJSON fallback content (line-delimited JSON).
     """
     df_json = _cstr(gophers.ReadParquet(parquet_input.encode('utf-8')))
-    return DataFrame(df_json)
+    return _reader_result(df_json)
+
+def ReadXLSX(xlsx_input, sheet=""):
+    """
+    xlsx_input: path to an .xlsx file; sheet: sheet name ("" for the first sheet).
+    """
+    df_json = _cstr(gophers.ReadXLSX(xlsx_input.encode('utf-8'), sheet.encode('utf-8')))
+    return _reader_result(df_json)
 
 def GetAPI(endpoint, headers, query_params):
     # Store the JSON representation of DataFrame from Go.
@@ -884,7 +1227,7 @@ def ReadSqlite(db_path, table=None, query=None):
     t = "" if table is None else table
     q = "" if query is None else query
     df_json = _cstr(gophers.ReadSqlite(db_path.encode('utf-8'), t.encode('utf-8'), q.encode('utf-8')))
-    return DataFrame(df_json)
+    return _reader_result(df_json)
 
 def GetSqliteTables(db_path: str):
     """
@@ -921,14 +1264,14 @@ def SqliteSQL(db_path: str, sql_text: str):
         db_path.encode('utf-8'),
         sql_text.encode('utf-8')
     )
-    return DataFrame(df_json)
+    return _reader_result(df_json)
 
 # Display functions
 def DisplayHTML(html):
     display(HTML(html))
 
 def DisplayChart(chart):
-    html = gophers.DisplayChartWrapper(chart.html.encode('utf-8'))
+    html = _cstr(gophers.DisplayChartWrapper(chart.html.encode('utf-8')))
     display(HTML(html))
 
 # Report methods
@@ -937,6 +1280,35 @@ def CreateReport(title):
     # print("CreateReport: Created report JSON:", report_json)
     return Report(report_json)
 
+def ReadReportSpec(input):
+    """Reads a report spec (JSON or YAML, path or string) into a dict."""
+    result = _cstr(gophers.ReadReportSpecWrapper(input.encode('utf-8')))
+    return json.loads(result)
+
+def ReportSpecToYAML(spec):
+    """Returns a report spec dict as YAML."""
+    result = _cstr(gophers.ReportSpecToYAMLWrapper(json.dumps(spec).encode('utf-8')))
+    return result
+
+def RenderReport(spec, datasets):
+    """Builds a Report from a spec (dict, or JSON/YAML path or string) and a dict of named DataFrames."""
+    if isinstance(spec, dict):
+        spec = json.dumps(spec)
+    frames = {name: json.loads(df.df_json) for name, df in datasets.items()}
+    result = _cstr(gophers.RenderReportWrapper(spec.encode('utf-8'), json.dumps(frames).encode('utf-8')))
+    return Report(result)
+
+def SQL(query, tables):
+    """Runs a SELECT query over a dict of named DataFrames and returns the result DataFrame."""
+    frames = {name: json.loads(df.df_json) for name, df in tables.items()}
+    result = _cstr(gophers.SQLWrapper(query.encode('utf-8'), json.dumps(frames).encode('utf-8')))
+    return DataFrame(result)
+
+def WriteXLSX(filename, sheets):
+    """Writes a dict of sheet name -> DataFrame, in order, to one Excel workbook."""
+    payload = [{"name": name, "dataframe": json.loads(df.df_json)} for name, df in sheets.items()]
+    result = _cstr(gophers.WriteXLSXWrapper(filename.encode('utf-8'), json.dumps(payload).encode('utf-8')))
+
 def _udf_to_string(v):
     """Best-effort conversion to string (mirrors the Go UDF behavior)."""
     if v is None:
@@ -1006,19 +1378,24 @@ class DataFrame:
 
     def Help(self):
         print("""DataFrame Help:
-    BarChart(title, subtitle, groupcol, aggs)
+    AreaChart(title, subtitle, xcol, ycols, options)
+    BarChart(title, subtitle, groupcol, aggs, options)
+    BoxPlot(value_col, group_col, options)
+    BubbleChart(title, subtitle, xcol, ycol, sizecol, groupcol, options)
     Clone()
     Column(col_name, col_spec)
-    ColumnChart(title, subtitle, groupcol, aggs)
+    ColumnChart(title, subtitle, groupcol, aggs, options)
     Columns()
     Collect(col_name)
     Count()
     CountDistinct(cols)
     CountDuplicates(cols)
     CreateReport(title)
+    DataTable(*cols)
+    Describe(*percentiles)
     Display()
-    DisplayBrowser()
-    DisplayToFile(file_path)
+    DisplayBrowser(options)
+    DisplayToFile(file_path, options)
     Drop(*cols)
     DropDuplicates(cols)
     DropNA(cols)
@@ -1027,19 +1404,30 @@ class DataFrame:
     Flatten(*cols)
     GroupBy(groupCol, aggs)
     Head(chars)
-    Join(df2, col1, col2, how)
+    Heatmap(x_col, y_col, value_col, agg, options)
+    Histogram(col, bins, options)
+    Join(df2, col1, col2, how, suffixes)
+    JoinOn(df2, on, how, suffixes)
+    LineChart(title, subtitle, xcol, ycols, options)
     OrderBy(col, asc)
+    PieChart(title, subtitle, namecol, agg, options)
+    Pivot(index, pivot_col, value_col, agg)
     PostAPI(endpoint, headers, query_params)
+    Profile()
+    ScatterPlot(title, subtitle, xcol, ycol, groupcol, options)
     Select(*cols)
     Show(chars, record_count)
     Sort(*cols)
-    StackedBarChart(title, subtitle, groupcol, aggs)
-    StackedPercentChart(title, subtitle, groupcol, aggs)
+    StackedBarChart(title, subtitle, groupcol, aggs, options)
+    StackedPercentChart(title, subtitle, groupcol, aggs, options)
     StringArrayConvert(col_name)
     Tail(chars)
-    ToCSVFile(filename)
+    ToCSVFile(filename, options)
     ToJSON()
+    ToXLSXFile(filename, sheet)
+    TreeMap(title, subtitle, groupcols, agg, options)
     Union(df2)
+    Unpivot(id_cols, value_cols, var_name, value_name)
     Vertical(chars, record_count)
     WriteSqlite(db_path, table_name, mode, key_cols)""")
         
@@ -1054,23 +1442,33 @@ class DataFrame:
         return json.loads(cols_json)
 
     def Count(self):
-        return gophers.CountWrapper(self.df_json.encode('utf-8'))
+        return _count(gophers.CountWrapper(self.df_json.encode('utf-8')))
 
     def CountDuplicates(self, cols=None):
         if cols is None:
             cols_json = json.dumps([])
         else:
             cols_json = json.dumps(cols)
-        return gophers.CountDuplicatesWrapper(self.df_json.encode('utf-8'),
-                                              cols_json.encode('utf-8'))
+        return _count(gophers.CountDuplicatesWrapper(self.df_json.encode('utf-8'),
+                                                     cols_json.encode('utf-8')))
 
     def CountDistinct(self, cols=None):
         if cols is None:
             cols_json = json.dumps([])
         else:
             cols_json = json.dumps(cols)
-        return gophers.CountDistinctWrapper(self.df_json.encode('utf-8'),
-                                            cols_json.encode('utf-8'))
+        return _count(gophers.CountDistinctWrapper(self.df_json.encode('utf-8'),
+                                                   cols_json.encode('utf-8')))
+
+    def Describe(self, *percentiles):
+        """percentiles in [0, 1]; defaults to the quartiles"""
+        new_json = _cstr(gophers.DescribeWrapper(self.df_json.encode('utf-8'),
+                                                 json.dumps(list(percentiles)).encode('utf-8')))
+        return DataFrame(new_json)
+
+    def Profile(self):
+        """Report with an overview page and one page per column"""
+        return Report(_cstr(gophers.ProfileWrapper(self.df_json.encode('utf-8'))))
 
     def Collect(self, col_name):
         collected = _cstr(gophers.CollectWrapper(self.df_json.encode('utf-8'),
@@ -1092,8 +1490,9 @@ class DataFrame:
         print(result)
         return result
 
-    def DisplayBrowser(self):
-        err = _cstr(gophers.DisplayBrowserWrapper(self.df_json.encode('utf-8')))
+    def DisplayBrowser(self, options=None):
+        """options: optional dict, e.g. {"offline": True} to embed the CDN assets"""
+        err = _cstr(gophers.DisplayBrowserWrapper(self.df_json.encode('utf-8'), json.dumps(options or {}).encode('utf-8')))
         if err:
             print("Error displaying in browser:", err)
         return self
@@ -1104,26 +1503,28 @@ class DataFrame:
         display(HTML(html))
         # return self
     
-    def DisplayToFile(self, file_path):
-        err = gophers.DisplayToFileWrapper(self.df_json.encode('utf-8'), file_path.encode('utf-8')).decode('utf-8')
+    def DisplayToFile(self, file_path, options=None):
+        """options: optional dict, e.g. {"offline": True} to embed the CDN assets"""
+        err = _cstr(gophers.DisplayToFileWrapper(self.df_json.encode('utf-8'), file_path.encode('utf-8'), json.dumps(options or {}).encode('utf-8')))
         if err:
             print("Error writing to file:", err)
         return self
         
     # Chart methods
-    def BarChart(self, title, subtitle, groupcol, aggs):
+    def BarChart(self, title, subtitle, groupcol, aggs, options=None):
         # Make sure aggs is a list
         if not isinstance(aggs, list):
             aggs = [aggs]
         
         aggs_json = json.dumps(aggs)
-        html = gophers.BarChartWrapper(
+        html = _cstr(gophers.BarChartWrapper(
             self.df_json.encode('utf-8'), 
             title.encode('utf-8'), 
             subtitle.encode('utf-8'), 
             groupcol.encode('utf-8'), 
-            aggs_json.encode('utf-8')
-        ).decode('utf-8')
+            aggs_json.encode('utf-8'),
+            json.dumps(options or {}).encode('utf-8')
+        ))
         
         # Create a Chart object
         chart = Chart(html)
@@ -1135,19 +1536,20 @@ class DataFrame:
         # Return the Chart object
         return chart
     
-    def ColumnChart(self, title, subtitle, groupcol, aggs):
+    def ColumnChart(self, title, subtitle, groupcol, aggs, options=None):
         # Make sure aggs is a list
         if not isinstance(aggs, list):
             aggs = [aggs]
         
         aggs_json = json.dumps(aggs)
-        html = gophers.ColumnChartWrapper(
+        html = _cstr(gophers.ColumnChartWrapper(
             self.df_json.encode('utf-8'), 
             title.encode('utf-8'), 
             subtitle.encode('utf-8'), 
             groupcol.encode('utf-8'), 
-            aggs_json.encode('utf-8')
-        ).decode('utf-8')
+            aggs_json.encode('utf-8'),
+            json.dumps(options or {}).encode('utf-8')
+        ))
         
         # Create a Chart object
         chart = Chart(html)
@@ -1158,17 +1560,65 @@ class DataFrame:
         # Return the Chart object
         return chart
     
-    def StackedBarChart(self, title, subtitle, groupcol, aggs):
+    def StackedBarChart(self, title, subtitle, groupcol, aggs, options=None):
         aggs_json = json.dumps([agg.__dict__ for agg in aggs])
-        html = gophers.StackedBarChartWrapper(self.df_json.encode('utf-8'), title.encode('utf-8'), subtitle.encode('utf-8'), groupcol.encode('utf-8'), aggs_json.encode('utf-8')).decode('utf-8')
+        html = _cstr(gophers.StackedBarChartWrapper(self.df_json.encode('utf-8'), title.encode('utf-8'), subtitle.encode('utf-8'), groupcol.encode('utf-8'), aggs_json.encode('utf-8'), json.dumps(options or {}).encode('utf-8')))
         display(HTML(html))
         return self
     
-    def StackedPercentChart(self, title, subtitle, groupcol, aggs):
+    def StackedPercentChart(self, title, subtitle, groupcol, aggs, options=None):
         aggs_json = json.dumps([agg.__dict__ for agg in aggs])
-        html = gophers.StackedPercentChartWrapper(self.df_json.encode('utf-8'), title.encode('utf-8'), subtitle.encode('utf-8'), groupcol.encode('utf-8'), aggs_json.encode('utf-8')).decode('utf-8')
+        html = _cstr(gophers.StackedPercentChartWrapper(self.df_json.encode('utf-8'), title.encode('utf-8'), subtitle.encode('utf-8'), groupcol.encode('utf-8'), aggs_json.encode('utf-8'), json.dumps(options or {}).encode('utf-8')))
         display(HTML(html))
         return self
+
+    def PieChart(self, title, subtitle, namecol, agg, options=None):
+        """agg: a single aggregation, e.g. Sum("sales")"""
+        chart_json = _cstr(gophers.PieChartWrapper, self.df_json.encode('utf-8'), title.encode('utf-8'), subtitle.encode('utf-8'), namecol.encode('utf-8'), json.dumps(agg).encode('utf-8'), json.dumps(options or {}).encode('utf-8'))
+        return Chart(chart_json)
+
+    def LineChart(self, title, subtitle, xcol, ycols, options=None):
+        """ycols: a column name or list of names; date/time x columns are plotted on a datetime axis."""
+        chart_json = _cstr(gophers.LineChartWrapper, self.df_json.encode('utf-8'), title.encode('utf-8'), subtitle.encode('utf-8'), xcol.encode('utf-8'), json.dumps([ycols] if isinstance(ycols, str) else list(ycols)).encode('utf-8'), json.dumps(options or {}).encode('utf-8'))
+        return Chart(chart_json)
+
+    def AreaChart(self, title, subtitle, xcol, ycols, options=None):
+        """ycols: a column name or list of names; date/time x columns are plotted on a datetime axis."""
+        chart_json = _cstr(gophers.AreaChartWrapper, self.df_json.encode('utf-8'), title.encode('utf-8'), subtitle.encode('utf-8'), xcol.encode('utf-8'), json.dumps([ycols] if isinstance(ycols, str) else list(ycols)).encode('utf-8'), json.dumps(options or {}).encode('utf-8'))
+        return Chart(chart_json)
+
+    def ScatterPlot(self, title, subtitle, xcol, ycol, groupcol="", options=None):
+        chart_json = _cstr(gophers.ScatterPlotWrapper, self.df_json.encode('utf-8'), title.encode('utf-8'), subtitle.encode('utf-8'), xcol.encode('utf-8'), ycol.encode('utf-8'), groupcol.encode('utf-8'), json.dumps(options or {}).encode('utf-8'))
+        return Chart(chart_json)
+
+    def BubbleChart(self, title, subtitle, xcol, ycol, sizecol, groupcol="", options=None):
+        chart_json = _cstr(gophers.BubbleChartWrapper, self.df_json.encode('utf-8'), title.encode('utf-8'), subtitle.encode('utf-8'), xcol.encode('utf-8'), ycol.encode('utf-8'), sizecol.encode('utf-8'), groupcol.encode('utf-8'), json.dumps(options or {}).encode('utf-8'))
+        return Chart(chart_json)
+
+    def TreeMap(self, title, subtitle, groupcols, agg, options=None):
+        """groupcols: hierarchy columns, outermost first; agg: a single aggregation"""
+        if isinstance(groupcols, str):
+            groupcols = [groupcols]
+        chart_json = _cstr(gophers.TreeMapWrapper, self.df_json.encode('utf-8'), title.encode('utf-8'), subtitle.encode('utf-8'), json.dumps(list(groupcols)).encode('utf-8'), json.dumps(agg).encode('utf-8'), json.dumps(options or {}).encode('utf-8'))
+        return Chart(chart_json)
+
+    def DataTable(self, *cols):
+        chart_json = _cstr(gophers.DataTableWrapper, self.df_json.encode('utf-8'), json.dumps(list(cols)).encode('utf-8'))
+        return Chart(chart_json)
+
+    def Histogram(self, col, bins=0, options=None):
+        """bins <= 0 picks the bin count with Sturges' rule"""
+        chart_json = _cstr(gophers.HistogramWrapper, self.df_json.encode('utf-8'), col.encode('utf-8'), int(bins), json.dumps(options or {}).encode('utf-8'))
+        return Chart(chart_json)
+
+    def BoxPlot(self, value_col, group_col="", options=None):
+        chart_json = _cstr(gophers.BoxPlotWrapper, self.df_json.encode('utf-8'), value_col.encode('utf-8'), group_col.encode('utf-8'), json.dumps(options or {}).encode('utf-8'))
+        return Chart(chart_json)
+
+    def Heatmap(self, x_col, y_col, value_col, agg, options=None):
+        """agg: a single aggregation, e.g. Sum("sales")"""
+        chart_json = _cstr(gophers.HeatmapWrapper, self.df_json.encode('utf-8'), x_col.encode('utf-8'), y_col.encode('utf-8'), value_col.encode('utf-8'), json.dumps(agg).encode('utf-8'), json.dumps(options or {}).encode('utf-8'))
+        return Chart(chart_json)
     
     # Transform functions
     def Column(self, col_name, col_spec):
@@ -1244,17 +1694,55 @@ class DataFrame:
             df2.df_json.encode('utf-8')
         ))
         return self
-    def Join(self, df2, col1, col2, how="inner"):
+    def Join(self, df2, col1, col2, how="inner", suffixes=("_l", "_r")):
+        # col1/col2 are column names or lists of names for composite keys
         jt = str(how).lower()
-        if jt not in ("inner", "left", "right", "outer"):
-            print("Error: how must be one of inner|left|right|outer")
+        if jt not in ("inner", "left", "right", "outer", "semi", "anti", "cross"):
+            print("Error: how must be one of inner|left|right|outer|semi|anti|cross")
             return self
+        key = lambda k: json.dumps(list(k)) if isinstance(k, (list, tuple)) else (k or "")
         self.df_json = _cstr(gophers.JoinWrapper(
             self.df_json.encode('utf-8'),
             df2.df_json.encode('utf-8'),
-            col1.encode('utf-8'),
-            col2.encode('utf-8'),
-            jt.encode('utf-8')
+            key(col1).encode('utf-8'),
+            key(col2).encode('utf-8'),
+            jt.encode('utf-8'),
+            json.dumps(list(suffixes)).encode('utf-8')
+        ))
+        return self
+    def JoinOn(self, df2, on, how="inner", suffixes=("_l", "_r")):
+        # on is a ColumnExpr predicate over the joined column names, e.g. Col("start").Le(Col("ts"))
+        jt = str(how).lower()
+        if jt not in ("inner", "left", "right", "outer", "semi", "anti"):
+            print("Error: how must be one of inner|left|right|outer|semi|anti")
+            return self
+        self.df_json = _cstr(gophers.JoinOnWrapper(
+            self.df_json.encode('utf-8'),
+            df2.df_json.encode('utf-8'),
+            on.to_json().encode('utf-8'),
+            jt.encode('utf-8'),
+            json.dumps(list(suffixes)).encode('utf-8')
+        ))
+        return self
+    def Pivot(self, index, pivot_col, value_col, agg):
+        # agg is an aggregation such as Sum("sales"); it is applied to value_col
+        if isinstance(index, str):
+            index = [index]
+        self.df_json = _cstr(gophers.PivotWrapper(
+            self.df_json.encode('utf-8'),
+            json.dumps(list(index)).encode('utf-8'),
+            pivot_col.encode('utf-8'),
+            value_col.encode('utf-8'),
+            json.dumps(agg).encode('utf-8')
+        ))
+        return self
+    def Unpivot(self, id_cols, value_cols=None, var_name="variable", value_name="value"):
+        self.df_json = _cstr(gophers.UnpivotWrapper(
+            self.df_json.encode('utf-8'),
+            json.dumps(list(id_cols or [])).encode('utf-8'),
+            json.dumps(list(value_cols or [])).encode('utf-8'),
+            var_name.encode('utf-8'),
+            value_name.encode('utf-8')
         ))
         return self
     def Sort(self, *cols):
@@ -1368,10 +1856,16 @@ class DataFrame:
         return DataFrame(new_json)
     
     # Sink Functions
-    def ToCSVFile(self, filename):
-        gophers.ToCSVFile(self.df_json.encode('utf-8'), filename.encode('utf-8'))
+    def ToCSVFile(self, filename, options=None):
+        """options: optional dict, e.g. {"delimiter": "\\t", "line_ending": "\\n", "null_values": ["NA"], "encoding": "utf-16"}"""
+        _cstr(gophers.ToCSVFile(self.df_json.encode('utf-8'), filename.encode('utf-8'), json.dumps(options or {}).encode('utf-8')))
         # add output giving file name/location
         return self
+
+    def ToXLSXFile(self, filename, sheet="Sheet1"):
+        """Writes the DataFrame to a one-sheet Excel workbook, replacing the file; see WriteXLSX for several sheets."""
+        result = _cstr(gophers.ToXLSXFile(self.df_json.encode('utf-8'), filename.encode('utf-8'), sheet.encode('utf-8')))
+        return self
     
     def ToJSON(self):
         """
@@ -1,7 +1,9 @@
 package gophers
 
 import (
+	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"html"
 	"os"
 	"os/exec"
@@ -39,6 +41,9 @@ func CreateReport(title string) *Report {
 		<link href="https://cdn.jsdelivr.net/npm/daisyui@4.7.2/dist/full.min.css" rel="stylesheet" type="text/css" />
 		<script src="https://cdn.tailwindcss.com"></script>
 		<script src="https://code.highcharts.com/highcharts.js"></script>
+		<script src="https://code.highcharts.com/highcharts-more.js"></script>
+		<script src="https://code.highcharts.com/modules/treemap.js"></script>
+		<script src="https://code.highcharts.com/modules/heatmap.js"></script>
 		<script src="https://code.highcharts.com/modules/boost.js"></script>
 		<script src="https://code.highcharts.com/modules/exporting.js"></script>
 		<link rel="stylesheet" href="https://fonts.googleapis.com/css2?family=Material+Symbols+Outlined:opsz,wght,FILL,GRAD@20..48,100..700,0..1,-50..200" />
@@ -166,116 +171,10 @@ func (report *Report) SetErr(err string) error {
 
 // Open - open the report in browser
 func (report *Report) Open() error {
-	// add html element for page
-	html := report.Top +
-		report.Primary +
-		report.Secondary +
-		report.Accent +
-		report.Neutral +
-		report.Base100 +
-		report.Info +
-		report.Success +
-		report.Warning +
-		report.Err +
-		report.Htmlheading
-	if len(report.Pageshtml) > 1 {
-		html += `
-        <div id="app"  style="text-align: center;" class="drawer w-full lg:drawer-open">
-            <input id="my-drawer-2" type="checkbox" class="drawer-toggle" />
-            <div class="drawer-content flex flex-col">
-                <!-- Navbar -->
-                <div class="w-full navbar bg-neutral text-neutral-content shadow-lg ">
-            ` +
-			fmt.Sprintf(`<div class="flex-1 px-2 mx-2 btn btn-sm btn-neutral normal-case text-xl shadow-none hover:bg-neutral hover:border-neutral flex content-center"><a class="lg:ml-0 ml-14 text-4xl">%s</a></div>`, report.Title) +
-			`
-                <div class="flex-none lg:hidden">
-                    <label for="my-drawer-2" class="btn btn-neutral btn-square shadow-lg hover:shadow-xl hover:-translate-y-0.5 no-animation">
-                    <svg xmlns="http://www.w3.org/2000/svg" fill="none" viewBox="0 0 24 24"
-                        class="inline-block w-6 h-6 stroke-current">
-                        <path stroke-linecap="round" stroke-linejoin="round" stroke-width="2" d="M4 6h16M4 12h16M4 18h16"></path>
-                    </svg>
-                    </label>
-                    </div>
-                </div>
-                <!-- content goes here! -->
-                <div  class="w-full lg:w-3/4 md:w-3/4 sm:w-5/6 mx-auto flex-col justify-self-center">
-            `
-
-	} else {
-		html += `
-        <div id="app"  style="text-align: center;">
-            <!-- Navbar -->
-            <div class="w-full navbar bg-neutral text-neutral-content shadow-lg ">
-        ` +
-			fmt.Sprintf(`<div class="flex-1 px-2 mx-2 btn btn-sm btn-neutral normal-case text-xl shadow-none hover:bg-neutral hover:border-neutral flex content-center"><a class=" text-4xl">%s</a></div>
-            </div>`, report.Title) +
-			`<div  class="w-full lg:w-3/4 md:w-3/4 sm:w-5/6 mx-auto flex-col justify-self-center">`
-
-	}
-	// iterate over pageshtml and add each stored HTML snippet
-	for _, pageMap := range report.Pageshtml {
-		// iterate in order
-		// fmt.Println(pageMap)
-		for i := 0; i < len(pageMap); i++ {
-			html += pageMap[strconv.Itoa(i)]
-		}
-	}
-	if len(report.Pageshtml) > 1 {
-		html += `
-            </div>
-        </div>
-        <!-- <br> -->
-        <div class="drawer-side">
-            <label for="my-drawer-2" class="drawer-overlay bg-neutral"></label>
-            <ul class="menu p-4 w-80 bg-neutral h-full overflow-y-auto min-h-screen text-base-content shadow-none space-y-2 ">
-            <div class="card w-72 bg-base-100 shadow-xl">
-                <div class="card-body">
-                    <div class="flex space-x-6 place-content-center">
-                        <h2 class="card-title black-text-shadow-sm flex justify">Pages</h2>
-                    </div>
-                <div class="flex flex-col w-full h-1px">
-                    <div class="divider"></div>
-                </div>
-                <div class="space-y-4">
-        `
-		for page, _ := range report.Pageshtml {
-			html += fmt.Sprintf(`
-            <button v-if="page == '%s' " @click="page = '%s' " class="btn btn-block btn-sm btn-neutral text-white bg-neutral shadow-lg  hover:shadow-xl hover:-translate-y-0.5 no-animation " >%s</button>
-            <button v-else @click="page = '%s' " class="btn btn-block btn-sm bg-base-100 btn-outline btn-neutral hover:text-white shadow-lg hover:shadow-xl hover:-translate-y-0.5 no-animation " >%s</button>
-            
-            `, page, page, page, page, page)
-		}
-	} else {
-		html += `
-            </div>
-        </div>
-        `
-	}
-	html += report.Scriptheading
-	pages := `pages: [`
-	count := 0
-	for page, _ := range report.Pageshtml {
-		if count == 0 {
-			html += fmt.Sprintf("%q", page) + ","
-		}
-		pages += fmt.Sprintf("%q", page) + ", "
-		count++
-	}
-	pages = strings.TrimSuffix(pages, ", ") + `],`
-	html += pages
-	html += report.Scriptmiddle
-	// iterate over pagesjs similarly
-	for _, jsMap := range report.Pagesjs {
-		// fmt.Println("printing jsMap")
-		// fmt.Println(jsMap)
-		for i := 0; i < len(jsMap); i++ {
-			html += jsMap[strconv.Itoa(i)]
-		}
+	html, err := report.HTML()
+	if err != nil {
+		return err
 	}
-
-	html += report.Bottom
-	// fmt.Println("printing html:")
-	// fmt.Println(html)
 	// Create a temporary file
 	tmpFile, err := os.CreateTemp(os.TempDir(), "temp-*.html")
 	if err != nil {
@@ -308,13 +207,47 @@ func (report *Report) Open() error {
 }
 
 // Save - save report to html file
-func (report *Report) Save(filename string) error {
+// With SaveOptions{SVG: true} charts are rendered to inline SVG in Go instead of
+// Highcharts scripts, so they display without JavaScript (emails, PDFs, CI artifacts).
+// With SaveOptions{Offline: true} the CDN scripts, styles and fonts are replaced by
+// the pinned copies embedded in the package, so the file works without network access.
+func (report *Report) Save(filename string, opts ...SaveOptions) error {
+	html, err := report.HTML(opts...)
+	if err != nil {
+		return err
+	}
 	file, err := os.Create(filename)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
+	// Write the HTML string to the file
+	if _, err := file.Write([]byte(html)); err != nil {
+		return fmt.Errorf("failed to write to file: %v", err)
+	}
+
+	return nil
+}
+
+// HTML returns the report page that Save writes.
+func (report *Report) HTML(opts ...SaveOptions) (string, error) {
+	var opt SaveOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	// chart element id -> inline SVG
+	svgs := map[string]string{}
+	if opt.SVG {
+		for _, jsMap := range report.Pagesjs {
+			for _, script := range jsMap {
+				if id, config, ok := chartScriptConfig(script); ok {
+					svgs[id] = renderSVG(config)
+				}
+			}
+		}
+	}
+
 	// add html element for page
 	html := report.Top +
 		report.Primary +
@@ -367,7 +300,7 @@ func (report *Report) Save(filename string) error {
 		// iterate in order
 		// fmt.Println(pageMap)
 		for i := 0; i < len(pageMap); i++ {
-			html += pageMap[strconv.Itoa(i)]
+			html += inlineChartSVG(pageMap[strconv.Itoa(i)], svgs)
 		}
 	}
 	if len(report.Pageshtml) > 1 {
@@ -414,23 +347,46 @@ func (report *Report) Save(filename string) error {
 	pages = strings.TrimSuffix(pages, ", ") + `],`
 	html += pages
 	html += report.Scriptmiddle
+	filterJS := report.filterScripts()
+	filters := filterJS != ""
+	if filters {
+		html += reportFilterScript
+	}
 	// iterate over pagesjs similarly
 	for _, jsMap := range report.Pagesjs {
 		// fmt.Println("printing jsMap")
 		// fmt.Println(jsMap)
 		for i := 0; i < len(jsMap); i++ {
+			if id, _, ok := chartScriptConfig(jsMap[strconv.Itoa(i)]); ok && svgs[id] != "" {
+				continue
+			}
 			html += jsMap[strconv.Itoa(i)]
 		}
 	}
+	if filters {
+		html += filterJS + "gophersReport.init();\n"
+	}
 
 	html += report.Bottom
-
-	// Write the HTML string to the file
-	if _, err := file.Write([]byte(html)); err != nil {
-		return fmt.Errorf("failed to write to file: %v", err)
+	if opt.Offline {
+		return inlineAssets(html)
 	}
+	return html, nil
+}
 
-	return nil
+// inlineChartSVG swaps a chart element written by AddChart for its SVG rendering
+// when svgs has one for the element id.
+func inlineChartSVG(element string, svgs map[string]string) string {
+	start := strings.Index(element, ` id="`)
+	if len(svgs) == 0 || !strings.HasPrefix(element, `<div v-show=`) || start < 0 {
+		return element
+	}
+	rest := element[start+len(` id="`):]
+	end := strings.Index(rest, `"`)
+	if end < 0 || svgs[rest[:end]] == "" {
+		return element
+	}
+	return element[:start] + fmt.Sprintf(` id="%s" class="flex justify-center mx-auto p-4">%s</div>`, rest[:end], svgs[rest[:end]])
 }
 
 // AddPage adds a new page to the report.
@@ -447,6 +403,7 @@ func (report *Report) AddPage(name string) {
 
 	html := `<h1 v-if="page == '` + name + `' " class="text-8xl pt-24 pb-24"> ` + name + `</h1>` // Page Title at top of page
 	report.Pageshtml[name][strconv.Itoa(len(report.Pageshtml[name]))] = html
+	report.layoutPage(name)
 
 	// fmt.Println("AddPage: Added page:", name)
 	// fmt.Println("AddPage: Updated pageshtml:", report.Pageshtml)
@@ -464,6 +421,12 @@ func (report *Report) AddPage(name string) {
 // add title text-2xl - this should just be the page name and automatically populate at the top of the page...
 // add html to page map
 func (report *Report) AddHTML(page string, text string) {
+	report.addHTML(page, text)
+	report.record(page, ReportBlock{Type: "html", Text: text})
+}
+
+// addHTML places text on the page in a sandboxed iframe.
+func (report *Report) addHTML(page string, text string) {
 	report.init() // Ensure maps are initialized
 
 	// Check if the page exists
@@ -489,7 +452,8 @@ func (report *Report) AddDataframe(page string, df *DataFrame) {
 		fmt.Println("Page does not exist. Use AddPage()")
 		return
 	}
-	report.AddHTML(page, text)
+	report.addHTML(page, text)
+	report.record(page, ReportBlock{Type: "dataframe", Data: df})
 }
 
 // // AddDataframe embeds the DataFrame HTML directly on the page (no iframe)
@@ -538,7 +502,11 @@ func (report *Report) AddChart(page string, chart Chart) {
 	js := fmt.Sprintf(`%s%s%s`, chart.Jspreid, chartId, chart.Jspostid)
 
 	report.Pageshtml[page][idhtml] = html
-	report.Pagesjs[page][idjs] = js
+	// html-only charts such as DataTable have no script
+	if chart.Jspreid != "" {
+		report.Pagesjs[page][idjs] = js
+	}
+	report.record(page, ReportBlock{Type: "chart", Chart: &chart, ID: chartId})
 
 	// fmt.Println("DASH:", report.Pageshtml)
 	// fmt.Printf("AddChart: Added chart to page %s at index %s\n", page, idhtml)
@@ -585,6 +553,7 @@ func (report *Report) AddHeading(page string, heading string, size int) {
 
 	html := `<h1 v-if="page == '` + page + fmt.Sprintf(`' " class="%s p-8 flex justify-start"> `, text_size) + heading + `</h1>`
 	report.Pageshtml[page][strconv.Itoa(len(report.Pageshtml[page]))] = html
+	report.record(page, ReportBlock{Type: "heading", Text: heading, Size: size})
 
 	// fmt.Printf("AddHeading: Added heading to page %s with size %d\n", page, size)
 	// fmt.Println("AddHeading: Updated pageshtml:", report.Pageshtml)
@@ -603,6 +572,7 @@ func (report *Report) AddText(page string, text string) {
 	html := `<h1 v-if="page == '` + page + fmt.Sprintf(`' " class="%s pl-12 pr-12 flex justify-start text-left"> `, text_size) + text + `</h1>`
 	idx := strconv.Itoa(len(report.Pageshtml[page]))
 	report.Pageshtml[page][idx] = html
+	report.record(page, ReportBlock{Type: "text", Text: text})
 
 	// fmt.Printf("AddText: Added text to page %s at index %s\n", page, idx)
 	// fmt.Println("AddText: Updated pageshtml:", report.Pageshtml)
@@ -621,9 +591,10 @@ func (report *Report) AddSubText(page string, text string) {
 	text_size := "text-sm"
 	html := `<h1 v-if="page == '` + page + fmt.Sprintf(`' " class="%s pl-12 pr-12 pb-8 flex justify-center"> `, text_size) + text + `</h1>`
 	report.Pageshtml[page][strconv.Itoa(len(report.Pageshtml[page]))] = html
+	report.record(page, ReportBlock{Type: "subtext", Text: text})
 
-	fmt.Println("AddSubText: Added subtext to page:", page)
-	fmt.Println("AddSubText: Updated pageshtml:", report.Pageshtml)
+	// fmt.Println("AddSubText: Added subtext to page:", page)
+	// fmt.Println("AddSubText: Updated pageshtml:", report.Pageshtml)
 }
 
 // add bullet list
@@ -643,8 +614,400 @@ func (report *Report) AddBullets(page string, text ...string) {
 	}
 	html += `</ul>`
 	report.Pageshtml[page][strconv.Itoa(len(report.Pageshtml[page]))] = html
+	report.record(page, ReportBlock{Type: "bullets", Items: append([]string(nil), text...)})
+
+	// fmt.Println("AddBullets: Added bullets to page:", page)
+	// fmt.Println("AddBullets: Updated pageshtml:", report.Pageshtml)
+
+}
+
+// filterKinds are the controls AddFilter can place on a page.
+var filterKinds = map[string]bool{"select": true, "multiselect": true, "range": true, "daterange": true}
+
+// AddFilter adds a filter control for column to the page. kind is "select",
+// "multiselect", "range" (numeric min/max sliders) or "daterange". In the saved
+// report the control lists the values of column found in the data of the page's
+// charts, and changing it re-filters those rows and redraws every chart on the
+// page that reads column. Charts built with custom aggregations stay static.
+func (report *Report) AddFilter(page string, column string, kind string) error {
+	report.init() // Ensure maps are initialized
+
+	if _, exists := report.Pageshtml[page]; !exists {
+		return fmt.Errorf("AddFilter: page %q does not exist, use AddPage()", page)
+	}
+	kind = strings.ToLower(kind)
+	if !filterKinds[kind] {
+		return fmt.Errorf("AddFilter: unknown filter kind %q (select, multiselect, range or daterange)", kind)
+	}
+
+	label := html.EscapeString(column)
+	var control string
+	switch kind {
+	case "select":
+		control = `<select class="select select-bordered select-sm"></select>`
+	case "multiselect":
+		control = `<select multiple class="select select-bordered h-32"></select>`
+	case "range":
+		control = `<input type="range" data-bound="lo" class="range range-sm range-primary w-48" /><input type="range" data-bound="hi" class="range range-sm range-primary w-48" /><span data-label class="text-sm"></span>`
+	case "daterange":
+		control = `<input type="date" data-bound="lo" class="input input-bordered input-sm" /><span>to</span><input type="date" data-bound="hi" class="input input-bordered input-sm" />`
+	}
+	filterhtml := fmt.Sprintf(`<div v-show="page == '%s'" class="flex justify-center items-center gap-4 p-4" data-gophers-filter data-page="%s" data-column="%s" data-kind="%s"><span class="font-bold">%s</span>%s</div>`,
+		page, html.EscapeString(page), label, kind, label, control)
+
+	report.Pageshtml[page][strconv.Itoa(len(report.Pageshtml[page]))] = filterhtml
+	report.record(page, ReportBlock{Type: "filter", Column: column, Kind: kind})
+	return nil
+}
+
+// layoutPage returns the Layout entry for page, appending it on first use.
+func (report *Report) layoutPage(name string) *ReportPage {
+	for i := range report.Layout {
+		if report.Layout[i].Name == name {
+			return &report.Layout[i]
+		}
+	}
+	report.Layout = append(report.Layout, ReportPage{Name: name})
+	return &report.Layout[len(report.Layout)-1]
+}
+
+// record appends block to the page's Layout entry.
+func (report *Report) record(page string, block ReportBlock) {
+	p := report.layoutPage(page)
+	p.Blocks = append(p.Blocks, block)
+}
 
-	fmt.Println("AddBullets: Added bullets to page:", page)
-	fmt.Println("AddBullets: Updated pageshtml:", report.Pageshtml)
+// filterScripts registers the charts of pages with an AddFilter control with
+// the report filter script: each chart's spec and config, and the source rows it
+// is rebuilt from (once per DataFrame). Only the columns the chart specs and the
+// page's filters read are written, and pages without filters write no data.
+func (report *Report) filterScripts() string {
+	var b strings.Builder
+	registered := map[string]bool{}
+	for _, page := range report.Layout {
+		filterCols := []string{}
+		for _, block := range page.Blocks {
+			if block.Type == "filter" {
+				filterCols = append(filterCols, block.Column)
+			}
+		}
+		if len(filterCols) == 0 {
+			continue
+		}
+		for _, block := range page.Blocks {
+			chart := block.Chart
+			if block.Type != "chart" || chart == nil || chart.Jspreid == "" || chart.Spec == nil || chart.Spec.Source == nil {
+				continue
+			}
+			spec := *chart.Spec
+			cols := map[string][]interface{}{}
+			for _, col := range append(append(append([]string{}, spec.Cols...), filterCols...), specAggColumns(spec)...) {
+				if data, ok := spec.Source.Data[col]; ok {
+					cols[col] = data
+				}
+			}
+			data, err := json.Marshal(map[string]interface{}{"cols": cols, "rows": spec.Source.Rows})
+			if err != nil {
+				continue
+			}
+			config, err := chart.config()
+			if err != nil {
+				continue
+			}
+			h := fnv.New64a()
+			h.Write(data)
+			dataset := fmt.Sprintf("ds%x", h.Sum64())
+			if !registered[dataset] {
+				registered[dataset] = true
+				fmt.Fprintf(&b, "gophersReport.dataset('%s', %s);\n", dataset, data)
+			}
+			spec.Source = nil
+			specJSON, _ := json.Marshal(spec)
+			configJSON, _ := json.Marshal(config)
+			pageJSON, _ := json.Marshal(page.Name)
+			fmt.Fprintf(&b, "gophersReport.chart(%s, '%s', '%s', %s, %s);\n", pageJSON, block.ID, dataset, specJSON, configJSON)
+		}
+	}
+	return b.String()
+}
 
+// specAggColumns returns the columns the aggregations of spec read.
+func specAggColumns(spec ChartSpec) []string {
+	cols := make([]string, len(spec.Aggs))
+	for i, a := range spec.Aggs {
+		cols[i] = a.Column
+	}
+	return cols
 }
+
+// reportFilterScript rebuilds report charts in the browser from filtered rows.
+// The rebuilders mirror the Go chart builders in charts.go; keep them in step.
+const reportFilterScript = `
+window.gophersReport = window.gophersReport || (function () {
+	var datasets = {}, charts = [], state = {};
+	var isNum = function (v) { return typeof v === 'number'; };
+	var num = function (v) {
+		if (isNum(v)) return v;
+		if (typeof v === 'string' && v.trim() !== '' && !isNaN(Number(v))) return Number(v);
+		return null;
+	};
+	var str = function (v) { return v === null || v === undefined ? 'null' : String(v); };
+	var time = function (v) {
+		if (isNum(v)) return v;
+		if (typeof v !== 'string') return null;
+		var s = v.trim();
+		if (/^\d{4}-\d{2}-\d{2} \d/.test(s)) s = s.replace(' ', 'T');
+		if (/T\d{2}:\d{2}(:\d{2}(\.\d+)?)?$/.test(s)) s += 'Z';
+		var ms = Date.parse(s);
+		return isNaN(ms) ? null : ms;
+	};
+	var nums = function (vals) { return vals.filter(isNum); };
+	var aggs = {
+		sum: function (v) { return nums(v).reduce(function (a, b) { return a + b; }, 0); },
+		max: function (v) { var n = nums(v); return n.length ? n.reduce(function (a, b) { return b > a ? b : a; }) : null; },
+		min: function (v) { var n = nums(v); return n.length ? n.reduce(function (a, b) { return b < a ? b : a; }) : null; },
+		mean: function (v) { var n = nums(v); return n.length ? aggs.sum(n) / n.length : null; },
+		median: function (v) {
+			var n = nums(v).sort(function (a, b) { return a - b; }), m = n.length >> 1;
+			if (!n.length) return null;
+			return n.length % 2 ? n[m] : (n[m - 1] + n[m]) / 2;
+		},
+		mode: function (v) {
+			var freq = new Map(), mode = null, max = 0;
+			nums(v).forEach(function (x) {
+				var c = (freq.get(x) || 0) + 1;
+				freq.set(x, c);
+				if (c > max) { max = c; mode = x; }
+			});
+			return mode;
+		},
+		unique: function (v) { return new Set(v.map(str)).size; },
+		first: function (v) { return v.length ? v[0] : null; }
+	};
+	var quantile = function (sorted, q) {
+		var pos = q * (sorted.length - 1), lo = Math.floor(pos), hi = Math.ceil(pos);
+		return sorted[lo] + (sorted[hi] - sorted[lo]) * (pos - lo);
+	};
+	var g6 = function (x) { return String(parseFloat(x.toPrecision(6))); };
+
+	// groups returns the rows of idx grouped on keys, in first-appearance order.
+	var groups = function (cols, idx, keys) {
+		var index = new Map(), out = [];
+		idx.forEach(function (i) {
+			var k = keys.map(function (c) { return str((cols[c] || [])[i]); }).join('\u0000');
+			var g = index.get(k);
+			if (!g) { g = { first: i, rows: [] }; index.set(k, g); out.push(g); }
+			g.rows.push(i);
+		});
+		return out;
+	};
+	var aggregate = function (cols, g, a) {
+		var col = cols[a.column] || [];
+		return aggs[a.fn](g.rows.map(function (i) { return col[i]; }));
+	};
+	var xkey = function (axis, v) { return axis === 'datetime' ? time(v) : num(v); };
+
+	var grouped = function (cols, idx, s, c) {
+		var key = s.cols[0], gs = groups(cols, idx, [key]);
+		c.xAxis.categories = gs.map(function (g) { return str(cols[key][g.first]); });
+		c.series = s.aggs.map(function (a, k) {
+			return Object.assign({}, c.series[k], { data: gs.map(function (g) { return aggregate(cols, g, a); }) });
+		});
+	};
+	var xy = function (cols, idx, s, c) {
+		var xs = cols[s.cols[0]] || [], order = idx.slice();
+		if (s.axis === 'category') {
+			c.xAxis.categories = order.map(function (i) { return str(xs[i]); });
+		} else {
+			order.sort(function (a, b) { return (xkey(s.axis, xs[a]) || 0) - (xkey(s.axis, xs[b]) || 0); });
+		}
+		c.series = s.cols.slice(1).map(function (y, k) {
+			var ys = cols[y] || [];
+			return Object.assign({}, c.series[k], {
+				data: order.map(function (i) {
+					return s.axis === 'category' ? num(ys[i]) : [xs[i] == null ? null : xkey(s.axis, xs[i]), num(ys[i])];
+				})
+			});
+		});
+	};
+	var point = function (cols, idx, s, c) {
+		var xs = cols[s.cols[0]] || [], ys = cols[s.cols[1]] || [], zs = cols[s.cols[2]] || [], gs = cols[s.cols[3]] || [];
+		if (s.axis === 'category') c.xAxis.categories = idx.map(function (i) { return str(xs[i]); });
+		var index = new Map(), series = [];
+		idx.forEach(function (i, k) {
+			var name = s.cols[3] ? str(gs[i]) : s.cols[1], se = index.get(name);
+			if (!se) { se = { name: name, data: [] }; index.set(name, se); series.push(se); }
+			var p = [s.axis === 'category' ? k : (xs[i] == null ? null : xkey(s.axis, xs[i])), num(ys[i])];
+			if (s.kind === 'bubble') p.push(num(zs[i]));
+			se.data.push(p);
+		});
+		c.series = series;
+	};
+	var build = {
+		bar: grouped, column: grouped, stackedbar: grouped, stackedpercent: grouped,
+		line: xy, area: xy, scatter: point, bubble: point,
+		pie: function (cols, idx, s, c) {
+			var key = s.cols[0];
+			c.series[0].data = groups(cols, idx, [key]).map(function (g) {
+				return { name: str(cols[key][g.first]), y: num(aggregate(cols, g, s.aggs[0])) };
+			});
+		},
+		treemap: function (cols, idx, s, c) {
+			var data = [], seen = {};
+			groups(cols, idx, s.cols).forEach(function (g) {
+				var parent = '';
+				s.cols.forEach(function (col, level) {
+					var name = str(cols[col][g.first]), id = parent + '/' + name, p = { id: id, name: name };
+					if (parent !== '') p.parent = parent;
+					if (level === s.cols.length - 1) {
+						p.value = num(aggregate(cols, g, s.aggs[0]));
+						data.push(p);
+					} else if (!seen[id]) {
+						seen[id] = true;
+						data.push(p);
+					}
+					parent = id;
+				});
+			});
+			c.series[0].data = data;
+		},
+		histogram: function (cols, idx, s, c) {
+			var col = cols[s.cols[0]] || [], values = [], categories = [], counts = [];
+			idx.forEach(function (i) { var v = num(col[i]); if (v !== null && !isNaN(v)) values.push(v); });
+			if (values.length) {
+				var bins = s.bins > 0 ? s.bins : Math.ceil(Math.log2(values.length)) + 1;
+				var lo = Math.min.apply(null, values), hi = Math.max.apply(null, values), width = (hi - lo) / bins;
+				if (width === 0) { bins = 1; width = 1; }
+				for (var b = 0; b < bins; b++) {
+					counts.push(0);
+					categories.push(g6(lo + b * width) + ' - ' + g6(lo + b * width + width));
+				}
+				values.forEach(function (v) { counts[Math.min(Math.floor((v - lo) / width), bins - 1)]++; });
+			}
+			c.xAxis.categories = categories;
+			c.subtitle = Object.assign({}, c.subtitle, { text: values.length + ' values' });
+			c.series[0].data = counts;
+		},
+		boxplot: function (cols, idx, s, c) {
+			var vs = cols[s.cols[0]] || [], gs = cols[s.cols[1]] || [], index = new Map(), categories = [], sets = [];
+			idx.forEach(function (i) {
+				var v = num(vs[i]);
+				if (v === null) return;
+				var name = s.cols[1] ? str(gs[i]) : s.cols[0];
+				if (!index.has(name)) { index.set(name, sets.length); categories.push(name); sets.push([]); }
+				sets[index.get(name)].push(v);
+			});
+			var boxes = [], outliers = [];
+			sets.forEach(function (vals, gi) {
+				vals.sort(function (a, b) { return a - b; });
+				var q1 = quantile(vals, 0.25), median = quantile(vals, 0.5), q3 = quantile(vals, 0.75), iqr = q3 - q1;
+				var low = median, high = median;
+				vals.forEach(function (v) {
+					if (v < q1 - 1.5 * iqr || v > q3 + 1.5 * iqr) { outliers.push([gi, v]); return; }
+					low = Math.min(low, v);
+					high = Math.max(high, v);
+				});
+				boxes.push([low, q1, median, q3, high]);
+			});
+			c.xAxis.categories = categories;
+			c.series[0].data = boxes;
+			c.series[1].data = outliers;
+		},
+		heatmap: function (cols, idx, s, c) {
+			var xi = new Map(), yi = new Map(), xc = [], yc = [];
+			var pos = function (index, cats, v) {
+				var k = str(v);
+				if (!index.has(k)) { index.set(k, cats.length); cats.push(k); }
+				return index.get(k);
+			};
+			c.series[0].data = groups(cols, idx, [s.cols[0], s.cols[1]]).map(function (g) {
+				return [pos(xi, xc, cols[s.cols[0]][g.first]), pos(yi, yc, cols[s.cols[1]][g.first]), num(aggregate(cols, g, s.aggs[0]))];
+			});
+			c.series[0].dataLabels = Object.assign({}, c.series[0].dataLabels, { enabled: c.series[0].data.length <= 400 });
+			c.xAxis.categories = xc;
+			c.yAxis.categories = yc;
+		}
+	};
+
+	var render = function (ch) {
+		var ds = datasets[ch.dataset], filters = state[ch.page] || {}, idx = [];
+		for (var i = 0; i < ds.rows; i++) {
+			var keep = true;
+			for (var col in filters) {
+				if (ds.cols[col] && !filters[col](ds.cols[col][i])) { keep = false; break; }
+			}
+			if (keep) idx.push(i);
+		}
+		var config = JSON.parse(JSON.stringify(ch.config));
+		build[ch.spec.kind](ds.cols, idx, ch.spec, config);
+		var old = Highcharts.charts.find(function (c) { return c && c.renderTo && c.renderTo.id === ch.id; });
+		if (old) old.destroy();
+		Highcharts.chart(ch.id, config);
+	};
+
+	// pageValues returns the values of column in the datasets of the page's charts.
+	var pageValues = function (page, column) {
+		var values = [], seen = {};
+		charts.forEach(function (ch) {
+			var col = datasets[ch.dataset].cols[column];
+			if (ch.page !== page || !col || seen[ch.dataset]) return;
+			seen[ch.dataset] = true;
+			values = values.concat(col);
+		});
+		return values;
+	};
+	var day = function (v) { var t = time(v); return t === null ? null : new Date(t).toISOString().slice(0, 10); };
+
+	var setupFilter = function (el) {
+		var page = el.dataset.page, column = el.dataset.column, kind = el.dataset.kind, values = pageValues(page, column);
+		var apply = function (pred) {
+			state[page] = state[page] || {};
+			if (pred) state[page][column] = pred; else delete state[page][column];
+			charts.forEach(function (ch) { if (ch.page === page && datasets[ch.dataset].cols[column]) render(ch); });
+		};
+		if (kind === 'select' || kind === 'multiselect') {
+			var sel = el.querySelector('select'), distinct = Array.from(new Set(values.map(str)));
+			distinct.sort(function (a, b) { return a.localeCompare(b, undefined, { numeric: true }); });
+			if (kind === 'select') sel.add(new Option('All', ''));
+			distinct.forEach(function (v) { sel.add(new Option(v, v)); });
+			sel.addEventListener('change', function () {
+				var picked = new Set(Array.from(sel.selectedOptions).map(function (o) { return o.value; }).filter(function (v) { return v !== ''; }));
+				apply(picked.size ? function (v) { return picked.has(str(v)); } : null);
+			});
+		} else if (kind === 'range') {
+			var ns = values.map(num).filter(function (v) { return v !== null; });
+			var lo = el.querySelector('[data-bound="lo"]'), hi = el.querySelector('[data-bound="hi"]'), label = el.querySelector('[data-label]');
+			var min = ns.length ? Math.min.apply(null, ns) : 0, max = ns.length ? Math.max.apply(null, ns) : 0;
+			[lo, hi].forEach(function (input) { input.min = min; input.max = max; input.step = 'any'; });
+			lo.value = min;
+			hi.value = max;
+			var update = function () {
+				var a = Math.min(Number(lo.value), Number(hi.value)), b = Math.max(Number(lo.value), Number(hi.value));
+				label.textContent = g6(a) + ' - ' + g6(b);
+				apply(a > min || b < max ? function (v) { v = num(v); return v !== null && v >= a && v <= b; } : null);
+			};
+			label.textContent = g6(min) + ' - ' + g6(max);
+			lo.addEventListener('input', update);
+			hi.addEventListener('input', update);
+		} else if (kind === 'daterange') {
+			var days = values.map(day).filter(function (v) { return v !== null; }).sort();
+			var from = el.querySelector('[data-bound="lo"]'), to = el.querySelector('[data-bound="hi"]');
+			if (days.length) {
+				[from, to].forEach(function (input) { input.min = days[0]; input.max = days[days.length - 1]; });
+			}
+			var change = function () {
+				var a = from.value, b = to.value;
+				apply(a || b ? function (v) { var d = day(v); return d !== null && (!a || d >= a) && (!b || d <= b); } : null);
+			};
+			from.addEventListener('change', change);
+			to.addEventListener('change', change);
+		}
+	};
+
+	return {
+		dataset: function (name, data) { datasets[name] = data; },
+		chart: function (page, id, dataset, spec, config) { charts.push({ page: page, id: id, dataset: dataset, spec: spec, config: config }); },
+		init: function () { document.querySelectorAll('[data-gophers-filter]').forEach(setupFilter); }
+	};
+})();
+`
@@ -0,0 +1,330 @@
+package gophers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ReadReportSpec reads a ReportSpec from a JSON or YAML file path or string.
+// Input starting with "{" is parsed as JSON, anything else as YAML.
+func ReadReportSpec(input string) (*ReportSpec, error) {
+	// Treat input as a file path if it exists, else as raw JSON/YAML text.
+	content := input
+	if fileExists(input) {
+		b, err := os.ReadFile(input)
+		if err != nil {
+			return nil, fmt.Errorf("ReadReportSpec: read file: %w", err)
+		}
+		content = string(b)
+	}
+
+	spec := &ReportSpec{}
+	if strings.HasPrefix(strings.TrimSpace(content), "{") {
+		if err := json.Unmarshal([]byte(content), spec); err != nil {
+			return nil, fmt.Errorf("ReadReportSpec: invalid JSON: %w", err)
+		}
+	} else if err := yaml.Unmarshal([]byte(content), spec); err != nil {
+		return nil, fmt.Errorf("ReadReportSpec: invalid YAML: %w", err)
+	}
+	return spec, nil
+}
+
+// ToJSON returns the spec as indented JSON.
+func (spec *ReportSpec) ToJSON() string {
+	b, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// ToYAML returns the spec as YAML.
+func (spec *ReportSpec) ToYAML() string {
+	b, err := yaml.Marshal(spec)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// Save writes the spec to filename, as YAML for .yaml/.yml files and JSON otherwise.
+func (spec *ReportSpec) Save(filename string) error {
+	out := spec.ToJSON()
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		out = spec.ToYAML()
+	}
+	return os.WriteFile(filename, []byte(out), 0644)
+}
+
+// Spec returns the report as a ReportSpec, built from the blocks recorded as
+// they were added, together with the DataFrames its dataframe and chart blocks
+// read, named data_1, data_2, ... in order of first use. RenderReport of the
+// result rebuilds the report:
+//
+//	report2, err := RenderReport(report.Spec())
+//
+// Charts without a ChartSpec (custom aggregations, Profile charts) cannot be
+// rebuilt and are left out, as is raw HTML added to Pageshtml directly.
+func (report *Report) Spec() (*ReportSpec, map[string]*DataFrame) {
+	spec := &ReportSpec{Title: report.Title, Pages: []ReportPageSpec{}}
+	datasets := map[string]*DataFrame{}
+	names := map[*DataFrame]string{}
+	dataset := func(df *DataFrame) string {
+		name, ok := names[df]
+		if !ok {
+			name = fmt.Sprintf("data_%d", len(names)+1)
+			names[df] = name
+			datasets[name] = df
+		}
+		return name
+	}
+
+	defaults := CreateReport("")
+	for _, c := range []struct{ name, value, def string }{
+		{"primary", report.Primary, defaults.Primary},
+		{"secondary", report.Secondary, defaults.Secondary},
+		{"accent", report.Accent, defaults.Accent},
+		{"neutral", report.Neutral, defaults.Neutral},
+		{"base100", report.Base100, defaults.Base100},
+		{"info", report.Info, defaults.Info},
+		{"success", report.Success, defaults.Success},
+		{"warning", report.Warning, defaults.Warning},
+		{"error", report.Err, defaults.Err},
+	} {
+		if c.value == c.def {
+			continue
+		}
+		// the Set methods store `name: "color",`
+		v := strings.TrimSuffix(strings.TrimSpace(c.value), `",`)
+		if i := strings.LastIndex(v, `"`); i >= 0 {
+			if spec.Colors == nil {
+				spec.Colors = map[string]string{}
+			}
+			spec.Colors[c.name] = v[i+1:]
+		}
+	}
+
+	for _, page := range report.Layout {
+		ps := ReportPageSpec{Name: page.Name, Blocks: []ReportBlockSpec{}}
+		for _, b := range page.Blocks {
+			block := ReportBlockSpec{Type: b.Type, Text: b.Text, Size: b.Size, Items: b.Items, Column: b.Column, Kind: b.Kind}
+			switch b.Type {
+			case "dataframe":
+				if b.Data == nil {
+					continue
+				}
+				block.Dataset = dataset(b.Data)
+			case "chart":
+				if b.Chart == nil || b.Chart.Spec == nil || b.Chart.Spec.Source == nil {
+					continue
+				}
+				block.Dataset = dataset(b.Chart.Spec.Source)
+				block.Chart = reportChartSpec(*b.Chart)
+			}
+			ps.Blocks = append(ps.Blocks, block)
+		}
+		spec.Pages = append(spec.Pages, ps)
+	}
+	return spec, datasets
+}
+
+// reportChartSpec turns a chart's ChartSpec into the ReportChartSpec that
+// rebuilds it, taking the title and subtitle from its Highcharts config.
+func reportChartSpec(chart Chart) *ReportChartSpec {
+	cs := chart.Spec
+	rc := &ReportChartSpec{Kind: cs.Kind, Cols: append([]string(nil), cs.Cols...), Aggs: cs.Aggs, Bins: cs.Bins, Options: cs.Options}
+	if cs.Kind == "scatter" && len(rc.Cols) == 4 {
+		// pointChart records [x, y, size, group]; ScatterPlot takes no size
+		rc.Cols = []string{rc.Cols[0], rc.Cols[1], rc.Cols[3]}
+	}
+	if config, err := chart.config(); err == nil {
+		rc.Title, _ = svgMap(config["title"])["text"].(string)
+		rc.Subtitle, _ = svgMap(config["subtitle"])["text"].(string)
+	}
+	return rc
+}
+
+// RenderReport builds the report described by spec, reading the "dataframe" and
+// "chart" blocks from datasets by name. Rendering the same spec against fresh
+// DataFrames regenerates the same layout with new data.
+func RenderReport(spec *ReportSpec, datasets map[string]*DataFrame) (*Report, error) {
+	if spec == nil {
+		return nil, fmt.Errorf("RenderReport: nil spec")
+	}
+	report := CreateReport(spec.Title)
+	for name, color := range spec.Colors {
+		if err := report.setColor(name, color); err != nil {
+			return nil, fmt.Errorf("RenderReport: %w", err)
+		}
+	}
+	for _, page := range spec.Pages {
+		report.AddPage(page.Name)
+		for i, block := range page.Blocks {
+			if err := report.addBlock(page.Name, block, datasets); err != nil {
+				return nil, fmt.Errorf("RenderReport: page %q block %d: %w", page.Name, i+1, err)
+			}
+		}
+	}
+	return report, nil
+}
+
+// setColor sets a theme color by its ReportSpec.Colors name.
+func (report *Report) setColor(name string, color string) error {
+	switch strings.ToLower(name) {
+	case "primary":
+		return report.SetPrimary(color)
+	case "secondary":
+		return report.SetSecondary(color)
+	case "accent":
+		return report.SetAccent(color)
+	case "neutral":
+		return report.SetNeutral(color)
+	case "base100", "base-100":
+		return report.SetBase100(color)
+	case "info":
+		return report.SetInfo(color)
+	case "success":
+		return report.SetSuccess(color)
+	case "warning":
+		return report.SetWarning(color)
+	case "error", "err":
+		return report.SetErr(color)
+	}
+	return fmt.Errorf("unknown color %q", name)
+}
+
+// addBlock renders one ReportBlockSpec onto page.
+func (report *Report) addBlock(page string, block ReportBlockSpec, datasets map[string]*DataFrame) error {
+	dataset := func() (*DataFrame, error) {
+		df := datasets[block.Dataset]
+		if df == nil {
+			return nil, fmt.Errorf("unknown dataset %q", block.Dataset)
+		}
+		return df, nil
+	}
+
+	switch strings.ToLower(block.Type) {
+	case "heading":
+		report.AddHeading(page, block.Text, block.Size)
+	case "text":
+		report.AddText(page, block.Text)
+	case "subtext":
+		report.AddSubText(page, block.Text)
+	case "html":
+		report.AddHTML(page, block.Text)
+	case "bullets":
+		report.AddBullets(page, block.Items...)
+	case "dataframe":
+		df, err := dataset()
+		if err != nil {
+			return err
+		}
+		report.AddDataframe(page, df)
+	case "chart":
+		df, err := dataset()
+		if err != nil {
+			return err
+		}
+		if block.Chart == nil {
+			return fmt.Errorf("chart block has no chart")
+		}
+		chart, err := block.Chart.build(df)
+		if err != nil {
+			return err
+		}
+		report.AddChart(page, chart)
+	case "filter":
+		return report.AddFilter(page, block.Column, block.Kind)
+	default:
+		return fmt.Errorf("unknown block type %q", block.Type)
+	}
+	return nil
+}
+
+// build calls the chart builder named by Kind on df.
+func (c ReportChartSpec) build(df *DataFrame) (Chart, error) {
+	var opts []ChartOptions
+	if c.Options != nil {
+		opts = append(opts, *c.Options)
+	}
+	aggs := make([]Aggregation, 0, len(c.Aggs))
+	for _, a := range c.Aggs {
+		agg, ok := aggregationByName(a.Fn, a.Column)
+		if !ok {
+			return Chart{}, fmt.Errorf("unknown aggregation %q", a.Fn)
+		}
+		if a.Name != "" {
+			agg = agg.Alias(a.Name)
+		}
+		aggs = append(aggs, agg)
+	}
+	kind := strings.ToLower(c.Kind)
+	for _, a := range c.Aggs {
+		// Heatmap aggregates its value column, so its aggregation may leave Column empty
+		if _, ok := df.Data[a.Column]; !ok && !(kind == "heatmap" && a.Column == "") {
+			return Chart{}, fmt.Errorf("dataset has no column %q", a.Column)
+		}
+	}
+
+	// minimum columns and aggregations per builder
+	need := map[string][2]int{
+		"bar": {1, 1}, "column": {1, 1}, "stackedbar": {1, 1}, "stackedpercent": {1, 1},
+		"pie": {1, 1}, "line": {2, 0}, "area": {2, 0}, "scatter": {2, 0}, "bubble": {3, 0},
+		"treemap": {1, 1}, "histogram": {1, 0}, "boxplot": {1, 0}, "heatmap": {3, 1}, "datatable": {0, 0},
+	}
+	n, ok := need[kind]
+	if !ok {
+		return Chart{}, fmt.Errorf("unknown chart kind %q", c.Kind)
+	}
+	if len(c.Cols) < n[0] || len(aggs) < n[1] {
+		return Chart{}, fmt.Errorf("%s chart needs %d column(s) and %d aggregation(s)", kind, n[0], n[1])
+	}
+	// the first n[0] columns are required; later ones (e.g. a scatter group) may be ""
+	for i, col := range c.Cols {
+		if _, ok := df.Data[col]; !ok && (col != "" || i < n[0]) {
+			return Chart{}, fmt.Errorf("dataset has no column %q", col)
+		}
+	}
+	col := func(i int) string {
+		if i < len(c.Cols) {
+			return c.Cols[i]
+		}
+		return ""
+	}
+
+	switch kind {
+	case "bar":
+		return df.BarChart(c.Title, c.Subtitle, col(0), aggs, opts...), nil
+	case "column":
+		return df.ColumnChart(c.Title, c.Subtitle, col(0), aggs, opts...), nil
+	case "stackedbar":
+		return df.StackedBarChart(c.Title, c.Subtitle, col(0), aggs, opts...), nil
+	case "stackedpercent":
+		return df.StackedPercentChart(c.Title, c.Subtitle, col(0), aggs, opts...), nil
+	case "pie":
+		return df.PieChart(c.Title, c.Subtitle, col(0), aggs[0], opts...), nil
+	case "line":
+		return df.LineChart(c.Title, c.Subtitle, col(0), c.Cols[1:], opts...), nil
+	case "area":
+		return df.AreaChart(c.Title, c.Subtitle, col(0), c.Cols[1:], opts...), nil
+	case "scatter":
+		return df.ScatterPlot(c.Title, c.Subtitle, col(0), col(1), col(2), opts...), nil
+	case "bubble":
+		return df.BubbleChart(c.Title, c.Subtitle, col(0), col(1), col(2), col(3), opts...), nil
+	case "treemap":
+		return df.TreeMap(c.Title, c.Subtitle, c.Cols, aggs[0], opts...), nil
+	case "histogram":
+		return df.Histogram(col(0), c.Bins, opts...), nil
+	case "boxplot":
+		return df.BoxPlot(col(0), col(1), opts...), nil
+	case "heatmap":
+		return df.Heatmap(col(0), col(1), col(2), aggs[0], opts...), nil
+	}
+	return df.DataTable(c.Cols...), nil
+}
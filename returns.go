@@ -4,10 +4,14 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"math"
 	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 func (df *DataFrame) Columns() []string {
@@ -374,8 +378,7 @@ func (df *DataFrame) Dtypes() [][2]string {
 		wg.Add(1)
 		go func(idx int, col string) {
 			defer wg.Done()
-			data := df.Data[col]
-			t, _ := inferColumnType(data)
+			t, _ := df.columnType(col)
 			out[idx] = [2]string{col, t}
 		}(i, c)
 	}
@@ -395,8 +398,7 @@ func (df *DataFrame) Schema() []ColumnSchema {
 		wg.Add(1)
 		go func(idx int, col string) {
 			defer wg.Done()
-			data := df.Data[col]
-			t, nullable := inferColumnType(data)
+			t, nullable := df.columnType(col)
 			out[idx] = ColumnSchema{Name: col, Type: t, Nullable: nullable}
 		}(i, c)
 	}
@@ -431,6 +433,203 @@ func (df *DataFrame) SchemaJSON() string {
 	return string(b)
 }
 
+// describeTopK is the number of most frequent values Describe lists per column.
+const describeTopK = 5
+
+// Describe summarizes every column in one row: its type, the count of values,
+// nulls, NaNs and distinct values, the mean and sample standard deviation, min, the
+// requested percentiles (0.25, 0.5 and 0.75 by default, named "25%", ...) and
+// max, plus the most frequent values of string and boolean columns as
+// {"value", "count"} maps under "top". Mean, std and percentiles are computed
+// for numeric and timestamp columns; percentiles interpolate linearly. NaN is
+// counted under "nan" and, like null, left out of every other statistic. Rows
+// are scanned in parallel shards, as in GroupBy.
+func (df *DataFrame) Describe(percentiles ...float64) *DataFrame {
+	if len(percentiles) == 0 {
+		percentiles = []float64{0.25, 0.5, 0.75}
+	}
+	var pcts []float64
+	var pctNames []string
+	for _, p := range percentiles {
+		name := strconv.FormatFloat(math.Round(p*1e6)/1e4, 'f', -1, 64) + "%"
+		if math.IsNaN(p) || p < 0 || p > 1 {
+			fmt.Printf("Describe error: percentile %v is outside [0, 1]\n", p)
+			continue
+		}
+		if slices.Contains(pctNames, name) {
+			continue
+		}
+		pcts = append(pcts, p)
+		pctNames = append(pctNames, name)
+	}
+
+	cols := []string{"column", "type", "count", "nulls", "nan", "distinct", "mean", "std", "min"}
+	cols = append(cols, pctNames...)
+	cols = append(cols, "max", "top")
+	out := &DataFrame{Cols: cols, Data: make(map[string][]interface{}, len(cols))}
+	if df != nil {
+		out.Rows = len(df.Cols)
+	}
+	for _, c := range cols {
+		out.Data[c] = make([]interface{}, out.Rows)
+	}
+	if df == nil {
+		return out
+	}
+
+	for i, name := range df.Cols {
+		c := df.typedColumn(name)
+		t, _ := df.columnType(name)
+		st := describeColumn(c)
+		out.Data["column"][i] = name
+		out.Data["type"][i] = t
+		out.Data["count"][i] = st.count
+		out.Data["nulls"][i] = c.n - st.count - st.nan
+		out.Data["nan"][i] = st.nan
+		out.Data["distinct"][i] = len(st.freq)
+		if st.count == 0 {
+			continue
+		}
+		if c.kind != kindNested {
+			out.Data["min"][i] = c.value(st.min)
+			out.Data["max"][i] = c.value(st.max)
+		}
+
+		switch c.kind {
+		case kindInt64, kindFloat64:
+			out.Data["mean"][i] = st.mean
+			if st.count > 1 {
+				out.Data["std"][i] = math.Sqrt(st.m2 / float64(st.count-1))
+			}
+			sort.Float64s(st.values)
+			for j, p := range pcts {
+				out.Data[pctNames[j]][i] = quantile(st.values, p)
+			}
+		case kindTimestamp:
+			loc := c.times[st.min].Location()
+			out.Data["mean"][i] = time.Unix(0, int64(st.mean)).In(loc)
+			sort.Float64s(st.values)
+			for j, p := range pcts {
+				out.Data[pctNames[j]][i] = time.Unix(0, int64(quantile(st.values, p))).In(loc)
+			}
+		case kindString, kindBool:
+			order := append([]typedKey(nil), st.order...)
+			sort.SliceStable(order, func(a, b int) bool { return st.freq[order[a]].n > st.freq[order[b]].n })
+			if len(order) > describeTopK {
+				order = order[:describeTopK]
+			}
+			top := make([]interface{}, len(order))
+			for j, k := range order {
+				f := st.freq[k]
+				top[j] = map[string]interface{}{"value": c.value(f.first), "count": f.n}
+			}
+			out.Data["top"][i] = top
+		}
+	}
+	return out
+}
+
+// describeStats accumulates the statistics of one column over a range of rows.
+type describeStats struct {
+	count    int
+	nan      int
+	mean, m2 float64 // Welford running mean and sum of squared deviations
+	min, max int     // rows holding the smallest and largest values, -1 if none
+	values   []float64
+	freq     map[typedKey]*describeFreq
+	order    []typedKey // distinct keys in first-appearance order
+}
+
+// describeFreq counts one distinct value and remembers the row it first appears in.
+type describeFreq struct {
+	first int
+	n     int
+}
+
+// describeColumn scans a typed column in parallel shards and merges the
+// shard statistics in shard order.
+func describeColumn(c *typedColumn) *describeStats {
+	w := runtime.GOMAXPROCS(0)
+	chunk := (c.n + w - 1) / w
+	if chunk < 1 {
+		chunk = 1
+	}
+	var shards []*describeStats
+	var wg sync.WaitGroup
+	for s := 0; s < c.n; s += chunk {
+		e := s + chunk
+		if e > c.n {
+			e = c.n
+		}
+		st := &describeStats{min: -1, max: -1, freq: map[typedKey]*describeFreq{}}
+		shards = append(shards, st)
+		wg.Add(1)
+		go func(st *describeStats, s, e int) {
+			defer wg.Done()
+			for i := s; i < e; i++ {
+				if !c.isValid(i) {
+					continue
+				}
+				if c.kind == kindFloat64 && math.IsNaN(c.floats[i]) {
+					st.nan++
+					continue
+				}
+				st.count++
+				if f, ok := c.float(i); ok {
+					d := f - st.mean
+					st.mean += d / float64(st.count)
+					st.m2 += d * (f - st.mean)
+					st.values = append(st.values, f)
+				}
+				if st.min < 0 || c.compare(i, st.min) < 0 {
+					st.min = i
+				}
+				if st.max < 0 || c.compare(i, st.max) > 0 {
+					st.max = i
+				}
+				k := c.key(i)
+				if f := st.freq[k]; f != nil {
+					f.n++
+				} else {
+					st.freq[k] = &describeFreq{first: i, n: 1}
+					st.order = append(st.order, k)
+				}
+			}
+		}(st, s, e)
+	}
+	wg.Wait()
+
+	total := &describeStats{min: -1, max: -1, freq: map[typedKey]*describeFreq{}}
+	for _, st := range shards {
+		total.nan += st.nan
+		if st.count == 0 {
+			continue
+		}
+		// parallel variance combination (Chan et al.)
+		n := total.count + st.count
+		d := st.mean - total.mean
+		total.mean += d * float64(st.count) / float64(n)
+		total.m2 += st.m2 + d*d*float64(total.count)*float64(st.count)/float64(n)
+		total.count = n
+		total.values = append(total.values, st.values...)
+		if total.min < 0 || c.compare(st.min, total.min) < 0 {
+			total.min = st.min
+		}
+		if total.max < 0 || c.compare(st.max, total.max) > 0 {
+			total.max = st.max
+		}
+		for _, k := range st.order {
+			if f := total.freq[k]; f != nil {
+				f.n += st.freq[k].n
+			} else {
+				total.freq[k] = st.freq[k]
+				total.order = append(total.order, k)
+			}
+		}
+	}
+	return total
+}
+
 // --- helpers ---
 
 // inferColumnType inspects all values in a column and returns a Spark-like type string and nullability.
@@ -452,6 +651,16 @@ func inferColumnType(col []interface{}) (string, bool) {
 	return elemType, nullable
 }
 
+// columnType is inferColumnType for column col of df, with a date column
+// (see DataFrame.Dates) typed "date" rather than "timestamp".
+func (df *DataFrame) columnType(col string) (string, bool) {
+	t, nullable := inferColumnType(df.Data[col])
+	if t == "timestamp" && df.Dates[col] {
+		t = "date"
+	}
+	return t, nullable
+}
+
 // typeString maps Go values to Spark-ish types.
 func typeString(v interface{}) string {
 	switch t := v.(type) {
@@ -463,6 +672,8 @@ func typeString(v interface{}) string {
 		return "int"
 	case float32, float64:
 		return "float"
+	case time.Time:
+		return "timestamp"
 	case []string:
 		// array<string>
 		return "array<string>"
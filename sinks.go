@@ -1,6 +1,7 @@
 package gophers
 
 import (
+	"bytes"
 	"database/sql"
 	"encoding/csv"
 	"encoding/json"
@@ -14,26 +15,57 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"golang.org/x/text/transform"
 )
 
-func (df *DataFrame) ToCSVFile(filename string) error {
+// ToCSVFile writes the DataFrame as CSV. An optional CSVOptions sets the
+// delimiter, header row, null token, output encoding and line ending (CRLF by default).
+// Times are written as ISO-8601 ("2006-01-02" for dates) unless TimeFormat is set.
+func (df *DataFrame) ToCSVFile(filename string, opts ...CSVOptions) error {
     if filename == "" {
         filename = "dataframe.csv"
     }
+    var opt CSVOptions
+    if len(opts) > 0 {
+        opt = opts[0]
+    }
     f, err := os.Create(filename)
     if err != nil {
         return err
     }
     defer f.Close()
 
-    w := csv.NewWriter(f)
-    // Windows-friendly newlines
-    w.UseCRLF = true
+    var out io.Writer = f
+    if opt.Encoding != "" {
+        enc, err := csvEncoder(opt.Encoding)
+        if err != nil {
+            return err
+        }
+        tw := transform.NewWriter(f, enc)
+        defer tw.Close()
+        out = tw
+    }
+
+    w := csv.NewWriter(out)
+    // Windows-friendly newlines unless LF is asked for
+    w.UseCRLF = opt.LineEnding != "\n"
+    if d := []rune(opt.Delimiter); len(d) > 0 {
+        w.Comma = d[0]
+    }
+    null := ""
+    if len(opt.NullValues) > 0 {
+        null = opt.NullValues[0]
+    }
+    dates := dateColumns(df)
     defer w.Flush()
 
     // Header
-    if err := w.Write(df.Cols); err != nil {
-        return err
+    if !opt.NoHeader {
+        if err := w.Write(df.Cols); err != nil {
+            return err
+        }
     }
 
     // Rows
@@ -49,11 +81,13 @@ func (df *DataFrame) ToCSVFile(filename string) error {
             }
             switch x := v.(type) {
             case nil:
-                record[j] = ""
+                record[j] = null
             case string:
                 record[j] = x
             case []byte:
                 record[j] = string(x)
+            case time.Time:
+                record[j] = isoTime(x, opt.TimeFormat, dates[col])
             case []interface{}:
                 b, _ := json.Marshal(x)
                 record[j] = string(b)
@@ -67,6 +101,7 @@ func (df *DataFrame) ToCSVFile(filename string) error {
             return err
         }
     }
+    w.Flush()
     return w.Error()
 }
 // // dataframe to json file
@@ -167,80 +202,214 @@ func (df *DataFrame) safeGet(col string, i int) any {
     }
     return arr[i]
 }
-// ...existing code...
-func (df *DataFrame) ToJSONFile(filename string) error {
+
+// jsonOptionsOf returns the first of opts, or the zero JSONOptions.
+func jsonOptionsOf(opts []JSONOptions) JSONOptions {
+    if len(opts) > 0 {
+        return opts[0]
+    }
+    return JSONOptions{}
+}
+
+// jsonRow builds row i for the JSON sinks, writing times as ISO-8601
+// ("2006-01-02" in date columns) or with the TimeFormat of opt.
+func (df *DataFrame) jsonRow(i int, opt JSONOptions, dates map[string]bool) map[string]interface{} {
+    row := make(map[string]interface{}, len(df.Cols))
+    for _, col := range df.Cols {
+        v := df.safeGet(col, i)
+        if t, ok := v.(time.Time); ok {
+            v = isoTime(t, opt.TimeFormat, dates[col])
+        }
+        row[col] = v
+    }
+    return row
+}
+
+// ToJSONFile writes the DataFrame as a JSON array of row objects.
+func (df *DataFrame) ToJSONFile(filename string, opts ...JSONOptions) error {
     file, err := os.Create(filename)
     if err != nil { return err }
     defer file.Close()
     enc := json.NewEncoder(file)
+    opt, dates := jsonOptionsOf(opts), dateColumns(df)
     rows := make([]map[string]interface{}, df.Rows)
     for i := 0; i < df.Rows; i++ {
-        row := make(map[string]interface{}, len(df.Cols))
-        for _, col := range df.Cols {
-            row[col] = df.safeGet(col, i)
-        }
-        rows[i] = row
+        rows[i] = df.jsonRow(i, opt, dates)
     }
     return enc.Encode(rows)
 }
 
-func (df *DataFrame) ToJSON() string {
+// ToJSON returns the DataFrame as a JSON array of row objects.
+func (df *DataFrame) ToJSON(opts ...JSONOptions) string {
+    opt, dates := jsonOptionsOf(opts), dateColumns(df)
     rows := make([]map[string]interface{}, df.Rows)
     for i := 0; i < df.Rows; i++ {
-        row := make(map[string]interface{}, len(df.Cols))
-        for _, col := range df.Cols {
-            row[col] = df.safeGet(col, i)
-        }
-        rows[i] = row
+        rows[i] = df.jsonRow(i, opt, dates)
     }
     b, _ := json.Marshal(rows)
     return string(b)
 }
 
-func (df *DataFrame) ToNDJSONFile(filename string) error {
+// ToNDJSONFile writes the DataFrame as newline-delimited JSON, one row object per line.
+func (df *DataFrame) ToNDJSONFile(filename string, opts ...JSONOptions) error {
     file, err := os.Create(filename)
     if err != nil { return err }
     defer file.Close()
     enc := json.NewEncoder(file)
+    opt, dates := jsonOptionsOf(opts), dateColumns(df)
     for i := 0; i < df.Rows; i++ {
-        row := make(map[string]interface{}, len(df.Cols))
-        for _, col := range df.Cols {
-            row[col] = df.safeGet(col, i)
-        }
-        if err := enc.Encode(row); err != nil {
+        if err := enc.Encode(df.jsonRow(i, opt, dates)); err != nil {
             return err
         }
     }
     return nil
 }
 
-// write to parquet?
-func (df *DataFrame) ToParquetFile(filename string) error {
-	// fw, err := ParquetFile.NewLocalFileWriter(filename)
-	// if err != nil {
-	// 	return err
-	// }
-	// defer fw.Close()
-
-	// pw, err := Writer.NewParquetWriter(fw, new(map[string]interface{}), 4)
-	// if err != nil {
-	// 	return err
-	// }
-	// defer pw.WriteStop()
-
-	// for i := 0; i < df.Rows; i++ {
-	// 	row := make(map[string]interface{})
-	// 	for _, col := range df.Cols {
-	// 		row[col] = df.Data[col][i]
-	// 	}
-	// 	if err := pw.Write(row); err != nil {
-	// 		return err
-	// 	}
-	// }
+// ToParquetFile writes the DataFrame to a parquet file. Column types come from
+// Schema(); arrays become parquet lists and maps become groups. An optional
+// ParquetOptions sets the compression codec (snappy by default) and row group size.
+func (df *DataFrame) ToParquetFile(filename string, opts ...ParquetOptions) error {
+	if filename == "" {
+		filename = "dataframe.parquet"
+	}
+	var opt ParquetOptions
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	codec, err := parquetCodec(opt.Compression)
+	if err != nil {
+		return err
+	}
 
+	group := parquet.Group{}
+	for _, col := range df.Cols {
+		if df.Dates[col] && allTimes(df.Data[col]) {
+			// date columns are written as DATE so they read back as dates
+			group[col] = parquet.Optional(parquet.Date())
+			continue
+		}
+		group[col] = parquetNodeOf(df.Data[col])
+	}
+	schema := parquet.NewSchema("dataframe", group)
+	nodes := parquetRoot(schema)
+	numLeaves := len(schema.Columns())
+
+	// Shred rows into parquet rows in parallel, preserving order.
+	rows := make([]parquet.Row, df.Rows)
+	w := runtime.GOMAXPROCS(0)
+	chunk := (df.Rows + w - 1) / w
+	if chunk < 1 {
+		chunk = 1
+	}
+	var wg sync.WaitGroup
+	for start := 0; start < df.Rows; start += chunk {
+		end := start + chunk
+		if end > df.Rows {
+			end = df.Rows
+		}
+		wg.Add(1)
+		go func(s, e int) {
+			defer wg.Done()
+			out := make([][]parquet.Value, numLeaves)
+			for i := s; i < e; i++ {
+				for l := range out {
+					out[l] = out[l][:0]
+				}
+				for _, node := range nodes {
+					node.shred(df.safeGet(node.name, i), 0, 0, out)
+				}
+				var row parquet.Row
+				for _, vals := range out {
+					row = append(row, vals...)
+				}
+				rows[i] = row
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	order, _ := json.Marshal(df.Cols)
+	writerOpts := []parquet.WriterOption{
+		schema,
+		parquet.Compression(codec),
+		parquet.KeyValueMetadata(parquetColumnsKey, string(order)),
+	}
+	if opt.RowGroupSize > 0 {
+		writerOpts = append(writerOpts, parquet.MaxRowsPerRowGroup(int64(opt.RowGroupSize)))
+	}
+	pw := parquet.NewWriter(f, writerOpts...)
+	if _, err := pw.WriteRows(rows); err != nil {
+		return err
+	}
+	return pw.Close()
+}
+
+// ToXLSXFile writes the DataFrame to filename as an Excel workbook with one
+// sheet. Numbers, bools and times become typed cells (times with a date format,
+// without a time of day for date columns) under a bold, frozen header row. An
+// existing file is replaced; use WriteXLSX to write several DataFrames to one
+// workbook. sheet defaults to "Sheet1".
+func (df *DataFrame) ToXLSXFile(filename string, sheet string) error {
+	if filename == "" {
+		filename = "dataframe.xlsx"
+	}
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+	if err := saveXLSX(filename, []XLSXSheet{{Name: sheet, DataFrame: df}}); err != nil {
+		return fmt.Errorf("ToXLSXFile: %w", err)
+	}
 	return nil
 }
 
+// WriteXLSX writes the sheets, in order, to filename as one Excel workbook,
+// replacing any existing file. Sheet names must be unique (Excel ignores case)
+// and follow Excel's naming rules; an empty name becomes "Sheet<n>".
+func WriteXLSX(filename string, sheets ...XLSXSheet) error {
+	if len(sheets) == 0 {
+		return fmt.Errorf("WriteXLSX: no sheets")
+	}
+	if err := saveXLSX(filename, sheets); err != nil {
+		return fmt.Errorf("WriteXLSX: %w", err)
+	}
+	return nil
+}
+
+// saveXLSX checks the sheet names and writes the workbook to filename.
+func saveXLSX(filename string, sheets []XLSXSheet) error {
+	seen := map[string]bool{}
+	out := make([]XLSXSheet, len(sheets))
+	for i, sheet := range sheets {
+		if sheet.Name == "" {
+			sheet.Name = fmt.Sprintf("Sheet%d", i+1)
+		}
+		if err := validSheetName(sheet.Name); err != nil {
+			return err
+		}
+		key := strings.ToLower(sheet.Name)
+		if seen[key] {
+			return fmt.Errorf("duplicate sheet name %q", sheet.Name)
+		}
+		seen[key] = true
+		if sheet.DataFrame == nil {
+			sheet.DataFrame = &DataFrame{Data: map[string][]interface{}{}}
+		}
+		out[i] = sheet
+	}
+
+	var buf bytes.Buffer
+	if err := writeXLSX(&buf, out); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, buf.Bytes(), 0644)
+}
+
 // write to table? (mongo, postgres, mysql, sqlite, etc)
 // JDBC?
 
@@ -265,6 +434,15 @@ func inferSQLiteTypes(df *DataFrame) map[string]string {
 					sqlType = "REAL"
 				case []byte:
 					sqlType = "BLOB"
+				case time.Time:
+					// DATE and TIMESTAMP keep the ISO-8601 text readable as times
+					sqlType = "TEXT"
+					switch t, _ := df.columnType(col); t {
+					case "date":
+						sqlType = "DATE"
+					case "timestamp":
+						sqlType = "TIMESTAMP"
+					}
 				default:
 					sqlType = "TEXT"
 				}
@@ -276,6 +454,15 @@ func inferSQLiteTypes(df *DataFrame) map[string]string {
 	return types
 }
 
+// sqliteValue converts a value for binding: times are stored as ISO-8601 text,
+// without a time of day in date columns.
+func sqliteValue(v interface{}, date bool) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return isoTime(t, "", date)
+	}
+	return v
+}
+
 func tableExists(tx *sql.Tx, table string) (bool, error) {
 	var cnt int
 	row := tx.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?`, table)
@@ -355,6 +542,8 @@ func upsertSqliteTx(tx *sql.Tx, table string, df *DataFrame, keys []string, crea
 		_, _ = tx.Exec(fmt.Sprintf(`CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (%s)`, quoteIdent(ixName), quoteIdent(table), strings.Join(qKeys, ",")))
 	}
 
+	dates := dateColumns(df)
+
 	// Try modern ON CONFLICT DO UPDATE (SQLite >= 3.24.0)
 	colsQuoted := make([]string, 0, len(df.Cols))
 	valHolders := make([]string, 0, len(df.Cols))
@@ -388,7 +577,7 @@ func upsertSqliteTx(tx *sql.Tx, table string, df *DataFrame, keys []string, crea
 		for i := 0; i < df.Rows; i++ {
 			args := make([]interface{}, 0, len(df.Cols))
 			for _, c := range df.Cols {
-				args = append(args, sql.Named(c, df.Data[c][i]))
+				args = append(args, sql.Named(c, sqliteValue(df.Data[c][i], dates[c])))
 			}
 			if _, err := stmt.Exec(args...); err != nil {
 				return fmt.Errorf("UpsertSqlite: exec upsert error at row %d: %w", i, err)
@@ -432,11 +621,11 @@ func upsertSqliteTx(tx *sql.Tx, table string, df *DataFrame, keys []string, crea
 		upArgs := make([]interface{}, 0, len(setQ)+len(keys))
 		for _, c := range df.Cols {
 			if _, isKey := keySet[c]; !isKey {
-				upArgs = append(upArgs, df.Data[c][i])
+				upArgs = append(upArgs, sqliteValue(df.Data[c][i], dates[c]))
 			}
 		}
 		for _, k := range keys {
-			upArgs = append(upArgs, df.Data[k][i])
+			upArgs = append(upArgs, sqliteValue(df.Data[k][i], dates[k]))
 		}
 		res, err := upStmt.Exec(upArgs...)
 		if err != nil {
@@ -445,7 +634,7 @@ func upsertSqliteTx(tx *sql.Tx, table string, df *DataFrame, keys []string, crea
 		if aff, _ := res.RowsAffected(); aff == 0 {
 			insArgs := make([]interface{}, 0, len(df.Cols))
 			for _, c := range df.Cols {
-				insArgs = append(insArgs, df.Data[c][i])
+				insArgs = append(insArgs, sqliteValue(df.Data[c][i], dates[c]))
 			}
 			if _, err := inStmt.Exec(insArgs...); err != nil {
 				return fmt.Errorf("UpsertSqlite: insert error at row %d: %w", i, err)
@@ -504,6 +693,7 @@ func (df *DataFrame) WriteSqlite(dbPath string, table string, mode string, keys
 			return fmt.Errorf("WriteSqlite: delete error: %w", err)
 		}
 		// Insert all rows
+		dates := dateColumns(df)
 		colsQuoted := make([]string, 0, len(df.Cols))
 		valQ := make([]string, 0, len(df.Cols))
 		for _, c := range df.Cols {
@@ -519,7 +709,7 @@ func (df *DataFrame) WriteSqlite(dbPath string, table string, mode string, keys
 		for i := 0; i < df.Rows; i++ {
 			args := make([]interface{}, 0, len(df.Cols))
 			for _, c := range df.Cols {
-				args = append(args, sql.Named(c, df.Data[c][i]))
+				args = append(args, sql.Named(c, sqliteValue(df.Data[c][i], dates[c])))
 			}
 			if _, err := stmt.Exec(args...); err != nil {
 				return fmt.Errorf("WriteSqlite: insert error at row %d: %w", i, err)
@@ -1,10 +1,11 @@
 package gophers
 
 import (
+	"archive/zip"
 	"bytes"
 	"database/sql"
-	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -22,9 +23,8 @@ import (
 
 	"gopkg.in/yaml.v2"
 
-	// "github.com/xitongsys/parquet-go/ParquetFile"
-	// "github.com/xitongsys/parquet-go/Writer"
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/parquet-go/parquet-go"
 )
 
 // Create dataframe function
@@ -115,66 +115,104 @@ func fileExists(filename string) bool {
 	return !info.IsDir()
 }
 
+// jsonErrorOffset returns the byte offset carried by a JSON syntax or type error.
+func jsonErrorOffset(err error) (int64, bool) {
+	var se *json.SyntaxError
+	if errors.As(err, &se) {
+		return se.Offset, true
+	}
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) {
+		return te.Offset, true
+	}
+	return 0, false
+}
+
+// textPosition returns the 1-based line and column of byte offset off in text.
+func textPosition(text string, off int64) (int, int) {
+	if off < 0 {
+		off = 0
+	}
+	if off > int64(len(text)) {
+		off = int64(len(text))
+	}
+	before := text[:off]
+	line := strings.Count(before, "\n") + 1
+	col := len(before) - strings.LastIndex(before, "\n")
+	if col < 1 {
+		col = 1
+	}
+	return line, col
+}
+
+// ndjsonLineError reports a bad NDJSON line with its line and column number.
+func ndjsonLineError(text string, line int, err error) error {
+	if off, ok := jsonErrorOffset(err); ok {
+		_, col := textPosition(text, off)
+		return fmt.Errorf("line %d, column %d: %w", line, col, err)
+	}
+	return fmt.Errorf("line %d: %w", line, err)
+}
+
 // Functions for intaking data and returning dataframe
 // ReadCSV parses CSV from a file path or raw CSV text and returns a DataFrame (pure Go).
-func ReadCSV(input string) *DataFrame {
-	// If input is a file path, read it; otherwise treat as raw CSV text.
-	csvContent := input
-	if fi, err := os.Stat(input); err == nil && !fi.IsDir() {
-		b, err := os.ReadFile(input)
-		if err != nil {
-			log.Fatalf("ReadCSV: read file: %v", err)
-		}
-		csvContent = string(b)
+// An optional CSVOptions sets the delimiter, header mode, comment prefix, null
+// tokens, type inference and source encoding. Repeated header names get a
+// suffix: a, a_2, a_3.
+func ReadCSV(input string, opts ...CSVOptions) (*DataFrame, error) {
+	var opt CSVOptions
+	if len(opts) > 0 {
+		opt = opts[0]
 	}
+	return readCSV(input, nil, nil, opt)
+}
 
-	r := csv.NewReader(strings.NewReader(csvContent))
-	headers, err := r.Read()
+// readCSV reads CSV from a file path or raw text. When columns is non-nil only
+// those columns are kept, and rows failing any of preds are skipped while reading.
+func readCSV(input string, columns []string, preds []Column, opt CSVOptions) (*DataFrame, error) {
+	s, err := newCSVScanner(input, columns, preds, opt)
 	if err != nil {
-		log.Fatalf("ReadCSV: read headers: %v", err)
+		return nil, fmt.Errorf("ReadCSV: %w", err)
 	}
-
-	rows := make([]map[string]interface{}, 0, 1024)
-	for {
-		record, err := r.Read()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			log.Fatalf("ReadCSV: read record: %v", err)
-		}
-		row := make(map[string]interface{}, len(headers))
-		for i, h := range headers {
-			if i < len(record) {
-				row[h] = record[i]
-			} else {
-				row[h] = ""
-			}
-		}
-		rows = append(rows, row)
+	defer s.Close()
+	df, _, err := s.read(0)
+	if err != nil {
+		return nil, fmt.Errorf("ReadCSV: %w", err)
 	}
-	return Dataframe(rows)
+	return df, nil
 }
 
 // Pure Go: parse path-or-JSON into a DataFrame, no cgo types.
-func ReadJSON(input string) *DataFrame {
+func ReadJSON(input string) (*DataFrame, error) {
 	// Allow file path or raw JSON
 	jsonContent := input
 	if fileExists(input) {
 		bytes, err := os.ReadFile(input)
 		if err != nil {
-			log.Fatalf("ReadJSONCore: read file: %v", err)
+			return nil, fmt.Errorf("ReadJSON: read file: %w", err)
 		}
 		jsonContent = string(bytes)
 	}
 
 	trimmed := strings.TrimSpace(jsonContent)
 	if len(trimmed) == 0 {
-		return &DataFrame{Cols: []string{}, Data: map[string][]interface{}{}, Rows: 0}
+		return &DataFrame{Cols: []string{}, Data: map[string][]interface{}{}, Rows: 0}, nil
+	}
+
+	// errors report line/column in the original text
+	source := jsonContent
+	var shift int64
+	posErr := func(what string, err error) error {
+		if off, ok := jsonErrorOffset(err); ok {
+			line, col := textPosition(source, off+shift)
+			return fmt.Errorf("ReadJSON: %s at line %d, column %d: %w", what, line, col, err)
+		}
+		return fmt.Errorf("ReadJSON: %s: %w", what, err)
 	}
 
 	// Single object -> wrap in array
 	if trimmed[0] == '{' {
+		shift = int64(len(source)-len(strings.TrimLeft(source, " \t\r\n"))) - 1
 		jsonContent = "[" + trimmed + "]"
 		trimmed = jsonContent
 	}
@@ -184,19 +222,19 @@ func ReadJSON(input string) *DataFrame {
 		dec := json.NewDecoder(strings.NewReader(jsonContent))
 		tok, err := dec.Token()
 		if err != nil || tok != json.Delim('[') {
-			log.Fatalf("ReadJSONCore: decode start: %v", err)
+			return nil, posErr("decode start", err)
 		}
 
 		raws := make([]json.RawMessage, 0, 1024)
 		for dec.More() {
 			var rm json.RawMessage
 			if err := dec.Decode(&rm); err != nil {
-				log.Fatalf("ReadJSONCore: decode element: %v", err)
+				return nil, posErr(fmt.Sprintf("decode element %d", len(raws)), err)
 			}
 			raws = append(raws, rm)
 		}
 		if _, err := dec.Token(); err != nil {
-			log.Fatalf("ReadJSONCore: decode end: %v", err)
+			return nil, posErr("decode end", err)
 		}
 
 		rows := make([]map[string]interface{}, len(raws))
@@ -230,31 +268,31 @@ func ReadJSON(input string) *DataFrame {
 			}
 			wg.Wait()
 		}
-		return Dataframe(rows)
+		return Dataframe(rows), nil
 	}
 
 	// Fallback
 	var rows []map[string]interface{}
 	if err := json.Unmarshal([]byte(jsonContent), &rows); err != nil {
-		log.Fatalf("ReadJSONCore: unmarshal: %v", err)
+		return nil, posErr("unmarshal", err)
 	}
-	return Dataframe(rows)
+	return Dataframe(rows), nil
 }
 
 // Pure Go NDJSON reader: path-or-string -> *DataFrame
-func ReadNDJSON(input string) *DataFrame {
+func ReadNDJSON(input string) (*DataFrame, error) {
     // If input is a file path, load file contents.
     if fileExists(input) {
         b, err := os.ReadFile(input)
         if err != nil {
-            log.Fatalf("ReadNDJSONCore: read file: %v", err)
+            return nil, fmt.Errorf("ReadNDJSON: read file: %w", err)
         }
         input = string(b)
     }
     lines := strings.Split(input, "\n")
     n := len(lines)
     if n == 0 {
-        return Dataframe([]map[string]interface{}{})
+        return Dataframe([]map[string]interface{}{}), nil
     }
 
     // Pass 1: build per-shard masks and counts (non-empty lines)
@@ -302,6 +340,7 @@ func ReadNDJSON(input string) *DataFrame {
         total += counts[i]
     }
     rows := make([]map[string]interface{}, total)
+    errs := make([]error, w)
 
     // Pass 2: scatter decoded rows in order
     for g := 0; g < w; g++ {
@@ -316,7 +355,7 @@ func ReadNDJSON(input string) *DataFrame {
         base := offsets[g]
         mask := masks[g]
         wg.Add(1)
-        go func(s, e, outStart int, mask []bool) {
+        go func(idx, s, e, outStart int, mask []bool) {
             defer wg.Done()
             out := outStart
             var tmp map[string]interface{}
@@ -324,32 +363,38 @@ func ReadNDJSON(input string) *DataFrame {
                 if !mask[i-s] {
                     continue
                 }
-                raw := strings.TrimSpace(lines[i])
-                // Decode; on error leave nil row (safe for Dataframe)
-                if err := json.Unmarshal([]byte(raw), &tmp); err == nil {
-                    // copy map to avoid races on tmp reuse
-                    m := make(map[string]interface{}, len(tmp))
-                    for k, v := range tmp {
-                        m[k] = v
-                    }
-                    rows[out] = m
+                // Decode; the first bad line of the shard is reported
+                if err := json.Unmarshal([]byte(lines[i]), &tmp); err != nil {
+                    errs[idx] = ndjsonLineError(lines[i], i+1, err)
+                    return
+                }
+                // copy map to avoid races on tmp reuse
+                m := make(map[string]interface{}, len(tmp))
+                for k, v := range tmp {
+                    m[k] = v
                 }
+                rows[out] = m
                 out++
             }
-        }(start, end, base, mask)
+        }(g, start, end, base, mask)
     }
     wg.Wait()
+    for _, err := range errs {
+        if err != nil {
+            return nil, fmt.Errorf("ReadNDJSON: %w", err)
+        }
+    }
 
-    return Dataframe(rows)
+    return Dataframe(rows), nil
 }
 // Pure Go: YAML path-or-string -> *DataFrame
-func ReadYAML(input string) *DataFrame {
+func ReadYAML(input string) (*DataFrame, error) {
 	// Treat input as a file path if it exists, else as raw YAML text.
 	yamlContent := input
 	if fileExists(input) {
 		b, err := os.ReadFile(input)
 		if err != nil {
-			log.Fatalf("ReadYAMLCore: read file: %v", err)
+			return nil, fmt.Errorf("ReadYAML: read file: %w", err)
 		}
 		yamlContent = string(b)
 	}
@@ -357,14 +402,14 @@ func ReadYAML(input string) *DataFrame {
 	// Unmarshal into generic interface to support map or list roots.
 	var any interface{}
 	if err := yaml.Unmarshal([]byte(yamlContent), &any); err != nil {
-		log.Fatalf("ReadYAMLCore: unmarshal: %v", err)
+		return nil, fmt.Errorf("ReadYAML: unmarshal: %w", err)
 	}
 
 	switch v := any.(type) {
 	case map[interface{}]interface{}:
 		// Single object -> one-row DataFrame
 		rows := mapToRows(convertMapKeysToString(v))
-		return Dataframe(rows)
+		return Dataframe(rows), nil
 	case []interface{}:
 		// List of objects -> multi-row DataFrame
 		rows := make([]map[string]interface{}, 0, len(v))
@@ -379,124 +424,169 @@ func ReadYAML(input string) *DataFrame {
 				rows = append(rows, map[string]interface{}{"value": m})
 			}
 		}
-		return Dataframe(rows)
+		return Dataframe(rows), nil
 	default:
 		// Scalar -> single-row DataFrame with generic column
-		return Dataframe([]map[string]interface{}{{"value": v}})
+		return Dataframe([]map[string]interface{}{{"value": v}}), nil
 	}
 }
 
-// ReadParquet reads a parquet file or newline-delimited JSON content (fallback) and builds a DataFrame.
-// Concurrency is used to parse each line in parallel while preserving order.
-func ReadParquet(input string) *DataFrame {
-    // If input is a file path, load its contents.
+// ReadParquet reads a parquet file (or raw parquet bytes) and builds a DataFrame.
+// Row groups are decoded in parallel; nested lists and groups become
+// []interface{} and map[string]interface{} values.
+func ReadParquet(input string) (*DataFrame, error) {
+    var r io.ReaderAt
+    var size int64
     if fileExists(input) {
-        bytes, err := os.ReadFile(input)
+        f, err := os.Open(input)
         if err != nil {
-            log.Fatalf("ReadParquet: read file error: %v", err)
+            return nil, fmt.Errorf("ReadParquet: open file error: %w", err)
         }
-        input = string(bytes)
-    }
-
-    lines := strings.Split(input, "\n")
-    n := len(lines)
-    if n == 0 {
-        return Dataframe([]map[string]interface{}{})
+        defer f.Close()
+        info, err := f.Stat()
+        if err != nil {
+            return nil, fmt.Errorf("ReadParquet: stat file error: %w", err)
+        }
+        r, size = f, info.Size()
+    } else {
+        r, size = strings.NewReader(input), int64(len(input))
     }
 
-    // Pass 1: mask + counts (non-empty trimmed lines)
-    w := runtime.GOMAXPROCS(0)
-    if w < 1 {
-        w = 1
+    pf, err := parquet.OpenFile(r, size)
+    if err != nil {
+        return nil, fmt.Errorf("ReadParquet: open parquet error: %w", err)
     }
-    chunk := (n + w - 1) / w
-
-    masks := make([][]bool, w)
-    counts := make([]int, w)
+    nodes := parquetRoot(pf.Schema())
 
+    groups := pf.RowGroups()
+    parts := make([]map[string][]interface{}, len(groups))
+    errs := make([]error, len(groups))
     var wg sync.WaitGroup
-    for g := 0; g < w; g++ {
-        start := g * chunk
-        end := start + chunk
-        if start >= n {
-            break
-        }
-        if end > n {
-            end = n
-        }
+    for g, rg := range groups {
         wg.Add(1)
-        go func(idx, s, e int) {
+        go func(idx int, rg parquet.RowGroup) {
             defer wg.Done()
-            mask := make([]bool, e-s)
-            cnt := 0
-            for i := s; i < e; i++ {
-                if strings.TrimSpace(lines[i]) != "" {
-                    mask[i-s] = true
-                    cnt++
-                }
-            }
-            masks[idx] = mask
-            counts[idx] = cnt
-        }(g, start, end)
+            parts[idx], errs[idx] = readParquetRowGroup(rg, nodes)
+        }(g, rg)
     }
     wg.Wait()
 
-    // Prefix-sum to compute output offsets
-    total := 0
-    offsets := make([]int, w)
-    for i := 0; i < w; i++ {
-        offsets[i] = total
-        total += counts[i]
-    }
-    rows := make([]map[string]interface{}, total)
-
-    // Pass 2: scatter decoded rows in order
-    for g := 0; g < w; g++ {
-        start := g * chunk
-        end := start + chunk
-        if start >= n {
-            break
+    df := &DataFrame{Data: make(map[string][]interface{}, len(nodes))}
+    for _, node := range nodes {
+        df.Cols = append(df.Cols, node.name)
+        col := make([]interface{}, 0, pf.NumRows())
+        for g := range parts {
+            if errs[g] != nil {
+                return nil, fmt.Errorf("ReadParquet: row group %d: %w", g, errs[g])
+            }
+            col = append(col, parts[g][node.name]...)
         }
-        if end > n {
-            end = n
+        df.Data[node.name] = col
+        if node.kind == pqLeaf {
+            if lt := node.typ.LogicalType(); lt != nil && lt.Date != nil {
+                df.setDate(node.name, true)
+            }
         }
-        base := offsets[g]
-        mask := masks[g]
-        wg.Add(1)
-        go func(s, e, outStart int, mask []bool) {
-            defer wg.Done()
-            out := outStart
-            var tmp map[string]interface{}
-            for i := s; i < e; i++ {
-                if !mask[i-s] {
-                    continue
-                }
-                raw := strings.TrimSpace(lines[i])
-                if err := json.Unmarshal([]byte(raw), &tmp); err == nil {
-                    m := make(map[string]interface{}, len(tmp))
-                    for k, v := range tmp {
-                        m[k] = v
-                    }
-                    rows[out] = m
+    }
+    df.Rows = int(pf.NumRows())
+
+    // restore the original column order written by ToParquetFile
+    if order, ok := pf.Lookup(parquetColumnsKey); ok {
+        var cols []string
+        if json.Unmarshal([]byte(order), &cols) == nil && len(cols) == len(df.Cols) {
+            known := true
+            for _, c := range cols {
+                if _, ok := df.Data[c]; !ok {
+                    known = false
                 }
-                out++
             }
-        }(start, end, base, mask)
+            if known {
+                df.Cols = cols
+            }
+        }
     }
-    wg.Wait()
+    return df, nil
+}
+
+// ReadXLSX reads sheet of an Excel workbook (a file path or raw xlsx bytes) into
+// a DataFrame, taking column names from the first row. sheet "" reads the first
+// sheet. Numbers become int or float64, cells with a date format become
+// time.Time, and booleans and strings keep their types; empty cells are nil.
+// A column whose times all have a format without a time of day is a date
+// column (see DataFrame.Dates).
+func ReadXLSX(input string, sheet string) (*DataFrame, error) {
+	var zr *zip.Reader
+	if fileExists(input) {
+		rc, err := zip.OpenReader(input)
+		if err != nil {
+			return nil, fmt.Errorf("ReadXLSX: open file error: %w", err)
+		}
+		defer rc.Close()
+		zr = &rc.Reader
+	} else {
+		r, err := zip.NewReader(strings.NewReader(input), int64(len(input)))
+		if err != nil {
+			return nil, fmt.Errorf("ReadXLSX: not a file path or xlsx content: %w", err)
+		}
+		zr = r
+	}
+
+	wb, err := openXLSX(zr)
+	if err != nil {
+		return nil, fmt.Errorf("ReadXLSX: %w", err)
+	}
+	if len(wb.names) == 0 {
+		return nil, fmt.Errorf("ReadXLSX: workbook has no sheets")
+	}
+	name := wb.names[0]
+	if sheet != "" {
+		name = ""
+		for _, n := range wb.names {
+			if strings.EqualFold(n, sheet) {
+				name = n
+			}
+		}
+		if name == "" {
+			return nil, fmt.Errorf("ReadXLSX: no sheet %q (sheets: %s)", sheet, strings.Join(wb.names, ", "))
+		}
+	}
+	df, err := wb.sheet(name)
+	if err != nil {
+		return nil, fmt.Errorf("ReadXLSX: sheet %q: %w", name, err)
+	}
+	return df, nil
+}
 
-    return Dataframe(rows)
+// uniqueName returns name, or name_2, name_3, ... when it is already in seen,
+// and records the result in seen.
+func uniqueName(seen map[string]bool, name string) string {
+	for base, n := name, 2; seen[name]; n++ {
+		name = fmt.Sprintf("%s_%d", base, n)
+	}
+	seen[name] = true
+	return name
 }
-func fetchRows(db *sql.DB, query string, tableLabel string) ([]map[string]interface{}, error) {
+
+// fetchRows runs query and returns its rows, and the columns declared DATE,
+// which hold dates (see DataFrame.Dates) rather than timestamps.
+func fetchRows(db *sql.DB, query string, tableLabel string) ([]map[string]interface{}, map[string]bool, error) {
 	rows, err := db.Query(query)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
 	cols, err := rows.Columns()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	dates := map[string]bool{}
+	if types, err := rows.ColumnTypes(); err == nil {
+		for i, t := range types {
+			if strings.EqualFold(t.DatabaseTypeName(), "DATE") {
+				dates[cols[i]] = true
+			}
+		}
 	}
 
 	out := make([]map[string]interface{}, 0, 128)
@@ -507,7 +597,7 @@ func fetchRows(db *sql.DB, query string, tableLabel string) ([]map[string]interf
 			ptrs[i] = &vals[i]
 		}
 		if err := rows.Scan(ptrs...); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		row := make(map[string]interface{}, len(cols)+1)
 		for i, c := range cols {
@@ -516,7 +606,7 @@ func fetchRows(db *sql.DB, query string, tableLabel string) ([]map[string]interf
 			case []byte:
 				row[c] = string(t)
 			case time.Time:
-				row[c] = t.Format(time.RFC3339Nano)
+				row[c] = t
 			default:
 				row[c] = v
 			}
@@ -526,11 +616,13 @@ func fetchRows(db *sql.DB, query string, tableLabel string) ([]map[string]interf
 		}
 		out = append(out, row)
 	}
-	return out, rows.Err()
+	return out, dates, rows.Err()
 }
 
 // ReadSqlite is pure Go. It returns a DataFrame from a sqlite DB given either a table or a query.
 // If both table and query are empty, it reads all user tables and concatenates them (adds _table column).
+// DATE, DATETIME and TIMESTAMP columns are read as time.Time; DATE columns
+// are date columns (see DataFrame.Dates).
 func ReadSqlite(path, table, query string) (*DataFrame, error) {
 	db, err := sql.Open("sqlite3", path)
 	if err != nil {
@@ -539,20 +631,21 @@ func ReadSqlite(path, table, query string) (*DataFrame, error) {
 	defer db.Close()
 
 	var rows []map[string]interface{}
+	dates := map[string]bool{}
 	switch {
 	case strings.TrimSpace(query) != "":
-		rs, err := fetchRows(db, query, "")
+		rs, ds, err := fetchRows(db, query, "")
 		if err != nil {
 			return nil, fmt.Errorf("ReadSqliteDF: query error: %w", err)
 		}
-		rows = append(rows, rs...)
+		rows, dates = append(rows, rs...), ds
 	case strings.TrimSpace(table) != "":
 		q := fmt.Sprintf(`SELECT * FROM %q`, table)
-		rs, err := fetchRows(db, q, "")
+		rs, ds, err := fetchRows(db, q, "")
 		if err != nil {
 			return nil, fmt.Errorf("ReadSqliteDF: table read error: %w", err)
 		}
-		rows = append(rows, rs...)
+		rows, dates = append(rows, rs...), ds
 	default:
 		// read all user tables concurrently
 		names := []string{}
@@ -575,7 +668,7 @@ func ReadSqlite(path, table, query string) (*DataFrame, error) {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				rs, err := fetchRows(db, fmt.Sprintf(`SELECT * FROM %q`, tbl), tbl)
+				rs, ds, err := fetchRows(db, fmt.Sprintf(`SELECT * FROM %q`, tbl), tbl)
 				if err != nil {
 					// non-fatal; log and continue
 					log.Printf("ReadSqliteDF: read table %s error: %v", tbl, err)
@@ -583,13 +676,20 @@ func ReadSqlite(path, table, query string) (*DataFrame, error) {
 				}
 				mu.Lock()
 				rows = append(rows, rs...)
+				for c := range ds {
+					dates[c] = true
+				}
 				mu.Unlock()
 			}()
 		}
 		wg.Wait()
 	}
 
-	return Dataframe(rows), nil
+	df := Dataframe(rows)
+	for c := range dates {
+		df.setDate(c, true)
+	}
+	return df, nil
 }
 
 // Clone creates a deep copy of the DataFrame (new Cols slice and new per-column []interface{}).
@@ -613,7 +713,7 @@ func (df *DataFrame) Clone() *DataFrame {
 		}()
 	}
 	wg.Wait()
-	return &DataFrame{Cols: newCols, Data: newData, Rows: df.Rows}
+	return &DataFrame{Cols: newCols, Data: newData, Rows: df.Rows, Dates: df.datesOf(df.Cols)}
 }
 
 func CloneJSON(in string) string {
@@ -688,31 +788,34 @@ func GetAPI(endpoint string, headers map[string]string, queryParams map[string]s
 	if err != nil {
 		return nil, fmt.Errorf("GetAPI: read body: %w", err)
 	}
-	df := ReadJSON(string(body))
+	df, err := ReadJSON(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("GetAPI: %w", err)
+	}
 	return df, nil
 }
 
 // ReadHTML scrapes a URL / file / raw HTML and returns a DataFrame of element metadata.
 // All HTML fragments are stored as escaped strings (safe for plain text display).
-func ReadHTML(input string) *DataFrame {
+func ReadHTML(input string) (*DataFrame, error) {
     raw := input
     var baseURL *url.URL
     if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
         resp, err := http.Get(input)
         if err != nil {
-            log.Fatalf("ReadHTML: GET error: %v", err)
+            return nil, fmt.Errorf("ReadHTML: GET error: %w", err)
         }
         defer resp.Body.Close()
         b, err := io.ReadAll(resp.Body)
         if err != nil {
-            log.Fatalf("ReadHTML: read body: %v", err)
+            return nil, fmt.Errorf("ReadHTML: read body: %w", err)
         }
         raw = string(b)
         baseURL, _ = url.Parse(input)
     } else if fileExists(input) {
         b, err := os.ReadFile(input)
         if err != nil {
-            log.Fatalf("ReadHTML: read file: %v", err)
+            return nil, fmt.Errorf("ReadHTML: read file: %w", err)
         }
         raw = string(b)
     }
@@ -730,7 +833,7 @@ func ReadHTML(input string) *DataFrame {
     if isDoc(raw) {
         doc, err := html.Parse(strings.NewReader(raw))
         if err != nil {
-            log.Fatalf("ReadHTML: parse: %v", err)
+            return nil, fmt.Errorf("ReadHTML: parse: %w", err)
         }
         roots = []*html.Node{doc}
     } else {
@@ -738,7 +841,7 @@ func ReadHTML(input string) *DataFrame {
         ctx := &html.Node{Type: html.ElementNode, DataAtom: atom.Div, Data: "div"}
         frags, err := html.ParseFragment(strings.NewReader(raw), ctx)
         if err != nil {
-            log.Fatalf("ReadHTML: parse fragment: %v", err)
+            return nil, fmt.Errorf("ReadHTML: parse fragment: %w", err)
         }
         roots = frags
     }
@@ -843,7 +946,7 @@ func ReadHTML(input string) *DataFrame {
 	}
 
 	if len(nodes) == 0 {
-		return Dataframe([]map[string]interface{}{})
+		return Dataframe([]map[string]interface{}{}), nil
 	}
 
 	// Helpers
@@ -929,26 +1032,26 @@ func ReadHTML(input string) *DataFrame {
             "inner_html_str": out[i].inner,
         }
     }
-	return Dataframe(rows)
+	return Dataframe(rows), nil
 }
 
 // ReadHTMLTop scrapes a URL / file / raw HTML and returns a DataFrame of element metadata.
 // All HTML fragments are stored as escaped strings (safe for plain text display).
-func ReadHTMLTop(input string) *DataFrame {
+func ReadHTMLTop(input string) (*DataFrame, error) {
     // Normalize input (URL/file/raw)
     raw := input
     var baseURL *url.URL
     if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
         resp, err := http.Get(input)
-        if err != nil { log.Fatalf("ReadHTMLTop: GET error: %v", err) }
+        if err != nil { return nil, fmt.Errorf("ReadHTMLTop: GET error: %w", err) }
         defer resp.Body.Close()
         b, err := io.ReadAll(resp.Body)
-        if err != nil { log.Fatalf("ReadHTMLTop: read body: %v", err) }
+        if err != nil { return nil, fmt.Errorf("ReadHTMLTop: read body: %w", err) }
         raw = string(b)
         baseURL, _ = url.Parse(input)
     } else if fileExists(input) {
         b, err := os.ReadFile(input)
-        if err != nil { log.Fatalf("ReadHTMLTop: read file: %v", err) }
+        if err != nil { return nil, fmt.Errorf("ReadHTMLTop: read file: %w", err) }
         raw = string(b)
     }
 
@@ -990,7 +1093,7 @@ func ReadHTMLTop(input string) *DataFrame {
     topNodes := make([]*html.Node, 0, 8)
     if isDoc(raw) {
         doc, err := html.Parse(strings.NewReader(raw))
-        if err != nil { log.Fatalf("ReadHTMLTop: parse: %v", err) }
+        if err != nil { return nil, fmt.Errorf("ReadHTMLTop: parse: %w", err) }
         // Find the <html> element
         var htmlElem *html.Node
         for c := doc.FirstChild; c != nil && htmlElem == nil; c = c.NextSibling {
@@ -1012,7 +1115,7 @@ func ReadHTMLTop(input string) *DataFrame {
         // Fragment: treat parser outputs as top-level
         ctx := &html.Node{Type: html.ElementNode, DataAtom: atom.Div, Data: "div"}
         frags, err := html.ParseFragment(strings.NewReader(raw), ctx)
-        if err != nil { log.Fatalf("ReadHTMLTop: parse fragment: %v", err) }
+        if err != nil { return nil, fmt.Errorf("ReadHTMLTop: parse fragment: %w", err) }
         for _, n := range frags {
             if n.Type == html.ElementNode && n.Data != "script" && n.Data != "iframe" {
                 topNodes = append(topNodes, n)
@@ -1021,7 +1124,7 @@ func ReadHTMLTop(input string) *DataFrame {
     }
 
     if len(topNodes) == 0 {
-        return Dataframe([]map[string]interface{}{})
+        return Dataframe([]map[string]interface{}{}), nil
     }
 
     // Precompute direct text + attrs
@@ -1108,7 +1211,7 @@ func ReadHTMLTop(input string) *DataFrame {
             "inner_html_str": out[i].inner,
         }
     }
-    return Dataframe(rows)
+    return Dataframe(rows), nil
 }
 // javascript request source? (django/flask?)
 
@@ -1180,11 +1283,15 @@ func SqliteSQL(path string, sqlText string) (*DataFrame, error) {
         return strings.HasPrefix(t, "select")
     }
     if isSelect(last) {
-        rows, err := fetchRows(db, last, "")
+        rows, dates, err := fetchRows(db, last, "")
         if err != nil {
             return nil, fmt.Errorf("SqliteSQL: select error: %w", err)
         }
-        return Dataframe(rows), nil
+        df := Dataframe(rows)
+        for c := range dates {
+            df.setDate(c, true)
+        }
+        return df, nil
     }
 
     res, err := db.Exec(last)
@@ -0,0 +1,1584 @@
+package gophers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// SQL runs a SELECT statement over DataFrames registered by name in tables,
+// without a round trip through SQLite. It supports
+//
+//	SELECT [DISTINCT] expr [AS name], t.*, *
+//	FROM table [AS] t
+//	[INNER | LEFT | RIGHT | FULL [OUTER] | CROSS] JOIN table [AS] u [ON cond]
+//	WHERE cond  GROUP BY expr, ...  HAVING cond
+//	ORDER BY expr | alias | position [ASC | DESC], ...  LIMIT n [OFFSET m]
+//
+// Expressions have =, <>, <, <=, >, >=, AND, OR, NOT, + - * / %, IS [NOT] NULL,
+// [NOT] LIKE, [NOT] IN (...), [NOT] BETWEEN, CASE WHEN, CAST(x AS type), the
+// scalar functions LOWER, UPPER, LENGTH, TRIM, LTRIM, RTRIM, CONCAT, COALESCE,
+// GREATEST, LEAST, ABS, ROUND, FLOOR, CEIL, SQRT, LN, EXP, POWER and MOD,
+// and the aggregates COUNT(*), COUNT([DISTINCT] x), SUM, AVG, MIN, MAX, MEDIAN
+// and FIRST, which skip nulls. The query compiles to ColumnExpr trees run by
+// Filter, Column, Join or JoinOn, GroupBy, OrderBy and DropDuplicates, so
+// column values keep their types. Columns are named by their alias, their
+// column name or the expression text.
+//
+// Unlike the Column methods, conditions use SQL's three-valued logic: a
+// comparison with NULL is unknown, and WHERE, HAVING and ON keep only rows
+// where the condition is true. So WHERE v = NULL and WHERE NOT (v > 5) skip
+// rows where v is NULL, and WHERE dept NOT IN (10, NULL) returns no rows,
+// since dept <> NULL is never true; use IS [NOT] NULL to test for NULL.
+//
+//	out, err := gophers.SQL(`SELECT c.name, SUM(o.amount) AS total
+//	    FROM orders o JOIN customers c ON o.customer_id = c.id
+//	    GROUP BY c.name ORDER BY total DESC LIMIT 10`,
+//	    map[string]*gophers.DataFrame{"orders": orders, "customers": customers})
+func SQL(query string, tables map[string]*DataFrame) (*DataFrame, error) {
+	tokens, err := sqlTokenize(query)
+	if err != nil {
+		return nil, fmt.Errorf("SQL: %w", err)
+	}
+	p := &sqlParser{tokens: tokens}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, fmt.Errorf("SQL: %w", err)
+	}
+	df, err := stmt.run(tables)
+	if err != nil {
+		return nil, fmt.Errorf("SQL: %w", err)
+	}
+	return df, nil
+}
+
+// ---------- tokens ----------
+
+const (
+	sqlIdent  = iota // bare identifier or keyword
+	sqlQuoted        // "quoted" or `quoted` identifier
+	sqlString        // 'string literal'
+	sqlNumber
+	sqlSymbol
+	sqlEOF
+)
+
+type sqlToken struct {
+	kind int
+	text string
+	pos  int
+}
+
+// sqlTokenize splits a query into tokens.
+func sqlTokenize(query string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	rs := []rune(query)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(rs) && rs[i+1] == '-':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(rs) && (unicode.IsLetter(rs[i]) || unicode.IsDigit(rs[i]) || rs[i] == '_' || rs[i] == '$') {
+				i++
+			}
+			tokens = append(tokens, sqlToken{sqlIdent, string(rs[start:i]), start})
+		case unicode.IsDigit(r) || r == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1]):
+			start := i
+			for i < len(rs) && (unicode.IsDigit(rs[i]) || rs[i] == '.') {
+				i++
+			}
+			if i < len(rs) && (rs[i] == 'e' || rs[i] == 'E') {
+				i++
+				if i < len(rs) && (rs[i] == '+' || rs[i] == '-') {
+					i++
+				}
+				for i < len(rs) && unicode.IsDigit(rs[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, sqlToken{sqlNumber, string(rs[start:i]), start})
+		case r == '\'' || r == '"' || r == '`':
+			start := i
+			var b strings.Builder
+			closed := false
+			for i++; i < len(rs); i++ {
+				if rs[i] == r {
+					// a doubled quote is an escaped quote
+					if i+1 < len(rs) && rs[i+1] == r {
+						b.WriteRune(r)
+						i++
+						continue
+					}
+					closed = true
+					i++
+					break
+				}
+				b.WriteRune(rs[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated quote at position %d", start)
+			}
+			kind := sqlQuoted
+			if r == '\'' {
+				kind = sqlString
+			}
+			tokens = append(tokens, sqlToken{kind, b.String(), start})
+		default:
+			start := i
+			two := ""
+			if i+1 < len(rs) {
+				two = string(rs[i : i+2])
+			}
+			switch {
+			case two == "<=" || two == ">=" || two == "<>" || two == "!=" || two == "==" || two == "||":
+				tokens = append(tokens, sqlToken{sqlSymbol, two, start})
+				i += 2
+			case strings.ContainsRune("=<>+-*/%(),.;", r):
+				tokens = append(tokens, sqlToken{sqlSymbol, string(r), start})
+				i++
+			default:
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, start)
+			}
+		}
+	}
+	return append(tokens, sqlToken{kind: sqlEOF, pos: len(rs)}), nil
+}
+
+// ---------- syntax tree ----------
+
+// sqlSelect is a parsed SELECT statement.
+type sqlSelect struct {
+	distinct bool
+	items    []sqlItem
+	from     sqlTable
+	joins    []sqlJoin
+	where    *sqlExpr
+	groupBy  []*sqlExpr
+	having   *sqlExpr
+	orderBy  []sqlOrder
+	limit    int // -1 without LIMIT
+	offset   int
+}
+
+// sqlItem is one SELECT list entry: an expression, * or table.*.
+type sqlItem struct {
+	expr  *sqlExpr
+	alias string
+	star  bool
+	table string // qualifier of table.*
+}
+
+type sqlTable struct {
+	name  string
+	alias string
+}
+
+type sqlJoin struct {
+	kind  string // a Join joinType
+	table sqlTable
+	on    *sqlExpr
+}
+
+type sqlOrder struct {
+	expr *sqlExpr
+	desc bool
+}
+
+// sqlExpr is an expression node. op is "col", "lit", "star", "call", "cast",
+// "case", "neg", a ColumnExpr type ("and", "eq", "add", "isnull", ...) or
+// "between"; args holds the operands.
+type sqlExpr struct {
+	op       string
+	table    string      // col: optional qualifier
+	name     string      // col: column name; call: lower-case function name; cast: type
+	value    interface{} // lit
+	args     []*sqlExpr
+	distinct bool // call: COUNT(DISTINCT x)
+}
+
+// sqlAggregates maps the SQL aggregate functions to aggregationByName names.
+var sqlAggregates = map[string]string{
+	"count": "", "sum": "sum", "avg": "mean", "mean": "mean", "min": "min",
+	"max": "max", "median": "median", "first": "first",
+}
+
+// sqlReserved are the keywords that end an expression, so they are not taken
+// as an implicit alias.
+var sqlReserved = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "GROUP": true, "BY": true, "HAVING": true,
+	"ORDER": true, "LIMIT": true, "OFFSET": true, "JOIN": true, "INNER": true, "LEFT": true,
+	"RIGHT": true, "FULL": true, "OUTER": true, "CROSS": true, "ON": true, "AS": true,
+	"AND": true, "OR": true, "NOT": true, "IS": true, "NULL": true, "IN": true, "LIKE": true,
+	"BETWEEN": true, "DISTINCT": true, "ASC": true, "DESC": true, "CASE": true, "WHEN": true,
+	"THEN": true, "ELSE": true, "END": true, "UNION": true, "TRUE": true, "FALSE": true,
+}
+
+var sqlSymbols = map[string]string{"+": "add", "-": "sub", "*": "mul", "/": "div", "%": "mod"}
+
+// String renders the expression as SQL; it names unaliased result columns.
+func (e *sqlExpr) String() string {
+	switch e.op {
+	case "col":
+		return e.name
+	case "lit":
+		switch v := e.value.(type) {
+		case nil:
+			return "NULL"
+		case string:
+			return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+		}
+		return fmt.Sprint(e.value)
+	case "star":
+		return "*"
+	case "call":
+		args := make([]string, len(e.args))
+		for i, a := range e.args {
+			args[i] = a.String()
+		}
+		if e.distinct {
+			return e.name + "(DISTINCT " + strings.Join(args, ", ") + ")"
+		}
+		return e.name + "(" + strings.Join(args, ", ") + ")"
+	case "cast":
+		return "CAST(" + e.args[0].String() + " AS " + e.name + ")"
+	case "case":
+		var b strings.Builder
+		b.WriteString("CASE")
+		for i := 0; i+1 < len(e.args); i += 2 {
+			fmt.Fprintf(&b, " WHEN %s THEN %s", e.args[i], e.args[i+1])
+		}
+		if len(e.args)%2 == 1 {
+			fmt.Fprintf(&b, " ELSE %s", e.args[len(e.args)-1])
+		}
+		return b.String() + " END"
+	case "neg":
+		return "-" + e.args[0].String()
+	case "not":
+		return "NOT " + e.args[0].String()
+	case "isnull":
+		return e.args[0].String() + " IS NULL"
+	case "isnotnull":
+		return e.args[0].String() + " IS NOT NULL"
+	case "isin":
+		vals := make([]string, len(e.args)-1)
+		for i, a := range e.args[1:] {
+			vals[i] = a.String()
+		}
+		return e.args[0].String() + " IN (" + strings.Join(vals, ", ") + ")"
+	case "between":
+		return fmt.Sprintf("%s BETWEEN %s AND %s", e.args[0], e.args[1], e.args[2])
+	case "like", "notlike":
+		op := map[string]string{"like": "LIKE", "notlike": "NOT LIKE"}[e.op]
+		return fmt.Sprintf("%s %s %s", e.args[0], op, e.args[1])
+	}
+	op := map[string]string{"and": "AND", "or": "OR", "eq": "=", "ne": "<>", "lt": "<", "le": "<=",
+		"gt": ">", "ge": ">=", "add": "+", "sub": "-", "mul": "*", "div": "/", "mod": "%"}[e.op]
+	return fmt.Sprintf("(%s %s %s)", e.args[0], op, e.args[1])
+}
+
+// isAggregate reports whether e is an aggregate function call.
+func (e *sqlExpr) isAggregate() bool {
+	if e.op != "call" {
+		return false
+	}
+	_, ok := sqlAggregates[e.name]
+	return ok
+}
+
+// hasAggregate reports whether e contains an aggregate function call.
+func (e *sqlExpr) hasAggregate() bool {
+	if e == nil {
+		return false
+	}
+	if e.isAggregate() {
+		return true
+	}
+	for _, a := range e.args {
+		if a.hasAggregate() {
+			return true
+		}
+	}
+	return false
+}
+
+// ---------- parser ----------
+
+type sqlParser struct {
+	tokens []sqlToken
+	i      int
+}
+
+func (p *sqlParser) peek() sqlToken { return p.tokens[p.i] }
+
+func (p *sqlParser) next() sqlToken {
+	t := p.tokens[p.i]
+	if t.kind != sqlEOF {
+		p.i++
+	}
+	return t
+}
+
+// isKeyword reports whether the next tokens are the given keywords.
+func (p *sqlParser) isKeyword(kws ...string) bool {
+	for k, kw := range kws {
+		if p.i+k >= len(p.tokens) {
+			return false
+		}
+		t := p.tokens[p.i+k]
+		if t.kind != sqlIdent || !strings.EqualFold(t.text, kw) {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptKeyword consumes the given keywords if they come next.
+func (p *sqlParser) acceptKeyword(kws ...string) bool {
+	if !p.isKeyword(kws...) {
+		return false
+	}
+	p.i += len(kws)
+	return true
+}
+
+func (p *sqlParser) expectKeyword(kws ...string) error {
+	if !p.acceptKeyword(kws...) {
+		return p.errorf("expected %s", strings.Join(kws, " "))
+	}
+	return nil
+}
+
+func (p *sqlParser) isSymbol(s string) bool {
+	t := p.peek()
+	return t.kind == sqlSymbol && t.text == s
+}
+
+func (p *sqlParser) acceptSymbol(s string) bool {
+	if !p.isSymbol(s) {
+		return false
+	}
+	p.i++
+	return true
+}
+
+func (p *sqlParser) expectSymbol(s string) error {
+	if !p.acceptSymbol(s) {
+		return p.errorf("expected %q", s)
+	}
+	return nil
+}
+
+// errorf reports a syntax error at the next token.
+func (p *sqlParser) errorf(format string, args ...interface{}) error {
+	t := p.peek()
+	found := "end of query"
+	if t.kind != sqlEOF {
+		found = strconv.Quote(t.text)
+	}
+	return fmt.Errorf("%s at position %d, found %s", fmt.Sprintf(format, args...), t.pos, found)
+}
+
+// identifier consumes a bare or quoted identifier.
+func (p *sqlParser) identifier() (string, error) {
+	t := p.peek()
+	if t.kind == sqlQuoted || t.kind == sqlIdent && !sqlReserved[strings.ToUpper(t.text)] {
+		p.i++
+		return t.text, nil
+	}
+	return "", p.errorf("expected identifier")
+}
+
+// alias consumes an optional [AS] alias.
+func (p *sqlParser) alias() (string, error) {
+	if p.acceptKeyword("AS") {
+		return p.identifier()
+	}
+	t := p.peek()
+	if t.kind == sqlQuoted || t.kind == sqlIdent && !sqlReserved[strings.ToUpper(t.text)] {
+		return p.identifier()
+	}
+	return "", nil
+}
+
+func (p *sqlParser) parseSelect() (*sqlSelect, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	stmt := &sqlSelect{limit: -1}
+	stmt.distinct = p.acceptKeyword("DISTINCT")
+	if !stmt.distinct {
+		p.acceptKeyword("ALL")
+	}
+	for {
+		item, err := p.parseItem()
+		if err != nil {
+			return nil, err
+		}
+		stmt.items = append(stmt.items, item)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	var err error
+	if stmt.from, err = p.parseTable(); err != nil {
+		return nil, err
+	}
+	for {
+		kind := ""
+		switch {
+		case p.acceptKeyword("JOIN"), p.acceptKeyword("INNER", "JOIN"):
+			kind = "inner"
+		case p.acceptKeyword("LEFT", "JOIN"), p.acceptKeyword("LEFT", "OUTER", "JOIN"):
+			kind = "left"
+		case p.acceptKeyword("RIGHT", "JOIN"), p.acceptKeyword("RIGHT", "OUTER", "JOIN"):
+			kind = "right"
+		case p.acceptKeyword("FULL", "JOIN"), p.acceptKeyword("FULL", "OUTER", "JOIN"):
+			kind = "outer"
+		case p.acceptKeyword("CROSS", "JOIN"), p.acceptSymbol(","):
+			kind = "cross"
+		}
+		if kind == "" {
+			break
+		}
+		join := sqlJoin{kind: kind}
+		if join.table, err = p.parseTable(); err != nil {
+			return nil, err
+		}
+		if kind != "cross" {
+			if err := p.expectKeyword("ON"); err != nil {
+				return nil, err
+			}
+			if join.on, err = p.parseExpr(); err != nil {
+				return nil, err
+			}
+		}
+		stmt.joins = append(stmt.joins, join)
+	}
+
+	if p.acceptKeyword("WHERE") {
+		if stmt.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("GROUP", "BY") {
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			stmt.groupBy = append(stmt.groupBy, e)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("HAVING") {
+		if stmt.having, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("ORDER", "BY") {
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			o := sqlOrder{expr: e}
+			if p.acceptKeyword("DESC") {
+				o.desc = true
+			} else {
+				p.acceptKeyword("ASC")
+			}
+			stmt.orderBy = append(stmt.orderBy, o)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if p.acceptKeyword("LIMIT") {
+		if stmt.limit, err = p.count("LIMIT"); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("OFFSET") {
+		if stmt.offset, err = p.count("OFFSET"); err != nil {
+			return nil, err
+		}
+	}
+	p.acceptSymbol(";")
+	if p.peek().kind != sqlEOF {
+		return nil, p.errorf("unexpected token")
+	}
+	return stmt, nil
+}
+
+// count consumes the non-negative integer of LIMIT or OFFSET.
+func (p *sqlParser) count(clause string) (int, error) {
+	t := p.peek()
+	n, err := strconv.Atoi(t.text)
+	if t.kind != sqlNumber || err != nil || n < 0 {
+		return 0, p.errorf("%s expects a non-negative integer", clause)
+	}
+	p.i++
+	return n, nil
+}
+
+func (p *sqlParser) parseItem() (sqlItem, error) {
+	if p.acceptSymbol("*") {
+		return sqlItem{star: true}, nil
+	}
+	// table.*
+	if t := p.peek(); (t.kind == sqlIdent || t.kind == sqlQuoted) && p.i+2 < len(p.tokens) &&
+		p.tokens[p.i+1].text == "." && p.tokens[p.i+2].kind == sqlSymbol && p.tokens[p.i+2].text == "*" {
+		p.i += 3
+		return sqlItem{star: true, table: t.text}, nil
+	}
+	e, err := p.parseExpr()
+	if err != nil {
+		return sqlItem{}, err
+	}
+	alias, err := p.alias()
+	return sqlItem{expr: e, alias: alias}, err
+}
+
+func (p *sqlParser) parseTable() (sqlTable, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return sqlTable{}, err
+	}
+	alias, err := p.alias()
+	if alias == "" {
+		alias = name
+	}
+	return sqlTable{name: name, alias: alias}, err
+}
+
+// parseExpr parses an expression; precedence from loosest to tightest is
+// OR, AND, NOT, comparisons, + -, * / %, unary minus.
+func (p *sqlParser) parseExpr() (*sqlExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &sqlExpr{op: "or", args: []*sqlExpr{left, right}}
+	}
+	return left, nil
+}
+
+func (p *sqlParser) parseAnd() (*sqlExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &sqlExpr{op: "and", args: []*sqlExpr{left, right}}
+	}
+	return left, nil
+}
+
+func (p *sqlParser) parseNot() (*sqlExpr, error) {
+	if p.acceptKeyword("NOT") {
+		e, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &sqlExpr{op: "not", args: []*sqlExpr{e}}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *sqlParser) parseComparison() (*sqlExpr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	ops := map[string]string{"=": "eq", "==": "eq", "<>": "ne", "!=": "ne", "<": "lt", "<=": "le", ">": "gt", ">=": "ge"}
+	if t := p.peek(); t.kind == sqlSymbol && ops[t.text] != "" {
+		p.i++
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &sqlExpr{op: ops[t.text], args: []*sqlExpr{left, right}}, nil
+	}
+
+	if p.acceptKeyword("IS") {
+		op := "isnull"
+		if p.acceptKeyword("NOT") {
+			op = "isnotnull"
+		}
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return &sqlExpr{op: op, args: []*sqlExpr{left}}, nil
+	}
+
+	not := p.acceptKeyword("NOT")
+	var e *sqlExpr
+	switch {
+	case p.acceptKeyword("LIKE"):
+		t := p.next()
+		if t.kind != sqlString {
+			p.i--
+			return nil, p.errorf("LIKE expects a string pattern")
+		}
+		op := "like"
+		if not {
+			op, not = "notlike", false
+		}
+		e = &sqlExpr{op: op, args: []*sqlExpr{left, {op: "lit", value: t.text}}}
+	case p.acceptKeyword("IN"):
+		if err := p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		e = &sqlExpr{op: "isin", args: []*sqlExpr{left}}
+		for {
+			v, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			e.args = append(e.args, v)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	case p.acceptKeyword("BETWEEN"):
+		lo, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+		hi, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		e = &sqlExpr{op: "between", args: []*sqlExpr{left, lo, hi}}
+	default:
+		if not {
+			return nil, p.errorf("expected LIKE, IN or BETWEEN after NOT")
+		}
+		return left, nil
+	}
+	if not {
+		e = &sqlExpr{op: "not", args: []*sqlExpr{e}}
+	}
+	return e, nil
+}
+
+func (p *sqlParser) parseAdditive() (*sqlExpr, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.isSymbol("+") || p.isSymbol("-") || p.isSymbol("||") {
+		t := p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		if t.text == "||" {
+			left = &sqlExpr{op: "call", name: "concat", args: []*sqlExpr{left, right}}
+			continue
+		}
+		left = &sqlExpr{op: sqlSymbols[t.text], args: []*sqlExpr{left, right}}
+	}
+	return left, nil
+}
+
+func (p *sqlParser) parseMultiplicative() (*sqlExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isSymbol("*") || p.isSymbol("/") || p.isSymbol("%") {
+		t := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &sqlExpr{op: sqlSymbols[t.text], args: []*sqlExpr{left, right}}
+	}
+	return left, nil
+}
+
+func (p *sqlParser) parseUnary() (*sqlExpr, error) {
+	if p.acceptSymbol("-") {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if e.op == "lit" {
+			switch v := e.value.(type) {
+			case int64:
+				e.value = -v
+				return e, nil
+			case float64:
+				e.value = -v
+				return e, nil
+			}
+		}
+		return &sqlExpr{op: "neg", args: []*sqlExpr{e}}, nil
+	}
+	p.acceptSymbol("+")
+	return p.parsePrimary()
+}
+
+func (p *sqlParser) parsePrimary() (*sqlExpr, error) {
+	t := p.peek()
+	switch t.kind {
+	case sqlNumber:
+		p.i++
+		if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return &sqlExpr{op: "lit", value: n}, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			p.i--
+			return nil, p.errorf("invalid number")
+		}
+		return &sqlExpr{op: "lit", value: f}, nil
+	case sqlString:
+		p.i++
+		return &sqlExpr{op: "lit", value: t.text}, nil
+	case sqlSymbol:
+		if p.acceptSymbol("(") {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expectSymbol(")")
+		}
+		return nil, p.errorf("expected expression")
+	case sqlQuoted:
+		return p.parseColumn()
+	case sqlIdent:
+		switch strings.ToUpper(t.text) {
+		case "NULL":
+			p.i++
+			return &sqlExpr{op: "lit"}, nil
+		case "TRUE", "FALSE":
+			p.i++
+			return &sqlExpr{op: "lit", value: strings.EqualFold(t.text, "TRUE")}, nil
+		case "CASE":
+			return p.parseCase()
+		case "CAST":
+			if p.i+1 < len(p.tokens) && p.tokens[p.i+1].text == "(" {
+				return p.parseCast()
+			}
+		}
+		if p.i+1 < len(p.tokens) && p.tokens[p.i+1].kind == sqlSymbol && p.tokens[p.i+1].text == "(" {
+			return p.parseCall()
+		}
+		if sqlReserved[strings.ToUpper(t.text)] {
+			return nil, p.errorf("expected expression")
+		}
+		return p.parseColumn()
+	}
+	return nil, p.errorf("expected expression")
+}
+
+// parseColumn parses column or table.column.
+func (p *sqlParser) parseColumn() (*sqlExpr, error) {
+	name, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+	if p.acceptSymbol(".") {
+		col, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		return &sqlExpr{op: "col", table: name, name: col}, nil
+	}
+	return &sqlExpr{op: "col", name: name}, nil
+}
+
+func (p *sqlParser) parseCall() (*sqlExpr, error) {
+	e := &sqlExpr{op: "call", name: strings.ToLower(p.next().text)}
+	p.i++ // (
+	if e.name == "count" && p.acceptSymbol("*") {
+		e.args = []*sqlExpr{{op: "star"}}
+		return e, p.expectSymbol(")")
+	}
+	e.distinct = p.acceptKeyword("DISTINCT")
+	if p.acceptSymbol(")") {
+		return e, nil
+	}
+	for {
+		a, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		e.args = append(e.args, a)
+		if !p.acceptSymbol(",") {
+			break
+		}
+	}
+	return e, p.expectSymbol(")")
+}
+
+func (p *sqlParser) parseCast() (*sqlExpr, error) {
+	p.i += 2 // CAST (
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	typ, err := p.identifier()
+	if err != nil {
+		return nil, err
+	}
+	// ignore a length or precision, e.g. VARCHAR(20) or DECIMAL(10, 2)
+	if p.acceptSymbol("(") {
+		for !p.isSymbol(")") && p.peek().kind != sqlEOF {
+			p.i++
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+	return &sqlExpr{op: "cast", name: strings.ToUpper(typ), args: []*sqlExpr{x}}, p.expectSymbol(")")
+}
+
+// parseCase parses a searched CASE WHEN cond THEN value ... [ELSE value] END.
+func (p *sqlParser) parseCase() (*sqlExpr, error) {
+	p.i++ // CASE
+	e := &sqlExpr{op: "case"}
+	for p.acceptKeyword("WHEN") {
+		cond, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		val, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		e.args = append(e.args, cond, val)
+	}
+	if len(e.args) == 0 {
+		return nil, p.errorf("expected WHEN")
+	}
+	if p.acceptKeyword("ELSE") {
+		val, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		e.args = append(e.args, val)
+	}
+	return e, p.expectKeyword("END")
+}
+
+// ---------- planning ----------
+
+// sqlColumn is a column of the FROM relation. The relation's DataFrame names
+// it "table.column", so joined tables never collide.
+type sqlColumn struct {
+	table string
+	name  string
+}
+
+func (c sqlColumn) key() string { return c.table + "." + c.name }
+
+// sqlCompiler turns expressions into ColumnExprs over the working DataFrame.
+// Once grouped, group expressions and aggregates read the GroupBy output
+// columns and any other column reference is an error.
+type sqlCompiler struct {
+	cols    []sqlColumn
+	grouped bool
+	groups  map[string]string // canonical group expression -> column
+	aggs    map[string]string // canonical aggregate call -> column
+}
+
+// resolve finds the relation column a reference names.
+func (c *sqlCompiler) resolve(e *sqlExpr) (sqlColumn, error) {
+	var found []sqlColumn
+	for _, col := range c.cols {
+		if col.name == e.name && (e.table == "" || col.table == e.table) {
+			found = append(found, col)
+		}
+	}
+	ref := e.name
+	if e.table != "" {
+		ref = e.table + "." + e.name
+	}
+	switch len(found) {
+	case 0:
+		return sqlColumn{}, fmt.Errorf("unknown column %s", ref)
+	case 1:
+		return found[0], nil
+	}
+	return sqlColumn{}, fmt.Errorf("column %s is ambiguous", ref)
+}
+
+// canonical renders e with resolved column references, so t.x and x compare equal.
+func (c *sqlCompiler) canonical(e *sqlExpr) string {
+	if e.op == "col" {
+		if col, err := c.resolve(e); err == nil {
+			return col.key()
+		}
+	}
+	if len(e.args) == 0 {
+		return e.op + ":" + e.String()
+	}
+	args := make([]string, len(e.args))
+	for i, a := range e.args {
+		args[i] = c.canonical(a)
+	}
+	return fmt.Sprintf("%s:%s:%t(%s)", e.op, e.name, e.distinct, strings.Join(args, ","))
+}
+
+func sqlRaw(e ColumnExpr) json.RawMessage {
+	b, _ := json.Marshal(e)
+	return b
+}
+
+func sqlBinary(op string, l, r ColumnExpr) ColumnExpr {
+	return ColumnExpr{Type: op, Left: sqlRaw(l), Right: sqlRaw(r)}
+}
+
+// compile turns e into a ColumnExpr.
+func (c *sqlCompiler) compile(e *sqlExpr) (ColumnExpr, error) {
+	if c.grouped {
+		if col, ok := c.groups[c.canonical(e)]; ok {
+			return ColumnExpr{Type: "col", Name: col}, nil
+		}
+		if e.isAggregate() {
+			return ColumnExpr{Type: "col", Name: c.aggs[c.canonical(e)]}, nil
+		}
+	}
+	args := make([]ColumnExpr, 0, len(e.args))
+	if e.op != "call" || !e.isAggregate() {
+		for _, a := range e.args {
+			x, err := c.compile(a)
+			if err != nil {
+				return ColumnExpr{}, err
+			}
+			args = append(args, x)
+		}
+	}
+
+	switch e.op {
+	case "col":
+		if c.grouped {
+			return ColumnExpr{}, fmt.Errorf("column %s must appear in GROUP BY or in an aggregate", e.String())
+		}
+		col, err := c.resolve(e)
+		if err != nil {
+			return ColumnExpr{}, err
+		}
+		return ColumnExpr{Type: "col", Name: col.key()}, nil
+	case "lit":
+		return ColumnExpr{Type: "lit", Value: e.value}, nil
+	case "star":
+		return ColumnExpr{}, fmt.Errorf("* is only allowed in SELECT and COUNT(*)")
+	case "and", "or", "eq", "ne", "lt", "le", "gt", "ge", "add", "sub", "mul", "div", "mod":
+		return sqlBinary(e.op, args[0], args[1]), nil
+	case "neg":
+		return sqlBinary("sub", ColumnExpr{Type: "lit", Value: int64(0)}, args[0]), nil
+	case "not", "isnull", "isnotnull":
+		return ColumnExpr{Type: e.op, Expr: sqlRaw(args[0])}, nil
+	case "like", "notlike":
+		return ColumnExpr{Type: e.op, Expr: sqlRaw(args[0]), Pattern: e.args[1].value}, nil
+	case "isin":
+		vals, _ := json.Marshal(args[1:])
+		return ColumnExpr{Type: "isin", Expr: sqlRaw(args[0]), Cols: vals}, nil
+	case "between":
+		return sqlBinary("and", sqlBinary("ge", args[0], args[1]), sqlBinary("le", args[0], args[2])), nil
+	case "case":
+		// nested if, innermost is ELSE (or NULL)
+		out := ColumnExpr{Type: "lit"}
+		if len(args)%2 == 1 {
+			out = args[len(args)-1]
+		}
+		for i := len(args)/2*2 - 2; i >= 0; i -= 2 {
+			out = ColumnExpr{Type: "if", Cond: sqlRaw(args[i]), True: sqlRaw(args[i+1]), False: sqlRaw(out)}
+		}
+		return out, nil
+	case "cast":
+		types := map[string]string{
+			"INT": "int", "INTEGER": "int", "BIGINT": "int", "SMALLINT": "int",
+			"FLOAT": "float", "DOUBLE": "float", "REAL": "float", "DECIMAL": "float", "NUMERIC": "float",
+			"STRING": "string", "TEXT": "string", "VARCHAR": "string", "CHAR": "string",
+		}
+		typ, ok := types[e.name]
+		if !ok {
+			return ColumnExpr{}, fmt.Errorf("unsupported CAST type %s", e.name)
+		}
+		return ColumnExpr{Type: "cast", Col: string(sqlRaw(args[0])), Datatype: typ}, nil
+	case "call":
+		if e.isAggregate() {
+			return ColumnExpr{}, fmt.Errorf("aggregate %s is not allowed here", e.String())
+		}
+		return sqlFunction(e, args)
+	}
+	return ColumnExpr{}, fmt.Errorf("unsupported expression %s", e.String())
+}
+
+// sqlLogic compiles the nodes of a SQL expression that follow three-valued
+// logic, where NULL means unknown: a comparison with a NULL side is NULL; AND
+// is false if either side is false, else NULL if either is NULL; OR is true if
+// either side is true, else NULL if either is NULL; NOT NULL is NULL; x IN
+// (...) is NULL when x is NULL, or when nothing matches and a value is NULL;
+// and CASE WHEN takes the next branch when its condition is NULL. WHERE, HAVING
+// and ON keep only rows where the condition is true. It returns false for the
+// other node types, which compile as in Compile.
+func sqlLogic(e ColumnExpr) (Column, bool) {
+	sub := func(raw json.RawMessage) Column {
+		var x ColumnExpr
+		_ = json.Unmarshal(raw, &x)
+		return compileExpr(x, true)
+	}
+	switch e.Type {
+	case "eq", "ne", "gt", "ge", "lt", "le":
+		l, r, op := sub(e.Left), sub(e.Right), e.Type
+		return Column{
+			Name: fmt.Sprintf("(%s)%s(%s)", l.Name, compareSymbols[op], r.Name),
+			Fn: func(row map[string]interface{}) interface{} {
+				lv, rv := l.Fn(row), r.Fn(row)
+				if lv == nil || rv == nil {
+					return nil
+				}
+				return compareValues(op, lv, rv)
+			},
+		}, true
+	case "and", "or":
+		l, r := sub(e.Left), sub(e.Right)
+		// AND stops at false, OR at true
+		decisive := e.Type == "or"
+		return Column{
+			Name: fmt.Sprintf("(%s) %s (%s)", l.Name, strings.ToUpper(e.Type), r.Name),
+			Fn: func(row map[string]interface{}) interface{} {
+				unknown := false
+				for _, c := range []Column{l, r} {
+					b, ok := c.Fn(row).(bool)
+					if !ok {
+						unknown = true
+					} else if b == decisive {
+						return decisive
+					}
+				}
+				if unknown {
+					return nil
+				}
+				return !decisive
+			},
+		}, true
+	case "not":
+		return Not(sub(e.Expr)), true
+	case "isin":
+		x := sub(e.Expr)
+		var raw []json.RawMessage
+		_ = json.Unmarshal(e.Cols, &raw)
+		vals := make([]Column, len(raw))
+		for i := range raw {
+			vals[i] = sub(raw[i])
+		}
+		return Column{
+			Name: x.Name + ".isin",
+			Fn: func(row map[string]interface{}) interface{} {
+				v := x.Fn(row)
+				if v == nil {
+					return nil
+				}
+				unknown := false
+				for _, c := range vals {
+					if w := c.Fn(row); w == nil {
+						unknown = true
+					} else if eqValues(v, w) {
+						return true
+					}
+				}
+				if unknown {
+					return nil
+				}
+				return false
+			},
+		}, true
+	case "if":
+		cond, t, f := sub(e.Cond), sub(e.True), sub(e.False)
+		return Column{
+			Name: "If",
+			Fn: func(row map[string]interface{}) interface{} {
+				if b, _ := cond.Fn(row).(bool); b {
+					return t.Fn(row)
+				}
+				return f.Fn(row)
+			},
+			date: allDates(t, f),
+		}, true
+	}
+	return Column{}, false
+}
+
+// sqlFunction compiles a scalar function call.
+func sqlFunction(e *sqlExpr, args []ColumnExpr) (ColumnExpr, error) {
+	arity := func(n int) error {
+		if len(args) != n {
+			return fmt.Errorf("%s expects %d argument(s)", strings.ToUpper(e.name), n)
+		}
+		return nil
+	}
+	switch e.name {
+	case "lower", "upper", "length", "trim", "ltrim", "rtrim":
+		if err := arity(1); err != nil {
+			return ColumnExpr{}, err
+		}
+		return ColumnExpr{Type: e.name, Expr: sqlRaw(args[0])}, nil
+	case "abs", "floor", "ceil", "ceiling", "sqrt", "ln", "exp":
+		if err := arity(1); err != nil {
+			return ColumnExpr{}, err
+		}
+		typ := map[string]string{"ceiling": "ceil", "ln": "log"}[e.name]
+		if typ == "" {
+			typ = e.name
+		}
+		return ColumnExpr{Type: typ, Expr: sqlRaw(args[0])}, nil
+	case "round":
+		if len(args) == 1 {
+			return ColumnExpr{Type: "round", Expr: sqlRaw(args[0])}, nil
+		}
+		if err := arity(2); err != nil {
+			return ColumnExpr{}, err
+		}
+		n, ok := asInt64(args[1].Value)
+		if args[1].Type != "lit" || !ok {
+			return ColumnExpr{}, fmt.Errorf("ROUND expects an integer number of places")
+		}
+		return ColumnExpr{Type: "round", Expr: sqlRaw(args[0]), Index: int(n)}, nil
+	case "power", "pow", "mod":
+		if err := arity(2); err != nil {
+			return ColumnExpr{}, err
+		}
+		typ := "pow"
+		if e.name == "mod" {
+			typ = "mod"
+		}
+		return sqlBinary(typ, args[0], args[1]), nil
+	case "concat", "coalesce", "greatest", "least":
+		if len(args) == 0 {
+			return ColumnExpr{}, fmt.Errorf("%s expects arguments", strings.ToUpper(e.name))
+		}
+		cols, _ := json.Marshal(args)
+		return ColumnExpr{Type: e.name, Cols: cols}, nil
+	}
+	return ColumnExpr{}, fmt.Errorf("unknown function %s", strings.ToUpper(e.name))
+}
+
+// aggregation builds the GroupBy Aggregation for an aggregate call reading col.
+// Nulls are skipped; an aggregate over no values is null, COUNT is 0.
+func (e *sqlExpr) aggregation(col string) (Aggregation, error) {
+	if len(e.args) != 1 {
+		return Aggregation{}, fmt.Errorf("%s expects one argument", strings.ToUpper(e.name))
+	}
+	if e.name == "count" {
+		star := e.args[0].op == "star"
+		distinct := e.distinct
+		return Aggregation{ColumnName: col, Fn: func(vals []interface{}) interface{} {
+			if star {
+				return len(vals)
+			}
+			seen := map[string]struct{}{}
+			n := 0
+			for _, v := range vals {
+				if v == nil {
+					continue
+				}
+				if distinct {
+					seen[canonicalKey(v)] = struct{}{}
+				}
+				n++
+			}
+			if distinct {
+				return len(seen)
+			}
+			return n
+		}}, nil
+	}
+	if e.distinct {
+		return Aggregation{}, fmt.Errorf("DISTINCT is only supported in COUNT")
+	}
+	agg, _ := aggregationByName(sqlAggregates[e.name], col)
+	fn := agg.Fn
+	agg.Fn = func(vals []interface{}) interface{} {
+		present := make([]interface{}, 0, len(vals))
+		for _, v := range vals {
+			if v != nil {
+				present = append(present, v)
+			}
+		}
+		if len(present) == 0 {
+			return nil
+		}
+		return fn(present)
+	}
+	return agg, nil
+}
+
+// sqlRelation exposes df as a relation named alias: the same column slices
+// under "alias.column" names.
+func sqlRelation(df *DataFrame, alias string) (*DataFrame, []sqlColumn) {
+	rel := &DataFrame{Data: make(map[string][]interface{}, len(df.Cols)), Rows: df.Rows}
+	cols := make([]sqlColumn, len(df.Cols))
+	for i, name := range df.Cols {
+		cols[i] = sqlColumn{table: alias, name: name}
+		rel.Cols = append(rel.Cols, cols[i].key())
+		rel.Data[cols[i].key()] = df.Data[name]
+		rel.setDate(cols[i].key(), df.Dates[name])
+	}
+	return rel, cols
+}
+
+// lookupTable finds a table by name, ignoring case when there is no exact match.
+func lookupTable(tables map[string]*DataFrame, name string) (*DataFrame, error) {
+	if df, ok := tables[name]; ok && df != nil {
+		return df, nil
+	}
+	for k, df := range tables {
+		if strings.EqualFold(k, name) && df != nil {
+			return df, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown table %s", name)
+}
+
+// equiKeys splits an ON condition into left and right key columns when it is
+// a conjunction of column equalities between the two sides.
+func (c *sqlCompiler) equiKeys(on *sqlExpr, left, right []sqlColumn) ([]string, []string, bool) {
+	if on.op == "and" {
+		l1, r1, ok1 := c.equiKeys(on.args[0], left, right)
+		l2, r2, ok2 := c.equiKeys(on.args[1], left, right)
+		return append(l1, l2...), append(r1, r2...), ok1 && ok2
+	}
+	if on.op != "eq" || on.args[0].op != "col" || on.args[1].op != "col" {
+		return nil, nil, false
+	}
+	a, errA := c.resolve(on.args[0])
+	b, errB := c.resolve(on.args[1])
+	if errA != nil || errB != nil {
+		return nil, nil, false
+	}
+	side := func(col sqlColumn, cols []sqlColumn) bool {
+		for _, x := range cols {
+			if x == col {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case side(a, left) && side(b, right):
+		return []string{a.key()}, []string{b.key()}, true
+	case side(b, left) && side(a, right):
+		return []string{b.key()}, []string{a.key()}, true
+	}
+	return nil, nil, false
+}
+
+// run executes the statement.
+func (stmt *sqlSelect) run(tables map[string]*DataFrame) (*DataFrame, error) {
+	base, err := lookupTable(tables, stmt.from.name)
+	if err != nil {
+		return nil, err
+	}
+	df, cols := sqlRelation(base, stmt.from.alias)
+	c := &sqlCompiler{cols: cols}
+	aliases := map[string]bool{stmt.from.alias: true}
+
+	// FROM ... JOIN
+	for _, j := range stmt.joins {
+		if aliases[j.table.alias] {
+			return nil, fmt.Errorf("table alias %s is used twice", j.table.alias)
+		}
+		aliases[j.table.alias] = true
+		t, err := lookupTable(tables, j.table.name)
+		if err != nil {
+			return nil, err
+		}
+		right, rightCols := sqlRelation(t, j.table.alias)
+		leftCols := c.cols
+		c.cols = append(append([]sqlColumn(nil), leftCols...), rightCols...)
+		switch {
+		case j.kind == "cross":
+			df = df.Join(right, nil, nil, "cross")
+		default:
+			if lk, rk, ok := c.equiKeys(j.on, leftCols, rightCols); ok {
+				df = df.Join(right, lk, rk, j.kind)
+				break
+			}
+			on, err := c.compile(j.on)
+			if err != nil {
+				return nil, err
+			}
+			df = df.JoinOn(right, compileExpr(on, true), j.kind)
+		}
+		if df == nil {
+			return nil, fmt.Errorf("join with %s failed", j.table.name)
+		}
+	}
+
+	// WHERE
+	if stmt.where != nil {
+		if stmt.where.hasAggregate() {
+			return nil, fmt.Errorf("aggregates are not allowed in WHERE")
+		}
+		cond, err := c.compile(stmt.where)
+		if err != nil {
+			return nil, err
+		}
+		df = df.Filter(compileExpr(cond, true))
+	}
+
+	// GROUP BY and aggregates
+	grouped := len(stmt.groupBy) > 0 || stmt.having != nil
+	for _, item := range stmt.items {
+		grouped = grouped || item.expr.hasAggregate()
+	}
+	for _, o := range stmt.orderBy {
+		grouped = grouped || o.expr.hasAggregate()
+	}
+	if grouped {
+		if df, err = stmt.group(c, df); err != nil {
+			return nil, err
+		}
+		if stmt.having != nil {
+			cond, err := c.compile(stmt.having)
+			if err != nil {
+				return nil, err
+			}
+			df = df.Filter(compileExpr(cond, true))
+		}
+	}
+
+	// SELECT list: output name -> working column
+	var names, srcs []string
+	used := map[string]bool{}
+	output := func(name, src string) {
+		unique := name
+		for n := 2; used[unique]; n++ {
+			unique = fmt.Sprintf("%s_%d", name, n)
+		}
+		used[unique] = true
+		names = append(names, unique)
+		srcs = append(srcs, src)
+	}
+	for i, item := range stmt.items {
+		if item.star {
+			if grouped {
+				return nil, fmt.Errorf("SELECT * is not allowed with GROUP BY or aggregates")
+			}
+			matched := false
+			for _, col := range c.cols {
+				if item.table == "" || col.table == item.table {
+					output(col.name, col.key())
+					matched = true
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unknown table %s in %s.*", item.table, item.table)
+			}
+			continue
+		}
+		x, err := c.compile(item.expr)
+		if err != nil {
+			return nil, err
+		}
+		name := item.alias
+		if name == "" {
+			name = item.expr.String()
+		}
+		output(name, df.sqlColumn(fmt.Sprintf("__select_%d", i), x))
+	}
+	if stmt.distinct {
+		df = df.DropDuplicates(srcs...)
+	}
+
+	// ORDER BY, applied last key first with the stable OrderBy
+	keys := make([]string, len(stmt.orderBy))
+	for i, o := range stmt.orderBy {
+		if n, ok := o.expr.value.(int64); ok && o.expr.op == "lit" {
+			if n < 1 || int(n) > len(srcs) {
+				return nil, fmt.Errorf("ORDER BY position %d is out of range", n)
+			}
+			keys[i] = srcs[n-1]
+			continue
+		}
+		if o.expr.op == "col" && o.expr.table == "" {
+			for j, item := range stmt.items {
+				if item.alias != "" && item.alias == o.expr.name {
+					keys[i] = srcs[j]
+				}
+			}
+			if keys[i] != "" {
+				continue
+			}
+		}
+		x, err := c.compile(o.expr)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = df.sqlColumn(fmt.Sprintf("__order_%d", i), x)
+	}
+	for i := len(keys) - 1; i >= 0; i-- {
+		df = df.OrderBy(keys[i], !stmt.orderBy[i].desc)
+	}
+
+	// LIMIT / OFFSET, copying the output columns
+	start, end := stmt.offset, df.Rows
+	if start > end {
+		start = end
+	}
+	if stmt.limit >= 0 && start+stmt.limit < end {
+		end = start + stmt.limit
+	}
+	out := &DataFrame{Cols: names, Data: make(map[string][]interface{}, len(names)), Rows: end - start}
+	for i, name := range names {
+		vals := make([]interface{}, end-start)
+		if src := df.Data[srcs[i]]; len(src) >= end {
+			copy(vals, src[start:end])
+		}
+		out.Data[name] = vals
+		out.setDate(name, df.Dates[srcs[i]])
+	}
+	return out, nil
+}
+
+// sqlColumn returns the working column holding x, adding it as name unless x
+// reads a column as is.
+func (df *DataFrame) sqlColumn(name string, x ColumnExpr) string {
+	if x.Type == "col" {
+		if _, ok := df.Data[x.Name]; ok {
+			return x.Name
+		}
+	}
+	df.Column(name, compileExpr(x, true))
+	return name
+}
+
+// group runs GROUP BY and the aggregates of the statement, then switches the
+// compiler to the grouped columns.
+func (stmt *sqlSelect) group(c *sqlCompiler, df *DataFrame) (*DataFrame, error) {
+	c.groups = map[string]string{}
+	c.aggs = map[string]string{}
+	var keys []string
+	for i, g := range stmt.groupBy {
+		if g.hasAggregate() {
+			return nil, fmt.Errorf("aggregates are not allowed in GROUP BY")
+		}
+		x, err := c.compile(g)
+		if err != nil {
+			return nil, err
+		}
+		col := df.sqlColumn(fmt.Sprintf("__group_%d", i), x)
+		c.groups[c.canonical(g)] = col
+		keys = append(keys, col)
+	}
+
+	var aggs []Aggregation
+	var collect func(e *sqlExpr) error
+	collect = func(e *sqlExpr) error {
+		if e == nil {
+			return nil
+		}
+		if !e.isAggregate() {
+			for _, a := range e.args {
+				if err := collect(a); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		k := c.canonical(e)
+		if _, ok := c.aggs[k]; ok {
+			return nil
+		}
+		for _, a := range e.args {
+			if a.hasAggregate() {
+				return fmt.Errorf("aggregates cannot be nested: %s", e.String())
+			}
+		}
+		name := fmt.Sprintf("__agg_%d", len(aggs))
+		src := ""
+		switch {
+		case len(e.args) == 1 && e.args[0].op == "star":
+			if len(df.Cols) == 0 {
+				df.Column(name+"_arg", ColumnExpr{Type: "lit", Value: true})
+			}
+			src = df.Cols[0]
+		case len(e.args) == 1:
+			x, err := c.compile(e.args[0])
+			if err != nil {
+				return err
+			}
+			src = df.sqlColumn(name+"_arg", x)
+		}
+		agg, err := e.aggregation(src)
+		if err != nil {
+			return err
+		}
+		c.aggs[k] = name
+		aggs = append(aggs, agg.Alias(name))
+		return nil
+	}
+	for _, item := range stmt.items {
+		if err := collect(item.expr); err != nil {
+			return nil, err
+		}
+	}
+	if err := collect(stmt.having); err != nil {
+		return nil, err
+	}
+	for _, o := range stmt.orderBy {
+		if err := collect(o.expr); err != nil {
+			return nil, err
+		}
+	}
+
+	var grouped *DataFrame
+	if len(keys) == 0 {
+		// one row over the whole input, even when it is empty
+		grouped = &DataFrame{Data: map[string][]interface{}{}, Rows: 1}
+		for _, agg := range aggs {
+			vals := df.Data[agg.ColumnName]
+			if len(vals) > df.Rows {
+				vals = vals[:df.Rows]
+			}
+			grouped.Cols = append(grouped.Cols, agg.outputName())
+			grouped.Data[agg.outputName()] = []interface{}{agg.Fn(vals)}
+		}
+	} else {
+		grouped = df.GroupBy(keys, aggs...)
+	}
+	c.grouped = true
+	return grouped, nil
+}
@@ -0,0 +1,922 @@
+package gophers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// streamBatchRows is the batch size Collect uses when it streams a file scan
+// into a GroupBy instead of loading the whole file.
+const streamBatchRows = 65536
+
+// BatchIterator yields the result of a LazyFrame as a sequence of DataFrames.
+//
+//	it := ScanCSV("big.csv").Filter(Col("x").Gt(0)).Batches(100000)
+//	defer it.Close()
+//	for it.Next() {
+//		batch := it.DataFrame()
+//		...
+//	}
+//	if err := it.Err(); err != nil { ... }
+type BatchIterator struct {
+	src batchStream
+	cur *DataFrame
+	err error
+}
+
+// Batches returns an iterator over the optimized plan in DataFrames of at most
+// n rows. CSV and NDJSON scans are read incrementally and Filter, Select, Drop
+// and row-wise Column steps run batch by batch, so files larger than memory can
+// be processed. A GroupBy over such a scan keeps only partial aggregates per
+// group and emits its result once the input is exhausted. Other operators
+// (joins, OrderBy, window functions) need their whole input and are collected
+// first, then split into batches.
+func (lf *LazyFrame) Batches(n int) *BatchIterator {
+	if n <= 0 {
+		n = streamBatchRows
+	}
+	plan := optimizePlan(lf.plan)
+	src, ok := plan.stream(n)
+	if !ok {
+		src = &sliceStream{run: plan.run, size: n}
+	}
+	return &BatchIterator{src: src}
+}
+
+// Next advances to the next batch, returning false when the input is exhausted
+// or an error occurred (see Err).
+func (it *BatchIterator) Next() bool {
+	if it.err != nil || it.src == nil {
+		return false
+	}
+	df, err := it.src.next()
+	if err != nil {
+		it.err = err
+		it.Close()
+		return false
+	}
+	if df == nil {
+		it.Close()
+		return false
+	}
+	it.cur = df
+	return true
+}
+
+// DataFrame returns the current batch.
+func (it *BatchIterator) DataFrame() *DataFrame {
+	return it.cur
+}
+
+// Err returns the first error hit while reading, if any.
+func (it *BatchIterator) Err() error {
+	return it.err
+}
+
+// Close releases the underlying file. It is safe to call more than once.
+func (it *BatchIterator) Close() error {
+	if it.src == nil {
+		return nil
+	}
+	err := it.src.close()
+	it.src = nil
+	return err
+}
+
+// batchStream produces DataFrame batches; next returns nil at the end.
+type batchStream interface {
+	next() (*DataFrame, error)
+	close() error
+}
+
+// stream returns a batch stream for the node when it can run incrementally:
+// a CSV/NDJSON scan followed by row-wise operators and at most one GroupBy.
+func (n *lazyNode) stream(size int) (batchStream, bool) {
+	switch n.op {
+	case "scan_csv":
+		s, err := newCSVScanner(n.path, n.cols, compilePreds(n.preds), n.csv)
+		if err != nil {
+			return &errStream{err: fmt.Errorf("ScanCSV: %w", err)}, true
+		}
+		return &scanStream{op: "ScanCSV", read: s.read, closer: s, size: size}, true
+	case "scan_ndjson":
+		s, err := newNDJSONScanner(n.path, n.cols, compilePreds(n.preds))
+		if err != nil {
+			return &errStream{err: fmt.Errorf("ScanNDJSON: %w", err)}, true
+		}
+		return &scanStream{op: "ScanNDJSON", read: s.read, closer: s, size: size}, true
+	case "filter", "select", "drop", "column":
+		if n.op == "column" {
+			for _, e := range n.exprs {
+				if isWindow(e.spec) {
+					return nil, false
+				}
+			}
+		}
+		in, ok := n.input.stream(size)
+		if !ok {
+			return nil, false
+		}
+		return &mapStream{in: in, fn: n.apply}, true
+	case "groupby":
+		in, ok := n.input.stream(size)
+		if !ok {
+			return nil, false
+		}
+		keys, aggs := n.keys, n.aggs
+		return &sliceStream{run: func() (*DataFrame, error) {
+			return streamGroupBy(in, keys, aggs)
+		}, closer: in, size: size}, true
+	}
+	return nil, false
+}
+
+// apply runs a row-wise operator on one batch.
+func (n *lazyNode) apply(df *DataFrame) *DataFrame {
+	switch n.op {
+	case "filter":
+		return df.Filter(n.cond)
+	case "select":
+		return df.Select(n.cols...)
+	case "drop":
+		return df.Drop(n.cols...)
+	case "column":
+		return df.columns(n.exprs)
+	}
+	return df
+}
+
+// scanStream reads fixed-size batches from a file scanner.
+type scanStream struct {
+	op     string
+	read   func(limit int) (*DataFrame, bool, error)
+	closer io.Closer
+	size   int
+	done   bool
+}
+
+func (s *scanStream) next() (*DataFrame, error) {
+	if s.done {
+		return nil, nil
+	}
+	df, more, err := s.read(s.size)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.op, err)
+	}
+	s.done = !more
+	if df.Rows == 0 {
+		return nil, nil
+	}
+	return df, nil
+}
+
+func (s *scanStream) close() error {
+	if s.closer == nil {
+		return nil
+	}
+	err := s.closer.Close()
+	s.closer = nil
+	return err
+}
+
+// mapStream applies a row-wise operator to every batch, skipping batches it empties.
+type mapStream struct {
+	in batchStream
+	fn func(*DataFrame) *DataFrame
+}
+
+func (s *mapStream) next() (*DataFrame, error) {
+	for {
+		df, err := s.in.next()
+		if df == nil || err != nil {
+			return nil, err
+		}
+		if df = s.fn(df); df != nil && df.Rows > 0 {
+			return df, nil
+		}
+	}
+}
+
+func (s *mapStream) close() error {
+	return s.in.close()
+}
+
+// sliceStream materializes a DataFrame on first use and hands it out in slices.
+type sliceStream struct {
+	run    func() (*DataFrame, error)
+	closer batchStream
+	size   int
+	df     *DataFrame
+	pos    int
+}
+
+func (s *sliceStream) next() (*DataFrame, error) {
+	if s.df == nil {
+		df, err := s.run()
+		if err != nil {
+			return nil, err
+		}
+		if df == nil {
+			df = &DataFrame{Data: map[string][]interface{}{}}
+		}
+		s.df = df
+	}
+	if s.pos >= s.df.Rows {
+		return nil, nil
+	}
+	end := s.pos + s.size
+	if end > s.df.Rows {
+		end = s.df.Rows
+	}
+	df := s.df.rowRange(s.pos, end)
+	s.pos = end
+	return df, nil
+}
+
+func (s *sliceStream) close() error {
+	if s.closer != nil {
+		return s.closer.close()
+	}
+	return nil
+}
+
+// errStream reports an error opening a source.
+type errStream struct {
+	err error
+}
+
+func (s *errStream) next() (*DataFrame, error) { return nil, s.err }
+func (s *errStream) close() error              { return nil }
+
+// rowRange returns rows [start, end) sharing the underlying column slices.
+func (df *DataFrame) rowRange(start, end int) *DataFrame {
+	out := &DataFrame{Cols: append([]string(nil), df.Cols...), Data: make(map[string][]interface{}, len(df.Cols)), Rows: end - start, Dates: df.datesOf(df.Cols)}
+	for _, c := range df.Cols {
+		col := df.Data[c]
+		if end <= len(col) {
+			out.Data[c] = col[start:end:end]
+			continue
+		}
+		vals := make([]interface{}, end-start)
+		for i := start; i < end && i < len(col); i++ {
+			vals[i-start] = col[i]
+		}
+		out.Data[c] = vals
+	}
+	return out
+}
+
+// csvScanner reads a CSV file (or raw CSV text) incrementally, keeping only the
+// projected columns and the rows matching every predicate.
+type csvScanner struct {
+	r       *csv.Reader
+	file    *os.File
+	opt     CSVOptions
+	nulls   map[string]struct{}
+	keep    []string
+	pos     []int
+	preds   []Column
+	row     map[string]interface{}
+	pending []string           // first data record in no-header mode
+	types   map[string]csvType // InferTypes column types, fixed once decided
+}
+
+func newCSVScanner(input string, columns []string, preds []Column, opt CSVOptions) (*csvScanner, error) {
+	// If input is a file path, read it; otherwise treat as raw CSV text.
+	s := &csvScanner{preds: preds, opt: opt}
+	var src io.Reader = strings.NewReader(input)
+	if fi, err := os.Stat(input); err == nil && !fi.IsDir() {
+		f, err := os.Open(input)
+		if err != nil {
+			return nil, fmt.Errorf("read file: %v", err)
+		}
+		s.file = f
+		src = f
+	}
+	if opt.Encoding != "" {
+		dec, err := csvDecoder(opt.Encoding)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		src = transform.NewReader(src, dec)
+	}
+	comment := []rune(opt.Comment)
+	if len(comment) > 1 {
+		src = &commentFilter{r: bufio.NewReader(src), prefix: []byte(opt.Comment)}
+	}
+
+	s.r = csv.NewReader(src)
+	s.r.ReuseRecord = true
+	s.r.LazyQuotes = opt.LazyQuotes
+	if d := []rune(opt.Delimiter); len(d) > 0 {
+		s.r.Comma = d[0]
+	}
+	if len(comment) == 1 {
+		s.r.Comment = comment[0]
+	}
+	if len(opt.NullValues) > 0 {
+		s.nulls = make(map[string]struct{}, len(opt.NullValues))
+		for _, v := range opt.NullValues {
+			s.nulls[v] = struct{}{}
+		}
+	}
+
+	first, err := s.r.Read()
+	if err != nil && !(err == io.EOF && opt.NoHeader) {
+		s.Close()
+		return nil, fmt.Errorf("read headers: %v", err)
+	}
+	headers := append([]string(nil), first...)
+	if opt.NoHeader {
+		// generated names; the first record is data
+		if err == nil {
+			s.pending = headers
+		}
+		headers = make([]string, len(first))
+		for i := range headers {
+			headers[i] = fmt.Sprintf("column_%d", i+1)
+		}
+	} else {
+		// repeated names get a suffix (a, a_2, ...) so no column is read twice
+		seen := make(map[string]bool, len(headers))
+		for i, h := range headers {
+			headers[i] = uniqueName(seen, h)
+		}
+	}
+
+	// positions of the kept columns in each record
+	s.keep = headers
+	if columns != nil {
+		s.keep = columns
+	}
+	s.pos = make([]int, len(s.keep))
+	for i, c := range s.keep {
+		s.pos[i] = -1
+		for j, h := range headers {
+			if h == c {
+				s.pos[i] = j
+				break
+			}
+		}
+	}
+	s.row = make(map[string]interface{}, len(s.keep))
+	return s, nil
+}
+
+// read returns up to limit rows (all remaining rows when limit <= 0) and
+// whether more input may follow. With InferTypes the batch is converted
+// column by column before the predicates run, so a batch can hold fewer
+// than limit rows.
+func (s *csvScanner) read(limit int) (*DataFrame, bool, error) {
+	capacity := 1024
+	if limit > 0 && limit < capacity {
+		capacity = limit
+	}
+	df := &DataFrame{Cols: append([]string(nil), s.keep...), Data: make(map[string][]interface{}, len(s.keep))}
+	for _, c := range s.keep {
+		df.Data[c] = make([]interface{}, 0, capacity)
+	}
+	more := true
+	for limit <= 0 || df.Rows < limit {
+		record := s.pending
+		s.pending = nil
+		if record == nil {
+			var err error
+			record, err = s.r.Read()
+			if err == io.EOF {
+				more = false
+				break
+			}
+			if err != nil {
+				return df, false, fmt.Errorf("read record: %v", err)
+			}
+		}
+		for i, c := range s.keep {
+			v := ""
+			if s.pos[i] >= 0 && s.pos[i] < len(record) {
+				v = record[s.pos[i]]
+			}
+			if _, ok := s.nulls[v]; ok {
+				s.row[c] = nil
+			} else {
+				s.row[c] = v
+			}
+		}
+		if !s.opt.InferTypes && !matchesAll(s.preds, s.row) {
+			continue
+		}
+		for _, c := range s.keep {
+			df.Data[c] = append(df.Data[c], s.row[c])
+		}
+		df.Rows++
+	}
+	if s.opt.InferTypes {
+		s.inferTypes(df)
+		for _, p := range s.preds {
+			if df.Rows == 0 {
+				break
+			}
+			df = df.Filter(p)
+		}
+	}
+	return df, more, nil
+}
+
+// Close closes the underlying file, if any.
+func (s *csvScanner) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// csvDecoder returns a decoder to UTF-8 for an encoding name such as
+// "utf-16", "latin1", "windows-1252" or "shift_jis". A byte order mark, when
+// present, takes precedence.
+func csvDecoder(name string) (transform.Transformer, error) {
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported encoding %q", name)
+	}
+	return unicode.BOMOverride(enc.NewDecoder()), nil
+}
+
+// csvEncoder returns an encoder from UTF-8 for an encoding name.
+func csvEncoder(name string) (*encoding.Encoder, error) {
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported encoding %q", name)
+	}
+	return enc.NewEncoder(), nil
+}
+
+// commentFilter drops lines starting with a multi-character comment prefix.
+type commentFilter struct {
+	r      *bufio.Reader
+	prefix []byte
+	buf    []byte
+}
+
+func (c *commentFilter) Read(p []byte) (int, error) {
+	for len(c.buf) == 0 {
+		line, err := c.r.ReadBytes('\n')
+		if len(line) > 0 && !bytes.HasPrefix(line, c.prefix) {
+			c.buf = line
+		}
+		if err != nil {
+			if len(c.buf) == 0 {
+				return 0, err
+			}
+			break
+		}
+	}
+	n := copy(p, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+// csvType is the type InferTypes settles on for a CSV column.
+type csvType int
+
+const (
+	csvUnknown csvType = iota // no non-empty value seen yet
+	csvString
+	csvInt
+	csvFloat
+	csvBool
+	csvDate // "2006-01-02" values, read as a date column
+	csvTime
+)
+
+// inferTypes converts each column of CSV strings to int, float64, bool or
+// time.Time when every non-empty value parses as that type (checked in that
+// order); empty values in converted columns become nil. A column whose values
+// are all "2006-01-02" dates is a date column (see DataFrame.Dates). A
+// column's type is kept across batches and widened when a later batch needs
+// it (int to float64, date to timestamp, any other mismatch to string), so no
+// value is lost; batches read before the widening keep the narrower type.
+func (s *csvScanner) inferTypes(df *DataFrame) {
+	if s.types == nil {
+		s.types = make(map[string]csvType, len(df.Cols))
+	}
+	for _, c := range df.Cols {
+		s.types[c] = widenCSVType(s.types[c], inferCSVType(df.Data[c]))
+	}
+	var wg sync.WaitGroup
+	for _, c := range df.Cols {
+		df.setDate(c, s.types[c] == csvDate)
+		wg.Add(1)
+		go func(vals []interface{}, t csvType) {
+			defer wg.Done()
+			convertCSVColumn(vals, t)
+		}(df.Data[c], s.types[c])
+	}
+	wg.Wait()
+}
+
+// widenCSVType returns the narrowest type that holds values of both a and b.
+func widenCSVType(a, b csvType) csvType {
+	switch {
+	case a == b || b == csvUnknown:
+		return a
+	case a == csvUnknown:
+		return b
+	case (a == csvInt && b == csvFloat) || (a == csvFloat && b == csvInt):
+		return csvFloat
+	case (a == csvDate && b == csvTime) || (a == csvTime && b == csvDate):
+		return csvTime
+	}
+	return csvString
+}
+
+// inferCSVType returns the narrowest type every non-empty value parses as.
+func inferCSVType(vals []interface{}) csvType {
+	isInt, isFloat, isBool, isDate, dateOnly := true, true, true, true, true
+	seen := false
+	for _, v := range vals {
+		str, ok := v.(string)
+		if !ok || str == "" {
+			continue
+		}
+		seen = true
+		if isInt {
+			if _, err := strconv.Atoi(str); err != nil {
+				isInt = false
+			}
+		}
+		if isFloat && !isInt {
+			if _, err := strconv.ParseFloat(str, 64); err != nil || !strings.ContainsAny(str, "0123456789") {
+				isFloat = false
+			}
+		}
+		if isBool {
+			if l := strings.ToLower(str); l != "true" && l != "false" {
+				isBool = false
+			}
+		}
+		if isDate {
+			if _, ok := parseTimeAny(str); !ok {
+				isDate = false
+			} else if _, err := time.Parse(time.DateOnly, str); err != nil {
+				dateOnly = false
+			}
+		}
+		if !isInt && !isFloat && !isBool && !isDate {
+			return csvString
+		}
+	}
+	switch {
+	case !seen:
+		return csvUnknown
+	case isInt:
+		return csvInt
+	case isFloat:
+		return csvFloat
+	case isBool:
+		return csvBool
+	case dateOnly:
+		return csvDate
+	}
+	return csvTime
+}
+
+// convertCSVColumn converts the strings of vals to t in place; a value that
+// does not parse as t is left as a string.
+func convertCSVColumn(vals []interface{}, t csvType) {
+	if t == csvUnknown || t == csvString {
+		return
+	}
+	for i, v := range vals {
+		str, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if str == "" {
+			vals[i] = nil
+			continue
+		}
+		switch t {
+		case csvInt:
+			if n, err := strconv.Atoi(str); err == nil {
+				vals[i] = n
+			}
+		case csvFloat:
+			if f, err := strconv.ParseFloat(str, 64); err == nil {
+				vals[i] = f
+			}
+		case csvBool:
+			if l := strings.ToLower(str); l == "true" || l == "false" {
+				vals[i] = l == "true"
+			}
+		case csvDate, csvTime:
+			if tm, ok := parseTimeAny(str); ok {
+				vals[i] = tm
+			}
+		}
+	}
+}
+
+// ndjsonScanner reads newline-delimited JSON (file or raw text) incrementally.
+// Columns appear in first-seen order; a column first seen in a later batch is
+// nil in the rows before it.
+type ndjsonScanner struct {
+	r     *bufio.Reader
+	file  *os.File
+	cols  []string
+	seen  map[string]struct{}
+	keep  []string
+	preds []Column
+	line  int
+}
+
+func newNDJSONScanner(input string, columns []string, preds []Column) (*ndjsonScanner, error) {
+	s := &ndjsonScanner{seen: map[string]struct{}{}, keep: columns, preds: preds}
+	var src io.Reader = strings.NewReader(input)
+	if fi, err := os.Stat(input); err == nil && !fi.IsDir() {
+		f, err := os.Open(input)
+		if err != nil {
+			return nil, fmt.Errorf("read file: %v", err)
+		}
+		s.file = f
+		src = f
+	}
+	s.r = bufio.NewReaderSize(src, 1<<20)
+	for _, c := range columns {
+		s.seen[c] = struct{}{}
+		s.cols = append(s.cols, c)
+	}
+	return s, nil
+}
+
+func (s *ndjsonScanner) read(limit int) (*DataFrame, bool, error) {
+	rows := make([]map[string]interface{}, 0, 1024)
+	more := true
+	for limit <= 0 || len(rows) < limit {
+		line, err := s.r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, false, fmt.Errorf("read line: %v", err)
+		}
+		if len(line) > 0 {
+			s.line++
+		}
+		if strings.TrimSpace(string(line)) != "" {
+			var m map[string]interface{}
+			if jerr := json.Unmarshal(line, &m); jerr != nil {
+				return nil, false, ndjsonLineError(string(line), s.line, jerr)
+			}
+			for k, v := range m {
+				// JSON numbers decode as float64; keep whole numbers as int
+				if f, ok := v.(float64); ok && f == float64(int(f)) {
+					m[k] = int(f)
+				}
+			}
+			if s.keep == nil {
+				for k := range m {
+					if _, ok := s.seen[k]; !ok {
+						s.seen[k] = struct{}{}
+						s.cols = append(s.cols, k)
+					}
+				}
+			}
+			if matchesAll(s.preds, m) {
+				rows = append(rows, m)
+			}
+		}
+		if err == io.EOF {
+			more = false
+			break
+		}
+	}
+
+	df := &DataFrame{Cols: append([]string(nil), s.cols...), Data: make(map[string][]interface{}, len(s.cols)), Rows: len(rows)}
+	for _, c := range s.cols {
+		vals := make([]interface{}, len(rows))
+		for i, r := range rows {
+			vals[i] = r[c]
+		}
+		df.Data[c] = vals
+	}
+	return df, more, nil
+}
+
+// Close closes the underlying file, if any.
+func (s *ndjsonScanner) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+func matchesAll(preds []Column, row map[string]interface{}) bool {
+	for _, p := range preds {
+		if ok, _ := p.Fn(row).(bool); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ndjsonColumns returns the sorted projection for an NDJSON scan; NDJSON has no
+// header, so the plan's own Select decides the final column order.
+func ndjsonColumns(needed map[string]struct{}) []string {
+	cols := make([]string, 0, len(needed))
+	for c := range needed {
+		cols = append(cols, c)
+	}
+	sort.Strings(cols)
+	return cols
+}
+
+// partialAgg is the running state of one aggregation for one group. Sum, Min,
+// Max, Mean, First and Unique merge batch results in constant space; other
+// aggregations keep the group's values and run Fn once at the end.
+type partialAgg struct {
+	set    bool
+	num    float64
+	count  int
+	first  interface{}
+	seen   map[interface{}]struct{}
+	values []interface{}
+}
+
+func (p *partialAgg) add(a Aggregation, vals []interface{}) {
+	switch a.kind {
+	case "sum":
+		if f, ok := a.Fn(vals).(float64); ok {
+			p.num += f
+		}
+		p.set = true
+	case "min", "max":
+		f, ok := a.Fn(vals).(float64)
+		if ok && (!p.set || (a.kind == "min" && f < p.num) || (a.kind == "max" && f > p.num)) {
+			p.num = f
+			p.set = true
+		}
+	case "mean":
+		for _, v := range vals {
+			if f, err := toFloat64(v); err == nil {
+				p.num += f
+				p.count++
+			}
+		}
+	case "first":
+		if !p.set && len(vals) > 0 {
+			p.first = vals[0]
+			p.set = true
+		}
+	case "unique":
+		if p.seen == nil {
+			p.seen = make(map[interface{}]struct{})
+		}
+		for _, v := range vals {
+			p.seen[v] = struct{}{}
+		}
+	default:
+		p.values = append(p.values, vals...)
+	}
+}
+
+func (p *partialAgg) result(a Aggregation) interface{} {
+	switch a.kind {
+	case "sum":
+		return p.num
+	case "min", "max":
+		if !p.set {
+			return nil
+		}
+		return p.num
+	case "mean":
+		if p.count == 0 {
+			return nil
+		}
+		return p.num / float64(p.count)
+	case "first":
+		return p.first
+	case "unique":
+		return len(p.seen)
+	}
+	if p.values == nil {
+		p.values = []interface{}{}
+	}
+	return a.Fn(p.values)
+}
+
+// streamGroup is one output group of a streaming GroupBy.
+type streamGroup struct {
+	key   []interface{}
+	parts []partialAgg
+}
+
+// streamGroupBy aggregates a batch stream group by group, merging each batch's
+// partial results, so only one batch and the per-group state are in memory.
+// Groups appear in first-appearance order, like DataFrame.GroupBy.
+func streamGroupBy(in batchStream, keys []string, aggs []Aggregation) (*DataFrame, error) {
+	index := map[string]int{}
+	var groups []*streamGroup
+	var dates map[string]bool // date columns of the first batch
+	var b strings.Builder
+	for first := true; ; first = false {
+		df, err := in.next()
+		if err != nil {
+			return nil, err
+		}
+		if df == nil {
+			break
+		}
+		if first {
+			dates = df.Dates
+		}
+
+		keyCols := make([]*typedColumn, len(keys))
+		for i, k := range keys {
+			keyCols[i] = df.typedColumn(k)
+			if keyCols[i] == nil {
+				keyCols[i] = newTypedColumn(make([]interface{}, df.Rows))
+			}
+		}
+		batchGroups := groupRows(keyCols, df.Rows)
+
+		// map each batch group to its global group (serially), then merge in parallel
+		targets := make([]*streamGroup, len(batchGroups))
+		for i, grp := range batchGroups {
+			b.Reset()
+			key := make([]interface{}, len(keys))
+			for k := range keys {
+				key[k] = keyCols[k].boxed[grp.first]
+				ck := canonicalKey(key[k])
+				fmt.Fprintf(&b, "%d:%s", len(ck), ck)
+			}
+			gi, ok := index[b.String()]
+			if !ok {
+				gi = len(groups)
+				index[b.String()] = gi
+				groups = append(groups, &streamGroup{key: key, parts: make([]partialAgg, len(aggs))})
+			}
+			targets[i] = groups[gi]
+		}
+
+		w := runtime.GOMAXPROCS(0)
+		chunk := (len(batchGroups) + w - 1) / w
+		var wg sync.WaitGroup
+		for g := 0; g < w; g++ {
+			s := g * chunk
+			e := s + chunk
+			if s >= len(batchGroups) {
+				break
+			}
+			if e > len(batchGroups) {
+				e = len(batchGroups)
+			}
+			wg.Add(1)
+			go func(s, e int) {
+				defer wg.Done()
+				for i := s; i < e; i++ {
+					for j, agg := range aggs {
+						vals := []interface{}{}
+						if col, ok := df.Data[agg.ColumnName]; ok {
+							vals = gather(col, batchGroups[i].rows)
+						}
+						targets[i].parts[j].add(agg, vals)
+					}
+				}
+			}(s, e)
+		}
+		wg.Wait()
+	}
+
+	newCols := outputNames(keys, aggs)
+	out := &DataFrame{Cols: newCols, Data: make(map[string][]interface{}, len(newCols)), Rows: len(groups), Dates: groupDates(dates, keys, aggs, newCols)}
+	for _, c := range newCols {
+		out.Data[c] = make([]interface{}, len(groups))
+	}
+	for i, grp := range groups {
+		for k, key := range keys {
+			out.Data[key][i] = grp.key[k]
+		}
+		for j, agg := range aggs {
+			out.Data[newCols[len(keys)+j]][i] = grp.parts[j].result(agg)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,1088 @@
+package gophers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// svgPalette is the Highcharts default series palette, used when the chart sets no colors.
+var svgPalette = []string{"#2caffe", "#544fc5", "#00e272", "#fe6a35", "#6b8abc", "#d568fb", "#2ee0ca", "#fa4b42", "#feb56a", "#91e8e1"}
+
+// SVG renders the chart as a standalone SVG document in pure Go, with no
+// Highcharts script or browser involved. It reads the same config the chart
+// builders emit (bar, column, stacked, line, area, scatter, bubble, pie,
+// boxplot, heatmap and treemap charts) and honors ChartOptions such as
+// height/width, palette, axis ranges, log scales, legend and data labels.
+// DataTable charts have no config and return an error.
+func (c Chart) SVG() (string, error) {
+	config, err := c.config()
+	if err != nil {
+		return "", err
+	}
+	return renderSVG(config), nil
+}
+
+// SaveSVG writes the chart's SVG rendering to filename.
+func (c Chart) SaveSVG(filename string) error {
+	svg, err := c.SVG()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, []byte(svg), 0644)
+}
+
+// config decodes the Highcharts config carried in the chart's JS fragment.
+func (c Chart) config() (map[string]interface{}, error) {
+	s := strings.TrimSpace(c.Jspostid)
+	s = strings.TrimPrefix(s, "',")
+	s = strings.TrimSuffix(s, ";")
+	s = strings.TrimSuffix(strings.TrimSpace(s), ")")
+	if s == "" {
+		return nil, fmt.Errorf("SVG: chart %q has no chart config", c.Htmldivid)
+	}
+	var config map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &config); err != nil {
+		return nil, fmt.Errorf("SVG: chart %q: invalid chart config: %v", c.Htmldivid, err)
+	}
+	return config, nil
+}
+
+// chartScriptConfig splits a report script entry written by AddChart
+// (Highcharts.chart('<id>', {...});) into the element id and chart config.
+func chartScriptConfig(script string) (string, map[string]interface{}, bool) {
+	const prefix = "Highcharts.chart('"
+	if !strings.HasPrefix(script, prefix) {
+		return "", nil, false
+	}
+	rest := script[len(prefix):]
+	end := strings.Index(rest, "'")
+	if end < 0 {
+		return "", nil, false
+	}
+	config, err := Chart{Jspostid: rest[end:]}.config()
+	if err != nil {
+		return "", nil, false
+	}
+	return rest[:end], config, true
+}
+
+// svgSeries is one series of a chart config.
+type svgSeries struct {
+	name  string
+	kind  string
+	color string
+	data  []interface{}
+	raw   map[string]interface{}
+}
+
+// svgPoint is a decoded data point: x/y(/z) for cartesian charts or the five
+// box values for boxplots.
+type svgPoint struct {
+	x, y, z float64
+	ok      bool
+	box     []float64
+}
+
+type svgRect struct {
+	x, y, w, h float64
+}
+
+// svgCanvas accumulates SVG elements.
+type svgCanvas struct {
+	b      strings.Builder
+	colors []string
+	labels string // label color
+}
+
+func (cv *svgCanvas) color(i int) string {
+	return cv.colors[i%len(cv.colors)]
+}
+
+func (cv *svgCanvas) text(x, y float64, anchor string, size float64, fill string, s string, extra string) {
+	fmt.Fprintf(&cv.b, `<text x="%.1f" y="%.1f" text-anchor="%s" font-size="%g" fill="%s"%s>%s</text>`, x, y, anchor, size, fill, extra, html.EscapeString(s))
+}
+
+func (cv *svgCanvas) rect(r svgRect, fill string, extra string) {
+	fmt.Fprintf(&cv.b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"%s/>`, r.x, r.y, math.Max(r.w, 0), math.Max(r.h, 0), fill, extra)
+}
+
+func (cv *svgCanvas) line(x1, y1, x2, y2 float64, stroke string, width float64) {
+	fmt.Fprintf(&cv.b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="%g"/>`, x1, y1, x2, y2, stroke, width)
+}
+
+// renderSVG draws a Highcharts config as SVG.
+func renderSVG(config map[string]interface{}) string {
+	chart := svgMap(config["chart"])
+	width := svgNumber(chart["width"], 800)
+	height := svgNumber(chart["height"], 400)
+	background, _ := chart["backgroundColor"].(string)
+	if background == "" {
+		background = "#ffffff"
+	}
+	font := "Helvetica, Arial, sans-serif"
+	if f, ok := svgMap(chart["style"])["fontFamily"].(string); ok && f != "" {
+		font = f
+	}
+
+	cv := &svgCanvas{colors: svgStrings(config["colors"]), labels: "#333333"}
+	if len(cv.colors) == 0 {
+		cv.colors = svgPalette
+	}
+	fmt.Fprintf(&cv.b, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="%s">`, width, height, width, height, html.EscapeString(font))
+	cv.rect(svgRect{0, 0, width, height}, background, "")
+
+	top := 10.0
+	if title, _ := svgMap(config["title"])["text"].(string); title != "" {
+		cv.text(width/2, 30, "middle", 18, cv.labels, title, ` font-weight="bold"`)
+		top = 42
+	}
+	if subtitle, _ := svgMap(config["subtitle"])["text"].(string); subtitle != "" {
+		cv.text(width/2, top+8, "middle", 12, "#666666", subtitle, "")
+		top += 22
+	}
+
+	series := chartSeries(config, cv)
+	kind, _ := chart["type"].(string)
+	if kind == "" && len(series) > 0 {
+		kind = series[0].kind
+	}
+	if kind == "" {
+		kind = "line"
+	}
+
+	// legend items, drawn under the plot
+	legend := svgMap(config["legend"])
+	items := []svgSeries{}
+	switch kind {
+	case "pie":
+		if enabled, ok := legend["enabled"].(bool); ok && enabled && len(series) > 0 {
+			for i, p := range series[0].data {
+				items = append(items, svgSeries{name: svgPointName(p, i), color: cv.color(i)})
+			}
+		}
+	case "treemap", "heatmap":
+	default:
+		for _, s := range series {
+			if s.name != "Outliers" {
+				items = append(items, s)
+			}
+		}
+		if enabled, ok := legend["enabled"].(bool); (ok && !enabled) || (!ok && len(items) < 2) {
+			items = nil
+		}
+	}
+	rows := svgLegendRows(items, width-40)
+	area := svgRect{10, top + 10, width - 20, height - top - 20 - float64(len(rows))*20}
+
+	switch kind {
+	case "pie":
+		if len(series) > 0 {
+			cv.pie(config, series[0], area)
+		}
+	case "treemap":
+		if len(series) > 0 {
+			cv.treemap(series[0], area)
+		}
+	case "heatmap":
+		if len(series) > 0 {
+			cv.heatmap(config, series[0], area)
+		}
+	default:
+		cv.cartesian(config, kind, series, area)
+	}
+
+	y := area.y + area.h + 20
+	for _, row := range rows {
+		rowWidth := 0.0
+		for _, it := range row {
+			rowWidth += svgLegendWidth(it.name)
+		}
+		x := (width - rowWidth) / 2
+		for _, it := range row {
+			cv.rect(svgRect{x, y - 9, 10, 10}, it.color, ` rx="2"`)
+			cv.text(x+14, y, "start", 12, cv.labels, it.name, "")
+			x += svgLegendWidth(it.name)
+		}
+		y += 20
+	}
+
+	cv.b.WriteString("</svg>")
+	return cv.b.String()
+}
+
+// chartSeries decodes config.series, assigning palette colors in order.
+func chartSeries(config map[string]interface{}, cv *svgCanvas) []svgSeries {
+	out := []svgSeries{}
+	raw, _ := config["series"].([]interface{})
+	for i, r := range raw {
+		m := svgMap(r)
+		s := svgSeries{raw: m, color: cv.color(i)}
+		s.name, _ = m["name"].(string)
+		s.kind, _ = m["type"].(string)
+		if c, ok := m["color"].(string); ok && c != "" {
+			s.color = c
+		}
+		s.data, _ = m["data"].([]interface{})
+		out = append(out, s)
+	}
+	return out
+}
+
+// cartesian draws bar, column, line, area, scatter, bubble and boxplot series on x/y axes.
+func (cv *svgCanvas) cartesian(config map[string]interface{}, kind string, series []svgSeries, area svgRect) {
+	xAxis, yAxis := svgMap(config["xAxis"]), svgMap(config["yAxis"])
+	plotOptions := svgMap(config["plotOptions"])
+	categories := svgStrings(xAxis["categories"])
+	xType, _ := xAxis["type"].(string)
+	banded := kind == "bar" || kind == "column" || kind == "boxplot"
+	if xType == "" {
+		if categories != nil || banded {
+			xType = "category"
+		} else {
+			xType = "linear"
+		}
+	}
+	inverted := kind == "bar"
+	stacking := ""
+	for _, key := range []string{"series", kind} {
+		if s, ok := svgMap(plotOptions[key])["stacking"].(string); ok {
+			stacking = s
+		}
+	}
+	if !banded {
+		stacking = ""
+	}
+	labelsOn := false
+	for _, key := range []string{"series", kind} {
+		if on, ok := svgMap(svgMap(plotOptions[key])["dataLabels"])["enabled"].(bool); ok {
+			labelsOn = on
+		}
+	}
+
+	// decode points and find the data ranges
+	points := make([][]svgPoint, len(series))
+	nCat := len(categories)
+	xMin, xMax := math.Inf(1), math.Inf(-1)
+	for si, s := range series {
+		boxes := kind == "boxplot" && s.kind != "scatter"
+		for j, raw := range s.data {
+			p := svgDecodePoint(raw, j, boxes)
+			points[si] = append(points[si], p)
+			if xType == "category" {
+				nCat = max(nCat, int(p.x)+1)
+			} else if p.ok {
+				xMin, xMax = math.Min(xMin, p.x), math.Max(xMax, p.x)
+			}
+		}
+	}
+	for len(categories) < nCat {
+		categories = append(categories, strconv.Itoa(len(categories)))
+	}
+
+	// stacked totals per category (column/bar with stacking)
+	posTotal, negTotal := map[float64]float64{}, map[float64]float64{}
+	if stacking != "" {
+		for si := range series {
+			for _, p := range points[si] {
+				if !p.ok {
+					continue
+				}
+				if p.y >= 0 {
+					posTotal[p.x] += p.y
+				} else {
+					negTotal[p.x] += p.y
+				}
+			}
+		}
+	}
+
+	yLog := yAxis["type"] == "logarithmic"
+	yMin, yMax := math.Inf(1), math.Inf(-1)
+	include := func(v float64) {
+		if yLog && v <= 0 {
+			return
+		}
+		yMin, yMax = math.Min(yMin, v), math.Max(yMax, v)
+	}
+	switch {
+	case stacking == "percent":
+		include(0)
+		include(100)
+	case stacking != "":
+		for _, t := range posTotal {
+			include(t)
+		}
+		for _, t := range negTotal {
+			include(t)
+		}
+	default:
+		for si := range series {
+			for _, p := range points[si] {
+				if p.box != nil {
+					for _, v := range p.box {
+						include(v)
+					}
+				} else if p.ok {
+					include(p.y)
+				}
+			}
+		}
+	}
+	if (banded || kind == "area") && kind != "boxplot" && !yLog {
+		include(0)
+	}
+	if math.IsInf(yMin, 0) {
+		yMin, yMax = 0, 1
+	}
+	if v, ok := yAxis["min"].(float64); ok {
+		yMin = v
+	}
+	if v, ok := yAxis["max"].(float64); ok {
+		yMax = v
+	}
+	yTicks, yScale := svgScale(yMin, yMax, yLog, yAxis["min"] != nil, yAxis["max"] != nil)
+
+	xLog := xType == "logarithmic"
+	var xTicks []float64
+	var xScale func(float64) float64
+	if xType != "category" {
+		if math.IsInf(xMin, 0) {
+			xMin, xMax = 0, 1
+		}
+		if v, ok := xAxis["min"].(float64); ok {
+			xMin = v
+		}
+		if v, ok := xAxis["max"].(float64); ok {
+			xMax = v
+		}
+		if xType == "datetime" {
+			xTicks, xScale = svgTimeScale(xMin, xMax)
+		} else {
+			xTicks, xScale = svgScale(xMin, xMax, xLog, xAxis["min"] != nil, xAxis["max"] != nil)
+		}
+	}
+
+	// margins for tick labels and axis titles
+	xTitle, _ := svgMap(xAxis["title"])["text"].(string)
+	yTitle, _ := svgMap(yAxis["title"])["text"].(string)
+	valueLabels := make([]string, len(yTicks))
+	for i, t := range yTicks {
+		valueLabels[i] = svgFormat(t)
+		if stacking == "percent" {
+			valueLabels[i] += "%"
+		}
+	}
+	left, bottom := 10.0, 22.0
+	if inverted {
+		left += svgLabelWidth(categories, 11)
+	} else {
+		left += svgLabelWidth(valueLabels, 11)
+	}
+	leftTitle, bottomTitle := yTitle, xTitle
+	if inverted {
+		leftTitle, bottomTitle = xTitle, yTitle
+	}
+	if leftTitle != "" {
+		left += 18
+	}
+	if bottomTitle != "" {
+		bottom += 18
+	}
+	plot := svgRect{area.x + left, area.y, area.w - left - 10, area.h - bottom}
+
+	// value axis (vertical, or horizontal for bar charts)
+	valuePos := func(v float64) float64 {
+		t := yScale(v)
+		if inverted {
+			return plot.x + t*plot.w
+		}
+		return plot.y + plot.h - t*plot.h
+	}
+	band := 0.0
+	if nCat > 0 {
+		if inverted {
+			band = plot.h / float64(nCat)
+		} else {
+			band = plot.w / float64(nCat)
+		}
+	}
+	catPos := func(x float64) float64 {
+		if inverted {
+			return plot.y + (x+0.5)*band
+		}
+		return plot.x + (x+0.5)*band
+	}
+	xPos := func(x float64) float64 {
+		if xType == "category" {
+			return catPos(x)
+		}
+		return plot.x + xScale(x)*plot.w
+	}
+
+	grid := svgNumber(yAxis["gridLineWidth"], 1)
+	for i, t := range yTicks {
+		p := valuePos(t)
+		if inverted {
+			if grid > 0 {
+				cv.line(p, plot.y, p, plot.y+plot.h, "#e6e6e6", grid)
+			}
+			cv.text(p, plot.y+plot.h+15, "middle", 11, "#666666", valueLabels[i], "")
+		} else {
+			if grid > 0 {
+				cv.line(plot.x, p, plot.x+plot.w, p, "#e6e6e6", grid)
+			}
+			cv.text(plot.x-6, p+4, "end", 11, "#666666", valueLabels[i], "")
+		}
+	}
+
+	// category / x axis
+	xGrid := svgNumber(xAxis["gridLineWidth"], 0)
+	if xType == "category" {
+		step := 1
+		if !inverted {
+			// skip labels that would overlap
+			step = int(math.Ceil(svgLabelWidth(categories, 11) / math.Max(band, 1)))
+			step = max(step, 1)
+		}
+		for i, c := range categories {
+			if xGrid > 0 {
+				edge := float64(i) * band
+				if inverted {
+					cv.line(plot.x, plot.y+edge, plot.x+plot.w, plot.y+edge, "#e6e6e6", xGrid)
+				} else {
+					cv.line(plot.x+edge, plot.y, plot.x+edge, plot.y+plot.h, "#e6e6e6", xGrid)
+				}
+			}
+			if i%step != 0 {
+				continue
+			}
+			if inverted {
+				cv.text(plot.x-6, catPos(float64(i))+4, "end", 11, "#666666", c, "")
+			} else {
+				cv.text(catPos(float64(i)), plot.y+plot.h+15, "middle", 11, "#666666", c, "")
+			}
+		}
+	} else {
+		for _, t := range xTicks {
+			p := plot.x + xScale(t)*plot.w
+			if xGrid > 0 {
+				cv.line(p, plot.y, p, plot.y+plot.h, "#e6e6e6", xGrid)
+			}
+			label := svgFormat(t)
+			if xType == "datetime" {
+				label = svgTimeLabel(t, xMax-xMin)
+			}
+			cv.text(p, plot.y+plot.h+15, "middle", 11, "#666666", label, "")
+		}
+	}
+	if inverted {
+		cv.line(plot.x, plot.y, plot.x, plot.y+plot.h, "#ccd6eb", 1)
+	} else {
+		cv.line(plot.x, plot.y+plot.h, plot.x+plot.w, plot.y+plot.h, "#ccd6eb", 1)
+	}
+	if leftTitle != "" {
+		x, y := area.x+10, plot.y+plot.h/2
+		cv.text(x, y, "middle", 12, "#666666", leftTitle, fmt.Sprintf(` transform="rotate(-90 %.1f %.1f)"`, x, y))
+	}
+	if bottomTitle != "" {
+		cv.text(plot.x+plot.w/2, plot.y+plot.h+34, "middle", 12, "#666666", bottomTitle, "")
+	}
+
+	// series; stacks grow from the axis in series order
+	posBase, negBase := map[float64]float64{}, map[float64]float64{}
+	drawn := 0
+	for si, s := range series {
+		if kind != "boxplot" || s.kind != "scatter" {
+			drawn++
+		}
+		pts := points[si]
+		seriesKind := s.kind
+		if seriesKind == "" {
+			seriesKind = kind
+		}
+		switch seriesKind {
+		case "bar", "column":
+			n := drawn
+			slots := 0
+			for _, other := range series {
+				if other.kind == "" || other.kind == kind {
+					slots++
+				}
+			}
+			if stacking != "" {
+				n, slots = 1, 1
+			}
+			width := band * 0.8 / float64(max(slots, 1))
+			for _, p := range pts {
+				if !p.ok {
+					continue
+				}
+				from, to := 0.0, p.y
+				if stacking != "" {
+					base := posBase
+					if p.y < 0 {
+						base = negBase
+					}
+					from, to = base[p.x], base[p.x]+p.y
+					base[p.x] = to
+					if total := posTotal[p.x] - negTotal[p.x]; stacking == "percent" && total != 0 {
+						from, to = from/total*100, to/total*100
+					}
+				}
+				a, b := valuePos(from), valuePos(to)
+				if yLog && from <= 0 {
+					a = valuePos(yTicks[0])
+				}
+				offset := catPos(p.x) - band*0.4 + float64(n-1)*width
+				var r svgRect
+				if inverted {
+					r = svgRect{math.Min(a, b), offset, math.Abs(b - a), width - 1}
+				} else {
+					r = svgRect{offset, math.Min(a, b), width - 1, math.Abs(b - a)}
+				}
+				cv.rect(r, s.color, "")
+				if labelsOn {
+					label := svgFormat(p.y)
+					if stacking == "percent" {
+						label = svgFormat(math.Round(to-from)) + "%"
+					}
+					if inverted {
+						cv.text(r.x+r.w+4, r.y+r.h/2+4, "start", 10, cv.labels, label, "")
+					} else {
+						cv.text(r.x+r.w/2, r.y-4, "middle", 10, cv.labels, label, "")
+					}
+				}
+			}
+		case "boxplot":
+			width := band * 0.5
+			for _, p := range pts {
+				if p.box == nil {
+					continue
+				}
+				c := catPos(p.x)
+				low, q1, med, q3, high := valuePos(p.box[0]), valuePos(p.box[1]), valuePos(p.box[2]), valuePos(p.box[3]), valuePos(p.box[4])
+				cv.line(c, low, c, q1, s.color, 1)
+				cv.line(c, q3, c, high, s.color, 1)
+				cv.line(c-width/4, low, c+width/4, low, s.color, 1)
+				cv.line(c-width/4, high, c+width/4, high, s.color, 1)
+				cv.rect(svgRect{c - width/2, math.Min(q1, q3), width, math.Abs(q1 - q3)}, "#ffffff", fmt.Sprintf(` stroke="%s"`, s.color))
+				cv.line(c-width/2, med, c+width/2, med, s.color, 2)
+			}
+		case "scatter", "bubble":
+			zMax := 0.0
+			for _, p := range pts {
+				zMax = math.Max(zMax, math.Abs(p.z))
+			}
+			for _, p := range pts {
+				if !p.ok {
+					continue
+				}
+				r := 4.0
+				if seriesKind == "bubble" && zMax > 0 {
+					r = 6 + 24*math.Sqrt(math.Abs(p.z)/zMax)
+				}
+				fill, extra := s.color, ` fill-opacity="0.6"`
+				if marker := svgMap(s.raw["marker"]); marker["lineColor"] != nil {
+					fill, extra = "#ffffff", fmt.Sprintf(` stroke="%v"`, marker["lineColor"])
+				}
+				fmt.Fprintf(&cv.b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s"%s/>`, xPos(p.x), valuePos(p.y), r, fill, extra)
+			}
+		default: // line, area, spline
+			segments := [][]svgPoint{{}}
+			for _, p := range pts {
+				if !p.ok {
+					segments = append(segments, []svgPoint{})
+					continue
+				}
+				segments[len(segments)-1] = append(segments[len(segments)-1], p)
+			}
+			for _, seg := range segments {
+				if len(seg) == 0 {
+					continue
+				}
+				var path strings.Builder
+				for i, p := range seg {
+					cmd := "L"
+					if i == 0 {
+						cmd = "M"
+					}
+					fmt.Fprintf(&path, "%s%.1f %.1f ", cmd, xPos(p.x), valuePos(p.y))
+				}
+				if seriesKind == "area" {
+					base := valuePos(math.Max(yTicks[0], 0))
+					fmt.Fprintf(&cv.b, `<path d="%sL%.1f %.1f L%.1f %.1f Z" fill="%s" fill-opacity="0.5"/>`, path.String(), xPos(seg[len(seg)-1].x), base, xPos(seg[0].x), base, s.color)
+				}
+				fmt.Fprintf(&cv.b, `<path d="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.TrimSpace(path.String()), s.color)
+				if len(seg) == 1 {
+					fmt.Fprintf(&cv.b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`, xPos(seg[0].x), valuePos(seg[0].y), s.color)
+				}
+			}
+		}
+	}
+}
+
+// pie draws a pie series with name/percent labels.
+func (cv *svgCanvas) pie(config map[string]interface{}, s svgSeries, area svgRect) {
+	total := 0.0
+	values := make([]float64, len(s.data))
+	for i, p := range s.data {
+		if v, ok := svgMap(p)["y"].(float64); ok && v > 0 {
+			values[i] = v
+			total += v
+		} else if v, ok := p.(float64); ok && v > 0 {
+			values[i] = v
+			total += v
+		}
+	}
+	if total == 0 {
+		return
+	}
+	labelsOn := true
+	if on, ok := svgMap(svgMap(svgMap(config["plotOptions"])["pie"])["dataLabels"])["enabled"].(bool); ok {
+		labelsOn = on
+	}
+	if on, ok := svgMap(svgMap(svgMap(config["plotOptions"])["series"])["dataLabels"])["enabled"].(bool); ok {
+		labelsOn = on
+	}
+	cx, cy := area.x+area.w/2, area.y+area.h/2
+	r := math.Min(area.w, area.h)/2 - 30
+	if r < 10 {
+		r = math.Min(area.w, area.h) / 2
+	}
+	angle := -math.Pi / 2
+	for i, v := range values {
+		if v == 0 {
+			continue
+		}
+		sweep := v / total * 2 * math.Pi
+		if sweep >= 2*math.Pi-1e-9 {
+			fmt.Fprintf(&cv.b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s"/>`, cx, cy, r, cv.color(i))
+		} else {
+			large := 0
+			if sweep > math.Pi {
+				large = 1
+			}
+			x1, y1 := cx+r*math.Cos(angle), cy+r*math.Sin(angle)
+			x2, y2 := cx+r*math.Cos(angle+sweep), cy+r*math.Sin(angle+sweep)
+			fmt.Fprintf(&cv.b, `<path d="M%.1f %.1f L%.1f %.1f A%.1f %.1f 0 %d 1 %.1f %.1f Z" fill="%s" stroke="#ffffff" stroke-width="1"/>`, cx, cy, x1, y1, r, r, large, x2, y2, cv.color(i))
+		}
+		if labelsOn {
+			mid := angle + sweep/2
+			lx, ly := cx+(r+12)*math.Cos(mid), cy+(r+12)*math.Sin(mid)
+			anchor := "start"
+			if math.Cos(mid) < 0 {
+				anchor = "end"
+			}
+			cv.text(lx, ly+4, anchor, 11, cv.labels, fmt.Sprintf("%s: %.1f%%", svgPointName(s.data[i], i), v/total*100), "")
+		}
+		angle += sweep
+	}
+}
+
+// heatmap draws a heatmap series over x/y category axes with a color scale.
+func (cv *svgCanvas) heatmap(config map[string]interface{}, s svgSeries, area svgRect) {
+	xAxis, yAxis := svgMap(config["xAxis"]), svgMap(config["yAxis"])
+	xCats, yCats := svgStrings(xAxis["categories"]), svgStrings(yAxis["categories"])
+	cells := [][3]float64{}
+	vMin, vMax := math.Inf(1), math.Inf(-1)
+	for _, raw := range s.data {
+		a, _ := raw.([]interface{})
+		if len(a) < 3 {
+			continue
+		}
+		x, _ := a[0].(float64)
+		y, _ := a[1].(float64)
+		v, ok := a[2].(float64)
+		if !ok {
+			continue
+		}
+		cells = append(cells, [3]float64{x, y, v})
+		vMin, vMax = math.Min(vMin, v), math.Max(vMax, v)
+		for len(xCats) <= int(x) {
+			xCats = append(xCats, strconv.Itoa(len(xCats)))
+		}
+		for len(yCats) <= int(y) {
+			yCats = append(yCats, strconv.Itoa(len(yCats)))
+		}
+	}
+	if len(cells) == 0 {
+		return
+	}
+	colorAxis := svgMap(config["colorAxis"])
+	minColor, _ := colorAxis["minColor"].(string)
+	maxColor, _ := colorAxis["maxColor"].(string)
+	if minColor == "" {
+		minColor = "#ffffff"
+	}
+	if maxColor == "" {
+		maxColor = cv.color(0)
+	}
+	if v, ok := colorAxis["min"].(float64); ok {
+		vMin = v
+	}
+	if v, ok := colorAxis["max"].(float64); ok {
+		vMax = v
+	}
+
+	left := 10 + svgLabelWidth(yCats, 11)
+	plot := svgRect{area.x + left, area.y, area.w - left - 70, area.h - 22}
+	cw, ch := plot.w/float64(len(xCats)), plot.h/float64(len(yCats))
+	reversed, _ := yAxis["reversed"].(bool)
+	rowY := func(y float64) float64 {
+		if reversed {
+			return plot.y + y*ch
+		}
+		return plot.y + plot.h - (y+1)*ch
+	}
+	labelsOn, _ := svgMap(s.raw["dataLabels"])["enabled"].(bool)
+	for _, c := range cells {
+		t := 0.0
+		if vMax > vMin {
+			t = (c[2] - vMin) / (vMax - vMin)
+		}
+		r := svgRect{plot.x + c[0]*cw, rowY(c[1]), cw, ch}
+		cv.rect(r, svgMix(minColor, maxColor, t), ` stroke="#ffffff" stroke-width="1"`)
+		if labelsOn && cw > 24 && ch > 12 {
+			fill := cv.labels
+			if t > 0.6 {
+				fill = "#ffffff"
+			}
+			cv.text(r.x+r.w/2, r.y+r.h/2+4, "middle", 10, fill, svgFormat(c[2]), "")
+		}
+	}
+	for i, c := range xCats {
+		cv.text(plot.x+(float64(i)+0.5)*cw, plot.y+plot.h+15, "middle", 11, "#666666", c, "")
+	}
+	for i, c := range yCats {
+		cv.text(plot.x-6, rowY(float64(i))+ch/2+4, "end", 11, "#666666", c, "")
+	}
+
+	// color scale
+	fmt.Fprintf(&cv.b, `<defs><linearGradient id="heatscale" x1="0" y1="1" x2="0" y2="0"><stop offset="0" stop-color="%s"/><stop offset="1" stop-color="%s"/></linearGradient></defs>`, minColor, maxColor)
+	scale := svgRect{plot.x + plot.w + 20, plot.y, 12, plot.h}
+	cv.rect(scale, "url(#heatscale)", "")
+	cv.text(scale.x+16, scale.y+10, "start", 10, "#666666", svgFormat(vMax), "")
+	cv.text(scale.x+16, scale.y+scale.h, "start", 10, "#666666", svgFormat(vMin), "")
+}
+
+// treemap lays out a treemap series (points with id/parent/value) by
+// alternating horizontal and vertical slices, coloring each top-level branch.
+func (cv *svgCanvas) treemap(s svgSeries, area svgRect) {
+	type node struct {
+		name     string
+		value    float64
+		children []*node
+	}
+	nodes := map[string]*node{}
+	root := &node{}
+	order := []map[string]interface{}{}
+	for _, raw := range s.data {
+		m := svgMap(raw)
+		id, _ := m["id"].(string)
+		name, _ := m["name"].(string)
+		if id == "" {
+			id = name
+		}
+		n := &node{name: name}
+		if v, ok := m["value"].(float64); ok {
+			n.value = v
+		}
+		nodes[id] = n
+		order = append(order, m)
+	}
+	for _, m := range order {
+		id, _ := m["id"].(string)
+		if id == "" {
+			id, _ = m["name"].(string)
+		}
+		parent := root
+		if p, ok := nodes[fmt.Sprint(m["parent"])]; ok {
+			parent = p
+		}
+		parent.children = append(parent.children, nodes[id])
+	}
+	var total func(n *node) float64
+	total = func(n *node) float64 {
+		if len(n.children) == 0 {
+			return math.Max(n.value, 0)
+		}
+		n.value = 0
+		for _, c := range n.children {
+			n.value += total(c)
+		}
+		return n.value
+	}
+	total(root)
+
+	var layout func(n *node, r svgRect, horizontal bool, color string)
+	layout = func(n *node, r svgRect, horizontal bool, color string) {
+		if len(n.children) == 0 {
+			cv.rect(r, color, ` stroke="#ffffff" stroke-width="2"`)
+			if r.w > 40 && r.h > 16 {
+				cv.text(r.x+r.w/2, r.y+r.h/2+4, "middle", 11, "#ffffff", n.name, ` font-weight="bold"`)
+			}
+			return
+		}
+		children := append([]*node(nil), n.children...)
+		sort.SliceStable(children, func(a, b int) bool { return children[a].value > children[b].value })
+		offset := 0.0
+		for i, c := range children {
+			if n.value <= 0 || c.value <= 0 {
+				continue
+			}
+			share := c.value / n.value
+			cr := svgRect{r.x + offset*r.w, r.y, share * r.w, r.h}
+			if !horizontal {
+				cr = svgRect{r.x, r.y + offset*r.h, r.w, share * r.h}
+			}
+			offset += share
+			childColor := color
+			if n == root {
+				childColor = cv.color(i)
+			}
+			layout(c, cr, !horizontal, childColor)
+		}
+	}
+	layout(root, area, area.w >= area.h, cv.color(0))
+}
+
+// svgDecodePoint reads a series data entry: a number (y at index j), an
+// [x, y(, z)] array, a {x, y} object, or five box values for boxplots.
+func svgDecodePoint(raw interface{}, j int, boxes bool) svgPoint {
+	p := svgPoint{x: float64(j)}
+	switch v := raw.(type) {
+	case float64:
+		p.y, p.ok = v, true
+	case []interface{}:
+		if boxes && len(v) == 5 {
+			p.box = make([]float64, 5)
+			for i := range v {
+				p.box[i], _ = v[i].(float64)
+			}
+			return p
+		}
+		if len(v) >= 2 {
+			if x, ok := v[0].(float64); ok {
+				p.x = x
+			}
+			p.y, p.ok = v[1].(float64)
+		}
+		if len(v) >= 3 {
+			p.z, _ = v[2].(float64)
+		}
+	case map[string]interface{}:
+		if x, ok := v["x"].(float64); ok {
+			p.x = x
+		}
+		p.y, p.ok = v["y"].(float64)
+	}
+	return p
+}
+
+// svgScale returns axis ticks and a mapping from values to 0..1 along the axis.
+// Unless fixed by the config, the range is widened to round tick values.
+func svgScale(min, max float64, log bool, fixedMin, fixedMax bool) ([]float64, func(float64) float64) {
+	if min > max {
+		// a reversed range (e.g. YMin 5, YMax 1) is drawn the normal way up
+		min, max, fixedMin, fixedMax = max, min, fixedMax, fixedMin
+	}
+	if log {
+		if min <= 0 {
+			min = 1
+		}
+		if max <= min {
+			max = min * 10
+		}
+		lo, hi := math.Log10(min), math.Log10(max)
+		if !fixedMin {
+			lo = math.Floor(lo)
+		}
+		if !fixedMax {
+			hi = math.Ceil(hi)
+		}
+		if hi == lo {
+			hi = lo + 1
+		}
+		ticks := []float64{}
+		for e := math.Ceil(lo); e <= hi; e++ {
+			ticks = append(ticks, math.Pow(10, e))
+		}
+		if len(ticks) == 0 {
+			ticks = []float64{min, max}
+		}
+		return ticks, func(v float64) float64 {
+			if v <= 0 {
+				return 0
+			}
+			return (math.Log10(v) - lo) / (hi - lo)
+		}
+	}
+	if max == min {
+		min, max = min-1, max+1
+	}
+	step := svgNiceStep((max - min) / 5)
+	if !fixedMin {
+		min = math.Floor(min/step) * step
+	}
+	if !fixedMax {
+		max = math.Ceil(max/step) * step
+	}
+	ticks := []float64{}
+	for t := math.Ceil(min/step) * step; t <= max+step*1e-9; t += step {
+		ticks = append(ticks, math.Round(t/step)*step)
+	}
+	if len(ticks) == 0 {
+		// the range overflowed the step arithmetic; label the ends only
+		ticks = []float64{min, max}
+	}
+	return ticks, func(v float64) float64 { return (v - min) / (max - min) }
+}
+
+// svgTimeScale is svgScale for epoch-millisecond axes, with ticks on whole days
+// or larger units when the range allows.
+func svgTimeScale(min, max float64) ([]float64, func(float64) float64) {
+	if min > max {
+		min, max = max, min
+	}
+	if max == min {
+		min, max = min-43200000, max+43200000
+	}
+	day := 86400000.0
+	step := svgNiceStep((max - min) / 6)
+	if step >= day {
+		step = math.Ceil(step/day) * day
+	}
+	ticks := []float64{}
+	for t := math.Ceil(min/step) * step; t <= max; t += step {
+		ticks = append(ticks, t)
+	}
+	if len(ticks) == 0 {
+		ticks = []float64{min, max}
+	}
+	return ticks, func(v float64) float64 { return (v - min) / (max - min) }
+}
+
+// svgNiceStep rounds a raw tick step to 1, 2 or 5 times a power of ten.
+func svgNiceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch f := raw / mag; {
+	case f <= 1:
+		return mag
+	case f <= 2:
+		return 2 * mag
+	case f <= 5:
+		return 5 * mag
+	}
+	return 10 * mag
+}
+
+// svgTimeLabel formats an epoch-millisecond tick for an axis spanning span milliseconds.
+func svgTimeLabel(ms float64, span float64) string {
+	t := time.UnixMilli(int64(ms)).UTC()
+	day := 86400000.0
+	switch {
+	case span > 730*day:
+		return t.Format("2006")
+	case span > 60*day:
+		return t.Format("Jan 2006")
+	case span > 2*day:
+		return t.Format("2006-01-02")
+	}
+	return t.Format("01-02 15:04")
+}
+
+// svgFormat prints a number without trailing zeros.
+func svgFormat(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
+
+func svgPointName(raw interface{}, i int) string {
+	if name, ok := svgMap(raw)["name"].(string); ok {
+		return name
+	}
+	return strconv.Itoa(i)
+}
+
+// svgLabelWidth estimates the widest label in pixels.
+func svgLabelWidth(labels []string, size float64) float64 {
+	width := 0.0
+	for _, l := range labels {
+		width = math.Max(width, float64(len([]rune(l)))*size*0.6)
+	}
+	return math.Min(width, 160)
+}
+
+func svgLegendWidth(name string) float64 {
+	return 14 + float64(len([]rune(name)))*7.2 + 16
+}
+
+// svgLegendRows wraps legend items into rows that fit the width.
+func svgLegendRows(items []svgSeries, width float64) [][]svgSeries {
+	rows := [][]svgSeries{}
+	used := 0.0
+	for _, it := range items {
+		w := svgLegendWidth(it.name)
+		if len(rows) == 0 || used+w > width {
+			rows = append(rows, nil)
+			used = 0
+		}
+		rows[len(rows)-1] = append(rows[len(rows)-1], it)
+		used += w
+	}
+	return rows
+}
+
+// svgMix interpolates between two #rrggbb colors.
+func svgMix(from, to string, t float64) string {
+	parse := func(c string) [3]float64 {
+		var rgb [3]float64
+		c = strings.TrimPrefix(c, "#")
+		if len(c) == 3 {
+			c = string([]byte{c[0], c[0], c[1], c[1], c[2], c[2]})
+		}
+		for i := 0; i < 3 && len(c) >= 2*i+2; i++ {
+			n, _ := strconv.ParseUint(c[2*i:2*i+2], 16, 8)
+			rgb[i] = float64(n)
+		}
+		return rgb
+	}
+	a, b := parse(from), parse(to)
+	return fmt.Sprintf("#%02x%02x%02x", int(a[0]+(b[0]-a[0])*t), int(a[1]+(b[1]-a[1])*t), int(a[2]+(b[2]-a[2])*t))
+}
+
+func svgMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func svgNumber(v interface{}, def float64) float64 {
+	if f, ok := v.(float64); ok {
+		return f
+	}
+	return def
+}
+
+func svgStrings(v interface{}) []string {
+	raw, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]string, len(raw))
+	for i, r := range raw {
+		out[i] = fmt.Sprint(r)
+	}
+	return out
+}
@@ -61,6 +61,12 @@ func referencedCols(e ColumnExpr, acc map[string]struct{}) map[string]struct{} {
 				acc[e.Col] = struct{}{}
 			}
 		}
+	case "window":
+		if wc, err := windowFromExpr(e); err == nil {
+			for _, c := range wc.refs() {
+				acc[c] = struct{}{}
+			}
+		}
 	default:
 		var x ColumnExpr
 		if len(e.Expr) > 0 {
@@ -204,6 +210,7 @@ func rowKeyFast(cols []string, data map[string][]interface{}, i int) string {
 // Column adds or modifies a column. Accepts either:
 //   - ColumnExpr (will Compile to Column)
 //   - Column (already compiled)
+//   - WindowColumn, or a ColumnExpr of type "window" (evaluated over partitions)
 //
 // It keeps concurrency & referenced column optimization for ColumnExpr.
 func (df *DataFrame) Column(column string, spec interface{}) *DataFrame {
@@ -216,6 +223,7 @@ func (df *DataFrame) Column(column string, spec interface{}) *DataFrame {
 	values := make([]interface{}, df.Rows)
 	if df.Rows == 0 {
 		df.Data[column] = values
+		df.setDate(column, specDate(df, spec))
 		found := false
 		for _, c := range df.Cols {
 			if c == column {
@@ -233,7 +241,17 @@ func (df *DataFrame) Column(column string, spec interface{}) *DataFrame {
 	var refCols []string
 
 	switch v := spec.(type) {
+	case WindowColumn:
+		return df.windowColumn(column, v)
 	case ColumnExpr:
+		if strings.EqualFold(v.Type, "window") {
+			wc, err := windowFromExpr(v)
+			if err != nil {
+				fmt.Printf("Column error: %v\n", err)
+				return df
+			}
+			return df.windowColumn(column, wc)
+		}
 		compiled = Compile(v)
 		refSet := referencedCols(v, nil)
 		refCols = make([]string, 0, len(refSet))
@@ -293,6 +311,7 @@ func (df *DataFrame) Column(column string, spec interface{}) *DataFrame {
 	}
 	wg.Wait()
 
+	df.setDate(column, compiled.dateIn(df))
 	df.Data[column] = values
 	found := false
 	for _, c := range df.Cols {
@@ -444,6 +463,7 @@ func (df *DataFrame) StringArrayConvert(column string) *DataFrame {
 		}(start, end)
 	}
 	wg.Wait()
+	df.dropTyped(column)
 	return df
 }
 
@@ -451,7 +471,6 @@ func (df *DataFrame) Check() string {
 	for _, c := range df.Cols {
 		if len(df.Data[c]) != df.Rows {
 			panic("invariant violated: column length mismatch: " + c)
-			return fmt.Sprintf("column %s has length %d but expected %d", c, len(df.Data[c]), df.Rows)
 		}
 	}
 	return "ok"
@@ -459,7 +478,7 @@ func (df *DataFrame) Check() string {
 
 func (df *DataFrame) Filter(cond interface{}) *DataFrame {
 	if df == nil || df.Rows == 0 {
-		return &DataFrame{Cols: df.Cols, Data: make(map[string][]interface{}), Rows: 0}
+		return &DataFrame{Cols: df.Cols, Data: make(map[string][]interface{}), Rows: 0, Dates: df.datesOf(df.Cols)}
 	}
 
 	var pred Column
@@ -494,6 +513,12 @@ func (df *DataFrame) Filter(cond interface{}) *DataFrame {
 		return df
 	}
 
+	// Simple comparisons run directly on typed columns.
+	var full []bool
+	if e, ok := cond.(ColumnExpr); ok {
+		full, _ = vectorMask(e, df)
+	}
+
 	w := runtime.GOMAXPROCS(0)
 	chunk := (df.Rows + w - 1) / w
 	masks := make([][]bool, w)
@@ -512,9 +537,20 @@ func (df *DataFrame) Filter(cond interface{}) *DataFrame {
 		wg.Add(1)
 		go func(idx, s, e int) {
 			defer wg.Done()
+			cnt := 0
+			if full != nil {
+				mask := full[s:e]
+				for _, ok := range mask {
+					if ok {
+						cnt++
+					}
+				}
+				masks[idx] = mask
+				counts[idx] = cnt
+				return
+			}
 			mask := make([]bool, e-s)
 			row := make(map[string]interface{}, len(refCols))
-			cnt := 0
 			for i := s; i < e; i++ {
 				for _, c := range refCols {
 					row[c] = df.Data[c][i]
@@ -537,7 +573,7 @@ func (df *DataFrame) Filter(cond interface{}) *DataFrame {
 		total += counts[i]
 	}
 
-	out := &DataFrame{Cols: df.Cols, Data: make(map[string][]interface{}, len(df.Cols)), Rows: total}
+	out := &DataFrame{Cols: df.Cols, Data: make(map[string][]interface{}, len(df.Cols)), Rows: total, Dates: df.datesOf(df.Cols)}
 	for _, c := range df.Cols {
 		out.Data[c] = make([]interface{}, total)
 	}
@@ -896,6 +932,175 @@ func (df *DataFrame) KeysToCols(nestedCol string) *DataFrame {
 	return df
 }
 
+// Pivot reshapes long data into wide form: one row per distinct combination of
+// the index columns and one column per distinct value of pivotCol (in sorted
+// order), holding agg applied to the valueCol values of that cell. The
+// aggregation's own ColumnName is ignored; cells without rows are nil.
+// Usage: df.Pivot([]string{"region"}, "quarter", "sales", Sum("sales"))
+func (df *DataFrame) Pivot(index []string, pivotCol string, valueCol string, agg Aggregation) *DataFrame {
+	if df == nil {
+		return nil
+	}
+	for _, c := range append(append([]string(nil), index...), pivotCol, valueCol) {
+		if _, ok := df.Data[c]; !ok {
+			fmt.Printf("Pivot error: column %q not found\n", c)
+			return df
+		}
+	}
+
+	// distinct pivot values in sorted order become the new columns
+	pc := df.typedColumn(pivotCol)
+	pivots := groupRows([]*typedColumn{pc}, df.Rows)
+	sort.SliceStable(pivots, func(a, b int) bool { return pc.compare(pivots[a].first, pivots[b].first) < 0 })
+	pivotOf := make([]int, df.Rows)
+	pivotNames := make([]string, len(pivots))
+	for p, grp := range pivots {
+		name := "null"
+		if v := pc.boxed[grp.first]; v != nil {
+			name = fastToString(v)
+		}
+		pivotNames[p] = name
+		for _, r := range grp.rows {
+			pivotOf[r] = p
+		}
+	}
+
+	keyCols := make([]*typedColumn, len(index))
+	for i, k := range index {
+		keyCols[i] = df.typedColumn(k)
+	}
+	groups := groupRows(keyCols, df.Rows)
+
+	newCols := append(append([]string(nil), index...), pivotNames...)
+	newData := make(map[string][]interface{}, len(newCols))
+	for _, c := range newCols {
+		newData[c] = make([]interface{}, len(groups))
+	}
+	values := df.Data[valueCol]
+
+	// Parallel aggregate per index group
+	w := runtime.GOMAXPROCS(0)
+	chunk := (len(groups) + w - 1) / w
+	var wg sync.WaitGroup
+	for g := 0; g < w; g++ {
+		s := g * chunk
+		e := s + chunk
+		if s >= len(groups) {
+			break
+		}
+		if e > len(groups) {
+			e = len(groups)
+		}
+		wg.Add(1)
+		go func(s, e int) {
+			defer wg.Done()
+			cells := make([][]int, len(pivots))
+			for i := s; i < e; i++ {
+				grp := groups[i]
+				for k, key := range index {
+					newData[key][i] = keyCols[k].boxed[grp.first]
+				}
+				for p := range cells {
+					cells[p] = cells[p][:0]
+				}
+				for _, r := range grp.rows {
+					cells[pivotOf[r]] = append(cells[pivotOf[r]], r)
+				}
+				for p, rows := range cells {
+					if len(rows) > 0 {
+						newData[pivotNames[p]][i] = agg.Fn(gather(values, rows))
+					}
+				}
+			}
+		}(s, e)
+	}
+	wg.Wait()
+
+	out := &DataFrame{Cols: newCols, Data: newData, Rows: len(groups), Dates: df.datesOf(index)}
+	if df.Dates[valueCol] && agg.keepsDate() {
+		for _, name := range pivotNames {
+			out.setDate(name, true)
+		}
+	}
+	return out
+}
+
+// Unpivot (melt) reshapes wide data into long form: every input row becomes
+// one row per value column, holding the id columns, the value column's name in
+// varName and its value in valueName. With no valueCols every non-id column is
+// unpivoted. varName and valueName default to "variable" and "value".
+// Usage: df.Unpivot([]string{"region"}, []string{"q1", "q2"}, "quarter", "sales")
+func (df *DataFrame) Unpivot(idCols []string, valueCols []string, varName, valueName string) *DataFrame {
+	if df == nil {
+		return nil
+	}
+	if varName == "" {
+		varName = "variable"
+	}
+	if valueName == "" {
+		valueName = "value"
+	}
+	if len(valueCols) == 0 {
+		ids := make(map[string]struct{}, len(idCols))
+		for _, c := range idCols {
+			ids[c] = struct{}{}
+		}
+		for _, c := range df.Cols {
+			if _, ok := ids[c]; !ok {
+				valueCols = append(valueCols, c)
+			}
+		}
+	}
+
+	newCols := append(append([]string(nil), idCols...), varName, valueName)
+	total := df.Rows * len(valueCols)
+	newData := make(map[string][]interface{}, len(newCols))
+	for _, c := range newCols {
+		newData[c] = make([]interface{}, total)
+	}
+	cell := func(col []interface{}, i int) interface{} {
+		if i < len(col) {
+			return col[i]
+		}
+		return nil
+	}
+
+	w := runtime.GOMAXPROCS(0)
+	chunk := (df.Rows + w - 1) / w
+	var wg sync.WaitGroup
+	for g := 0; g < w; g++ {
+		start := g * chunk
+		end := start + chunk
+		if start >= df.Rows {
+			break
+		}
+		if end > df.Rows {
+			end = df.Rows
+		}
+		wg.Add(1)
+		go func(s, e int) {
+			defer wg.Done()
+			for i := s; i < e; i++ {
+				for j, vc := range valueCols {
+					out := i*len(valueCols) + j
+					for _, c := range idCols {
+						newData[c][out] = cell(df.Data[c], i)
+					}
+					newData[varName][out] = vc
+					newData[valueName][out] = cell(df.Data[vc], i)
+				}
+			}
+		}(start, end)
+	}
+	wg.Wait()
+
+	out := &DataFrame{Cols: newCols, Data: newData, Rows: total, Dates: df.datesOf(idCols)}
+	if len(df.datesOf(valueCols)) == len(valueCols) && len(valueCols) > 0 {
+		out.setDate(valueName, true)
+	}
+	return out
+}
+
 // withColumnRenamed
 // rename column
 func (df *DataFrame) Rename(column string, newcol string) *DataFrame {
@@ -918,8 +1123,10 @@ func (df *DataFrame) Rename(column string, newcol string) *DataFrame {
 	for _, col := range df.Cols {
 		if col == column {
 			newDF.Data[newcol] = df.Data[col]
+			newDF.setDate(newcol, df.Dates[col])
 		} else {
 			newDF.Data[col] = df.Data[col]
+			newDF.setDate(col, df.Dates[col])
 		}
 	}
 
@@ -956,6 +1163,7 @@ func (df *DataFrame) FillNA(repl string) *DataFrame {
 		}()
 	}
 	wg.Wait()
+	df.dropTyped(df.Cols...)
 	return df
 }
 
@@ -1129,9 +1337,10 @@ func (df *DataFrame) DropDuplicates(columns ...string) *DataFrame {
 // Select returns a new DataFrame containing only the specified columns.
 func (df *DataFrame) Select(columns ...string) *DataFrame {
 	newDF := &DataFrame{
-		Cols: columns,
-		Data: make(map[string][]interface{}),
-		Rows: df.Rows,
+		Cols:  columns,
+		Data:  make(map[string][]interface{}),
+		Rows:  df.Rows,
+		Dates: df.datesOf(columns),
 	}
 
 	for _, col := range columns {
@@ -1145,334 +1354,434 @@ func (df *DataFrame) Select(columns ...string) *DataFrame {
 	return newDF
 }
 
-// GroupBy groups the DataFrame rows by the value produced by groupcol.
-// For each group, applies provided Aggregations; all other columns are
-// automatically CollectList'ed to preserve data and alignment.
-func (df *DataFrame) GroupBy(groupcol string, aggs ...Aggregation) *DataFrame {
-	if df == nil || df.Rows == 0 {
-		return &DataFrame{Cols: []string{groupcol}, Data: map[string][]interface{}{groupcol: {}}, Rows: 0}
+// GroupBy groups the DataFrame rows by one or more key columns (a string or
+// []string) and applies the aggregations to each group. The result holds the
+// key columns followed by one column per aggregation, named by its Alias or
+// source column; columns that are not aggregated are dropped. Names that would
+// collide are made unique (see outputNames), e.g. Sum("x") and Mean("x") give
+// "sum_x" and "mean_x".
+func (df *DataFrame) GroupBy(groupcols interface{}, aggs ...Aggregation) *DataFrame {
+	var keys []string
+	switch v := groupcols.(type) {
+	case string:
+		keys = []string{v}
+	case []string:
+		keys = v
+	default:
+		fmt.Printf("GroupBy error: unsupported group columns type %T\n", v)
+		return df
 	}
 
-	// Build effective aggregation list: user-provided + default CollectList for the rest
-	aggSet := make(map[string]struct{}, len(aggs))
-	for _, a := range aggs {
-		aggSet[a.ColumnName] = struct{}{}
+	newCols := outputNames(keys, aggs)
+	if df == nil || df.Rows == 0 {
+		empty := &DataFrame{Cols: newCols, Data: make(map[string][]interface{}, len(newCols))}
+		if df != nil {
+			empty.Dates = groupDates(df.Dates, keys, aggs, newCols)
+		}
+		for _, c := range newCols {
+			empty.Data[c] = []interface{}{}
+		}
+		return empty
 	}
 
-	effectiveAggs := make([]Aggregation, 0, len(aggs)+len(df.Cols))
-	effectiveAggs = append(effectiveAggs, aggs...)
-	for _, c := range df.Cols {
-		if c == groupcol {
-			continue
-		}
-		if _, ok := aggSet[c]; !ok {
-			effectiveAggs = append(effectiveAggs, CollectList(c))
+	// group row indexes on the typed key columns, then aggregate per group
+	keyCols := make([]*typedColumn, len(keys))
+	for i, k := range keys {
+		keyCols[i] = df.typedColumn(k)
+		if keyCols[i] == nil {
+			keyCols[i] = newTypedColumn(make([]interface{}, df.Rows))
 		}
 	}
+	groups := groupRows(keyCols, df.Rows)
 
-	// shard in parallel: key -> (col -> []interface{})
-	w := runtime.GOMAXPROCS(0)
-	chunk := (df.Rows + w - 1) / w
-	type groupMap = map[interface{}]map[string][]interface{}
-	shards := make([]groupMap, w)
+	newData := make(map[string][]interface{}, len(newCols))
+	for _, c := range newCols {
+		newData[c] = make([]interface{}, len(groups))
+	}
 
+	// Parallel aggregate per group
+	w := runtime.GOMAXPROCS(0)
+	chunk := (len(groups) + w - 1) / w
 	var wg sync.WaitGroup
 	for g := 0; g < w; g++ {
-		start := g * chunk
-		end := start + chunk
-		if start >= df.Rows {
+		s := g * chunk
+		e := s + chunk
+		if s >= len(groups) {
 			break
 		}
-		if end > df.Rows {
-			end = df.Rows
+		if e > len(groups) {
+			e = len(groups)
 		}
 		wg.Add(1)
-		go func(idx, s, e int) {
+		go func(s, e int) {
 			defer wg.Done()
-			local := make(groupMap)
-			row := make(map[string]interface{}, len(df.Cols))
 			for i := s; i < e; i++ {
-				for _, c := range df.Cols {
-					row[c] = df.Data[c][i]
+				grp := groups[i]
+				for k, key := range keys {
+					newData[key][i] = keyCols[k].boxed[grp.first]
 				}
-				key := row[groupcol]
-				dst, ok := local[key]
-				if !ok {
-					dst = make(map[string][]interface{}, len(effectiveAggs))
-					for _, agg := range effectiveAggs {
-						dst[agg.ColumnName] = make([]interface{}, 0, 8)
-					}
-					local[key] = dst
-				}
-				for _, agg := range effectiveAggs {
-					if v, ok := row[agg.ColumnName]; ok {
-						dst[agg.ColumnName] = append(dst[agg.ColumnName], v)
+				for j, agg := range aggs {
+					vals := []interface{}{}
+					if col, ok := df.Data[agg.ColumnName]; ok {
+						vals = gather(col, grp.rows)
 					}
+					newData[newCols[len(keys)+j]][i] = agg.Fn(vals)
 				}
 			}
-			shards[idx] = local
-		}(g, start, end)
+		}(s, e)
 	}
 	wg.Wait()
 
-	// merge shards
-	master := make(groupMap)
-	for _, sh := range shards {
-		for key, cols := range sh {
-			dst, ok := master[key]
-			if !ok {
-				dst = make(map[string][]interface{}, len(cols))
-				for name := range cols {
-					dst[name] = make([]interface{}, 0, len(cols[name]))
-				}
-				master[key] = dst
-			}
-			for name, vals := range cols {
-				master[key][name] = append(master[key][name], vals...)
-			}
+	return &DataFrame{
+		Cols:  newCols,
+		Data:  newData,
+		Rows:  len(groups),
+		Dates: groupDates(df.Dates, keys, aggs, newCols),
+	}
+}
+
+// Join performs a join between the receiver (left DataFrame) and the provided right DataFrame.
+// leftOn and rightOn name the key columns of each side: a column name, or a []string
+// for composite keys matched position by position. Null keys never match.
+// joinType can be "inner", "left", "right", "outer", "semi" (left rows that have a match),
+// "anti" (left rows without one) or "cross" (every pair of rows; keys are ignored).
+// Columns present on both sides become name_l and name_r, or take the optional
+// suffixes, e.g. Join(right, "id", "id", "inner", "_old", "_new").
+// Semi and anti joins return the left columns unchanged.
+func (left *DataFrame) Join(right *DataFrame, leftOn, rightOn interface{}, joinType string, suffixes ...string) *DataFrame {
+	if left == nil || right == nil {
+		return nil
+	}
+	joinType = strings.ToLower(joinType)
+	if joinType == "cross" {
+		all := make([]int, right.Rows)
+		for i := range all {
+			all[i] = i
+		}
+		return left.joinRows(right, joinType, suffixes, func(int, map[string]interface{}, bool) []int {
+			return all
+		})
+	}
+
+	lkeys, lok := joinKeys(leftOn)
+	rkeys, rok := joinKeys(rightOn)
+	if !lok || !rok || len(lkeys) != len(rkeys) || len(lkeys) == 0 {
+		fmt.Printf("Join error: leftOn and rightOn must name the same number of key columns\n")
+		return nil
+	}
+
+	// index the right side by composite key
+	rightKeys := joinKeyStrings(right, rkeys)
+	leftKeys := joinKeyStrings(left, lkeys)
+	rightIndex := make(map[string][]int, right.Rows)
+	for i, k := range rightKeys {
+		if k != nil {
+			rightIndex[*k] = append(rightIndex[*k], i)
 		}
 	}
+	return left.joinRows(right, joinType, suffixes, func(li int, _ map[string]interface{}, _ bool) []int {
+		if leftKeys[li] == nil {
+			return nil
+		}
+		return rightIndex[*leftKeys[li]]
+	})
+}
 
-	// Output schema: group key + all aggregated columns (user aggs + defaults)
-	newCols := []string{groupcol}
-	for _, agg := range effectiveAggs {
-		newCols = append(newCols, agg.ColumnName)
+// JoinOn joins the receiver with right on an arbitrary predicate, a Column or
+// ColumnExpr evaluated for each pair of rows, enabling non-equi joins such as
+// ranges: JoinOn(events, Col("start").Le(Col("ts")), "inner").
+// The predicate sees the joined column names, so columns present on both sides
+// are referenced as name_l / name_r (or with the given suffixes).
+// joinType accepts the same values as Join except "cross". As in Join, null
+// keys never match: in the predicate, Eq and Ne with a null side are false.
+func (left *DataFrame) JoinOn(right *DataFrame, on interface{}, joinType string, suffixes ...string) *DataFrame {
+	if left == nil || right == nil {
+		return nil
 	}
-	newData := make(map[string][]interface{}, len(newCols))
-	for _, c := range newCols {
-		newData[c] = make([]interface{}, 0, len(master))
+	var pred Column
+	switch v := on.(type) {
+	case ColumnExpr:
+		pred = Compile(v)
+	case Column:
+		pred = v
+	default:
+		fmt.Printf("JoinOn error: unsupported predicate type %T\n", v)
+		return nil
 	}
+	ls, rs := joinSuffixes(suffixes)
+	leftOut, rightOut := joinOutputNames(left.Cols, right.Cols, ls, rs)
+	return left.joinRows(right, strings.ToLower(joinType), suffixes, func(li int, row map[string]interface{}, first bool) []int {
+		row[joinOnRow] = true
+		for _, c := range left.Cols {
+			row[leftOut[c]] = left.Data[c][li]
+		}
+		var matches []int
+		for ri := 0; ri < right.Rows; ri++ {
+			for _, c := range right.Cols {
+				row[rightOut[c]] = right.Data[c][ri]
+			}
+			if ok, _ := pred.Fn(row).(bool); ok {
+				matches = append(matches, ri)
+				if first {
+					break
+				}
+			}
+		}
+		return matches
+	})
+}
 
-	// Extract keys (order undefined; sort if needed)
-	keys := make([]interface{}, 0, len(master))
-	for k := range master {
-		keys = append(keys, k)
+// joinKeys normalizes a join key argument (string or []string).
+func joinKeys(on interface{}) ([]string, bool) {
+	switch v := on.(type) {
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
 	}
+	return nil, false
+}
 
-	// Parallel aggregate per key
-	w2 := runtime.GOMAXPROCS(0)
-	chunk2 := (len(keys) + w2 - 1) / w2
-	type rowAgg struct {
-		key  interface{}
-		vals []interface{} // len = 1 + len(effectiveAggs)
+// joinKeyStrings builds one hashable key per row from the key columns, in
+// parallel; rows with a null key part get nil so they never match.
+func joinKeyStrings(df *DataFrame, keys []string) []*string {
+	out := make([]*string, df.Rows)
+	cols := make([][]interface{}, len(keys))
+	for i, k := range keys {
+		cols[i] = df.Data[k]
 	}
-	rowsAgg := make([]rowAgg, len(keys))
-	var wg2 sync.WaitGroup
-	for g := 0; g < w2; g++ {
-		s := g * chunk2
-		e := s + chunk2
-		if s >= len(keys) {
+	w := runtime.GOMAXPROCS(0)
+	chunk := (df.Rows + w - 1) / w
+	var wg sync.WaitGroup
+	for g := 0; g < w; g++ {
+		start := g * chunk
+		end := start + chunk
+		if start >= df.Rows {
 			break
 		}
-		if e > len(keys) {
-			e = len(keys)
+		if end > df.Rows {
+			end = df.Rows
 		}
-		wg2.Add(1)
+		wg.Add(1)
 		go func(s, e int) {
-			defer wg2.Done()
+			defer wg.Done()
+			var b strings.Builder
+		rows:
 			for i := s; i < e; i++ {
-				k := keys[i]
-				cols := master[k]
-				ra := rowAgg{key: k, vals: make([]interface{}, 1+len(effectiveAggs))}
-				ra.vals[0] = k
-				for j, agg := range effectiveAggs {
-					ra.vals[1+j] = agg.Fn(cols[agg.ColumnName])
+				b.Reset()
+				for _, col := range cols {
+					if i >= len(col) || col[i] == nil {
+						continue rows
+					}
+					k := canonicalKey(col[i])
+					fmt.Fprintf(&b, "%d:%s", len(k), k)
 				}
-				rowsAgg[i] = ra
+				k := b.String()
+				out[i] = &k
 			}
-		}(s, e)
+		}(start, end)
 	}
-	wg2.Wait()
+	wg.Wait()
+	return out
+}
 
-	// Assemble
-	for _, r := range rowsAgg {
-		newData[groupcol] = append(newData[groupcol], r.vals[0])
-		for j, agg := range effectiveAggs {
-			newData[agg.ColumnName] = append(newData[agg.ColumnName], r.vals[1+j])
-		}
+// joinSuffixes returns the collision suffixes, defaulting to "_l" and "_r".
+func joinSuffixes(suffixes []string) (string, string) {
+	ls, rs := "_l", "_r"
+	if len(suffixes) > 0 {
+		ls = suffixes[0]
 	}
-
-	return &DataFrame{
-		Cols: newCols,
-		Data: newData,
-		Rows: len(newData[groupcol]),
+	if len(suffixes) > 1 {
+		rs = suffixes[1]
 	}
+	return ls, rs
 }
 
-// Join performs a join between the receiver (left DataFrame) and the provided right DataFrame.
-// leftOn is the join key column in the left DataFrame and rightOn is the join key column in the right DataFrame.
-// joinType can be "inner", "left", "right", or "outer". It returns a new joined DataFrame.
-// Parallel Join (index build + row assembly)
-// Join: keep both sides; collisions become name_l (left) and name_r (right)
-func (left *DataFrame) Join(right *DataFrame, leftOn, rightOn, joinType string) *DataFrame {
-	if left == nil || right == nil {
-		return nil
-	}
-
-	// Build collision-aware mappings
-	leftSet := make(map[string]struct{}, len(left.Cols))
-	rightSet := make(map[string]struct{}, len(right.Cols))
-	for _, c := range left.Cols {
+// joinOutputNames maps each input column to its joined name; columns present
+// on both sides take the side's suffix.
+func joinOutputNames(left, right []string, ls, rs string) (map[string]string, map[string]string) {
+	leftSet := make(map[string]struct{}, len(left))
+	rightSet := make(map[string]struct{}, len(right))
+	for _, c := range left {
 		leftSet[c] = struct{}{}
 	}
-	for _, c := range right.Cols {
+	for _, c := range right {
 		rightSet[c] = struct{}{}
 	}
-
-	leftOut := make(map[string]string, len(left.Cols))
-	rightOut := make(map[string]string, len(right.Cols))
-	newCols := make([]string, 0, len(left.Cols)+len(right.Cols))
-
-	for _, c := range left.Cols {
+	leftOut := make(map[string]string, len(left))
+	rightOut := make(map[string]string, len(right))
+	for _, c := range left {
+		leftOut[c] = c
 		if _, dup := rightSet[c]; dup {
-			name := c + "_l"
-			leftOut[c] = name
-			newCols = append(newCols, name)
-		} else {
-			leftOut[c] = c
-			newCols = append(newCols, c)
+			leftOut[c] = c + ls
 		}
 	}
-	for _, c := range right.Cols {
+	for _, c := range right {
+		rightOut[c] = c
 		if _, dup := leftSet[c]; dup {
-			name := c + "_r"
-			rightOut[c] = name
-			newCols = append(newCols, name)
-		} else {
-			rightOut[c] = c
-			newCols = append(newCols, c)
+			rightOut[c] = c + rs
 		}
 	}
+	return leftOut, rightOut
+}
 
-	// Build indices
-	leftIndex := make(map[string][]int, left.Rows)
-	rightIndex := make(map[string][]int, right.Rows)
+// joinRows probes every left row with matches (in parallel, keeping left row
+// order) and assembles the joined DataFrame. matches gets a scratch row map
+// per worker and first=true when a single match is enough (semi/anti).
+// Unmatched right rows of right and outer joins follow in right row order.
+func (left *DataFrame) joinRows(right *DataFrame, joinType string, suffixes []string, matches func(li int, row map[string]interface{}, first bool) []int) *DataFrame {
+	switch joinType {
+	case "inner", "left", "right", "outer", "semi", "anti", "cross":
+	default:
+		fmt.Printf("Unsupported join type %s\n", joinType)
+		return nil
+	}
+	existence := joinType == "semi" || joinType == "anti"
+	trackRight := joinType == "right" || joinType == "outer"
+
+	// probe: per-chunk (left, right) index pairs, -1 for a missing side
+	w := runtime.GOMAXPROCS(0)
+	chunk := (left.Rows + w - 1) / w
+	if chunk == 0 {
+		chunk = 1
+	}
+	lParts := make([][]int, w)
+	rParts := make([][]int, w)
+	seen := make([][]bool, w)
 	var wg sync.WaitGroup
-	wg.Add(2)
-	go func() {
-		defer wg.Done()
-		for i := 0; i < left.Rows; i++ {
-			v := left.Data[leftOn][i]
-			var k string
-			if v == nil {
-				// special marker so left NULLs never match right NULLs
-				k = "__JOIN_LEFT_NIL__"
-			} else {
-				k = canonicalKey(v)
+	for g := 0; g < w; g++ {
+		start := g * chunk
+		end := start + chunk
+		if start >= left.Rows {
+			break
+		}
+		if end > left.Rows {
+			end = left.Rows
+		}
+		wg.Add(1)
+		go func(idx, s, e int) {
+			defer wg.Done()
+			var ls, rs []int
+			var matched []bool
+			if trackRight {
+				matched = make([]bool, right.Rows)
 			}
-			leftIndex[k] = append(leftIndex[k], i)
-		}
-	}()
-	go func() {
-		defer wg.Done()
-		for i := 0; i < right.Rows; i++ {
-			v := right.Data[rightOn][i]
-			var k string
-			if v == nil {
-				// special marker so right NULLs never match left NULLs
-				k = "__JOIN_RIGHT_NIL__"
-			} else {
-				k = canonicalKey(v)
+			row := make(map[string]interface{}, len(left.Cols)+len(right.Cols))
+			for li := s; li < e; li++ {
+				m := matches(li, row, existence)
+				switch {
+				case existence:
+					if (len(m) > 0) == (joinType == "semi") {
+						ls = append(ls, li)
+						rs = append(rs, -1)
+					}
+				case len(m) == 0:
+					if joinType == "left" || joinType == "outer" {
+						ls = append(ls, li)
+						rs = append(rs, -1)
+					}
+				default:
+					for _, ri := range m {
+						ls = append(ls, li)
+						rs = append(rs, ri)
+						if matched != nil {
+							matched[ri] = true
+						}
+					}
+				}
 			}
-			rightIndex[k] = append(rightIndex[k], i)
-		}
-	}()
+			lParts[idx], rParts[idx], seen[idx] = ls, rs, matched
+		}(g, start, end)
+	}
 	wg.Wait()
 
-	type pair struct{ l, r *int }
-	pairs := []pair{}
-	switch joinType {
-	case "inner", "left", "outer":
-		for k, lRows := range leftIndex {
-			if rRows, ok := rightIndex[k]; ok {
-				for _, li := range lRows {
-					for _, ri := range rRows {
-						liCopy, riCopy := li, ri
-						pairs = append(pairs, pair{&liCopy, &riCopy})
-					}
-				}
-			} else if joinType == "left" || joinType == "outer" {
-				for _, li := range lRows {
-					liCopy := li
-					pairs = append(pairs, pair{&liCopy, nil})
+	var lIdx, rIdx []int
+	for g := 0; g < w; g++ {
+		lIdx = append(lIdx, lParts[g]...)
+		rIdx = append(rIdx, rParts[g]...)
+	}
+	if trackRight {
+		for ri := 0; ri < right.Rows; ri++ {
+			found := false
+			for _, m := range seen {
+				if m != nil && m[ri] {
+					found = true
+					break
 				}
 			}
-		}
-		if joinType == "outer" {
-			for k, rRows := range rightIndex {
-				if _, ok := leftIndex[k]; !ok {
-					for _, ri := range rRows {
-						riCopy := ri
-						pairs = append(pairs, pair{nil, &riCopy})
-					}
-				}
+			if !found {
+				lIdx = append(lIdx, -1)
+				rIdx = append(rIdx, ri)
 			}
 		}
-	case "right":
-		for k, rRows := range rightIndex {
-			if lRows, ok := leftIndex[k]; ok {
-				for _, li := range lRows {
-					for _, ri := range rRows {
-						liCopy, riCopy := li, ri
-						pairs = append(pairs, pair{&liCopy, &riCopy})
-					}
-				}
-			} else {
-				for _, ri := range rRows {
-					riCopy := ri
-					pairs = append(pairs, pair{nil, &riCopy})
-				}
-			}
+	}
+
+	// output columns
+	ls, rs := joinSuffixes(suffixes)
+	leftOut, rightOut := joinOutputNames(left.Cols, right.Cols, ls, rs)
+	rightCols := right.Cols
+	if existence {
+		leftOut = make(map[string]string, len(left.Cols))
+		for _, c := range left.Cols {
+			leftOut[c] = c
 		}
-	default:
-		fmt.Printf("Unsupported join type %s\n", joinType)
-		return nil
+		rightCols = nil
+	}
+	newCols := make([]string, 0, len(left.Cols)+len(rightCols))
+	for _, c := range left.Cols {
+		newCols = append(newCols, leftOut[c])
+	}
+	for _, c := range rightCols {
+		newCols = append(newCols, rightOut[c])
 	}
 
+	n := len(lIdx)
 	out := make(map[string][]interface{}, len(newCols))
 	for _, c := range newCols {
-		out[c] = make([]interface{}, len(pairs))
+		out[c] = make([]interface{}, n)
 	}
 
 	// Materialize pairs using mapped names
-	w := runtime.GOMAXPROCS(0)
-	chunk := (len(pairs) + w - 1) / w
-	wg = sync.WaitGroup{}
+	chunk = (n + w - 1) / w
 	for g := 0; g < w; g++ {
 		start := g * chunk
 		end := start + chunk
-		if start >= len(pairs) {
+		if start >= n {
 			break
 		}
-		if end > len(pairs) {
-			end = len(pairs)
+		if end > n {
+			end = n
 		}
 		wg.Add(1)
 		go func(s, e int) {
 			defer wg.Done()
 			for idx := s; idx < e; idx++ {
-				p := pairs[idx]
-				for _, c := range left.Cols {
-					name := leftOut[c]
-					if p.l != nil {
-						out[name][idx] = left.Data[c][*p.l]
-					} else {
-						out[name][idx] = nil
+				if l := lIdx[idx]; l >= 0 {
+					for _, c := range left.Cols {
+						if l < len(left.Data[c]) {
+							out[leftOut[c]][idx] = left.Data[c][l]
+						}
 					}
 				}
-				for _, c := range right.Cols {
-					name := rightOut[c]
-					if p.r != nil {
-						out[name][idx] = right.Data[c][*p.r]
-					} else {
-						out[name][idx] = nil
+				if r := rIdx[idx]; r >= 0 {
+					for _, c := range rightCols {
+						if r < len(right.Data[c]) {
+							out[rightOut[c]][idx] = right.Data[c][r]
+						}
 					}
 				}
 			}
 		}(start, end)
 	}
 	wg.Wait()
-	return &DataFrame{Cols: newCols, Data: out, Rows: len(pairs)}
+	df := &DataFrame{Cols: newCols, Data: out, Rows: n}
+	for _, c := range left.Cols {
+		df.setDate(leftOut[c], left.Dates[c])
+	}
+	for _, c := range rightCols {
+		df.setDate(rightOut[c], right.Dates[c])
+	}
+	return df
 }
 
 // Union appends the rows of the other DataFrame to the receiver.
@@ -1537,7 +1846,14 @@ func (df *DataFrame) Union(other *DataFrame) *DataFrame {
 		}()
 	}
 	wg.Wait()
-	return &DataFrame{Cols: newCols, Data: newData, Rows: total}
+	out := &DataFrame{Cols: newCols, Data: newData, Rows: total}
+	for _, c := range newCols {
+		// a date column stays one when the other side has it as dates or not at all
+		_, inDF := df.Data[c]
+		_, inOther := other.Data[c]
+		out.setDate(c, (df.Dates[c] || !inDF) && (other.Dates[c] || !inOther))
+	}
+	return out
 }
 
 // Drop removes the specified columns from the DataFrame.
@@ -1561,6 +1877,7 @@ func (df *DataFrame) Drop(columns ...string) *DataFrame {
 	// Update DataFrame.
 	df.Cols = newCols
 	df.Data = newData
+	df.Dates = df.datesOf(newCols)
 
 	return df
 }
@@ -1571,8 +1888,8 @@ func (df *DataFrame) Drop(columns ...string) *DataFrame {
 // OrderBy sorts the DataFrame by the specified column.
 // Parallelize the column rebuild after computing sorted indices.
 func (df *DataFrame) OrderBy(column string, asc bool) *DataFrame {
-	colData, ok := df.Data[column]
-	if !ok {
+	keys := df.typedColumn(column)
+	if keys == nil {
 		fmt.Printf("column %q does not exist\n", column)
 		return df
 	}
@@ -1581,45 +1898,14 @@ func (df *DataFrame) OrderBy(column string, asc bool) *DataFrame {
 		indices[i] = i
 	}
 
-	// Precompute keys once, then sort indices using them.
-	isString := true
-	for i := 0; i < df.Rows; i++ {
-		if v := colData[i]; v != nil {
-			if _, ok := v.(string); !ok {
-				isString = false
-				break
-			}
-		}
-	}
-
-	if isString {
-		keys := make([]string, df.Rows)
-		for i := 0; i < df.Rows; i++ {
-			if s, ok := colData[i].(string); ok {
-				keys[i] = s
-			}
+	// Sort indices on the typed column; NaN and then nulls sort last ascending.
+	sort.SliceStable(indices, func(i, j int) bool {
+		cmp := keys.compare(indices[i], indices[j])
+		if asc {
+			return cmp < 0
 		}
-		sort.SliceStable(indices, func(i, j int) bool {
-			ai, aj := keys[indices[i]], keys[indices[j]]
-			if asc {
-				return ai < aj
-			}
-			return ai > aj
-		})
-	} else {
-		keys := make([]float64, df.Rows)
-		for i := 0; i < df.Rows; i++ {
-			f, _ := toFloat64(colData[i])
-			keys[i] = f
-		}
-		sort.SliceStable(indices, func(i, j int) bool {
-			ai, aj := keys[indices[i]], keys[indices[j]]
-			if asc {
-				return ai < aj
-			}
-			return ai > aj
-		})
-	}
+		return cmp > 0
+	})
 
 	newData := make(map[string][]interface{}, len(df.Cols))
 	var wg sync.WaitGroup
@@ -1786,7 +2072,7 @@ func (df *DataFrame) ExceptAll(other *DataFrame, columns ...string) *DataFrame {
 		}
 		newData[c] = dst
 	}
-	return &DataFrame{Cols: append([]string(nil), df.Cols...), Data: newData, Rows: kept}
+	return &DataFrame{Cols: append([]string(nil), df.Cols...), Data: newData, Rows: kept, Dates: df.datesOf(df.Cols)}
 }
 
 // ConnectLLM creates a configuration object for LLM calls.
@@ -8,54 +8,124 @@ import (
 )
 
 // DataFrame represents a very simple dataframe structure.
+// Data is the boxed, row-aligned view of each column; GroupBy, OrderBy and
+// Filter work on typed columns derived from it (see columns.go).
 type DataFrame struct {
 	Cols []string
 	Data map[string][]interface{}
 	Rows int
+	// Dates lists the time columns that hold calendar dates, as ToDate and
+	// the readers of date-typed columns give; every other time column holds
+	// timestamps.
+	Dates map[string]bool `json:",omitempty"`
+
+	typed *typedCache // typed views of Data columns, built on first use
 }
 
 // Help returns a help string listing available DataFrame methods.
 func (df *DataFrame) Help() string {
 	help := `DataFrame Help:
-		BarChart(title, subtitle, groupcol, aggs)
+		AreaChart(title, subtitle, xcol, ycols, options)
+		BarChart(title, subtitle, groupcol, aggs, options)
+		BoxPlot(valueCol, groupCol, options)
+		BubbleChart(title, subtitle, xcol, ycol, sizecol, groupcol, options)
 		Clone()
 		Column(col_name, col_spec)
-		ColumnChart(title, subtitle, groupcol, aggs)
+		ColumnChart(title, subtitle, groupcol, aggs, options)
 		Columns()
 		Collect(col_name)
 		Count()
 		CountDistinct(cols)
 		CountDuplicates(cols)
 		CreateReport(title)
+		DataTable(cols...)
+		Describe(percentiles...)
 		Display()
-		DisplayBrowser()
-		DisplayToFile(file_path)
+		DisplayBrowser(options)
+		DisplayToFile(file_path, options)
 		Drop(*cols)
 		DropDuplicates(cols)
 		DropNA(cols)
 		FillNA(value)
 		Filter(condition)
 		Flatten(*cols)
-		GroupBy(groupCol, aggs)
+		GroupBy(groupCols, aggs)
 		Head(chars)
-		Join(df2, col1, col2, how)
+		Heatmap(xCol, yCol, valueCol, agg, options)
+		Histogram(col, bins, options)
+		Join(df2, col1, col2, how, suffixes)
+		JoinOn(df2, on, how, suffixes)
+		Lazy()
+		LineChart(title, subtitle, xcol, ycols, options)
 		OrderBy(col, asc)
+		PieChart(title, subtitle, namecol, agg, options)
+		Pivot(index, pivotCol, valueCol, agg)
 		PostAPI(endpoint, headers, query_params)
+		Profile()
+		ScatterPlot(title, subtitle, xcol, ycol, groupcol, options)
 		Select(*cols)
 		Show(chars, record_count)
 		Sort(*cols)
-		StackedBarChart(title, subtitle, groupcol, aggs)
-		StackedPercentChart(title, subtitle, groupcol, aggs)
+		StackedBarChart(title, subtitle, groupcol, aggs, options)
+		StackedPercentChart(title, subtitle, groupcol, aggs, options)
 		StringArrayConvert(col_name)
 		Tail(chars)
-		ToCSVFile(filename)
+		ToCSVFile(filename, options)
+		ToParquetFile(filename, options)
+		ToXLSXFile(filename, sheet)
+		TreeMap(title, subtitle, groupcols, agg, options)
 		Union(df2)
+		Unpivot(idCols, valueCols, varName, valueName)
 		Vertical(chars, record_count)
 		WriteSqlite(db_path, table_name, mode, key_cols)`
 	fmt.Println(help)
 	return help
 }
 
+// Help returns a help string listing the package-level functions.
+func Help() string {
+	help := `Functions Help:
+		Agg(aggs...)
+		And(conds...)
+		Col(name)
+		CollectList(col_name)
+		CollectSet(col_name)
+		Concat(delimiter, cols...)
+		CreateReport(title)
+		DenseRank()
+		DisplayChart(chart)
+		DisplayHTML(html)
+		GetAPI(endpoint, headers, query_params)
+		GetSqliteSchema(db_path, table)
+		GetSqliteTables(db_path)
+		If(condition, trueExpr, falseExpr)
+		Lag(col_name, offset, default)
+		Lead(col_name, offset, default)
+		Lit(value)
+		Or(conds...)
+		Rank()
+		ReadCSV(input, options)
+		ReadHTML(input)
+		ReadJSON(input)
+		ReadNDJSON(input)
+		ReadParquet(input)
+		ReadReportSpec(input)
+		ReadSqlite(db_path, table, query)
+		ReadXLSX(input, sheet)
+		ReadYAML(input)
+		RenderReport(spec, datasets)
+		RowNumber()
+		SHA256(cols...)
+		SHA512(cols...)
+		SQL(query, tables)
+		Sum(col_name)
+		UDF(fn, inputs...)
+		Window()
+		WriteXLSX(filename, sheets...)`
+	fmt.Println(help)
+	return help
+}
+
 type ColumnExpr struct {
 	Type      string          `json:"type"`
 	Name      string          `json:"name,omitempty"`
@@ -78,61 +148,157 @@ type ColumnExpr struct {
 	Suffix  interface{} `json:"suffix,omitempty"`
 	Pattern interface{} `json:"pattern,omitempty"`
 	Index   int         `json:"index,omitempty"`
+	Length  int         `json:"length,omitempty"` // Substr, LPad and RPad length
+	Count   int         `json:"count,omitempty"`  // Repeat and DateAdd count
+	Part    int         `json:"part,omitempty"`   // SplitPart 1-based part
+	Pad     string      `json:"pad,omitempty"`    // LPad and RPad pad string
+	From    string      `json:"from,omitempty"`   // Translate characters to replace
+	To      string      `json:"to,omitempty"`     // Translate replacement characters
 	// Add these for date functions
-	End    json.RawMessage `json:"end,omitempty"`    // For DateDiff (end date expression)
-	Start  json.RawMessage `json:"start,omitempty"`  // For DateDiff (start date expression)
-	Format string          `json:"format,omitempty"` // For DateDiff, ToEpoch, FromEpoch (date format string)
+	End     json.RawMessage `json:"end,omitempty"`     // For DateDiff (end date expression)
+	Start   json.RawMessage `json:"start,omitempty"`   // For DateDiff (start date expression) and Substr (1-based start)
+	Format  string          `json:"format,omitempty"`  // For DateDiff, ToEpoch, FromEpoch, FormatDate (date format string)
+	Formats []string        `json:"formats,omitempty"` // For ToDate, ToTimestamp (candidate formats)
+	Unit    string          `json:"unit,omitempty"`    // For DateTrunc, DateAdd ("year", "month", "day", ...)
+	Zone    string          `json:"zone,omitempty"`    // For FromUTC, ToUTC (IANA zone name)
 	// Add this for LLM Gen (arbitrary payload data)
 	Data json.RawMessage `json:"data,omitempty"` // For Gen (LLM config and inputs)
 }
 
 func (ce *ColumnExpr) Help() string {
 	help := `Column Help:
+    Abs()
+    Add(other)
+    Ceil()
     Contains(substr)
+    DateAdd(unit, n)
+    DateTrunc(unit)
+    Day()
+    DayOfWeek()
+    Div(other)
     EndsWith(suffix)
     Eq(other)
+    Exp()
+    Floor()
+    FormatDate(format)
+    FromUTC(zone)
     Ge(other)
     Gt(other)
+    Hour()
     HtmlUnescape()
+    InitCap()
     IsBetween(lower, upper)
     IsIn(values)
     IsNotNull()
     IsNull()
     Le(other)
+    Length()
+    Levenshtein(other)
     Like(pattern)
+    Log()
     Lower()
+    LPad(length, pad)
     Lt(other)
     LTrim()
+    Mod(other)
+    Month()
+    Mul(other)
     Ne(other)
     NotContains(substr)
     NotLike(pattern)
+    Pow(other)
+    Quarter()
+    Repeat(n)
     Replace(old, new)
+    Reverse()
+    Round(n)
+    RPad(length, pad)
     RTrim()
+    Soundex()
+    SplitPart(delimiter, part)
+    Sqrt()
     StartsWith(prefix)
+    Sub(other)
     Substr(start, length)
     Title()
+    ToDate(formats...)
+    ToTimestamp(formats...)
+    ToUTC(zone)
+    Translate(from, to)
     Trim()
-    Upper()`
+    Upper()
+    WeekOfYear()
+    Year()`
 	fmt.Println(help)
 	return help
 }
 
-// add other methods that modify the chart (no menu icon, no horizontal lines, highcharts vs apexcharts, colors, etc)?
+// Chart holds the HTML and Highcharts JS fragments that AddChart and DisplayChart
+// join around a div id. Use ChartOptions to change how a chart is drawn.
 type Chart struct {
 	Htmlpreid  string
 	Htmldivid  string
 	Htmlpostid string
 	Jspreid    string
 	Jspostid   string
+	Spec       *ChartSpec `json:",omitempty"` // how to rebuild the chart from filtered rows; nil when it can't be
+}
+
+// ChartSpec records how a chart builder turned its DataFrame into a chart, so
+// report filters (AddFilter) can rebuild the chart in the browser from the
+// filtered rows. Builders leave Chart.Spec nil for custom aggregations.
+type ChartSpec struct {
+	Kind    string         `json:"kind"`              // builder, e.g. "bar", "pie", "line", "scatter", "histogram"
+	Cols    []string       `json:"cols"`              // columns the builder reads, in argument order
+	Aggs    []ChartSpecAgg `json:"aggs,omitempty"`    // aggregations, for the grouped charts
+	Axis    string         `json:"axis,omitempty"`    // x axis kind for line/area/scatter/bubble: "datetime", "linear" or "category"
+	Bins    int            `json:"bins,omitempty"`    // Histogram bins argument
+	Options *ChartOptions  `json:"options,omitempty"` // options the builder was called with
+	Source  *DataFrame     `json:"source"`            // the rows the chart was built from
+}
+
+// ChartSpecAgg is a built-in aggregation in a ChartSpec.
+type ChartSpecAgg struct {
+	Fn     string `json:"fn" yaml:"fn"` // "sum", "max", "min", "mean", "median", "mode", "unique" or "first"
+	Column string `json:"column" yaml:"column"`
+	Name   string `json:"name,omitempty" yaml:"name,omitempty"` // output name (Alias), when it differs from Column
+}
+
+// ChartOptions customizes a chart builder's Highcharts config. Zero values keep the
+// chart's defaults; the pointer fields distinguish "not set" from off or 0.
+type ChartOptions struct {
+	// axis titles default to the column names; unset ranges let Highcharts pick
+	XAxisTitle string   `json:"x_axis_title,omitempty" yaml:"x_axis_title,omitempty"`
+	YAxisTitle string   `json:"y_axis_title,omitempty" yaml:"y_axis_title,omitempty"`
+	XMin       *float64 `json:"x_min,omitempty" yaml:"x_min,omitempty"`
+	XMax       *float64 `json:"x_max,omitempty" yaml:"x_max,omitempty"`
+	YMin       *float64 `json:"y_min,omitempty" yaml:"y_min,omitempty"`
+	YMax       *float64 `json:"y_max,omitempty" yaml:"y_max,omitempty"`
+
+	XLog            bool     `json:"x_log,omitempty" yaml:"x_log,omitempty"`                       // logarithmic x axis
+	YLog            bool     `json:"y_log,omitempty" yaml:"y_log,omitempty"`                       // logarithmic y axis
+	GridLines       *bool    `json:"grid_lines,omitempty" yaml:"grid_lines,omitempty"`             // grid lines on both axes
+	Palette         []string `json:"palette,omitempty" yaml:"palette,omitempty"`                   // series colors, e.g. ["#2563eb", "#f59e0b"]
+	Legend          string   `json:"legend,omitempty" yaml:"legend,omitempty"`                     // "top", "bottom", "left", "right" or "none"
+	DataLabels      *bool    `json:"data_labels,omitempty" yaml:"data_labels,omitempty"`           // values printed on points, bars and slices
+	Stacking        string   `json:"stacking,omitempty" yaml:"stacking,omitempty"`                 // "normal", "percent" or "none"
+	Exporting       *bool    `json:"exporting,omitempty" yaml:"exporting,omitempty"`               // the download/print menu (shown by default)
+	Height          int      `json:"height,omitempty" yaml:"height,omitempty"`                     // pixels
+	Width           int      `json:"width,omitempty" yaml:"width,omitempty"`                       // pixels
+	BackgroundColor string   `json:"background_color,omitempty" yaml:"background_color,omitempty"` // e.g. "transparent" or "#1f2937"
+	FontFamily      string   `json:"font_family,omitempty" yaml:"font_family,omitempty"`           // e.g. "Inter, sans-serif"
 }
 
 // AggregatorFn defines a function that aggregates a slice of values.
 type AggregatorFn func([]interface{}) interface{}
 
 // Aggregation holds a target column name and the aggregation function to apply.
+// OutputName (set with Alias) names the result column; it defaults to ColumnName.
 type Aggregation struct {
 	ColumnName string
 	Fn         AggregatorFn
+	OutputName string
+	kind       string // built-in function name, e.g. "sum"; empty for custom aggregations
 }
 
 type SimpleAggregation struct {
@@ -159,6 +325,27 @@ type Report struct {
 	Bottom        string
 	Pageshtml     map[string]map[string]string
 	Pagesjs       map[string]map[string]string
+	Layout        []ReportPage `json:",omitempty"` // pages and blocks in the order they were added; read by Spec and PDF
+}
+
+// ReportPage is a page of a Report and the blocks added to it.
+type ReportPage struct {
+	Name   string
+	Blocks []ReportBlock `json:",omitempty"`
+}
+
+// ReportBlock records the arguments of one Report Add call. Type is the
+// matching ReportBlockSpec type; the other fields are set as that type uses them.
+type ReportBlock struct {
+	Type   string
+	Text   string     `json:",omitempty"` // heading, text, subtext, html
+	Size   int        `json:",omitempty"` // heading
+	Items  []string   `json:",omitempty"` // bullets
+	Data   *DataFrame `json:",omitempty"` // dataframe
+	Chart  *Chart     `json:",omitempty"` // chart
+	ID     string     `json:",omitempty"` // chart element id
+	Column string     `json:",omitempty"` // filter
+	Kind   string     `json:",omitempty"` // filter
 }
 
 // Help returns a help string listing available Report methods.
@@ -167,12 +354,17 @@ func (report *Report) Help() string {
 		AddBullets(page, bullets)
 		AddChart(page, chart)
 		AddDataframe(page, df)
+		AddFilter(page, column, kind)
 		AddHeading(page, text, size)
 		AddHTML(page, text)
 		AddPage(name)
 		AddSubText(page, text)
 		AddText(page, text)
+		HTML(options)
 		Open()
+		PDF()
+		SavePDF(filename)
+		Spec()
         SetPrimary(color)
         SetSecondary(color)
         SetAccent(color)
@@ -182,7 +374,7 @@ func (report *Report) Help() string {
         SetSuccess(color)
         SetWarning(color)
         SetErr(color)
-		Save(filename)`
+		Save(filename, options)`
 	fmt.Println(help)
 	return help
 }
@@ -193,6 +385,10 @@ func (report *Report) Help() string {
 type Column struct {
 	Name string
 	Fn   func(row map[string]interface{}) interface{}
+
+	// date reports whether the column gives calendar dates when added to df
+	// (see DataFrame.Dates); nil means it does not.
+	date func(df *DataFrame) bool
 }
 
 type nodeInfo struct {
@@ -219,6 +415,92 @@ type ColumnSchema struct {
 	Nullable bool   `json:"nullable"`
 }
 
+// ParquetOptions configures ToParquetFile.
+type ParquetOptions struct {
+	Compression  string // "snappy" (default), "zstd", "gzip" or "none"
+	RowGroupSize int    // maximum rows per row group (0 uses the writer default)
+}
+
+// CSVOptions configures ReadCSV, ScanCSV and ToCSVFile. The zero value reads
+// and writes comma-separated UTF-8 with a header row, keeping values as strings.
+type CSVOptions struct {
+	Delimiter  string   `json:"delimiter"`   // field separator: "," (default), "\t", "|", ";"
+	NoHeader   bool     `json:"no_header"`   // no header row; columns are named column_1, column_2, ...
+	Comment    string   `json:"comment"`     // lines starting with this prefix are skipped when reading
+	NullValues []string `json:"null_values"` // tokens read as nil, e.g. "NA", "\\N"; the first is written for nil
+	InferTypes bool     `json:"infer_types"` // convert columns to int, float64, bool or time.Time when every value parses; batched scans widen a type (int to float64, else string) when a later batch needs it
+	LazyQuotes bool     `json:"lazy_quotes"` // allow quotes in unquoted fields and unescaped quotes in quoted fields
+	Encoding   string   `json:"encoding"`    // "utf-8" (default), "utf-16", "latin1", "windows-1252", "shift_jis", ...
+	LineEnding string   `json:"line_ending"` // "\r\n" (default) or "\n" when writing
+	TimeFormat string   `json:"time_format"` // format for time.Time values when writing, e.g. "dd/MM/yyyy"; ISO-8601 by default
+}
+
+// JSONOptions configures ToJSON, ToJSONFile and ToNDJSONFile.
+type JSONOptions struct {
+	TimeFormat string `json:"time_format"` // format for time.Time values, e.g. "yyyy-MM-dd HH:mm"; ISO-8601 by default
+}
+
+// SaveOptions controls how Report.Save (and DisplayBrowser/DisplayToFile) write HTML.
+type SaveOptions struct {
+	SVG     bool `json:"svg"`     // inline charts as SVG rendered in Go instead of Highcharts scripts
+	Offline bool `json:"offline"` // embed pinned copies of the CDN assets so the file works offline
+}
+
+// XLSXSheet is one worksheet for WriteXLSX.
+type XLSXSheet struct {
+	Name      string     `json:"name"` // 1 to 31 characters, none of []:*?/\
+	DataFrame *DataFrame `json:"dataframe"`
+}
+
+// ReportSpec is a structured, data-free report definition: pages of ordered
+// blocks whose tables and charts reference datasets by name. It round-trips
+// through JSON and YAML (ReadReportSpec, ToJSON, ToYAML), and RenderReport
+// builds the Report from it and a set of named DataFrames.
+type ReportSpec struct {
+	Title  string            `json:"title" yaml:"title"`
+	Colors map[string]string `json:"colors,omitempty" yaml:"colors,omitempty"` // primary, secondary, accent, neutral, base100, info, success, warning, error
+	Pages  []ReportPageSpec  `json:"pages" yaml:"pages"`
+}
+
+// ReportPageSpec is one page of a ReportSpec.
+type ReportPageSpec struct {
+	Name   string            `json:"name" yaml:"name"`
+	Blocks []ReportBlockSpec `json:"blocks" yaml:"blocks"`
+}
+
+// ReportBlockSpec is one block of a page. Type picks the Report method it is
+// rendered with and the fields it uses:
+//
+//	heading             Text, Size
+//	text, subtext, html Text
+//	bullets             Items
+//	dataframe           Dataset
+//	chart               Dataset, Chart
+//	filter              Column, Kind
+type ReportBlockSpec struct {
+	Type    string           `json:"type" yaml:"type"`
+	Text    string           `json:"text,omitempty" yaml:"text,omitempty"`
+	Size    int              `json:"size,omitempty" yaml:"size,omitempty"`
+	Items   []string         `json:"items,omitempty" yaml:"items,omitempty"`
+	Dataset string           `json:"dataset,omitempty" yaml:"dataset,omitempty"`
+	Chart   *ReportChartSpec `json:"chart,omitempty" yaml:"chart,omitempty"`
+	Column  string           `json:"column,omitempty" yaml:"column,omitempty"`
+	Kind    string           `json:"kind,omitempty" yaml:"kind,omitempty"`
+}
+
+// ReportChartSpec names a chart builder and its arguments. Cols are the builder's
+// column arguments in order, e.g. [x, y, group] for "scatter" or the group
+// columns for "treemap"; Aggs the aggregations of the grouped charts.
+type ReportChartSpec struct {
+	Kind     string         `json:"kind" yaml:"kind"` // bar, column, stackedbar, stackedpercent, pie, line, area, scatter, bubble, treemap, histogram, boxplot, heatmap or datatable
+	Title    string         `json:"title,omitempty" yaml:"title,omitempty"`
+	Subtitle string         `json:"subtitle,omitempty" yaml:"subtitle,omitempty"`
+	Cols     []string       `json:"cols,omitempty" yaml:"cols,omitempty"`
+	Aggs     []ChartSpecAgg `json:"aggs,omitempty" yaml:"aggs,omitempty"`
+	Bins     int            `json:"bins,omitempty" yaml:"bins,omitempty"`
+	Options  *ChartOptions  `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
 // LLM represents a connection to a Large Language Model provider.
 type LLM struct {
 	Provider string
@@ -0,0 +1,611 @@
+package gophers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Frame bounds for RowsBetween and RangeBetween.
+const (
+	UnboundedPreceding = math.MinInt
+	CurrentRow         = 0
+	UnboundedFollowing = math.MaxInt
+)
+
+// WindowSpec describes the rows a window function sees: the rows sharing the
+// current row's PartitionCols values, sorted by OrderCols and, for
+// aggregates, limited to Frame.
+type WindowSpec struct {
+	PartitionCols []string
+	OrderCols     []WindowOrder
+	Frame         *WindowFrame
+}
+
+// WindowOrder is one sort key of a window.
+type WindowOrder struct {
+	Column string
+	Asc    bool
+}
+
+// WindowFrame bounds an aggregate window relative to the current row. Start
+// and End count rows, or when Range is set they are distances on the single
+// order column (seconds for timestamp and date columns). Infinite bounds are
+// unbounded.
+type WindowFrame struct {
+	Range bool
+	Start float64
+	End   float64
+}
+
+// Window starts an empty window spec: a single partition in input order.
+// Usage: Window().PartitionBy("store").OrderBy("day", true)
+func Window() WindowSpec {
+	return WindowSpec{}
+}
+
+// PartitionBy splits the rows into independent windows by the given columns.
+func (w WindowSpec) PartitionBy(cols ...string) WindowSpec {
+	w.PartitionCols = append(append([]string(nil), w.PartitionCols...), cols...)
+	return w
+}
+
+// OrderBy sorts the rows of each partition; call it again to add tie breakers.
+func (w WindowSpec) OrderBy(column string, asc bool) WindowSpec {
+	w.OrderCols = append(append([]WindowOrder(nil), w.OrderCols...), WindowOrder{Column: column, Asc: asc})
+	return w
+}
+
+// RowsBetween limits aggregates to the rows from start to end relative to the
+// current row, e.g. RowsBetween(-6, CurrentRow) for a 7-row rolling window.
+func (w WindowSpec) RowsBetween(start, end int) WindowSpec {
+	s, _ := frameBound(start, math.Inf(-1))
+	e, _ := frameBound(end, math.Inf(1))
+	w.Frame = &WindowFrame{Start: s, End: e}
+	return w
+}
+
+// RangeBetween limits aggregates to the rows whose order value lies between
+// current+start and current+end. Bounds are numbers, time.Duration values for
+// timestamp or date columns, duration strings such as "-7d" or "-12h", or
+// UnboundedPreceding / UnboundedFollowing.
+func (w WindowSpec) RangeBetween(start, end interface{}) WindowSpec {
+	s, err := frameBound(start, math.Inf(-1))
+	if err != nil {
+		fmt.Printf("RangeBetween error: %v\n", err)
+		return w
+	}
+	e, err := frameBound(end, math.Inf(1))
+	if err != nil {
+		fmt.Printf("RangeBetween error: %v\n", err)
+		return w
+	}
+	w.Frame = &WindowFrame{Range: true, Start: s, End: e}
+	return w
+}
+
+// frameBound converts a frame bound to a float offset; nil and "unbounded"
+// map to unbounded.
+func frameBound(v interface{}, unbounded float64) (float64, error) {
+	switch b := v.(type) {
+	case nil:
+		return unbounded, nil
+	case int:
+		switch b {
+		case UnboundedPreceding:
+			return math.Inf(-1), nil
+		case UnboundedFollowing:
+			return math.Inf(1), nil
+		}
+		return float64(b), nil
+	case time.Duration:
+		return b.Seconds(), nil
+	case string:
+		s := strings.ToLower(strings.TrimSpace(b))
+		switch s {
+		case "unbounded":
+			return unbounded, nil
+		case "unbounded preceding":
+			return math.Inf(-1), nil
+		case "unbounded following":
+			return math.Inf(1), nil
+		case "current row", "current":
+			return 0, nil
+		}
+		if strings.HasSuffix(s, "d") {
+			days, err := strconv.ParseFloat(strings.TrimSuffix(s, "d"), 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid frame bound %q", b)
+			}
+			return days * 86400, nil
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid frame bound %q", b)
+		}
+		return d.Seconds(), nil
+	}
+	f, err := toFloat64(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid frame bound %v", v)
+	}
+	return f, nil
+}
+
+// WindowColumn is a window function bound to a WindowSpec. Pass it to
+// DataFrame.Column; build one with RowNumber, Rank, DenseRank, Lag, Lead or
+// Aggregation.Over.
+type WindowColumn struct {
+	Name   string
+	fn     string
+	col    string
+	offset int
+	def    interface{}
+	agg    Aggregation
+	spec   WindowSpec
+}
+
+// RowNumber numbers the rows of each partition from 1 in window order.
+func RowNumber() WindowColumn {
+	return WindowColumn{Name: "row_number()", fn: "row_number"}
+}
+
+// Rank ranks rows by the window order; ties share a rank and leave gaps (1, 1, 3).
+func Rank() WindowColumn {
+	return WindowColumn{Name: "rank()", fn: "rank"}
+}
+
+// DenseRank ranks rows by the window order without gaps after ties (1, 1, 2).
+func DenseRank() WindowColumn {
+	return WindowColumn{Name: "dense_rank()", fn: "dense_rank"}
+}
+
+// Lag returns the value of col offset rows before the current row in its
+// partition, or def (nil if omitted) when there is no such row.
+func Lag(col string, offset int, def ...interface{}) WindowColumn {
+	w := WindowColumn{Name: fmt.Sprintf("lag(%s, %d)", col, offset), fn: "lag", col: col, offset: offset}
+	if len(def) > 0 {
+		w.def = def[0]
+	}
+	return w
+}
+
+// Lead returns the value of col offset rows after the current row in its
+// partition, or def (nil if omitted) when there is no such row.
+func Lead(col string, offset int, def ...interface{}) WindowColumn {
+	w := WindowColumn{Name: fmt.Sprintf("lead(%s, %d)", col, offset), fn: "lead", col: col, offset: offset}
+	if len(def) > 0 {
+		w.def = def[0]
+	}
+	return w
+}
+
+// Over sets the window the function is evaluated in.
+func (w WindowColumn) Over(spec WindowSpec) WindowColumn {
+	w.spec = spec
+	return w
+}
+
+// Over turns the aggregation into a window aggregate. Without a frame it
+// covers the whole partition, or runs from the first row to the current row
+// when the window is ordered (cumulative sums and means). Nulls are skipped.
+// Usage: df.Column("running", Sum("amount").Over(Window().PartitionBy("id").OrderBy("day", true)))
+func (a Aggregation) Over(spec WindowSpec) WindowColumn {
+	name := a.kind
+	if name == "" {
+		name = "agg"
+	}
+	return WindowColumn{Name: fmt.Sprintf("%s(%s)", name, a.ColumnName), fn: "agg", col: a.ColumnName, agg: a, spec: spec}
+}
+
+// refs returns the columns the window function reads.
+func (w WindowColumn) refs() []string {
+	cols := append([]string(nil), w.spec.PartitionCols...)
+	for _, o := range w.spec.OrderCols {
+		cols = append(cols, o.Column)
+	}
+	if w.col != "" {
+		cols = append(cols, w.col)
+	}
+	return cols
+}
+
+// windowPayload is the JSON form of a window function carried in the Data
+// field of a ColumnExpr with type "window".
+type windowPayload struct {
+	Func        string      `json:"func"`
+	Col         string      `json:"col,omitempty"`
+	Offset      *int        `json:"offset,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+	PartitionBy []string    `json:"partition_by,omitempty"`
+	OrderBy     []struct {
+		Col string `json:"col"`
+		Asc *bool  `json:"asc,omitempty"`
+	} `json:"order_by,omitempty"`
+	Frame *struct {
+		Unit  string      `json:"unit"`
+		Start interface{} `json:"start"`
+		End   interface{} `json:"end"`
+	} `json:"frame,omitempty"`
+}
+
+// windowFromExpr decodes a "window" ColumnExpr, e.g.
+// {"type":"window","data":{"func":"sum","col":"x","partition_by":["g"],
+// "order_by":[{"col":"t","asc":true}],"frame":{"unit":"rows","start":-2,"end":0}}}
+func windowFromExpr(e ColumnExpr) (WindowColumn, error) {
+	var p windowPayload
+	if err := json.Unmarshal(e.Data, &p); err != nil {
+		return WindowColumn{}, fmt.Errorf("window: invalid data: %v", err)
+	}
+	offset := 1
+	if p.Offset != nil {
+		offset = *p.Offset
+	}
+
+	var w WindowColumn
+	switch fn := strings.ToLower(p.Func); fn {
+	case "row_number", "rownumber":
+		w = RowNumber()
+	case "rank":
+		w = Rank()
+	case "dense_rank", "denserank":
+		w = DenseRank()
+	case "lag":
+		w = Lag(p.Col, offset, p.Default)
+	case "lead":
+		w = Lead(p.Col, offset, p.Default)
+	default:
+		agg, ok := aggregationByName(fn, p.Col)
+		if !ok {
+			return WindowColumn{}, fmt.Errorf("window: unknown function %q", p.Func)
+		}
+		w = agg.Over(WindowSpec{})
+	}
+
+	spec := Window().PartitionBy(p.PartitionBy...)
+	for _, o := range p.OrderBy {
+		asc := true
+		if o.Asc != nil {
+			asc = *o.Asc
+		}
+		spec = spec.OrderBy(o.Col, asc)
+	}
+	if p.Frame != nil {
+		start, err := frameBound(p.Frame.Start, math.Inf(-1))
+		if err != nil {
+			return WindowColumn{}, fmt.Errorf("window: %v", err)
+		}
+		end, err := frameBound(p.Frame.End, math.Inf(1))
+		if err != nil {
+			return WindowColumn{}, fmt.Errorf("window: %v", err)
+		}
+		spec.Frame = &WindowFrame{Range: strings.EqualFold(p.Frame.Unit, "range"), Start: start, End: end}
+	}
+	return w.Over(spec), nil
+}
+
+// windowColumn evaluates wc and stores the result as column name.
+func (df *DataFrame) windowColumn(name string, wc WindowColumn) *DataFrame {
+	values, err := df.windowValues(wc)
+	if err != nil {
+		fmt.Printf("Column error: %v\n", err)
+		return df
+	}
+	df.setDate(name, df.Dates[wc.col] && (wc.fn == "lag" || wc.fn == "lead" || wc.fn == "agg" && wc.agg.keepsDate()))
+	df.Data[name] = values
+	for _, c := range df.Cols {
+		if c == name {
+			return df
+		}
+	}
+	df.Cols = append(df.Cols, name)
+	return df
+}
+
+// windowEval holds the typed inputs shared by all partitions of one window column.
+type windowEval struct {
+	wc     WindowColumn
+	orders []*typedColumn
+	asc    []bool
+	src    []interface{} // input column of lag/lead and aggregates
+	keys   []float64     // range frame order values, negated for descending order
+	keyOK  []bool
+	out    []interface{}
+}
+
+// windowValues computes a window column. Partitions are evaluated in parallel.
+func (df *DataFrame) windowValues(wc WindowColumn) ([]interface{}, error) {
+	lookup := func(name string) (*typedColumn, error) {
+		tc := df.typedColumn(name)
+		if tc == nil {
+			return nil, fmt.Errorf("window: column %q not found", name)
+		}
+		return tc, nil
+	}
+	e := &windowEval{wc: wc, out: make([]interface{}, df.Rows)}
+	keys := make([]*typedColumn, len(wc.spec.PartitionCols))
+	for i, c := range wc.spec.PartitionCols {
+		tc, err := lookup(c)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = tc
+	}
+	for _, o := range wc.spec.OrderCols {
+		tc, err := lookup(o.Column)
+		if err != nil {
+			return nil, err
+		}
+		e.orders = append(e.orders, tc)
+		e.asc = append(e.asc, o.Asc)
+	}
+	switch wc.fn {
+	case "row_number", "rank", "dense_rank":
+	default:
+		tc, err := lookup(wc.col)
+		if err != nil {
+			return nil, err
+		}
+		e.src = tc.boxed
+	}
+	if f := wc.spec.Frame; f != nil && f.Range {
+		if len(e.orders) != 1 {
+			return nil, fmt.Errorf("window: RangeBetween needs exactly one OrderBy column")
+		}
+		e.rangeKeys()
+	}
+
+	groups := groupRows(keys, df.Rows)
+	var next int64
+	var wg sync.WaitGroup
+	for g := 0; g < runtime.GOMAXPROCS(0); g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1)) - 1
+				if i >= len(groups) {
+					return
+				}
+				e.partition(groups[i].rows)
+			}
+		}()
+	}
+	wg.Wait()
+	return e.out, nil
+}
+
+// rangeKeys loads the order column as floats for range frames: numbers as
+// is, timestamps and date strings as Unix seconds.
+func (e *windowEval) rangeKeys() {
+	tc := e.orders[0]
+	e.keys = make([]float64, tc.n)
+	e.keyOK = make([]bool, tc.n)
+	for i := 0; i < tc.n; i++ {
+		switch tc.kind {
+		case kindInt64, kindFloat64:
+			e.keys[i], e.keyOK[i] = tc.float(i)
+		case kindTimestamp:
+			if tc.isValid(i) {
+				e.keys[i], e.keyOK[i] = float64(tc.times[i].UnixNano())/1e9, true
+			}
+		case kindString:
+			if t, ok := parseTimeAny(tc.strs[i]); ok && tc.isValid(i) {
+				e.keys[i], e.keyOK[i] = float64(t.UnixNano())/1e9, true
+			}
+		}
+		if !e.asc[0] {
+			e.keys[i] = -e.keys[i]
+		}
+	}
+}
+
+// parseTimeAny parses common date and timestamp layouts.
+func parseTimeAny(s string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// cmp orders two rows by the window's order columns.
+func (e *windowEval) cmp(i, j int) int {
+	for k, o := range e.orders {
+		c := o.compare(i, j)
+		if !e.asc[k] {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// partition evaluates the window function over one partition's rows.
+func (e *windowEval) partition(rows []int) {
+	if len(e.orders) > 0 {
+		sort.SliceStable(rows, func(a, b int) bool { return e.cmp(rows[a], rows[b]) < 0 })
+	}
+	switch e.wc.fn {
+	case "row_number":
+		for p, r := range rows {
+			e.out[r] = p + 1
+		}
+	case "rank", "dense_rank":
+		rank, dense := 0, 0
+		for p, r := range rows {
+			if p == 0 || e.cmp(rows[p-1], r) != 0 {
+				rank = p + 1
+				dense++
+			}
+			if e.wc.fn == "rank" {
+				e.out[r] = rank
+			} else {
+				e.out[r] = dense
+			}
+		}
+	case "lag", "lead":
+		off := e.wc.offset
+		if e.wc.fn == "lag" {
+			off = -off
+		}
+		for p, r := range rows {
+			if q := p + off; q >= 0 && q < len(rows) {
+				e.out[r] = e.src[rows[q]]
+			} else {
+				e.out[r] = e.wc.def
+			}
+		}
+	default:
+		e.aggregate(rows)
+	}
+}
+
+// bounds returns the frame [lo, hi) of position p within the sorted partition.
+func (e *windowEval) bounds(rows []int, p, validLo, validHi int) (int, int) {
+	m := len(rows)
+	f := e.wc.spec.Frame
+	switch {
+	case f == nil && len(e.orders) == 0:
+		return 0, m
+	case f == nil:
+		return 0, p + 1
+	case !f.Range:
+		lo, hi := 0, m
+		if !math.IsInf(f.Start, -1) {
+			lo = clampInt(p+int(f.Start), 0, m)
+		}
+		if !math.IsInf(f.End, 1) {
+			hi = clampInt(p+int(f.End)+1, lo, m)
+		}
+		return lo, hi
+	}
+	lo, hi := 0, m
+	if !e.keyOK[rows[p]] {
+		// null order values are peers of each other only
+		if validLo > 0 {
+			lo, hi = 0, validLo
+		} else {
+			lo, hi = validHi, m
+		}
+	} else {
+		k := e.keys[rows[p]]
+		valid := rows[validLo:validHi]
+		lo = validLo + sort.Search(len(valid), func(i int) bool { return e.keys[valid[i]] >= k+f.Start })
+		hi = validLo + sort.Search(len(valid), func(i int) bool { return e.keys[valid[i]] > k+f.End })
+	}
+	if math.IsInf(f.Start, -1) {
+		lo = 0
+	}
+	if math.IsInf(f.End, 1) {
+		hi = m
+	}
+	return lo, clampInt(hi, lo, m)
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// aggregate evaluates an aggregation over each row's frame. Sum and Mean use
+// prefix sums and Min/Max extend growing frames incrementally; other
+// aggregations are applied to the frame's non-null values.
+func (e *windowEval) aggregate(rows []int) {
+	m := len(rows)
+	validLo, validHi := 0, m
+	if e.keyOK != nil {
+		for validLo < m && !e.keyOK[rows[validLo]] {
+			validLo++
+		}
+		for validHi > validLo && !e.keyOK[rows[validHi-1]] {
+			validHi--
+		}
+	}
+
+	kind := e.wc.agg.kind
+	switch kind {
+	case "sum", "mean":
+		sums := make([]float64, m+1)
+		counts := make([]int, m+1)
+		for p, r := range rows {
+			sums[p+1], counts[p+1] = sums[p], counts[p]
+			if f, err := toFloat64(e.src[r]); err == nil {
+				sums[p+1] += f
+				counts[p+1]++
+			}
+		}
+		for p, r := range rows {
+			lo, hi := e.bounds(rows, p, validLo, validHi)
+			sum := sums[hi] - sums[lo]
+			if lo == 0 {
+				sum = sums[hi]
+			}
+			switch {
+			case kind == "sum":
+				e.out[r] = sum
+			case counts[hi] == counts[lo]:
+				e.out[r] = nil
+			default:
+				e.out[r] = sum / float64(counts[hi]-counts[lo])
+			}
+		}
+	case "min", "max":
+		prevLo, prevHi := -1, -1
+		var best float64
+		found := false
+		better := func(f float64) bool { return !found || (kind == "min" && f < best) || (kind == "max" && f > best) }
+		for p, r := range rows {
+			lo, hi := e.bounds(rows, p, validLo, validHi)
+			from := prevHi
+			if lo != prevLo || hi < prevHi {
+				from, found = lo, false
+			}
+			for q := from; q < hi; q++ {
+				if f, err := toFloat64(e.src[rows[q]]); err == nil && better(f) {
+					best, found = f, true
+				}
+			}
+			prevLo, prevHi = lo, hi
+			if found {
+				e.out[r] = best
+			} else {
+				e.out[r] = nil
+			}
+		}
+	default:
+		vals := make([]interface{}, 0, m)
+		prevLo, prevHi := -1, -1
+		var prev interface{}
+		for p, r := range rows {
+			lo, hi := e.bounds(rows, p, validLo, validHi)
+			if lo == prevLo && hi == prevHi {
+				e.out[r] = prev // same frame as the previous row, e.g. an unordered window
+				continue
+			}
+			vals = vals[:0]
+			for _, q := range rows[lo:hi] {
+				if v := e.src[q]; v != nil {
+					vals = append(vals, v)
+				}
+			}
+			prev, prevLo, prevHi = e.wc.agg.Fn(vals), lo, hi
+			e.out[r] = prev
+		}
+	}
+}
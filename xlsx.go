@@ -0,0 +1,550 @@
+package gophers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// cellXfs indexes in xlsxStyles.
+const (
+	xlsxStyleHeader   = 1
+	xlsxStyleDate     = 2
+	xlsxStyleDateTime = 3
+)
+
+// xlsxStyles has a bold, filled and underlined header style and date/datetime formats.
+const xlsxStyles = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd"/><numFmt numFmtId="165" formatCode="yyyy\-mm\-dd\ hh:mm:ss"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/><family val="2"/></font><font><b/><sz val="11"/><name val="Calibri"/><family val="2"/></font></fonts>
+<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill><fill><patternFill patternType="solid"><fgColor rgb="FFD9E1F2"/><bgColor indexed="64"/></patternFill></fill></fills>
+<borders count="2"><border><left/><right/><top/><bottom/><diagonal/></border><border><left/><right/><top/><bottom style="thin"><color rgb="FF8EA9DB"/></bottom><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="4"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="2" borderId="1" xfId="0" applyFont="1" applyFill="1" applyBorder="1"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>
+<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>
+</styleSheet>`
+
+// validSheetName checks the Excel rules for worksheet names.
+func validSheetName(name string) error {
+	if name == "" || utf8.RuneCountInString(name) > 31 {
+		return fmt.Errorf("sheet name %q must be 1 to 31 characters", name)
+	}
+	if strings.ContainsAny(name, `[]:*?/\`) || strings.HasPrefix(name, "'") || strings.HasSuffix(name, "'") {
+		return fmt.Errorf("sheet name %q contains a character Excel does not allow", name)
+	}
+	return nil
+}
+
+// xlsxColumn returns the column letters for a 0-based column index (0 -> A, 26 -> AA).
+func xlsxColumn(i int) string {
+	s := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		s = string(rune('A'+(i-1)%26)) + s
+	}
+	return s
+}
+
+// xlsxCellIndex returns the 0-based column and row of a cell reference such as "C12".
+func xlsxCellIndex(ref string) (int, int, bool) {
+	col, i := 0, 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		col = col*26 + int(ref[i]-'A'+1)
+	}
+	row, err := strconv.Atoi(ref[i:])
+	if i == 0 || err != nil || row < 1 {
+		return 0, 0, false
+	}
+	return col - 1, row - 1, true
+}
+
+// xlsxEscape escapes text for XML, dropping characters XML 1.0 cannot hold.
+func xlsxEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r == '\t' || r == '\n' || r == '\r' || (r >= 0x20 && r != 0xFFFE && r != 0xFFFF) {
+			b.WriteRune(r)
+		}
+	}
+	var out bytes.Buffer
+	xml.EscapeText(&out, []byte(b.String()))
+	return out.String()
+}
+
+// xlsxEpoch is day zero of Excel's 1900 date system.
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// xlsxSerial converts a time to an Excel date serial, keeping its wall clock.
+func xlsxSerial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(xlsxEpoch).Seconds() / 86400
+}
+
+// xlsxCell writes one typed cell; nil and non-finite values are left empty.
+// A time in a date column (see DataFrame.Dates) gets the date format.
+func xlsxCell(b *strings.Builder, ref string, v interface{}, date bool) {
+	switch t := v.(type) {
+	case nil:
+	case bool:
+		n := 0
+		if t {
+			n = 1
+		}
+		fmt.Fprintf(b, `<c r="%s" t="b"><v>%d</v></c>`, ref, n)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		f, _ := toFloat64(t)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return
+		}
+		fmt.Fprintf(b, `<c r="%s"><v>%s</v></c>`, ref, fastToString(t))
+	case time.Time:
+		style := xlsxStyleDateTime
+		if date {
+			style = xlsxStyleDate
+		}
+		fmt.Fprintf(b, `<c r="%s" s="%d"><v>%s</v></c>`, ref, style, strconv.FormatFloat(xlsxSerial(t), 'f', -1, 64))
+	default:
+		xlsxString(b, ref, xlsxText(t), 0)
+	}
+}
+
+// xlsxString writes an inline string cell.
+func xlsxString(b *strings.Builder, ref string, s string, style int) {
+	space := ""
+	if strings.TrimSpace(s) != s {
+		space = ` xml:space="preserve"`
+	}
+	styleAttr := ""
+	if style != 0 {
+		styleAttr = fmt.Sprintf(` s="%d"`, style)
+	}
+	fmt.Fprintf(b, `<c r="%s" t="inlineStr"%s><is><t%s>%s</t></is></c>`, ref, styleAttr, space, xlsxEscape(s))
+}
+
+// xlsxText formats a string cell; arrays and maps are written as JSON.
+func xlsxText(v interface{}) string {
+	switch v.(type) {
+	case []interface{}, map[string]interface{}:
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+	}
+	return fastToString(v)
+}
+
+// xlsxWorksheet renders a DataFrame as a worksheet: a styled, frozen header row
+// with an autofilter, then one typed row per DataFrame row.
+func xlsxWorksheet(df *DataFrame) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+
+	// widths from the header and the first rows
+	if len(df.Cols) > 0 {
+		b.WriteString("<cols>")
+		for j, col := range df.Cols {
+			width := utf8.RuneCountInString(col) + 4
+			vals := df.Data[col]
+			for i := 0; i < len(vals) && i < 1000; i++ {
+				n := 0
+				switch t := vals[i].(type) {
+				case nil:
+				case time.Time:
+					n = 19
+					if df.Dates[col] {
+						n = 10
+					}
+				default:
+					n = utf8.RuneCountInString(xlsxText(t))
+				}
+				if n+2 > width {
+					width = n + 2
+				}
+			}
+			if width > 60 {
+				width = 60
+			}
+			fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, j+1, j+1, width)
+		}
+		b.WriteString("</cols>")
+	}
+
+	b.WriteString("<sheetData>")
+	b.WriteString(`<row r="1">`)
+	for j, col := range df.Cols {
+		xlsxString(&b, xlsxColumn(j)+"1", col, xlsxStyleHeader)
+	}
+	b.WriteString("</row>")
+	letters := make([]string, len(df.Cols))
+	for j := range df.Cols {
+		letters[j] = xlsxColumn(j)
+	}
+	for i := 0; i < df.Rows; i++ {
+		row := strconv.Itoa(i + 2)
+		fmt.Fprintf(&b, `<row r="%s">`, row)
+		for j, col := range df.Cols {
+			if vals := df.Data[col]; i < len(vals) {
+				xlsxCell(&b, letters[j]+row, vals[i], df.Dates[col])
+			}
+		}
+		b.WriteString("</row>")
+	}
+	b.WriteString("</sheetData>")
+	if len(df.Cols) > 0 {
+		fmt.Fprintf(&b, `<autoFilter ref="A1:%s%d"/>`, xlsxColumn(len(df.Cols)-1), df.Rows+1)
+	}
+	b.WriteString("</worksheet>")
+	return b.String()
+}
+
+// writeXLSX writes the sheets, in order, as a workbook.
+func writeXLSX(w io.Writer, sheets []XLSXSheet) error {
+	zw := zip.NewWriter(w)
+	put := func(name string, content string) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(f, content)
+		return err
+	}
+
+	var types, workbook, rels strings.Builder
+	types.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	rels.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+	for i, sheet := range sheets {
+		fmt.Fprintf(&types, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xlsxEscape(sheet.Name), i+1, i+2)
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+2, i+1)
+	}
+	types.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	rels.WriteString(`</Relationships>`)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", types.String()},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", rels.String()},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, p := range parts {
+		if err := put(p.name, p.content); err != nil {
+			return err
+		}
+	}
+	for i, sheet := range sheets {
+		if err := put(fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), xlsxWorksheet(sheet.DataFrame)); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// xlsxWorkbook is an opened workbook: its sheets in order and what is needed to decode cells.
+type xlsxWorkbook struct {
+	files   map[string]*zip.File
+	names   []string
+	targets map[string]string // sheet name -> worksheet part
+	strings []string
+	dates   map[int]bool // cellXfs index -> date format
+	days    map[int]bool // cellXfs index -> date format without a time of day
+	epoch   time.Time
+}
+
+// xlsxRichText is a string item: plain text or rich text runs.
+type xlsxRichText struct {
+	T string `xml:"t"`
+	R []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s xlsxRichText) text() string {
+	if len(s.R) == 0 {
+		return s.T
+	}
+	var b strings.Builder
+	for _, r := range s.R {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+// openXLSX reads the workbook index, shared strings and date styles of an xlsx archive.
+func openXLSX(zr *zip.Reader) (*xlsxWorkbook, error) {
+	wb := &xlsxWorkbook{files: map[string]*zip.File{}, targets: map[string]string{}, dates: map[int]bool{}, days: map[int]bool{}, epoch: xlsxEpoch}
+	for _, f := range zr.File {
+		wb.files[f.Name] = f
+	}
+
+	var workbook struct {
+		Pr struct {
+			Date1904 string `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := wb.decode("xl/workbook.xml", &workbook); err != nil {
+		return nil, err
+	}
+	if workbook.Pr.Date1904 == "1" || workbook.Pr.Date1904 == "true" {
+		wb.epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	var rels struct {
+		Rels []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := wb.decode("xl/_rels/workbook.xml.rels", &rels); err != nil {
+		return nil, err
+	}
+	byID := map[string]string{}
+	for _, r := range rels.Rels {
+		target := strings.TrimPrefix(r.Target, "/")
+		if !strings.HasPrefix(r.Target, "/") {
+			target = path.Join("xl", r.Target)
+		}
+		byID[r.ID] = target
+	}
+	for _, s := range workbook.Sheets {
+		wb.names = append(wb.names, s.Name)
+		wb.targets[s.Name] = byID[s.ID]
+	}
+
+	if _, ok := wb.files["xl/sharedStrings.xml"]; ok {
+		var sst struct {
+			SI []xlsxRichText `xml:"si"`
+		}
+		if err := wb.decode("xl/sharedStrings.xml", &sst); err != nil {
+			return nil, err
+		}
+		for _, si := range sst.SI {
+			wb.strings = append(wb.strings, si.text())
+		}
+	}
+
+	if _, ok := wb.files["xl/styles.xml"]; ok {
+		var styles struct {
+			NumFmts []struct {
+				ID   int    `xml:"numFmtId,attr"`
+				Code string `xml:"formatCode,attr"`
+			} `xml:"numFmts>numFmt"`
+			Xfs []struct {
+				NumFmtID int `xml:"numFmtId,attr"`
+			} `xml:"cellXfs>xf"`
+		}
+		if err := wb.decode("xl/styles.xml", &styles); err != nil {
+			return nil, err
+		}
+		custom, clock := map[int]bool{}, map[int]bool{}
+		for _, f := range styles.NumFmts {
+			custom[f.ID], clock[f.ID] = xlsxDateFormat(f.Code)
+		}
+		for i, xf := range styles.Xfs {
+			id := xf.NumFmtID
+			wb.dates[i] = (id >= 14 && id <= 22) || (id >= 45 && id <= 47) || custom[id]
+			wb.days[i] = (id >= 14 && id <= 17) || (custom[id] && !clock[id])
+		}
+	}
+	return wb, nil
+}
+
+// decode unmarshals an XML part of the archive.
+func (wb *xlsxWorkbook) decode(name string, v interface{}) error {
+	f, ok := wb.files[name]
+	if !ok {
+		return fmt.Errorf("missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
+// xlsxDateFormat reports whether a custom number format displays a date or
+// time, and whether it shows a time of day.
+func xlsxDateFormat(code string) (date, clock bool) {
+	quoted, bracket := false, false
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '\\':
+			i++
+		case c == '[':
+			bracket = true
+		case c == ']':
+			bracket = false
+		case bracket:
+		case strings.IndexByte("dmyhsDMYHS", c) >= 0:
+			date = true
+			clock = clock || strings.IndexByte("hsHS", c) >= 0
+		}
+	}
+	return date, clock
+}
+
+// sheet decodes a worksheet into a DataFrame, taking column names from the first row.
+func (wb *xlsxWorkbook) sheet(name string) (*DataFrame, error) {
+	var ws struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				R  string       `xml:"r,attr"`
+				T  string       `xml:"t,attr"`
+				S  int          `xml:"s,attr"`
+				V  string       `xml:"v"`
+				IS xlsxRichText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := wb.decode(wb.targets[name], &ws); err != nil {
+		return nil, err
+	}
+
+	// cells by row and column; days marks the times with a date-only format
+	grid := map[int]map[int]interface{}{}
+	days := map[int]map[int]bool{}
+	minRow, maxRow, maxCol := -1, -1, -1
+	next := 0
+	for _, row := range ws.Rows {
+		r := row.R - 1
+		if row.R == 0 {
+			r = next
+		}
+		next = r + 1
+		for c, cell := range row.Cells {
+			col := c
+			if cell.R != "" {
+				cc, rr, ok := xlsxCellIndex(cell.R)
+				if !ok {
+					return nil, fmt.Errorf("invalid cell reference %q", cell.R)
+				}
+				col, r = cc, rr
+			}
+			v, err := wb.value(cell.T, cell.S, cell.V, cell.IS)
+			if err != nil {
+				return nil, fmt.Errorf("cell %s%d: %v", xlsxColumn(col), r+1, err)
+			}
+			if v == nil {
+				continue
+			}
+			if grid[r] == nil {
+				grid[r] = map[int]interface{}{}
+			}
+			grid[r][col] = v
+			if _, ok := v.(time.Time); ok && wb.days[cell.S] {
+				if days[r] == nil {
+					days[r] = map[int]bool{}
+				}
+				days[r][col] = true
+			}
+			if minRow < 0 || r < minRow {
+				minRow = r
+			}
+			if r > maxRow {
+				maxRow = r
+			}
+			if col > maxCol {
+				maxCol = col
+			}
+		}
+	}
+
+	df := &DataFrame{Data: map[string][]interface{}{}}
+	if minRow < 0 {
+		return df, nil
+	}
+	seen := map[string]bool{}
+	for j := 0; j <= maxCol; j++ {
+		name := strings.TrimSpace(fastToString(grid[minRow][j]))
+		if grid[minRow][j] == nil || name == "" {
+			name = fmt.Sprintf("column_%d", j+1)
+		}
+		name = uniqueName(seen, name)
+		df.Cols = append(df.Cols, name)
+		col := make([]interface{}, maxRow-minRow)
+		seenTime, date := false, true
+		for i := range col {
+			col[i] = grid[minRow+1+i][j]
+			if _, ok := col[i].(time.Time); ok {
+				seenTime = true
+				date = date && days[minRow+1+i][j]
+			}
+		}
+		df.Data[name] = col
+		df.setDate(name, seenTime && date)
+	}
+	df.Rows = maxRow - minRow
+	return df, nil
+}
+
+// value converts a cell to a Go value: string, bool, int, float64 or time.Time
+// for numbers with a date format. Error cells (#N/A, #DIV/0!) are nil.
+func (wb *xlsxWorkbook) value(t string, style int, v string, is xlsxRichText) (interface{}, error) {
+	switch t {
+	case "inlineStr":
+		return is.text(), nil
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || i < 0 || i >= len(wb.strings) {
+			return nil, fmt.Errorf("invalid shared string index %q", v)
+		}
+		return wb.strings[i], nil
+	case "str":
+		return v, nil
+	case "b":
+		return strings.TrimSpace(v) == "1" || strings.TrimSpace(v) == "true", nil
+	case "e":
+		return nil, nil
+	}
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	if t == "d" {
+		ts, err := time.Parse("2006-01-02T15:04:05", strings.TrimSuffix(v, "Z"))
+		if err != nil {
+			return nil, err
+		}
+		return ts, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", v)
+	}
+	if wb.dates[style] {
+		ms := math.Round(f * 86400000)
+		return wb.epoch.Add(time.Duration(ms) * time.Millisecond), nil
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int(f), nil
+	}
+	return f, nil
+}